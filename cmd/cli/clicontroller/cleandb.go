@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/minio/minio-go/v7"
+	"time"
 )
 
 func CleanDB(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, minio *minio.Client) {
@@ -48,6 +49,53 @@ func CleanDB(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, minio *m
 		return
 	}
 
+	driveUseCase := ucase.NewDriveUseCase(repos)
+	purged, err := driveUseCase.PurgeTrash(ctx, cfg.Drive.SavePath, time.Duration(cfg.Drive.TrashRetentionDays)*24*time.Hour)
+	if err != nil {
+		fmt.Printf("Error purge drive trash: %v", err)
+		logging.GetLogger(ctx).Errorf("Error purge drive trash: %v", err)
+		return
+	}
+	fmt.Printf("drive trash: purged %d items\n", purged)
+	logging.GetLogger(ctx).Printf("drive trash: purged %d items", purged)
+
+	pruned, err := driveUseCase.PruneVersions(
+		ctx,
+		cfg.Drive.SavePath,
+		cfg.Drive.VersionsMaxCount,
+		time.Duration(cfg.Drive.VersionsMaxAgeDays)*24*time.Hour,
+	)
+	if err != nil {
+		fmt.Printf("Error prune drive file versions: %v", err)
+		logging.GetLogger(ctx).Errorf("Error prune drive file versions: %v", err)
+		return
+	}
+	fmt.Printf("drive versions: pruned %d versions\n", pruned)
+	logging.GetLogger(ctx).Printf("drive versions: pruned %d versions", pruned)
+
+	reaped, reclaimed, err := driveUseCase.ReapUploads(
+		ctx,
+		cfg.Drive.SavePath,
+		time.Duration(cfg.Drive.UploadTTLHours)*time.Hour,
+	)
+	if err != nil {
+		fmt.Printf("Error reap abandoned drive uploads: %v", err)
+		logging.GetLogger(ctx).Errorf("Error reap abandoned drive uploads: %v", err)
+		return
+	}
+	fmt.Printf("drive uploads: removed %d abandoned uploads, reclaimed %d bytes\n", reaped, reclaimed)
+	logging.GetLogger(ctx).Printf("drive uploads: removed %d abandoned uploads, reclaimed %d bytes", reaped, reclaimed)
+
+	pendingObjectUseCase := ucase.NewPendingObjectUseCase(repos)
+	abandoned, err := pendingObjectUseCase.ReapAbandoned(ctx, time.Duration(cfg.StoragePendingTTLHours)*time.Hour)
+	if err != nil {
+		fmt.Printf("Error reap abandoned storage objects: %v", err)
+		logging.GetLogger(ctx).Errorf("Error reap abandoned storage objects: %v", err)
+		return
+	}
+	fmt.Printf("storage: removed %d abandoned objects\n", abandoned)
+	logging.GetLogger(ctx).Printf("storage: removed %d abandoned objects", abandoned)
+
 	rateLimiterUseCase := ucase.NewRateLimiterUseCase(repos)
 	err = rateLimiterUseCase.Clean(ctx)
 	if err != nil {
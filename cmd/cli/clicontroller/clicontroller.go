@@ -6,6 +6,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/minio/minio-go/v7"
 	"github.com/spf13/cobra"
+	"time"
 )
 
 func InitCliCommands(rootCmd *cobra.Command, ctx context.Context, cfg *config.Config, db *pgxpool.Pool, minio *minio.Client) {
@@ -35,4 +36,111 @@ func InitCliCommands(rootCmd *cobra.Command, ctx context.Context, cfg *config.Co
 			password := args[1]
 			UserRegister(ctx, cfg, db, minio, login, password)
 		}})
+
+	var filesQuota string
+	userSetQuotaCmd := &cobra.Command{
+		Use:   "user-set-quota <login> <bytes|default>",
+		Short: "Set the drive storage quota for a user, \"default\" returns to the plan or config limits",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			UserSetQuota(ctx, cfg, db, minio, args[0], args[1], filesQuota)
+		}}
+	userSetQuotaCmd.Flags().StringVar(&filesQuota, "files", "", "quota for note attachments in bytes")
+	rootCmd.AddCommand(userSetQuotaCmd)
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "user-set-plan <login> <plan|default>",
+		Short: "Assign a storage plan to a user, \"default\" removes the plan",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			UserSetPlan(ctx, cfg, db, minio, args[0], args[1])
+		}})
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "plan-save <name> <drive-bytes> <files-bytes>",
+		Short: "Create or update a storage plan",
+		Args:  cobra.ExactArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			PlanSave(ctx, cfg, db, minio, args[0], args[1], args[2])
+		}})
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "plan-list",
+		Short: "List storage plans",
+		Run: func(cmd *cobra.Command, args []string) {
+			PlanList(ctx, cfg, db, minio)
+		}})
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "recalculate-usage [login]",
+		Short: "Rebuild storage usage counters from stored files, for one user or for all users",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var login *string
+			if len(args) > 0 {
+				login = &args[0]
+			}
+			RecalculateUsage(ctx, cfg, db, minio, login)
+		}})
+
+	var (
+		migrateFrom         string
+		migrateTo           string
+		migrateDeleteSource bool
+		migrateRestart      bool
+	)
+	storageMigrateCmd := &cobra.Command{
+		Use:   "storage-migrate --from <local|s3> --to <local|s3>",
+		Short: "Copy all stored files to another storage and verify them, interrupted runs continue where they stopped",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			StorageMigrate(ctx, cfg, db, minio, migrateFrom, migrateTo, migrateDeleteSource, migrateRestart)
+		}}
+	storageMigrateCmd.Flags().StringVar(&migrateFrom, "from", "", "source storage: local or s3")
+	storageMigrateCmd.Flags().StringVar(&migrateTo, "to", "", "target storage: local or s3")
+	storageMigrateCmd.Flags().BoolVar(&migrateDeleteSource, "delete-source", false, "delete source objects after verification")
+	storageMigrateCmd.Flags().BoolVar(&migrateRestart, "restart", false, "ignore saved progress and check all objects again")
+	_ = storageMigrateCmd.MarkFlagRequired("from")
+	_ = storageMigrateCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(storageMigrateCmd)
+
+	var (
+		fsckRepair bool
+		fsckNoHash bool
+		fsckMinAge time.Duration
+	)
+	storageFsckCmd := &cobra.Command{
+		Use:   "storage-fsck",
+		Short: "Cross-check stored files with the database and report missing, orphaned and damaged objects",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			StorageFsck(ctx, cfg, db, minio, fsckRepair, !fsckNoHash, fsckMinAge)
+		}}
+	storageFsckCmd.Flags().BoolVar(&fsckRepair, "repair", false, "move orphaned objects to the quarantine directory")
+	storageFsckCmd.Flags().BoolVar(&fsckNoHash, "no-hash", false, "skip re-reading objects to verify SHA-256")
+	storageFsckCmd.Flags().DurationVar(&fsckMinAge, "min-age", 24*time.Hour, "ignore unreferenced objects newer than this, uploads may still be in progress")
+	rootCmd.AddCommand(storageFsckCmd)
+
+	var (
+		rotateFull   bool
+		rotateResume bool
+	)
+	driveRotateKeyCmd := &cobra.Command{
+		Use:   "drive-rotate-key",
+		Short: "Rewrap drive data keys with the current master key, with --full also re-encrypt all drive files",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			DriveRotateKey(ctx, cfg, db, minio, rotateFull, rotateResume)
+		}}
+	driveRotateKeyCmd.Flags().BoolVar(&rotateFull, "full", false, "replace data keys and re-encrypt every drive file")
+	driveRotateKeyCmd.Flags().BoolVar(&rotateResume, "resume", false, "continue an interrupted --full run without replacing data keys again")
+	rootCmd.AddCommand(driveRotateKeyCmd)
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "notes-encrypt",
+		Short: "Encrypt existing note attachments and notes, also run it after drive-rotate-key --full",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			NotesEncrypt(ctx, cfg, db, minio)
+		}})
 }
@@ -0,0 +1,59 @@
+package clicontroller
+
+import (
+	"assistant-go/internal/config"
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/minio/minio-go/v7"
+)
+
+func DriveRotateKey(
+	ctx context.Context,
+	cfg *config.Config,
+	db *pgxpool.Pool,
+	minio *minio.Client,
+	full bool,
+	resume bool,
+) {
+	keys, err := cfg.Drive.Keyring()
+	if err != nil {
+		fmt.Printf("Error loading drive encryption keys: %v", err)
+		return
+	}
+
+	repos := repository.NewRepositories(cfg, db, minio)
+	rotateUseCase := ucase.NewDriveKeyRotateUseCase(repos)
+
+	result, err := rotateUseCase.Rotate(ctx, dto.DriveKeyRotateIn{
+		SavePath:      cfg.Drive.SavePath,
+		UseEncryption: cfg.Drive.UseEncryption,
+		Keyring:       keys,
+		Full:          full || resume,
+		Resume:        resume,
+		OnProgress: func(progress dto.DriveKeyRotateProgress) {
+			fmt.Printf(
+				"re-encrypted %d, skipped %d, rewrapped keys %d\n",
+				progress.Reencrypted, len(progress.Skipped), progress.Rewrapped,
+			)
+		},
+	})
+	if result != nil {
+		for _, key := range result.Skipped {
+			fmt.Printf("skipped (missing or changed during rotation): %s\n", key)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error rotate drive key: %v", err)
+		return
+	}
+
+	db.Close()
+	fmt.Printf(
+		"successfully: data keys are wrapped by master key %s, %d files re-encrypted, %d unused keys deleted\n",
+		keys.CurrentID(), result.Reencrypted, result.DeletedKeys,
+	)
+}
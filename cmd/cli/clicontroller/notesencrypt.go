@@ -0,0 +1,51 @@
+package clicontroller
+
+import (
+	"assistant-go/internal/config"
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/minio/minio-go/v7"
+)
+
+func NotesEncrypt(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, minio *minio.Client) {
+	keys, err := cfg.Drive.Keyring()
+	if err != nil {
+		fmt.Printf("Error loading drive encryption keys: %v", err)
+		return
+	}
+
+	repos := repository.NewRepositories(cfg, db, minio)
+	encryptUseCase := ucase.NewNoteEncryptUseCase(repos)
+
+	result, err := encryptUseCase.Encrypt(ctx, dto.NoteEncryptIn{
+		FilesSavePath: cfg.File.SavePath,
+		EncryptFiles:  cfg.File.UseEncryption,
+		EncryptNotes:  cfg.Note.UseEncryption,
+		Keyring:       keys,
+		OnProgress: func(progress dto.NoteEncryptProgress) {
+			fmt.Printf(
+				"encrypted attachments %d, notes %d, skipped %d\n",
+				progress.Files, progress.Notes, len(progress.Skipped),
+			)
+		},
+	})
+	if result != nil {
+		for _, key := range result.Skipped {
+			fmt.Printf("skipped (missing or changed during encryption): %s\n", key)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error encrypt notes: %v", err)
+		return
+	}
+
+	db.Close()
+	fmt.Printf(
+		"successfully: %d attachments and %d notes encrypted, %d unused keys deleted\n",
+		result.Files, result.Notes, result.DeletedKeys,
+	)
+}
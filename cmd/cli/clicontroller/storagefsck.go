@@ -0,0 +1,67 @@
+package clicontroller
+
+import (
+	"assistant-go/internal/config"
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/minio/minio-go/v7"
+	"time"
+)
+
+func StorageFsck(
+	ctx context.Context,
+	cfg *config.Config,
+	db *pgxpool.Pool,
+	minio *minio.Client,
+	repair bool,
+	verifyHash bool,
+	minOrphanAge time.Duration,
+) {
+	keys, err := cfg.Drive.Keyring()
+	if err != nil {
+		fmt.Printf("Error loading drive encryption keys: %v", err)
+		return
+	}
+
+	repos := repository.NewRepositories(cfg, db, minio)
+	fsckUseCase := ucase.NewStorageFsckUseCase(repos)
+
+	report, err := fsckUseCase.Check(ctx, dto.StorageFsckIn{
+		DriveSavePath: cfg.Drive.SavePath,
+		FilesSavePath: cfg.File.SavePath,
+		UseEncryption: cfg.Drive.UseEncryption,
+		Keyring:       keys,
+		VerifyHash:    verifyHash,
+		Repair:        repair,
+		MinOrphanAge:  minOrphanAge,
+	})
+	if report != nil {
+		for _, issue := range report.Issues {
+			fmt.Printf("%s", issue.Problem)
+			if issue.Kind != "" {
+				fmt.Printf(" %s #%d", issue.Kind, issue.ID)
+			}
+			if issue.Key != "" {
+				fmt.Printf(" %s", issue.Key)
+			}
+			if issue.Detail != "" {
+				fmt.Printf(": %s", issue.Detail)
+			}
+			fmt.Println()
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error check storage: %v", err)
+		return
+	}
+
+	db.Close()
+	fmt.Printf(
+		"successfully: %d stored objects checked against %d records, %d issues found, %d objects quarantined\n",
+		report.Objects, report.Records, len(report.Issues), report.Quarantined,
+	)
+}
@@ -0,0 +1,61 @@
+package clicontroller
+
+import (
+	"assistant-go/internal/config"
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/minio/minio-go/v7"
+)
+
+func StorageMigrate(
+	ctx context.Context,
+	cfg *config.Config,
+	db *pgxpool.Pool,
+	minio *minio.Client,
+	from string,
+	to string,
+	deleteSource bool,
+	restart bool,
+) {
+	repos := repository.NewRepositories(cfg, db, minio)
+
+	storages := map[string]repository.FileStorageRepository{
+		config.FileUploadLocalPlace: repository.NewLocalStorageRepository(),
+	}
+	if minio != nil {
+		storages[config.FileUploadS3Place] = repository.NewS3StorageRepository(minio, cfg.S3.BucketName)
+	}
+	migrateUseCase := ucase.NewStorageMigrateUseCase(repos, storages)
+
+	result, err := migrateUseCase.Migrate(ctx, dto.StorageMigrateIn{
+		From:          from,
+		To:            to,
+		DriveSavePath: cfg.Drive.SavePath,
+		FilesSavePath: cfg.File.SavePath,
+		DeleteSource:  deleteSource,
+		Restart:       restart,
+		OnProgress: func(progress dto.StorageMigrateProgress) {
+			fmt.Printf(
+				"checked %d/%d: copied %d (%d bytes), already in place %d, deleted %d, missing %d\n",
+				progress.Checked, progress.Total, progress.Copied, progress.CopiedBytes,
+				progress.Skipped, progress.Deleted, len(progress.Missing),
+			)
+		},
+	})
+	if result != nil {
+		for _, key := range result.Missing {
+			fmt.Printf("missing in both storages: %s\n", key)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error migrate storage: %v", err)
+		return
+	}
+
+	db.Close()
+	fmt.Printf("successfully: %d objects moved from %s to %s\n", result.Checked, from, to)
+}
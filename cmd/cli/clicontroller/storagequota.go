@@ -0,0 +1,161 @@
+package clicontroller
+
+import (
+	"assistant-go/internal/config"
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/minio/minio-go/v7"
+	"strconv"
+)
+
+// quotaDefault - значение, которым в командах сбрасывается персональная квота или тариф
+const quotaDefault = "default"
+
+func UserSetQuota(
+	ctx context.Context,
+	cfg *config.Config,
+	db *pgxpool.Pool,
+	minio *minio.Client,
+	login string,
+	driveBytes string,
+	filesBytes string,
+) {
+	repos := repository.NewRepositories(cfg, db, minio)
+	quotaUseCase := ucase.NewStorageQuotaUseCase(repos)
+
+	var err error
+	if driveBytes == quotaDefault {
+		err = quotaUseCase.ResetUserQuota(ctx, login)
+	} else {
+		var driveLimit, filesLimit *int64
+		if driveLimit, err = parseQuotaBytes(driveBytes); err != nil {
+			fmt.Printf("Error parse drive quota: %v", err)
+			return
+		}
+		if filesBytes != "" {
+			if filesLimit, err = parseQuotaBytes(filesBytes); err != nil {
+				fmt.Printf("Error parse files quota: %v", err)
+				return
+			}
+		}
+		err = quotaUseCase.SetUserQuota(ctx, login, driveLimit, filesLimit)
+	}
+	if err != nil {
+		fmt.Printf("Error set user quota: %v", err)
+		return
+	}
+
+	printUserLimits(ctx, cfg, quotaUseCase, login)
+	db.Close()
+}
+
+func UserSetPlan(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, minio *minio.Client, login string, planName string) {
+	repos := repository.NewRepositories(cfg, db, minio)
+	quotaUseCase := ucase.NewStorageQuotaUseCase(repos)
+
+	var plan *string
+	if planName != quotaDefault {
+		plan = &planName
+	}
+
+	err := quotaUseCase.SetUserPlan(ctx, login, plan)
+	if err != nil {
+		fmt.Printf("Error set user plan: %v", err)
+		return
+	}
+
+	printUserLimits(ctx, cfg, quotaUseCase, login)
+	db.Close()
+}
+
+func PlanSave(
+	ctx context.Context,
+	cfg *config.Config,
+	db *pgxpool.Pool,
+	minio *minio.Client,
+	name string,
+	driveBytes string,
+	filesBytes string,
+) {
+	repos := repository.NewRepositories(cfg, db, minio)
+	quotaUseCase := ucase.NewStorageQuotaUseCase(repos)
+
+	driveLimit, err := strconv.ParseInt(driveBytes, 10, 64)
+	if err != nil {
+		fmt.Printf("Error parse drive limit: %v", err)
+		return
+	}
+	filesLimit, err := strconv.ParseInt(filesBytes, 10, 64)
+	if err != nil {
+		fmt.Printf("Error parse files limit: %v", err)
+		return
+	}
+
+	plan, err := quotaUseCase.SavePlan(ctx, name, driveLimit, filesLimit)
+	if err != nil {
+		fmt.Printf("Error save plan: %v", err)
+		return
+	}
+
+	db.Close()
+	fmt.Printf("plan %q saved: drive %d bytes, files %d bytes\n", plan.Name, plan.DriveLimit, plan.FilesLimit)
+}
+
+func PlanList(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, minio *minio.Client) {
+	repos := repository.NewRepositories(cfg, db, minio)
+	quotaUseCase := ucase.NewStorageQuotaUseCase(repos)
+
+	plans, err := quotaUseCase.GetPlans(ctx)
+	if err != nil {
+		fmt.Printf("Error get plans: %v", err)
+		return
+	}
+
+	db.Close()
+	for _, plan := range plans {
+		fmt.Printf("%s\tdrive %d bytes\tfiles %d bytes\n", plan.Name, plan.DriveLimit, plan.FilesLimit)
+	}
+}
+
+func RecalculateUsage(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, minio *minio.Client, login *string) {
+	repos := repository.NewRepositories(cfg, db, minio)
+	quotaUseCase := ucase.NewStorageQuotaUseCase(repos)
+
+	count, err := quotaUseCase.RecalculateUsage(ctx, login)
+	if err != nil {
+		fmt.Printf("Error recalculate usage: %v", err)
+		return
+	}
+
+	db.Close()
+	fmt.Printf("successfully: usage recalculated for %d users\n", count)
+}
+
+func parseQuotaBytes(value string) (*int64, error) {
+	bytes, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &bytes, nil
+}
+
+func printUserLimits(ctx context.Context, cfg *config.Config, quotaUseCase ucase.StorageQuotaUseCase, login string) {
+	limits, err := quotaUseCase.GetUserLimits(ctx, login, dto.StorageLimits{
+		DriveLimit: cfg.Drive.LimitPerUser << 20,
+		FilesLimit: cfg.File.LimitStoragePerUser << 20,
+	})
+	if err != nil {
+		fmt.Printf("Error get user limits: %v", err)
+		return
+	}
+
+	plan := "-"
+	if limits.PlanName != nil {
+		plan = *limits.PlanName
+	}
+	fmt.Printf("successfully: plan %s, drive %d bytes, files %d bytes\n", plan, limits.DriveLimit, limits.FilesLimit)
+}
@@ -29,11 +29,13 @@ func main() {
 		logging.GetLogger(ctx).Fatalln(err)
 	}
 
+	// клиент S3 нужен и при локальном хранилище, если файлы переносятся командой storage-migrate
 	var minioClient *minio.Client
-	if cfg.UploadPlace == config.FileUploadS3Place && cfg.S3.SecretAccessKey != "" {
+	if cfg.S3.SecretAccessKey != "" {
 		minioClient, err = minio.New(cfg.S3.Endpoint, &minio.Options{
 			Creds:  credentials.NewStaticV4(cfg.S3.AccessKey, cfg.S3.SecretAccessKey, ""),
 			Secure: cfg.S3.UseSSL,
+			Region: cfg.S3.Location,
 		})
 		if err != nil {
 			logging.GetLogger(ctx).Fatalln(err)
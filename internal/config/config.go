@@ -1,6 +1,7 @@
 package config
 
 import (
+	"assistant-go/pkg/keyring"
 	"github.com/ilyakaznacheev/cleanenv"
 	"log"
 	"os"
@@ -8,10 +9,11 @@ import (
 )
 
 const (
-	EnvDev            = "dev"
-	EnvTest           = "test"
-	EnvProd           = "prod"
-	FileUploadS3Place = "s3"
+	EnvDev               = "dev"
+	EnvTest              = "test"
+	EnvProd              = "prod"
+	FileUploadS3Place    = "s3"
+	FileUploadLocalPlace = "local"
 )
 
 type Config struct {
@@ -19,11 +21,13 @@ type Config struct {
 	BlockingParanoia          int    `env:"BLOCKING_PARANOIA" env-default:"2"`
 	ThisServiceDomain         string `env:"THIS_SERVICE_DOMAIN" env-required:"true"`
 	UploadPlace               string `env:"UPLOAD_PLACE" env-required:"true"`
+	StoragePendingTTLHours    int    `env:"STORAGE_PENDING_TTL_HOURS" env-default:"168"`
 	RegisteringNewUsersViaAPI bool   `env:"REGISTERING_NEW_USERS_VIA_API" env-default:"true"`
 	HTTP                      HTTPServer
 	DB                        Database
 	Cors                      Cors
 	File                      File
+	Note                      Note
 	Drive                     Drive
 	S3                        S3
 	RateLimiter               RateLimiter
@@ -65,14 +69,38 @@ type File struct {
 	UploadMaxSize       int64  `env:"FILE_UPLOAD_MAX_SIZE" env-required:"true"`
 	LimitStoragePerUser int64  `env:"FILE_LIMIT_STORAGE_PER_USER" env-required:"true"`
 	SavePath            string `env:"FILE_SAVE_PATH" env-default:"./uploads/user_files"`
+	UseEncryption       bool   `env:"FILE_USE_ENCRYPTION" env-default:"false"`
+}
+
+type Note struct {
+	UseEncryption bool `env:"NOTE_USE_ENCRYPTION" env-default:"false"`
 }
 
 type Drive struct {
-	UploadMaxSize int64  `env:"DRIVE_UPLOAD_MAX_SIZE"`
-	LimitPerUser  int64  `env:"DRIVE_LIMIT_STORAGE_PER_USER"`
-	SavePath      string `env:"FILE_SAVE_PATH" env-default:"./uploads/drive"`
-	UseEncryption bool   `env:"DRIVE_USE_FILE_ENCRYPTION" env-default:"false"`
-	EncryptionKey string `env:"DRIVE_ENCRYPTION_KEY" env-default:""`
+	UploadMaxSize         int64  `env:"DRIVE_UPLOAD_MAX_SIZE"`
+	LimitPerUser          int64  `env:"DRIVE_LIMIT_STORAGE_PER_USER"`
+	SavePath              string `env:"FILE_SAVE_PATH" env-default:"./uploads/drive"`
+	UseEncryption         bool   `env:"DRIVE_USE_FILE_ENCRYPTION" env-default:"false"`
+	EncryptionKey         string `env:"DRIVE_ENCRYPTION_KEY" env-default:""`
+	EncryptionKeyID       string `env:"DRIVE_ENCRYPTION_KEY_ID" env-default:"1"`
+	EncryptionRetiredKeys string `env:"DRIVE_ENCRYPTION_RETIRED_KEYS" env-default:""`
+	EncryptionLegacyKeyID string `env:"DRIVE_ENCRYPTION_LEGACY_KEY_ID" env-default:""`
+	TrashRetentionDays    int    `env:"DRIVE_TRASH_RETENTION_DAYS" env-default:"30"`
+	VersionsMaxCount      int    `env:"DRIVE_VERSIONS_MAX_COUNT" env-default:"10"`
+	VersionsMaxAgeDays    int    `env:"DRIVE_VERSIONS_MAX_AGE_DAYS" env-default:"90"`
+	UploadTTLHours        int    `env:"DRIVE_UPLOAD_TTL_HOURS" env-default:"24"`
+	ZipMaxSize            int64  `env:"DRIVE_ZIP_MAX_SIZE" env-default:"4096"`
+	ZipMaxEntries         int    `env:"DRIVE_ZIP_MAX_ENTRIES" env-default:"10000"`
+	ImportMaxSize         int64  `env:"DRIVE_IMPORT_MAX_SIZE" env-default:"1024"`
+	ImportMaxUnpackedSize int64  `env:"DRIVE_IMPORT_MAX_UNPACKED_SIZE" env-default:"4096"`
+	ImportMaxEntries      int    `env:"DRIVE_IMPORT_MAX_ENTRIES" env-default:"10000"`
+	WebDAVEnabled         bool   `env:"DRIVE_WEBDAV_ENABLED" env-default:"false"`
+	WebDAVTempPath        string `env:"DRIVE_WEBDAV_TEMP_PATH" env-default:""`
+}
+
+// Keyring собирает мастер-ключи шифрования диска: текущий и выведенные из оборота
+func (d *Drive) Keyring() (*keyring.Keyring, error) {
+	return keyring.New(d.EncryptionKeyID, d.EncryptionKey, d.EncryptionRetiredKeys, d.EncryptionLegacyKeyID)
 }
 
 type S3 struct {
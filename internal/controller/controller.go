@@ -5,6 +5,7 @@ import (
 	"assistant-go/internal/handler"
 	"assistant-go/internal/layer/repository"
 	"assistant-go/internal/layer/ucase"
+	"assistant-go/pkg/keyring"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/julienschmidt/httprouter"
 	"github.com/minio/minio-go/v7"
@@ -30,7 +31,12 @@ func New(cfg *config.Config, db *pgxpool.Pool, minio *minio.Client, router *http
 func (controller *Init) SetRoutes() error {
 	repos := repository.NewRepositories(controller.cfg, controller.db, controller.minio)
 
-	handler.InitHandler(repos, controller.cfg)
+	// набор ключей шифрования диска проверяется при запуске, а не при первой загрузке файла
+	keys, err := controller.cfg.Drive.Keyring()
+	if err != nil {
+		return err
+	}
+	handler.InitHandler(repos, controller.cfg, keys)
 
 	//controller.router.Handler(http.MethodGet, "/swagger", http.RedirectHandler("/swagger/index.html", http.StatusMovedPermanently))
 	//controller.router.Handler(http.MethodGet, "/swagger/*any", httpSwagger.WrapHandler)
@@ -48,10 +54,13 @@ func (controller *Init) SetRoutes() error {
 
 	controller.setUserRoutes(repos)
 	controller.setNotesCategories(repos)
-	controller.setNotes(repos)
+	controller.setNotes(repos, keys)
 	controller.setShareNotes(repos)
 	controller.setFiles(repos)
 	controller.setDrive(repos)
+	controller.setShareDrive(repos)
+	controller.setDriveGrants(repos)
+	controller.setDriveWebDAV(repos)
 
 	return nil
 }
@@ -87,6 +96,21 @@ func (controller *Init) setUserRoutes(repositories *repository.Repositories) {
 		"/api/user/change-password",
 		handler.BuildHandler(userHandler.ChangePassword, handler.AuthMW),
 	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/user/app-tokens",
+		handler.BuildHandler(userHandler.CreateAppToken, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/user/app-tokens",
+		handler.BuildHandler(userHandler.GetAppTokens, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodDelete,
+		"/api/user/app-tokens/:id",
+		handler.BuildHandler(userHandler.DeleteAppToken, handler.AuthMW),
+	)
 }
 
 func (controller *Init) setNotesCategories(repositories *repository.Repositories) {
@@ -120,8 +144,8 @@ func (controller *Init) setNotesCategories(repositories *repository.Repositories
 	)
 }
 
-func (controller *Init) setNotes(repositories *repository.Repositories) {
-	noteUseCase := ucase.NewNoteUseCase(repositories)
+func (controller *Init) setNotes(repositories *repository.Repositories, keys *keyring.Keyring) {
+	noteUseCase := ucase.NewNoteUseCase(repositories, controller.cfg.Note.UseEncryption, keys)
 	noteHandler := handler.NewNoteHandler(noteUseCase)
 
 	controller.router.Handler(
@@ -187,6 +211,83 @@ func (controller *Init) setShareNotes(repositories *repository.Repositories) {
 	)
 }
 
+func (controller *Init) setShareDrive(repositories *repository.Repositories) {
+	driveShareUseCase := ucase.NewDriveShareUseCase(repositories)
+	driveShareHandler := handler.NewDriveShareHandler(driveShareUseCase)
+
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive-shares",
+		handler.BuildHandler(driveShareHandler.Create, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive-shares",
+		handler.BuildHandler(driveShareHandler.GetActive, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodDelete,
+		"/api/drive-shares/:id",
+		handler.BuildHandler(driveShareHandler.Delete, handler.AuthMW),
+	)
+	// публичный доступ по ссылке
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive-shared/:hash/tree",
+		handler.BuildHandler(driveShareHandler.GetSharedTree),
+	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive-shared/:hash/files/:id",
+		handler.BuildHandler(driveShareHandler.GetSharedFile),
+	)
+	controller.router.Handler(
+		http.MethodHead,
+		"/api/drive-shared/:hash/files/:id",
+		handler.BuildHandler(driveShareHandler.GetSharedFile),
+	)
+}
+
+func (controller *Init) setDriveGrants(repositories *repository.Repositories) {
+	driveGrantUseCase := ucase.NewDriveGrantUseCase(repositories)
+	driveGrantHandler := handler.NewDriveGrantHandler(driveGrantUseCase)
+
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive-grants",
+		handler.BuildHandler(driveGrantHandler.Create, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive-grants",
+		handler.BuildHandler(driveGrantHandler.GetByStruct, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodDelete,
+		"/api/drive-grants/:id",
+		handler.BuildHandler(driveGrantHandler.Delete, handler.AuthMW),
+	)
+}
+
+func (controller *Init) setDriveWebDAV(repositories *repository.Repositories) {
+	if !controller.cfg.Drive.WebDAVEnabled {
+		return
+	}
+
+	driveUseCase := ucase.NewDriveUseCase(repositories)
+	userUseCase := ucase.NewUserUseCase(repositories)
+	webDAVHandler := handler.NewDriveWebDAVHandler(driveUseCase, userUseCase)
+
+	// авторизация выполняется в обработчике: клиентам WebDAV нужен ответ 401 с WWW-Authenticate
+	for _, method := range handler.WebDAVMethods {
+		controller.router.Handler(
+			method,
+			handler.WebDAVPrefix+"/*filepath",
+			handler.BuildHandler(webDAVHandler.Serve),
+		)
+	}
+}
+
 func (controller *Init) setFiles(repositories *repository.Repositories) {
 	fileUseCase := ucase.NewFileUseCase(repositories)
 	fileHandler := handler.NewFileHandler(fileUseCase)
@@ -201,6 +302,11 @@ func (controller *Init) setFiles(repositories *repository.Repositories) {
 		"/api/files/hash/:hash",
 		handler.BuildHandler(fileHandler.GetByHash),
 	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/files/hash/:hash/thumbnail",
+		handler.BuildHandler(fileHandler.GetThumbnailByHash),
+	)
 }
 
 func (controller *Init) setDrive(repositories *repository.Repositories) {
@@ -217,21 +323,113 @@ func (controller *Init) setDrive(repositories *repository.Repositories) {
 		"/api/drive/tree",
 		handler.BuildHandler(driveHandler.GetTree, handler.AuthMW),
 	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive/resolve",
+		handler.BuildHandler(driveHandler.ResolvePath, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive/ancestors/:id",
+		handler.BuildHandler(driveHandler.GetAncestors, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive/search",
+		handler.BuildHandler(driveHandler.Search, handler.AuthMW),
+	)
 	controller.router.Handler(
 		http.MethodGet,
 		"/api/drive/files/:id",
 		handler.BuildHandler(driveHandler.GetFile, handler.AuthMW),
 	)
+	controller.router.Handler(
+		http.MethodHead,
+		"/api/drive/files/:id",
+		handler.BuildHandler(driveHandler.GetFile, handler.AuthMW),
+	)
 	controller.router.Handler(
 		http.MethodPost,
 		"/api/drive/upload-file",
 		handler.BuildHandler(driveHandler.UploadFile, handler.AuthMW),
 	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive/hash-exists/:hash",
+		handler.BuildHandler(driveHandler.HashExists, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/import",
+		handler.BuildHandler(driveHandler.ImportArchive, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/zip",
+		handler.BuildHandler(driveHandler.GetZip, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/upload-by-hash",
+		handler.BuildHandler(driveHandler.UploadByHash, handler.AuthMW),
+	)
 	controller.router.Handler(
 		http.MethodDelete,
 		"/api/drive/:id",
 		handler.BuildHandler(driveHandler.Delete, handler.AuthMW),
 	)
+	// ==== versions
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive/files/:id/versions",
+		handler.BuildHandler(driveHandler.GetFileVersions, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive/files/:id/thumbnail",
+		handler.BuildHandler(driveHandler.GetThumbnail, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive/files/:id/versions/:versionId",
+		handler.BuildHandler(driveHandler.GetFile, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodHead,
+		"/api/drive/files/:id/versions/:versionId",
+		handler.BuildHandler(driveHandler.GetFile, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/files/:id/versions/:versionId/restore",
+		handler.BuildHandler(driveHandler.RestoreFileVersion, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/files/:id/versions-prune",
+		handler.BuildHandler(driveHandler.PruneFileVersions, handler.AuthMW),
+	)
+	// ==== trash
+	controller.router.Handler(
+		http.MethodGet,
+		"/api/drive/trash",
+		handler.BuildHandler(driveHandler.GetTrash, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/trash/restore",
+		handler.BuildHandler(driveHandler.RestoreFromTrash, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/trash/delete",
+		handler.BuildHandler(driveHandler.DeleteFromTrash, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/trash/empty",
+		handler.BuildHandler(driveHandler.EmptyTrash, handler.AuthMW),
+	)
 	controller.router.Handler(
 		http.MethodPatch,
 		"/api/drive/files/:id/rename",
@@ -247,6 +445,37 @@ func (controller *Init) setDrive(repositories *repository.Repositories) {
 		"/api/drive/renmov",
 		handler.BuildHandler(driveHandler.RenMov, handler.AuthMW),
 	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/copy",
+		handler.BuildHandler(driveHandler.Copy, handler.AuthMW),
+	)
+	// ==== tus
+	controller.router.Handler(
+		http.MethodOptions,
+		"/api/drive/tus/",
+		handler.BuildHandler(driveHandler.TusOptions),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/tus/",
+		handler.BuildHandler(driveHandler.TusCreate, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodHead,
+		"/api/drive/tus/:id",
+		handler.BuildHandler(driveHandler.TusHead, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPatch,
+		"/api/drive/tus/:id",
+		handler.BuildHandler(driveHandler.TusPatch, handler.AuthMW),
+	)
+	controller.router.Handler(
+		http.MethodPost,
+		"/api/drive/tus/:id",
+		handler.BuildHandler(driveHandler.TusMethodOverride, handler.AuthMW),
+	)
 	// ==== chunks
 	controller.router.Handler(
 		http.MethodPost,
@@ -9,6 +9,7 @@ import (
 	"assistant-go/internal/layer/ucase"
 	"assistant-go/internal/locale"
 	"assistant-go/internal/storage/postgres"
+	"assistant-go/pkg/keyring"
 	"encoding/json"
 	"errors"
 	"net"
@@ -22,13 +23,15 @@ var blockIpRepository repository.BlockIPRepository
 var blockEventRepository repository.BlockEventRepository
 var rateLimiterRepository repository.RateLimiterRepository
 var appConf *config.Config
+var appKeyring *keyring.Keyring
 
-func InitHandler(repos *repository.Repositories, cfg *config.Config) {
+func InitHandler(repos *repository.Repositories, cfg *config.Config, keys *keyring.Keyring) {
 	userRepository = repos.UserRepository
 	blockIpRepository = repos.BlockIPRepository
 	blockEventRepository = repos.BlockEventRepository
 	rateLimiterRepository = repos.RateLimiterRepository
 	appConf = cfg
+	appKeyring = keys
 }
 
 var (
@@ -201,6 +204,14 @@ func GetIpAddress(r *http.Request) (string, error) {
 	return IPAddress, nil
 }
 
+// storageDefaultLimits возвращает лимиты из конфигурации для пользователей без персональной квоты и тарифа
+func storageDefaultLimits() dto.StorageLimits {
+	return dto.StorageLimits{
+		DriveLimit: appConf.Drive.LimitPerUser << 20,
+		FilesLimit: appConf.File.LimitStoragePerUser << 20,
+	}
+}
+
 func buildErrorMessage(lang string, err error) string {
 	switch {
 	case errors.Is(err, postgres.ErrUnexpectedDBError):
@@ -221,6 +232,8 @@ func buildErrorMessage(lang string, err error) string {
 		return locale.T(lang, "user_not_found")
 	case errors.Is(err, ucase.ErrUserPasswordsAreNotIdentical):
 		return locale.T(lang, "passwords_are_not_identical")
+	case errors.Is(err, ucase.ErrUserAppTokenNotFound):
+		return locale.T(lang, "user_app_token_not_found")
 	case errors.Is(err, ucase.ErrCategoryParentIdNotFound):
 		return locale.T(lang, "parent_id_of_the_category_not_found")
 	case errors.Is(err, ucase.ErrCategoryNotFound):
@@ -281,14 +294,86 @@ func buildErrorMessage(lang string, err error) string {
 		return locale.T(lang, "drive_filename_exists")
 	case errors.Is(err, ucase.ErrDriveRelocatableStructureNotFound):
 		return locale.T(lang, "drive_relocatable_structure_not_found")
+	case errors.Is(err, ucase.ErrDriveCopyIntoOneself):
+		return locale.T(lang, "drive_copy_into_oneself")
 	case errors.Is(err, ucase.ErrDriveMovingIntoOneself):
 		return locale.T(lang, "drive_moving_into_oneself")
 	case errors.Is(err, ucase.ErrDriveParentRefOfTheRelocatableStruct):
 		return locale.T(lang, "drive_parent_references_one_of_the_relocatable_struct")
+	case errors.Is(err, ucase.ErrStoragePlanNotFound):
+		return locale.T(lang, "storage_plan_not_found")
+	case errors.Is(err, ucase.ErrStorageQuotaInvalid):
+		return locale.T(lang, "storage_quota_invalid")
+	case errors.Is(err, ucase.ErrThumbnailUnsupported):
+		return locale.T(lang, "thumbnail_unsupported")
 	case errors.Is(err, ucase.ErrDriveEncrypting):
 		return locale.T(lang, "drive_encryption_error")
 	case errors.Is(err, ucase.ErrDriveDecrypting):
 		return locale.T(lang, "drive_decryption_error")
+	case errors.Is(err, ucase.ErrNoteEncrypting):
+		return locale.T(lang, "note_encryption_error")
+	case errors.Is(err, ucase.ErrNoteDecrypting):
+		return locale.T(lang, "note_decryption_error")
+	case errors.Is(err, ucase.ErrDriveTrashItemNotFound):
+		return locale.T(lang, "drive_trash_item_not_found")
+	case errors.Is(err, ucase.ErrDriveFileHashMismatch):
+		return locale.T(lang, "drive_file_hash_mismatch")
+	case errors.Is(err, ucase.ErrDriveBlobNotFound):
+		return locale.T(lang, "drive_blob_not_found")
+	case errors.Is(err, ucase.ErrDriveChunksSequenceBroken):
+		return locale.T(lang, "drive_chunks_sequence_broken")
+	case errors.Is(err, ucase.ErrDriveFileSizeMismatch):
+		return locale.T(lang, "drive_file_size_mismatch")
+	case errors.Is(err, ucase.ErrDriveUploadIncomplete):
+		return locale.T(lang, "drive_upload_incomplete")
+	case errors.Is(err, ucase.ErrDriveUploadCompleted):
+		return locale.T(lang, "drive_upload_completed")
+	case errors.Is(err, ucase.ErrDriveUploadOffsetMismatch):
+		return locale.T(lang, "drive_upload_offset_mismatch")
+	case errors.Is(err, ucase.ErrDriveChecksumMismatch):
+		return locale.T(lang, "drive_checksum_mismatch")
+	case errors.Is(err, ucase.ErrDriveZipTooLarge):
+		return locale.T(lang, "drive_zip_too_large")
+	case errors.Is(err, ucase.ErrDriveZipTooManyEntries):
+		return locale.T(lang, "drive_zip_too_many_entries")
+	case errors.Is(err, ucase.ErrDrivePathInvalid):
+		return locale.T(lang, "drive_path_invalid")
+	case errors.Is(err, ucase.ErrDrivePathNotFound):
+		return locale.T(lang, "drive_path_not_found")
+	case errors.Is(err, ucase.ErrDriveAccessDenied):
+		return locale.T(lang, "drive_access_denied")
+	case errors.Is(err, ucase.ErrDriveGrantNotFound):
+		return locale.T(lang, "drive_grant_not_found")
+	case errors.Is(err, ucase.ErrDriveGrantSelf):
+		return locale.T(lang, "drive_grant_self")
+	case errors.Is(err, ucase.ErrDriveGrantOnlyDirectories):
+		return locale.T(lang, "drive_grant_only_directories")
+	case errors.Is(err, ucase.ErrDriveShareNotFound):
+		return locale.T(lang, "drive_share_not_found")
+	case errors.Is(err, ucase.ErrDriveShareExpired):
+		return locale.T(lang, "drive_share_expired")
+	case errors.Is(err, ucase.ErrDriveShareDownloadLimit):
+		return locale.T(lang, "drive_share_download_limit")
+	case errors.Is(err, ucase.ErrDriveSharePasswordRequired):
+		return locale.T(lang, "drive_share_password_required")
+	case errors.Is(err, ucase.ErrDriveShareWrongPassword):
+		return locale.T(lang, "drive_share_wrong_password")
+	case errors.Is(err, ucase.ErrDriveShareInvalidExpiry):
+		return locale.T(lang, "drive_share_invalid_expiry")
+	case errors.Is(err, ucase.ErrDriveShareHashGeneration):
+		return locale.T(lang, "drive_share_hash_generation")
+	case errors.Is(err, ucase.ErrDriveImportUnsupportedArchive):
+		return locale.T(lang, "drive_import_unsupported_archive")
+	case errors.Is(err, ucase.ErrDriveImportInvalidArchive):
+		return locale.T(lang, "drive_import_invalid_archive")
+	case errors.Is(err, ucase.ErrDriveImportTooManyEntries):
+		return locale.T(lang, "drive_import_too_many_entries")
+	case errors.Is(err, ucase.ErrDriveImportTooLarge):
+		return locale.T(lang, "drive_import_too_large")
+	case errors.Is(err, ucase.ErrDriveImportUnsafePath):
+		return locale.T(lang, "drive_import_unsafe_path")
+	case errors.Is(err, ucase.ErrDriveImportUnsupportedEntry):
+		return locale.T(lang, "drive_import_unsupported_entry")
 	case errors.Is(err, ucase.ErrNoteShareExists):
 		return locale.T(lang, "note_share_exists")
 	case errors.Is(err, ucase.ErrNoteShareNotFound):
@@ -3,9 +3,11 @@ package handler
 import (
 	"assistant-go/internal/layer/dto"
 	"assistant-go/internal/layer/repository"
+	service "assistant-go/internal/layer/service/file"
 	"assistant-go/internal/layer/ucase"
 	"assistant-go/internal/locale"
 	"assistant-go/internal/logging"
+	"assistant-go/pkg/httprange"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,6 +17,8 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type DriveHandler struct {
@@ -50,7 +54,13 @@ func (h *DriveHandler) GetTree(w http.ResponseWriter, r *http.Request) {
 		parentID = &parentIDInt
 	}
 
-	driveTreeList, err := h.useCase.GetTree(r.Context(), parentID, authUser)
+	var driveTreeList []*dto.DriveTree
+	// root=shared - директории других пользователей, к которым выдан доступ
+	if r.URL.Query().Get("root") == "shared" && parentID == nil {
+		driveTreeList, err = h.useCase.GetSharedWithMe(r.Context(), authUser)
+	} else {
+		driveTreeList, err = h.useCase.GetTree(r.Context(), parentID, authUser)
+	}
 	if err != nil {
 		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
 		return
@@ -60,6 +70,134 @@ func (h *DriveHandler) GetTree(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+func (h *DriveHandler) Search(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	query := r.URL.Query()
+	searchDTO := &dto.DriveSearch{
+		Query: strings.TrimSpace(query.Get("q")),
+		Ext:   strings.TrimSpace(query.Get("ext")),
+		Page:  1,
+		Limit: 50,
+	}
+
+	parseErr := parseQueryInt(query.Get("page"), &searchDTO.Page)
+	if parseErr == nil {
+		parseErr = parseQueryInt(query.Get("limit"), &searchDTO.Limit)
+	}
+	if typeStr := query.Get("type"); typeStr != "" && parseErr == nil {
+		typeInt, err := strconv.ParseInt(typeStr, 10, 8)
+		parseErr = err
+		rowType := int8(typeInt)
+		searchDTO.Type = &rowType
+	}
+	if parseErr == nil {
+		searchDTO.MinSize, parseErr = parseQueryInt64(query.Get("minSize"))
+	}
+	if parseErr == nil {
+		searchDTO.MaxSize, parseErr = parseQueryInt64(query.Get("maxSize"))
+	}
+	if parseErr == nil {
+		searchDTO.CreatedFrom, parseErr = parseQueryTime(query.Get("createdFrom"), false)
+	}
+	if parseErr == nil {
+		searchDTO.CreatedTo, parseErr = parseQueryTime(query.Get("createdTo"), true)
+	}
+	if parseErr == nil {
+		searchDTO.UpdatedFrom, parseErr = parseQueryTime(query.Get("updatedFrom"), false)
+	}
+	if parseErr == nil {
+		searchDTO.UpdatedTo, parseErr = parseQueryTime(query.Get("updatedTo"), true)
+	}
+	if parseErr != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = searchDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	result, err := h.useCase.Search(r.Context(), searchDTO, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, result)
+	return
+}
+
+func (h *DriveHandler) ResolvePath(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	path := r.URL.Query().Get("path")
+	if path == "" || len(path) > 4096 {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	item, err := h.useCase.ResolvePath(r.Context(), path, authUser)
+	if err != nil {
+		responseStatus := http.StatusUnprocessableEntity
+		if errors.Is(err, ucase.ErrDrivePathNotFound) {
+			responseStatus = http.StatusNotFound
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, item)
+	return
+}
+
+func (h *DriveHandler) GetAncestors(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	structID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	items, err := h.useCase.GetAncestors(r.Context(), structID, authUser)
+	if err != nil {
+		responseStatus := http.StatusUnprocessableEntity
+		if errors.Is(err, ucase.ErrDriveStructNotFound) {
+			responseStatus = http.StatusNotFound
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, items)
+	return
+}
+
 func (h *DriveHandler) CreateDirectory(w http.ResponseWriter, r *http.Request) {
 	langRequest := locale.GetLangFromContext(r.Context())
 	var createDirectoryDTO dto.DriveCreateDirectory
@@ -148,13 +286,16 @@ func (h *DriveHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		ParentID:              parentID,
 		SHA256:                sha256,
 		UseEncryption:         appConf.Drive.UseEncryption,
-		EncryptionKey:         appConf.Drive.EncryptionKey,
+		Keyring:               appKeyring,
+		Replace:               r.URL.Query().Get("replace") == "true",
+		Path:                  r.URL.Query().Get("path"),
 	}
 
 	driveTreeList, err := h.useCase.UploadFile(r.Context(), uploadFileDto, authUser)
 	if err != nil {
 		switch {
 		case errors.Is(err, ucase.ErrDriveParentIdNotFound),
+			errors.Is(err, ucase.ErrDrivePathInvalid),
 			errors.Is(err, ucase.ErrDriveFileNotSafeFilename),
 			errors.Is(err, ucase.ErrDriveFileTooLarge),
 			errors.Is(err, ucase.ErrDriveFileTooLargeUseChunks):
@@ -176,6 +317,209 @@ func (h *DriveHandler) UploadFile(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+func (h *DriveHandler) HashExists(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	hash := params.ByName("hash")
+	if hash == "" {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	exists, err := h.useCase.HashExists(r.Context(), hash, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, dto.DriveHashExists{Exists: exists})
+	return
+}
+
+func (h *DriveHandler) UploadByHash(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	var uploadByHashDTO dto.DriveUploadByHash
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&uploadByHashDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = uploadByHashDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	driveTreeList, err := h.useCase.UploadByHash(r.Context(), uploadByHashDTO, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusCreated, driveTreeList)
+	return
+}
+
+func (h *DriveHandler) GetZip(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	var zipDTO dto.DriveZip
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&zipDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = zipDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	zipIn := dto.DriveZipIn{
+		StructIDs:     zipDTO.StructIDs,
+		SavePath:      appConf.Drive.SavePath,
+		UseEncryption: appConf.Drive.UseEncryption,
+		Keyring:       appKeyring,
+		MaxSizeBytes:  appConf.Drive.ZipMaxSize << 20,
+		MaxEntries:    appConf.Drive.ZipMaxEntries,
+	}
+
+	fileDto, err := h.useCase.GetZip(r.Context(), zipIn, authUser)
+	if err != nil {
+		if errors.Is(err, ucase.ErrDriveStructNotFound) {
+			BlockEventHandle(r, BlockEventFileNotFoundType)
+			SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusNotFound, 0)
+			return
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+	defer func() {
+		if closer, ok := fileDto.File.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}()
+
+	// размер архива заранее неизвестен, ответ отдается без Content-Length
+	filename := url.PathEscape(fileDto.OriginalFilename)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", filename))
+	w.Header().Set("Content-Type", "application/zip")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = io.Copy(w, fileDto.File)
+	if err != nil {
+		logging.GetLogger(r.Context()).Errorf("%s: %v", locale.T(langRequest, "file_failed_to_send"), err)
+		return
+	}
+}
+
+func (h *DriveHandler) ImportArchive(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		logging.GetLogger(r.Context()).Error(err)
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, buildErrorMessage(langRequest, ErrFileInvalidReadForm), http.StatusUnprocessableEntity, 0)
+		return
+	}
+	defer func(file multipart.File) {
+		_ = file.Close()
+	}(file)
+
+	importDTO := dto.DriveImport{
+		Conflict: r.URL.Query().Get("conflict"),
+	}
+	if importDTO.Conflict == "" {
+		importDTO.Conflict = ucase.ImportConflictSkip
+	}
+
+	if parentIDStr := r.URL.Query().Get("parentId"); parentIDStr != "" {
+		parentIDInt, err := strconv.Atoi(parentIDStr)
+		if err != nil {
+			BlockEventHandle(r, BlockEventInputDataType)
+			SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+			return
+		}
+		importDTO.ParentID = &parentIDInt
+	}
+
+	if err = importDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	if header.Size > appConf.Drive.ImportMaxSize<<20 {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, buildErrorMessage(langRequest, ucase.ErrDriveFileTooLarge), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	importIn := dto.DriveImportIn{
+		File:                  file,
+		Size:                  header.Size,
+		Filename:              header.Filename,
+		ParentID:              importDTO.ParentID,
+		Conflict:              importDTO.Conflict,
+		MaxEntries:            appConf.Drive.ImportMaxEntries,
+		MaxUnpackedSize:       appConf.Drive.ImportMaxUnpackedSize << 20,
+		MaxSizeBytes:          appConf.Drive.UploadMaxSize << 20,
+		StorageMaxSizePerUser: appConf.Drive.LimitPerUser << 20,
+		SavePath:              appConf.Drive.SavePath,
+		UseEncryption:         appConf.Drive.UseEncryption,
+		Keyring:               appKeyring,
+	}
+
+	result, err := h.useCase.ImportArchive(r.Context(), importIn, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	for _, failure := range result.Failed {
+		failure.Error = buildErrorMessage(langRequest, failure.Err)
+	}
+
+	SendResponse(w, http.StatusOK, result)
+	return
+}
+
 func (h *DriveHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	langRequest := locale.GetLangFromContext(r.Context())
 
@@ -205,7 +549,115 @@ func (h *DriveHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		structID = structIDInt
 	}
 
-	err = h.useCase.Delete(r.Context(), structID, appConf.Drive.SavePath, authUser)
+	err = h.useCase.Delete(r.Context(), structID, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusNoContent, nil)
+	return
+}
+
+func (h *DriveHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	trashList, err := h.useCase.GetTrash(r.Context(), authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, trashList)
+	return
+}
+
+func (h *DriveHandler) RestoreFromTrash(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	var trashActionDTO dto.DriveTrashAction
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&trashActionDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = trashActionDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	err = h.useCase.RestoreFromTrash(r.Context(), trashActionDTO.StructID, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusNoContent, nil)
+	return
+}
+
+func (h *DriveHandler) DeleteFromTrash(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	var trashActionDTO dto.DriveTrashAction
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&trashActionDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = trashActionDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	err = h.useCase.DeleteFromTrash(r.Context(), trashActionDTO.StructID, appConf.Drive.SavePath, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusNoContent, nil)
+	return
+}
+
+func (h *DriveHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = h.useCase.EmptyTrash(r.Context(), appConf.Drive.SavePath, authUser)
 	if err != nil {
 		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
 		return
@@ -244,51 +696,97 @@ func (h *DriveHandler) GetFile(w http.ResponseWriter, r *http.Request) {
 		structID = structIDInt
 	}
 
+	// маршрут /versions/:versionId отдает предыдущую версию файла тем же обработчиком
+	var versionID *int
+	if versionIDStr := params.ByName("versionId"); versionIDStr != "" {
+		versionIDInt, err := strconv.Atoi(versionIDStr)
+		if err != nil {
+			BlockEventHandle(r, BlockEventInputDataType)
+			SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+			return
+		}
+		versionID = &versionIDInt
+	}
+
 	getFileDTO := &dto.GetFile{
 		StructID:      structID,
 		SavePath:      appConf.Drive.SavePath,
 		MaxSizeBytes:  appConf.Drive.UploadMaxSize << 20,
 		UseEncryption: appConf.Drive.UseEncryption,
-		EncryptionKey: appConf.Drive.EncryptionKey,
+		Keyring:       appKeyring,
+		VersionID:     versionID,
 	}
 
-	fileDto, err := h.useCase.GetFile(r.Context(), getFileDTO, authUser)
+	fileInfo, err := h.useCase.GetFileInfo(r.Context(), getFileDTO, authUser)
 	if err != nil {
 		var responseStatus int
 		if errors.Is(err, ucase.ErrFileNotFound) {
 			responseStatus = http.StatusNotFound
 			BlockEventHandle(r, BlockEventFileNotFoundType)
-		} else if errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
-			responseStatus = http.StatusNotFound
 		} else {
 			responseStatus = http.StatusUnprocessableEntity
 		}
 		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
 		return
 	}
-	defer func() {
-		if closer, ok := fileDto.File.(io.Closer); ok {
-			_ = closer.Close()
-		}
-	}()
 
-	filename := url.PathEscape(fileDto.OriginalFilename)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", filename))
-	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Length", strconv.FormatInt(fileDto.SizeBytes, 10))
-	w.WriteHeader(http.StatusOK)
+	sendDriveFile(w, r, langRequest, getFileDTO, fileInfo, func() (*dto.FileResponse, error) {
+		return h.useCase.GetFile(r.Context(), getFileDTO, authUser)
+	})
+}
 
-	_, err = io.Copy(w, fileDto.File)
+func (h *DriveHandler) GetThumbnail(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	structID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	size, err := getThumbnailSize(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	thumbnailDTO := &dto.GetThumbnail{
+		StructID:      structID,
+		Size:          size,
+		SavePath:      appConf.Drive.SavePath,
+		UseEncryption: appConf.Drive.UseEncryption,
+		Keyring:       appKeyring,
+	}
+	if err = thumbnailDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	fileDto, err := h.useCase.GetThumbnail(r.Context(), thumbnailDTO, authUser)
 	if err != nil {
-		SendErrorResponse(
-			w,
-			fmt.Sprintf("%s: %v", locale.T(langRequest, "file_failed_to_send"), err),
-			http.StatusInternalServerError,
-			0,
-		)
+		var responseStatus int
+		if errors.Is(err, ucase.ErrFileNotFound) {
+			responseStatus = http.StatusNotFound
+			BlockEventHandle(r, BlockEventFileNotFoundType)
+		} else {
+			responseStatus = http.StatusUnprocessableEntity
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
 		return
 	}
-	return
+
+	sendThumbnail(w, r, langRequest, fileDto)
 }
 
 func (h *DriveHandler) Rename(w http.ResponseWriter, r *http.Request) {
@@ -354,7 +852,7 @@ func (h *DriveHandler) Space(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dtoSpace, err := h.useCase.Space(r.Context(), authUser, appConf.Drive.LimitPerUser<<20)
+	dtoSpace, err := h.useCase.Space(r.Context(), authUser, storageDefaultLimits())
 	if err != nil {
 		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
 		return
@@ -398,6 +896,45 @@ func (h *DriveHandler) RenMov(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+func (h *DriveHandler) Copy(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	var copyDTO dto.DriveCopy
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&copyDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = copyDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	copyIn := dto.DriveCopyIn{
+		DriveCopy:             copyDTO,
+		SavePath:              appConf.Drive.SavePath,
+		StorageMaxSizePerUser: appConf.Drive.LimitPerUser << 20,
+	}
+
+	driveTreeList, err := h.useCase.Copy(r.Context(), authUser, copyIn)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusCreated, driveTreeList)
+}
+
 func (h *DriveHandler) ChunkPrepare(w http.ResponseWriter, r *http.Request) {
 	langRequest := locale.GetLangFromContext(r.Context())
 	var chunkPrepareDTO dto.DriveChunkPrepare
@@ -508,7 +1045,7 @@ func (h *DriveHandler) UploadChunk(w http.ResponseWriter, r *http.Request) {
 		StorageMaxSizePerUser: appConf.Drive.LimitPerUser << 20,
 		SavePath:              appConf.Drive.SavePath,
 		UseEncryption:         appConf.Drive.UseEncryption,
-		EncryptionKey:         appConf.Drive.EncryptionKey,
+		Keyring:               appKeyring,
 	}
 
 	err = h.useCase.ChunkUpload(r.Context(), authUser, uploadChunkDTO)
@@ -534,7 +1071,14 @@ func (h *DriveHandler) ChunkEnd(w http.ResponseWriter, r *http.Request) {
 	langRequest := locale.GetLangFromContext(r.Context())
 	var chunkEndDTO dto.DriveChunkEnd
 
-	err := json.NewDecoder(r.Body).Decode(&chunkEndDTO)
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&chunkEndDTO)
 	if err != nil {
 		BlockEventHandle(r, BlockEventDecodeBodyType)
 		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
@@ -547,7 +1091,14 @@ func (h *DriveHandler) ChunkEnd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.useCase.ChunkEnd(r.Context(), chunkEndDTO.StructID)
+	chunkEndIn := dto.DriveChunkEndIn{
+		StructID:      chunkEndDTO.StructID,
+		SavePath:      appConf.Drive.SavePath,
+		UseEncryption: appConf.Drive.UseEncryption,
+		Keyring:       appKeyring,
+	}
+
+	err = h.useCase.ChunkEnd(r.Context(), authUser, chunkEndIn)
 	if err != nil {
 		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
 		return
@@ -643,7 +1194,7 @@ func (h *DriveHandler) GetChunkBytes(w http.ResponseWriter, r *http.Request) {
 		ChunkNumber:   chunkNumber,
 		MaxSizeBytes:  appConf.Drive.UploadMaxSize << 20,
 		UseEncryption: appConf.Drive.UseEncryption,
-		EncryptionKey: appConf.Drive.EncryptionKey,
+		Keyring:       appKeyring,
 	}
 
 	fileDto, err := h.useCase.GetChunkBytes(r.Context(), getChunkDto, authUser)
@@ -654,6 +1205,8 @@ func (h *DriveHandler) GetChunkBytes(w http.ResponseWriter, r *http.Request) {
 			BlockEventHandle(r, BlockEventFileNotFoundType)
 		} else if errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
 			responseStatus = http.StatusNotFound
+		} else if errors.Is(err, ucase.ErrDriveShareDownloadLimit) {
+			responseStatus = http.StatusGone
 		} else {
 			responseStatus = http.StatusUnprocessableEntity
 		}
@@ -666,9 +1219,7 @@ func (h *DriveHandler) GetChunkBytes(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	filename := url.PathEscape(fileDto.OriginalFilename)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", filename))
-	w.Header().Set("Content-Type", "application/octet-stream")
+	setDriveContentHeaders(w, r, fileDto.OriginalFilename, fileDto.MimeType)
 	w.Header().Set("Content-Length", strconv.FormatInt(fileDto.SizeBytes, 10))
 	w.WriteHeader(http.StatusOK)
 
@@ -730,3 +1281,331 @@ func (h *DriveHandler) UpdateFileHash(w http.ResponseWriter, r *http.Request) {
 	SendResponse(w, http.StatusCreated, nil)
 	return
 }
+
+// isNotModified проверяет условные заголовки If-None-Match и If-Modified-Since (RFC 7232)
+func (h *DriveHandler) GetFileVersions(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	structID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	versions, err := h.useCase.GetFileVersions(r.Context(), structID, appConf.Drive.UseEncryption, authUser)
+	if err != nil {
+		var responseStatus int
+		if errors.Is(err, ucase.ErrFileNotFound) {
+			responseStatus = http.StatusNotFound
+			BlockEventHandle(r, BlockEventFileNotFoundType)
+		} else {
+			responseStatus = http.StatusUnprocessableEntity
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, versions)
+	return
+}
+
+func (h *DriveHandler) RestoreFileVersion(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	structID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+	versionID, err := strconv.Atoi(params.ByName("versionId"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	err = h.useCase.RestoreFileVersion(r.Context(), structID, versionID, authUser)
+	if err != nil {
+		var responseStatus int
+		if errors.Is(err, ucase.ErrFileNotFound) {
+			responseStatus = http.StatusNotFound
+			BlockEventHandle(r, BlockEventFileNotFoundType)
+		} else {
+			responseStatus = http.StatusUnprocessableEntity
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusNoContent, nil)
+	return
+}
+
+func (h *DriveHandler) PruneFileVersions(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	var pruneDTO dto.DriveVersionsPrune
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	structID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&pruneDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = pruneDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	err = h.useCase.PruneFileVersions(r.Context(), structID, pruneDTO.Keep, appConf.Drive.SavePath, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusNoContent, nil)
+	return
+}
+
+// setDriveContentHeaders выставляет тип содержимого, определенный при загрузке. По умолчанию файл
+// скачивается, с ?disposition=inline - открывается в браузере. Активное содержимое (HTML, SVG)
+// изолируется CSP sandbox, чтобы его скрипты не выполнялись от имени приложения
+func setDriveContentHeaders(w http.ResponseWriter, r *http.Request, originalFilename string, mimeType string) {
+	disposition := "attachment"
+	if r.URL.Query().Get("disposition") == "inline" {
+		disposition = "inline"
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	filename := url.PathEscape(originalFilename)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename*=UTF-8''%s", disposition, filename))
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if service.NewFile().FileService().IsActiveMimeType(mimeType) {
+		w.Header().Set("Content-Security-Policy", "sandbox; default-src 'none'; img-src data:; style-src 'unsafe-inline'")
+	}
+}
+
+// sendDriveFile отдает файл с поддержкой условных запросов, Range и HEAD.
+// getFile вызывается после того, как в getFileDTO выставлен запрошенный диапазон
+func sendDriveFile(
+	w http.ResponseWriter,
+	r *http.Request,
+	langRequest string,
+	getFileDTO *dto.GetFile,
+	fileInfo *dto.DriveFileInfo,
+	getFile func() (*dto.FileResponse, error),
+) {
+	lastModified := fileInfo.ModifiedAt.UTC().Format(http.TimeFormat)
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", fileInfo.ETag)
+	w.Header().Set("Last-Modified", lastModified)
+
+	if isNotModified(r, fileInfo.ETag, fileInfo.ModifiedAt) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	byteRange, err := httprange.Parse(r.Header.Get("Range"), fileInfo.SizeBytes)
+	if err != nil {
+		if errors.Is(err, httprange.ErrNoOverlap) {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", fileInfo.SizeBytes))
+			SendErrorResponse(w, locale.T(langRequest, "drive_range_not_satisfiable"), http.StatusRequestedRangeNotSatisfiable, 0)
+			return
+		}
+		// некорректный или множественный Range игнорируется, отдаем файл целиком
+		byteRange = nil
+	}
+	if byteRange != nil && !isIfRangeMatched(r, fileInfo.ETag, fileInfo.ModifiedAt) {
+		byteRange = nil
+	}
+
+	responseStatus := http.StatusOK
+	if byteRange != nil {
+		getFileDTO.Range = &dto.FileRange{Offset: byteRange.Start, Length: byteRange.Length}
+		responseStatus = http.StatusPartialContent
+	}
+
+	// размер ответа берется только из fileInfo, по нему же считаются диапазоны
+	contentLength := fileInfo.SizeBytes
+	if byteRange != nil {
+		contentLength = byteRange.Length
+	}
+
+	setDriveContentHeaders(w, r, fileInfo.OriginalFilename, fileInfo.MimeType)
+
+	if r.Method == http.MethodHead {
+		if byteRange != nil {
+			w.Header().Set("Content-Range", byteRange.ContentRange(fileInfo.SizeBytes))
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(contentLength, 10))
+		w.WriteHeader(responseStatus)
+		return
+	}
+
+	fileDto, err := getFile()
+	if err != nil {
+		var responseStatus int
+		if errors.Is(err, ucase.ErrFileNotFound) {
+			responseStatus = http.StatusNotFound
+			BlockEventHandle(r, BlockEventFileNotFoundType)
+		} else if errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
+			responseStatus = http.StatusNotFound
+		} else {
+			responseStatus = http.StatusUnprocessableEntity
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+	defer func() {
+		if closer, ok := fileDto.File.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}()
+
+	if byteRange != nil {
+		w.Header().Set("Content-Range", byteRange.ContentRange(fileInfo.SizeBytes))
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(contentLength, 10))
+	w.WriteHeader(responseStatus)
+
+	_, err = io.Copy(w, fileDto.File)
+	if err != nil {
+		logging.GetLogger(r.Context()).Errorf("%s: %v", locale.T(langRequest, "file_failed_to_send"), err)
+		return
+	}
+}
+
+func parseQueryInt(value string, target *int) error {
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*target = parsed
+	return nil
+}
+
+func parseQueryInt64(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parseQueryTime принимает RFC 3339 или дату YYYY-MM-DD. Для верхней границы дата означает конец дня
+func parseQueryTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		parsed = parsed.Add(24*time.Hour - time.Second)
+	}
+	return &parsed, nil
+}
+
+func isNotModified(r *http.Request, etag string, modifiedAt time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagListMatches(ifNoneMatch, etag, false)
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !modifiedAt.UTC().Truncate(time.Second).After(since)
+}
+
+// isIfRangeMatched проверяет, что If-Range отсутствует или совпадает с текущей версией файла
+func isIfRangeMatched(r *http.Request, etag string, modifiedAt time.Time) bool {
+	ifRange := r.Header.Get("If-Range")
+	if ifRange == "" {
+		return true
+	}
+
+	if strings.HasPrefix(ifRange, "\"") || strings.HasPrefix(ifRange, "W/") {
+		return etagListMatches(ifRange, etag, true)
+	}
+
+	rangeTime, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	return modifiedAt.UTC().Truncate(time.Second).Equal(rangeTime)
+}
+
+func etagListMatches(list string, etag string, strong bool) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" && !strong {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if strong {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
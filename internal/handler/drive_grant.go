@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/ucase"
+	"assistant-go/internal/locale"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+type DriveGrantHandler struct {
+	useCase ucase.DriveGrantUseCase
+}
+
+func NewDriveGrantHandler(useCase ucase.DriveGrantUseCase) *DriveGrantHandler {
+	return &DriveGrantHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *DriveGrantHandler) Create(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	var createDTO dto.DriveGrantCreate
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&createDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = createDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	_, err = h.useCase.Create(r.Context(), createDTO, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	grants, err := h.useCase.GetByStruct(r.Context(), createDTO.StructID, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusCreated, grants)
+}
+
+func (h *DriveGrantHandler) GetByStruct(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	structID, err := strconv.Atoi(r.URL.Query().Get("structId"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	grants, err := h.useCase.GetByStruct(r.Context(), structID, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, grants)
+}
+
+func (h *DriveGrantHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	grantID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	err = h.useCase.Delete(r.Context(), grantID, authUser)
+	if err != nil {
+		responseStatus := http.StatusUnprocessableEntity
+		if errors.Is(err, ucase.ErrDriveGrantNotFound) {
+			responseStatus = http.StatusNotFound
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusNoContent, nil)
+}
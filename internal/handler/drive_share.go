@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/ucase"
+	"assistant-go/internal/layer/vmodel"
+	"assistant-go/internal/locale"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+)
+
+// SharePasswordHeader - пароль защищенной ссылки. Для прямых ссылок в браузере допускается query-параметр password
+const SharePasswordHeader = "X-Share-Password"
+
+type DriveShareHandler struct {
+	useCase ucase.DriveShareUseCase
+}
+
+func NewDriveShareHandler(useCase ucase.DriveShareUseCase) *DriveShareHandler {
+	return &DriveShareHandler{
+		useCase: useCase,
+	}
+}
+
+func (h *DriveShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	var createDTO dto.DriveShareCreate
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&createDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err = createDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	link, err := h.useCase.Create(r.Context(), createDTO, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusCreated, vmodel.DriveShareLinkFromEntity(link))
+}
+
+func (h *DriveShareHandler) GetActive(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	var structID *int
+	if structIDStr := r.URL.Query().Get("structId"); structIDStr != "" {
+		structIDInt, err := strconv.Atoi(structIDStr)
+		if err != nil {
+			BlockEventHandle(r, BlockEventInputDataType)
+			SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+			return
+		}
+		structID = &structIDInt
+	}
+
+	links, err := h.useCase.GetActive(r.Context(), structID, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, vmodel.DriveShareLinksFromEntities(links))
+}
+
+func (h *DriveShareHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	linkID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	err = h.useCase.Delete(r.Context(), linkID, authUser)
+	if err != nil {
+		responseStatus := http.StatusUnprocessableEntity
+		if errors.Is(err, ucase.ErrDriveShareNotFound) {
+			responseStatus = http.StatusNotFound
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *DriveShareHandler) GetSharedTree(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	access, ok := getShareAccess(w, r, langRequest)
+	if !ok {
+		return
+	}
+
+	tree, err := h.useCase.GetSharedTree(r.Context(), access)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), driveShareErrorStatus(r, err), 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, tree)
+}
+
+func (h *DriveShareHandler) GetSharedFile(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	access, ok := getShareAccess(w, r, langRequest)
+	if !ok {
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	structID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	sharedFileDTO := &dto.DriveSharedFileIn{
+		DriveShareAccess: access,
+		GetFile: dto.GetFile{
+			StructID:      structID,
+			SavePath:      appConf.Drive.SavePath,
+			MaxSizeBytes:  appConf.Drive.UploadMaxSize << 20,
+			UseEncryption: appConf.Drive.UseEncryption,
+			Keyring:       appKeyring,
+		},
+	}
+
+	fileInfo, err := h.useCase.GetSharedFileInfo(r.Context(), sharedFileDTO)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), driveShareErrorStatus(r, err), 0)
+		return
+	}
+
+	sendDriveFile(w, r, langRequest, &sharedFileDTO.GetFile, fileInfo, func() (*dto.FileResponse, error) {
+		return h.useCase.GetSharedFile(r.Context(), sharedFileDTO)
+	})
+}
+
+func getShareAccess(w http.ResponseWriter, r *http.Request, langRequest string) (dto.DriveShareAccess, bool) {
+	params := httprouter.ParamsFromContext(r.Context())
+	access := dto.DriveShareAccess{
+		Hash:     params.ByName("hash"),
+		Password: r.Header.Get(SharePasswordHeader),
+	}
+	if access.Password == "" {
+		access.Password = r.URL.Query().Get("password")
+	}
+	if access.Hash == "" || len(access.Hash) > 80 {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return access, false
+	}
+	return access, true
+}
+
+// driveShareErrorStatus подбирает статус ответа для публичных запросов и учитывает попытки перебора
+func driveShareErrorStatus(r *http.Request, err error) int {
+	switch {
+	case errors.Is(err, ucase.ErrDriveShareNotFound):
+		BlockEventHandle(r, BlockEventBruteForce)
+		return http.StatusNotFound
+	case errors.Is(err, ucase.ErrFileNotFound):
+		BlockEventHandle(r, BlockEventFileNotFoundType)
+		return http.StatusNotFound
+	case errors.Is(err, ucase.ErrDriveShareWrongPassword):
+		BlockEventHandle(r, BlockEventErrorSignInType)
+		return http.StatusForbidden
+	case errors.Is(err, ucase.ErrDriveSharePasswordRequired):
+		return http.StatusUnauthorized
+	case errors.Is(err, ucase.ErrDriveShareExpired), errors.Is(err, ucase.ErrDriveShareDownloadLimit):
+		return http.StatusGone
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
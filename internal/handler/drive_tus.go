@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/ucase"
+	"assistant-go/internal/locale"
+	"assistant-go/pkg/tus"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// tusBasePath - адрес создания загрузок, адрес загрузки - tusBasePath + id структуры
+const tusBasePath = "/api/drive/tus/"
+
+// TusOptions сообщает клиенту возможности сервера tus
+func (h *DriveHandler) TusOptions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(tus.HeaderResumable, tus.Version)
+	w.Header().Set(tus.HeaderVersion, tus.Version)
+	w.Header().Set(tus.HeaderExtension, tus.Extensions)
+	w.Header().Set(tus.HeaderMaxSize, strconv.FormatInt(appConf.Drive.UploadMaxSize<<20, 10))
+	w.Header().Set(tus.HeaderChecksumAlgorithm, tus.ChecksumAlgorithms)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TusCreate создает загрузку (расширение creation). Имя файла, родительская директория,
+// режим замены и sha256 всего файла передаются в Upload-Metadata: filename, parent_id, replace, sha256
+func (h *DriveHandler) TusCreate(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	w.Header().Set(tus.HeaderResumable, tus.Version)
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	if !h.checkTusVersion(w, r, langRequest) {
+		return
+	}
+
+	if r.Header.Get(tus.HeaderUploadDeferLength) != "" {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "drive_tus_invalid_header"), http.StatusBadRequest, 0)
+		return
+	}
+
+	uploadLength, err := strconv.ParseInt(r.Header.Get(tus.HeaderUploadLength), 10, 64)
+	if err != nil || uploadLength < 0 {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "drive_tus_invalid_header"), http.StatusBadRequest, 0)
+		return
+	}
+
+	metadata, err := tus.ParseMetadata(r.Header.Get(tus.HeaderUploadMetadata))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "drive_tus_invalid_header"), http.StatusBadRequest, 0)
+		return
+	}
+
+	chunkPrepareDTO := dto.DriveChunkPrepare{
+		Filename: metadata["filename"],
+		FullSize: uploadLength,
+		Replace:  metadata["replace"] == "true",
+	}
+	if parentIDStr := metadata["parent_id"]; parentIDStr != "" {
+		parentID, err := strconv.Atoi(parentIDStr)
+		if err != nil {
+			BlockEventHandle(r, BlockEventInputDataType)
+			SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+			return
+		}
+		chunkPrepareDTO.ParentID = &parentID
+	}
+	if sha256 := metadata["sha256"]; sha256 != "" {
+		chunkPrepareDTO.SHA256 = &sha256
+	}
+
+	if err = chunkPrepareDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusBadRequest, 0)
+		return
+	}
+
+	inDTO := dto.DriveChunkPrepareIn{
+		DriveChunkPrepare:     chunkPrepareDTO,
+		MaxSizeBytes:          appConf.Drive.UploadMaxSize << 20,
+		StorageMaxSizePerUser: appConf.Drive.LimitPerUser << 20,
+	}
+
+	responseDTO, err := h.useCase.ChunkPrepare(r.Context(), authUser, inDTO)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), tusErrorStatus(err), 0)
+		return
+	}
+
+	// пустой файл завершается сразу, PATCH-запросов для него не будет
+	if uploadLength == 0 {
+		chunkEndIn := dto.DriveChunkEndIn{
+			StructID:      responseDTO.StructID,
+			SavePath:      appConf.Drive.SavePath,
+			UseEncryption: appConf.Drive.UseEncryption,
+			Keyring:       appKeyring,
+		}
+		if err = h.useCase.ChunkEnd(r.Context(), authUser, chunkEndIn); err != nil {
+			SendErrorResponse(w, buildErrorMessage(langRequest, err), tusErrorStatus(err), 0)
+			return
+		}
+	}
+
+	w.Header().Set("Location", tusBasePath+strconv.Itoa(responseDTO.StructID))
+	w.Header().Set(tus.HeaderUploadOffset, "0")
+	w.WriteHeader(http.StatusCreated)
+}
+
+// TusHead возвращает смещение, с которого клиент должен продолжить загрузку
+func (h *DriveHandler) TusHead(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	w.Header().Set(tus.HeaderResumable, tus.Version)
+	w.Header().Set("Cache-Control", "no-store")
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	if !h.checkTusVersion(w, r, langRequest) {
+		return
+	}
+
+	structID, ok := h.getTusUploadID(w, r, langRequest)
+	if !ok {
+		return
+	}
+
+	upload, err := h.useCase.TusUploadInfo(r.Context(), structID, appConf.Drive.UseEncryption, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), tusErrorStatus(err), 0)
+		return
+	}
+
+	w.Header().Set(tus.HeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set(tus.HeaderUploadLength, strconv.FormatInt(upload.Length, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// TusPatch дописывает тело запроса в загрузку с указанного в Upload-Offset смещения
+func (h *DriveHandler) TusPatch(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	w.Header().Set(tus.HeaderResumable, tus.Version)
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	if !h.checkTusVersion(w, r, langRequest) {
+		return
+	}
+
+	structID, ok := h.getTusUploadID(w, r, langRequest)
+	if !ok {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != tus.OffsetContentType {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "drive_tus_invalid_header"), http.StatusUnsupportedMediaType, 0)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get(tus.HeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "drive_tus_invalid_header"), http.StatusBadRequest, 0)
+		return
+	}
+
+	// размер чанка в хранилище задается заранее, поэтому тело без Content-Length не принимается
+	if r.ContentLength < 0 {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "drive_tus_invalid_header"), http.StatusLengthRequired, 0)
+		return
+	}
+
+	appendDTO := dto.DriveTusAppend{
+		StructID:              structID,
+		Offset:                offset,
+		Body:                  r.Body,
+		ContentLength:         r.ContentLength,
+		MaxSizeBytes:          appConf.Drive.UploadMaxSize << 20,
+		StorageMaxSizePerUser: appConf.Drive.LimitPerUser << 20,
+		SavePath:              appConf.Drive.SavePath,
+		UseEncryption:         appConf.Drive.UseEncryption,
+		Keyring:               appKeyring,
+	}
+
+	if checksumHeader := r.Header.Get(tus.HeaderUploadChecksum); checksumHeader != "" {
+		appendDTO.ChecksumAlgorithm, appendDTO.Checksum, err = tus.ParseChecksum(checksumHeader)
+		if err != nil {
+			BlockEventHandle(r, BlockEventInputDataType)
+			SendErrorResponse(w, locale.T(langRequest, "drive_tus_invalid_header"), http.StatusBadRequest, 0)
+			return
+		}
+	}
+
+	upload, err := h.useCase.TusAppend(r.Context(), authUser, appendDTO)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), tusErrorStatus(err), 0)
+		return
+	}
+
+	w.Header().Set(tus.HeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TusTerminate удаляет незавершенную загрузку (расширение termination)
+func (h *DriveHandler) TusTerminate(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+	w.Header().Set(tus.HeaderResumable, tus.Version)
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	if !h.checkTusVersion(w, r, langRequest) {
+		return
+	}
+
+	structID, ok := h.getTusUploadID(w, r, langRequest)
+	if !ok {
+		return
+	}
+
+	err = h.useCase.TusTerminate(r.Context(), structID, appConf.Drive.SavePath, authUser)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), tusErrorStatus(err), 0)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TusMethodOverride обрабатывает POST с заголовком X-HTTP-Method-Override.
+// DELETE /api/drive/tus/:id нельзя зарегистрировать рядом с DELETE /api/drive/:id,
+// поэтому termination доступна только так (протокол tus допускает этот заголовок)
+func (h *DriveHandler) TusMethodOverride(w http.ResponseWriter, r *http.Request) {
+	switch strings.ToUpper(r.Header.Get(tus.HeaderMethodOverride)) {
+	case http.MethodPatch:
+		h.TusPatch(w, r)
+	case http.MethodDelete:
+		h.TusTerminate(w, r)
+	case http.MethodHead:
+		h.TusHead(w, r)
+	default:
+		langRequest := locale.GetLangFromContext(r.Context())
+		w.Header().Set(tus.HeaderResumable, tus.Version)
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "drive_tus_invalid_header"), http.StatusMethodNotAllowed, 0)
+	}
+}
+
+func (h *DriveHandler) checkTusVersion(w http.ResponseWriter, r *http.Request, langRequest string) bool {
+	if r.Header.Get(tus.HeaderResumable) == tus.Version {
+		return true
+	}
+	w.Header().Set(tus.HeaderVersion, tus.Version)
+	BlockEventHandle(r, BlockEventInputDataType)
+	SendErrorResponse(w, locale.T(langRequest, "drive_tus_version_unsupported"), http.StatusPreconditionFailed, 0)
+	return false
+}
+
+func (h *DriveHandler) getTusUploadID(w http.ResponseWriter, r *http.Request, langRequest string) (int, bool) {
+	params := httprouter.ParamsFromContext(r.Context())
+	structID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusNotFound, 0)
+		return 0, false
+	}
+	return structID, true
+}
+
+// tusErrorStatus сопоставляет ошибки загрузки статусам, которых ожидают клиенты tus
+func tusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ucase.ErrFileNotFound),
+		errors.Is(err, ucase.ErrDriveStructNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ucase.ErrDriveUploadOffsetMismatch),
+		errors.Is(err, ucase.ErrDriveUploadCompleted):
+		return http.StatusConflict
+	case errors.Is(err, ucase.ErrDriveChecksumMismatch),
+		errors.Is(err, ucase.ErrDriveFileHashMismatch):
+		return tus.StatusChecksumMismatch
+	case errors.Is(err, ucase.ErrDriveFileTooLarge),
+		errors.Is(err, ucase.ErrDriveFileSystemIsFull),
+		errors.Is(err, ucase.ErrDriveFileSizeMismatch):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
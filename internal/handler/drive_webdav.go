@@ -0,0 +1,784 @@
+package handler
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"assistant-go/internal/locale"
+	"assistant-go/internal/logging"
+	"context"
+	"errors"
+	"golang.org/x/net/webdav"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const WebDAVPrefix = "/dav"
+
+// типы элементов и состояние загрузки в DriveTree, значения как в ucase
+const (
+	driveTypeDirectory        int8 = 0
+	driveTypeFile             int8 = 1
+	driveUploadStateUploading int8 = 0
+)
+
+// WebDAVMethods - методы, которые обслуживает WebDAV (класс 1 и блокировки)
+var WebDAVMethods = []string{
+	http.MethodOptions,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPut,
+	http.MethodDelete,
+	"PROPFIND",
+	"PROPPATCH",
+	"MKCOL",
+	"COPY",
+	"MOVE",
+	"LOCK",
+	"UNLOCK",
+}
+
+type DriveWebDAVHandler struct {
+	useCase     ucase.DriveUseCase
+	userUseCase ucase.UserUseCase
+
+	lockSystem webdav.LockSystem
+}
+
+func NewDriveWebDAVHandler(useCase ucase.DriveUseCase, userUseCase ucase.UserUseCase) *DriveWebDAVHandler {
+	return &DriveWebDAVHandler{
+		useCase:     useCase,
+		userUseCase: userUseCase,
+		lockSystem:  webdav.NewMemLS(),
+	}
+}
+
+// Serve - точка входа WebDAV. Авторизация через HTTP Basic (логин и токен приложения) или Bearer
+func (h *DriveWebDAVHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := h.authenticate(r)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="drive", charset="UTF-8"`)
+		// клиенты WebDAV сначала приходят без учетных данных и ждут 401, это не считается подозрительным событием
+		if r.Header.Get("Authorization") != "" {
+			BlockEventHandle(r, BlockEventUnauthorizedType)
+		}
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	// webdav.Handler отвечает 405 на любую ошибку сохранения, поэтому заведомо неподходящий PUT отклоняется заранее
+	if r.Method == http.MethodPut && r.ContentLength > 0 {
+		if r.ContentLength > appConf.Drive.UploadMaxSize<<20 {
+			SendErrorResponse(w, buildErrorMessage(langRequest, ucase.ErrDriveFileTooLarge), http.StatusRequestEntityTooLarge, 0)
+			return
+		}
+		space, err := h.useCase.Space(r.Context(), authUser, storageDefaultLimits())
+		if err != nil {
+			SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+			return
+		}
+		if space.Drive.Used+r.ContentLength > space.Drive.Total {
+			SendErrorResponse(w, buildErrorMessage(langRequest, ucase.ErrDriveFileSystemIsFull), http.StatusInsufficientStorage, 0)
+			return
+		}
+	}
+
+	davHandler := &webdav.Handler{
+		Prefix:     WebDAVPrefix,
+		FileSystem: newDriveFS(h.useCase, authUser),
+		LockSystem: newUserLockSystem(h.lockSystem, authUser.ID),
+		Logger: func(r *http.Request, err error) {
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				logging.GetLogger(r.Context()).Errorf("webdav %s %s: %v", r.Method, r.URL.Path, err)
+			}
+		},
+	}
+	davHandler.ServeHTTP(w, r)
+}
+
+func (h *DriveWebDAVHandler) authenticate(r *http.Request) (*entity.User, error) {
+	if login, password, ok := r.BasicAuth(); ok {
+		return h.userUseCase.AuthenticateBasic(r.Context(), login, password)
+	}
+
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return nil, ucase.ErrUserIncorrectUsernameOrPassword
+	}
+	return h.userUseCase.AuthenticateToken(r.Context(), strings.TrimPrefix(header, prefix))
+}
+
+// userLockSystem - блокировки пользователя в общей для всех пользователей webdav.LockSystem.
+// Пути блокировок получают префикс с ID пользователя, а токены - ID владельца,
+// поэтому одинаковые пути разных пользователей не конфликтуют, а чужие токены не действуют
+type userLockSystem struct {
+	ls          webdav.LockSystem
+	root        string
+	tokenPrefix string
+}
+
+func newUserLockSystem(ls webdav.LockSystem, userID int) *userLockSystem {
+	return &userLockSystem{
+		ls:          ls,
+		root:        "/" + strconv.Itoa(userID),
+		tokenPrefix: strconv.Itoa(userID) + ":",
+	}
+}
+
+func (l *userLockSystem) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (func(), error) {
+	if name0 != "" {
+		name0 = l.root + name0
+	}
+	if name1 != "" {
+		name1 = l.root + name1
+	}
+	userConditions := make([]webdav.Condition, 0, len(conditions))
+	for _, condition := range conditions {
+		if condition.Token != "" {
+			token, ok := strings.CutPrefix(condition.Token, l.tokenPrefix)
+			if !ok {
+				// токен другого пользователя не подтверждает блокировку
+				continue
+			}
+			condition.Token = token
+		}
+		userConditions = append(userConditions, condition)
+	}
+	return l.ls.Confirm(now, name0, name1, userConditions...)
+}
+
+func (l *userLockSystem) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	details.Root = l.root + details.Root
+	token, err := l.ls.Create(now, details)
+	if err != nil {
+		return "", err
+	}
+	return l.tokenPrefix + token, nil
+}
+
+func (l *userLockSystem) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	token, ok := strings.CutPrefix(token, l.tokenPrefix)
+	if !ok {
+		return webdav.LockDetails{}, webdav.ErrNoSuchLock
+	}
+	details, err := l.ls.Refresh(now, token, duration)
+	if err != nil {
+		return webdav.LockDetails{}, err
+	}
+	details.Root = strings.TrimPrefix(details.Root, l.root)
+	if details.Root == "" {
+		details.Root = "/"
+	}
+	return details, nil
+}
+
+func (l *userLockSystem) Unlock(now time.Time, token string) error {
+	token, ok := strings.CutPrefix(token, l.tokenPrefix)
+	if !ok {
+		return webdav.ErrNoSuchLock
+	}
+	return l.ls.Unlock(now, token)
+}
+
+// driveFS отображает диск пользователя на webdav.FileSystem. Создается на каждый запрос:
+// PROPFIND открывает каждый элемент директории отдельно, поэтому листинги кэшируются до первого изменения
+type driveFS struct {
+	useCase ucase.DriveUseCase
+	user    *entity.User
+
+	mu    sync.Mutex
+	dirs  map[string]*int
+	lists map[string][]*dto.DriveTree
+}
+
+func newDriveFS(useCase ucase.DriveUseCase, user *entity.User) *driveFS {
+	driveFileSystem := &driveFS{
+		useCase: useCase,
+		user:    user,
+	}
+	driveFileSystem.invalidate()
+	return driveFileSystem
+}
+
+func (f *driveFS) Mkdir(ctx context.Context, name string, _ os.FileMode) error {
+	dirPath, base := splitWebDAVPath(name)
+	if base == "" {
+		return os.ErrExist
+	}
+
+	if _, err := f.stat(ctx, name); err == nil {
+		return os.ErrExist
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	parentID, err := f.resolveDir(ctx, dirPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.useCase.CreateDirectory(ctx, &dto.DriveCreateDirectory{Name: base, ParentID: parentID}, f.user)
+	f.invalidate()
+	return webdavError(err)
+}
+
+func (f *driveFS) OpenFile(ctx context.Context, name string, flag int, _ os.FileMode) (webdav.File, error) {
+	item, err := f.stat(ctx, name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		if item.IsDir() {
+			return &webdavDirFile{ctx: ctx, fs: f, info: item, dirPath: cleanWebDAVPath(name)}, nil
+		}
+		return &webdavReadFile{ctx: ctx, fs: f, info: item}, nil
+	}
+
+	if err != nil && flag&os.O_CREATE == 0 {
+		return nil, err
+	}
+	if err == nil && item.IsDir() {
+		return nil, os.ErrExist
+	}
+
+	dirPath, base := splitWebDAVPath(name)
+	if base == "" {
+		return nil, os.ErrInvalid
+	}
+	parentID, err := f.resolveDir(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(appConf.Drive.WebDAVTempPath, "webdav-*")
+	if err != nil {
+		return nil, err
+	}
+	return &webdavWriteFile{ctx: ctx, fs: f, parentID: parentID, name: base, tmp: tmp}, nil
+}
+
+// RemoveAll перемещает элемент в корзину
+func (f *driveFS) RemoveAll(ctx context.Context, name string) error {
+	item, err := f.stat(ctx, name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if item.item == nil {
+		return os.ErrPermission
+	}
+
+	err = f.useCase.Delete(ctx, item.item.ID, f.user)
+	f.invalidate()
+	return webdavError(err)
+}
+
+// Rename переносит элемент в другую директорию через RenMov и при необходимости переименовывает
+func (f *driveFS) Rename(ctx context.Context, oldName, newName string) error {
+	item, err := f.stat(ctx, oldName)
+	if err != nil {
+		return err
+	}
+	if item.item == nil {
+		return os.ErrPermission
+	}
+
+	oldDirPath, _ := splitWebDAVPath(oldName)
+	newDirPath, newBase := splitWebDAVPath(newName)
+	if newBase == "" {
+		return os.ErrInvalid
+	}
+
+	oldParentID, err := f.resolveDir(ctx, oldDirPath)
+	if err != nil {
+		return err
+	}
+	newParentID, err := f.resolveDir(ctx, newDirPath)
+	if err != nil {
+		return err
+	}
+	defer f.invalidate()
+
+	if !sameParent(oldParentID, newParentID) {
+		err = f.useCase.RenMov(ctx, f.user, dto.DriveRenMov{StructIDs: []int{item.item.ID}, ParentID: newParentID})
+		if err != nil {
+			return webdavError(err)
+		}
+	}
+	if newBase != item.item.Name {
+		err = f.useCase.Rename(ctx, item.item.ID, newBase, f.user)
+		if err != nil {
+			return webdavError(err)
+		}
+	}
+	return nil
+}
+
+func (f *driveFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	item, err := f.stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// stat ищет элемент в листинге родительской директории. Как и ResolvePath, при совпадении имен предпочитает директорию
+func (f *driveFS) stat(ctx context.Context, name string) (*driveFileInfo, error) {
+	dirPath, base := splitWebDAVPath(name)
+	if base == "" {
+		return &driveFileInfo{}, nil
+	}
+
+	list, err := f.list(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *dto.DriveTree
+	for _, item := range list {
+		if item.Name != base {
+			continue
+		}
+		if found == nil || item.Type < found.Type {
+			found = item
+		}
+	}
+	if found == nil {
+		return nil, os.ErrNotExist
+	}
+	return &driveFileInfo{item: found}, nil
+}
+
+// list возвращает содержимое директории без незавершенных загрузок
+func (f *driveFS) list(ctx context.Context, dirPath string) ([]*dto.DriveTree, error) {
+	f.mu.Lock()
+	cached, ok := f.lists[dirPath]
+	f.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	parentID, err := f.resolveDir(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := f.useCase.GetTree(ctx, parentID, f.user)
+	if err != nil {
+		return nil, webdavError(err)
+	}
+
+	list := make([]*dto.DriveTree, 0, len(tree))
+	for _, item := range tree {
+		if item.Type == driveTypeFile && item.UploadState == driveUploadStateUploading {
+			continue
+		}
+		list = append(list, item)
+	}
+
+	f.mu.Lock()
+	f.lists[dirPath] = list
+	f.mu.Unlock()
+	return list, nil
+}
+
+// resolveDir возвращает ID директории по пути, nil - корень диска
+func (f *driveFS) resolveDir(ctx context.Context, dirPath string) (*int, error) {
+	if dirPath == "/" {
+		return nil, nil
+	}
+
+	f.mu.Lock()
+	cached, ok := f.dirs[dirPath]
+	f.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	item, err := f.useCase.ResolvePath(ctx, dirPath, f.user)
+	if err != nil {
+		return nil, webdavError(err)
+	}
+	if item.Type != driveTypeDirectory {
+		return nil, os.ErrNotExist
+	}
+
+	f.mu.Lock()
+	f.dirs[dirPath] = &item.ID
+	f.mu.Unlock()
+	return &item.ID, nil
+}
+
+func (f *driveFS) invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dirs = make(map[string]*int)
+	f.lists = make(map[string][]*dto.DriveTree)
+}
+
+// upload сохраняет файл с заменой существующего. Файлы больше лимита одиночной загрузки
+// загружаются чанками, как tus: квота, шифрование и дедупликация остаются на стороне DriveUseCase
+func (f *driveFS) upload(ctx context.Context, parentID *int, name string, file *os.File, size int64) error {
+	_, err := f.useCase.UploadFile(ctx, dto.DriveUploadFile{
+		File:                  file,
+		OriginalFilename:      name,
+		MaxSizeBytes:          appConf.Drive.UploadMaxSize << 20,
+		StorageMaxSizePerUser: appConf.Drive.LimitPerUser << 20,
+		SavePath:              appConf.Drive.SavePath,
+		ParentID:              parentID,
+		UseEncryption:         appConf.Drive.UseEncryption,
+		Keyring:               appKeyring,
+		Replace:               true,
+	}, f.user)
+	if !errors.Is(err, ucase.ErrDriveFileTooLargeUseChunks) {
+		return err
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return ucase.ErrFileResettingPointer
+	}
+
+	prepared, err := f.useCase.ChunkPrepare(ctx, f.user, dto.DriveChunkPrepareIn{
+		DriveChunkPrepare: dto.DriveChunkPrepare{
+			Filename: name,
+			FullSize: size,
+			ParentID: parentID,
+			Replace:  true,
+		},
+		MaxSizeBytes:          appConf.Drive.UploadMaxSize << 20,
+		StorageMaxSizePerUser: appConf.Drive.LimitPerUser << 20,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = f.useCase.TusAppend(ctx, f.user, dto.DriveTusAppend{
+		StructID:              prepared.StructID,
+		Offset:                0,
+		Body:                  file,
+		ContentLength:         size,
+		MaxSizeBytes:          appConf.Drive.UploadMaxSize << 20,
+		StorageMaxSizePerUser: appConf.Drive.LimitPerUser << 20,
+		SavePath:              appConf.Drive.SavePath,
+		UseEncryption:         appConf.Drive.UseEncryption,
+		Keyring:               appKeyring,
+	})
+	if err != nil {
+		terminateErr := f.useCase.TusTerminate(ctx, prepared.StructID, appConf.Drive.SavePath, f.user)
+		if terminateErr != nil &&
+			!errors.Is(terminateErr, ucase.ErrDriveUploadCompleted) && !errors.Is(terminateErr, ucase.ErrFileNotFound) {
+			logging.GetLogger(ctx).Error(terminateErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// driveFileInfo - элемент диска для webdav. item = nil - корень
+type driveFileInfo struct {
+	item *dto.DriveTree
+}
+
+func (i *driveFileInfo) Name() string {
+	if i.item == nil {
+		return "/"
+	}
+	return i.item.Name
+}
+
+func (i *driveFileInfo) Size() int64 {
+	if i.IsDir() {
+		return 0
+	}
+	return i.item.Size
+}
+
+func (i *driveFileInfo) Mode() fs.FileMode {
+	if i.IsDir() {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (i *driveFileInfo) ModTime() time.Time {
+	if i.item == nil {
+		return time.Time{}
+	}
+	return i.item.UpdatedAt
+}
+
+func (i *driveFileInfo) IsDir() bool {
+	return i.item == nil || i.item.Type == driveTypeDirectory
+}
+
+func (i *driveFileInfo) Sys() any {
+	return nil
+}
+
+// ContentType определяется по расширению, иначе webdav открывал бы каждый файл листинга
+func (i *driveFileInfo) ContentType(_ context.Context) (string, error) {
+	contentType := mime.TypeByExtension(path.Ext(i.Name()))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return contentType, nil
+}
+
+func (i *driveFileInfo) ETag(_ context.Context) (string, error) {
+	if i.item == nil || i.item.SHA256 == nil {
+		return "", webdav.ErrNotImplemented
+	}
+	return `"` + *i.item.SHA256 + `"`, nil
+}
+
+// webdavReadFile читает файл через GetFile, поток открывается при первом чтении с текущего смещения
+type webdavReadFile struct {
+	ctx    context.Context
+	fs     *driveFS
+	info   *driveFileInfo
+	offset int64
+	reader io.Reader
+}
+
+func (f *webdavReadFile) Read(p []byte) (int, error) {
+	if f.offset >= f.info.Size() {
+		return 0, io.EOF
+	}
+
+	if f.reader == nil {
+		getFileDTO := &dto.GetFile{
+			StructID:      f.info.item.ID,
+			SavePath:      appConf.Drive.SavePath,
+			MaxSizeBytes:  appConf.Drive.UploadMaxSize << 20,
+			UseEncryption: appConf.Drive.UseEncryption,
+			Keyring:       appKeyring,
+		}
+		if f.offset > 0 {
+			getFileDTO.Range = &dto.FileRange{Offset: f.offset, Length: f.info.Size() - f.offset}
+		}
+
+		fileDto, err := f.fs.useCase.GetFile(f.ctx, getFileDTO, f.fs.user)
+		if err != nil {
+			return 0, webdavError(err)
+		}
+		f.reader = fileDto.File
+	}
+
+	n, err := f.reader.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *webdavReadFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.Size()
+	}
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+
+	if offset != f.offset {
+		f.closeReader()
+		f.offset = offset
+	}
+	return f.offset, nil
+}
+
+func (f *webdavReadFile) Readdir(_ int) ([]fs.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (f *webdavReadFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *webdavReadFile) Write(_ []byte) (int, error) {
+	return 0, os.ErrPermission
+}
+
+func (f *webdavReadFile) Close() error {
+	f.closeReader()
+	return nil
+}
+
+func (f *webdavReadFile) closeReader() {
+	if closer, ok := f.reader.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	f.reader = nil
+}
+
+type webdavDirFile struct {
+	ctx     context.Context
+	fs      *driveFS
+	info    *driveFileInfo
+	dirPath string
+	entries []fs.FileInfo
+	loaded  bool
+}
+
+func (f *webdavDirFile) Readdir(count int) ([]fs.FileInfo, error) {
+	if !f.loaded {
+		list, err := f.fs.list(f.ctx, f.dirPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			f.entries = append(f.entries, &driveFileInfo{item: item})
+		}
+		f.loaded = true
+	}
+
+	if count <= 0 {
+		entries := f.entries
+		f.entries = nil
+		return entries, nil
+	}
+	if len(f.entries) == 0 {
+		return nil, io.EOF
+	}
+	count = min(count, len(f.entries))
+	entries := f.entries[:count]
+	f.entries = f.entries[count:]
+	return entries, nil
+}
+
+func (f *webdavDirFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *webdavDirFile) Read(_ []byte) (int, error) {
+	return 0, os.ErrInvalid
+}
+
+func (f *webdavDirFile) Seek(_ int64, _ int) (int64, error) {
+	return 0, os.ErrInvalid
+}
+
+func (f *webdavDirFile) Write(_ []byte) (int, error) {
+	return 0, os.ErrInvalid
+}
+
+func (f *webdavDirFile) Close() error {
+	return nil
+}
+
+// webdavWriteFile копит тело PUT во временном файле, на диск пользователя файл сохраняется при закрытии
+type webdavWriteFile struct {
+	ctx      context.Context
+	fs       *driveFS
+	parentID *int
+	name     string
+	tmp      *os.File
+	size     int64
+	closed   bool
+}
+
+func (f *webdavWriteFile) Write(p []byte) (int, error) {
+	if f.size+int64(len(p)) > appConf.Drive.UploadMaxSize<<20 {
+		return 0, ucase.ErrDriveFileTooLarge
+	}
+	n, err := f.tmp.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *webdavWriteFile) Stat() (fs.FileInfo, error) {
+	return &driveFileInfo{item: &dto.DriveTree{
+		Name:      f.name,
+		Type:      driveTypeFile,
+		Size:      f.size,
+		UpdatedAt: time.Now().UTC(),
+	}}, nil
+}
+
+func (f *webdavWriteFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	defer func() {
+		_ = f.tmp.Close()
+		_ = os.Remove(f.tmp.Name())
+	}()
+
+	if _, err := f.tmp.Seek(0, io.SeekStart); err != nil {
+		return ucase.ErrFileResettingPointer
+	}
+	err := f.fs.upload(f.ctx, f.parentID, f.name, f.tmp, f.size)
+	f.fs.invalidate()
+	return err
+}
+
+func (f *webdavWriteFile) Read(_ []byte) (int, error) {
+	return 0, os.ErrInvalid
+}
+
+func (f *webdavWriteFile) Seek(_ int64, _ int) (int64, error) {
+	return 0, os.ErrInvalid
+}
+
+func (f *webdavWriteFile) Readdir(_ int) ([]fs.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+// webdavError приводит ошибки диска к ошибкам os, по которым webdav выбирает статус ответа
+func webdavError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ucase.ErrDrivePathNotFound),
+		errors.Is(err, ucase.ErrDrivePathInvalid),
+		errors.Is(err, ucase.ErrDriveStructNotFound),
+		errors.Is(err, ucase.ErrDriveParentIdNotFound),
+		errors.Is(err, ucase.ErrFileNotFound):
+		return os.ErrNotExist
+	case errors.Is(err, ucase.ErrDriveDirectoryExists):
+		return os.ErrExist
+	case errors.Is(err, ucase.ErrDriveAccessDenied):
+		return os.ErrPermission
+	default:
+		return err
+	}
+}
+
+func cleanWebDAVPath(name string) string {
+	return path.Clean("/" + name)
+}
+
+// splitWebDAVPath делит путь на родительскую директорию и имя. Для корня имя пустое
+func splitWebDAVPath(name string) (string, string) {
+	name = cleanWebDAVPath(name)
+	if name == "/" {
+		return "/", ""
+	}
+	dirPath, base := path.Split(name)
+	return cleanWebDAVPath(dirPath), base
+}
+
+func sameParent(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -13,6 +13,8 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -61,6 +63,8 @@ func (h *FileHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		MaxSizeBytes:     appConf.File.UploadMaxSize << 20,
 		StorageMaxSize:   appConf.File.LimitStoragePerUser << 20,
 		SavePath:         appConf.File.SavePath,
+		UseEncryption:    appConf.File.UseEncryption,
+		Keyring:          appKeyring,
 	}
 
 	upload, err := h.useCase.Upload(r.Context(), uploadFileDto, authUser)
@@ -117,6 +121,7 @@ func (h *FileHandler) GetByHash(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fileHashDto.SavePath = appConf.File.SavePath
+	fileHashDto.Keyring = appKeyring
 	fileDto, err := h.useCase.GetFileByHash(r.Context(), fileHashDto)
 	if err != nil {
 		var responseStatus int
@@ -153,3 +158,75 @@ func (h *FileHandler) GetByHash(w http.ResponseWriter, r *http.Request) {
 	}
 	return
 }
+
+func (h *FileHandler) GetThumbnailByHash(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	params := httprouter.ParamsFromContext(r.Context())
+	hashParam := params.ByName("hash")
+	if hashParam == "" {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	size, err := getThumbnailSize(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	// превью вложений заметок шифруются, если включено шифрование файлов диска или вложений
+	thumbnailDTO := dto.GetThumbnail{
+		Hash:          hashParam,
+		Size:          size,
+		SavePath:      appConf.File.SavePath,
+		UseEncryption: appConf.Drive.UseEncryption || appConf.File.UseEncryption,
+		Keyring:       appKeyring,
+	}
+	if err = thumbnailDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	fileDto, err := h.useCase.GetThumbnailByHash(r.Context(), thumbnailDTO)
+	if err != nil {
+		var responseStatus int
+		if errors.Is(err, ucase.ErrFileNotFound) {
+			responseStatus = http.StatusNotFound
+			BlockEventHandle(r, BlockEventFileNotFoundType)
+		} else if errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
+			responseStatus = http.StatusNotFound
+		} else {
+			responseStatus = http.StatusUnprocessableEntity
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+
+	sendThumbnail(w, r, langRequest, fileDto)
+}
+
+// getThumbnailSize читает размер превью из параметра size, по умолчанию - ucase.ThumbnailDefaultSize
+func getThumbnailSize(r *http.Request) (int, error) {
+	sizeParam := r.URL.Query().Get("size")
+	if sizeParam == "" {
+		return ucase.ThumbnailDefaultSize, nil
+	}
+	return strconv.Atoi(sizeParam)
+}
+
+func sendThumbnail(w http.ResponseWriter, r *http.Request, langRequest string, fileDto *dto.FileResponse) {
+	filename := url.PathEscape(fileDto.OriginalFilename)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename*=UTF-8''%s", filename))
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Content-Length", strconv.FormatInt(fileDto.SizeBytes, 10))
+	w.WriteHeader(http.StatusOK)
+
+	_, err := io.Copy(w, fileDto.File)
+	if err != nil {
+		logging.GetLogger(r.Context()).Errorf("%s: %v", locale.T(langRequest, "file_failed_to_send"), err)
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"assistant-go/internal/layer/vmodel"
 	"assistant-go/internal/locale"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/julienschmidt/httprouter"
 	"net/http"
+	"strconv"
 )
 
 type UserHandler struct {
@@ -151,3 +154,89 @@ func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 
 	SendResponse(w, http.StatusNoContent, nil)
 }
+
+func (h *UserHandler) CreateAppToken(w http.ResponseWriter, r *http.Request) {
+	var createDTO dto.UserAppTokenCreate
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&createDTO)
+	if err != nil {
+		BlockEventHandle(r, BlockEventDecodeBodyType)
+		SendErrorResponse(w, locale.T(langRequest, "error_reading_request_body"), http.StatusBadRequest, 0)
+		return
+	}
+
+	if err := createDTO.Validate(langRequest); err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, fmt.Sprint(err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	appToken, token, err := h.useCase.CreateAppToken(r.Context(), authUser.ID, createDTO)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusCreated, &vmodel.UserAppTokenCreated{
+		UserAppToken: vmodel.UserAppTokenFromEntity(appToken),
+		Token:        token,
+	})
+}
+
+func (h *UserHandler) GetAppTokens(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	tokens, err := h.useCase.GetAppTokens(r.Context(), authUser.ID)
+	if err != nil {
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), http.StatusUnprocessableEntity, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusOK, vmodel.UserAppTokensFromEntities(tokens))
+}
+
+func (h *UserHandler) DeleteAppToken(w http.ResponseWriter, r *http.Request) {
+	langRequest := locale.GetLangFromContext(r.Context())
+
+	authUser, err := GetAuthUser(r)
+	if err != nil {
+		BlockEventHandle(r, BlockEventUnauthorizedType)
+		SendErrorResponse(w, locale.T(langRequest, "unauthorized"), http.StatusUnauthorized, 0)
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+	tokenID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		BlockEventHandle(r, BlockEventInputDataType)
+		SendErrorResponse(w, locale.T(langRequest, "parameter_conversion_error"), http.StatusBadRequest, 0)
+		return
+	}
+
+	err = h.useCase.DeleteAppToken(r.Context(), authUser.ID, tokenID)
+	if err != nil {
+		responseStatus := http.StatusUnprocessableEntity
+		if errors.Is(err, ucase.ErrUserAppTokenNotFound) {
+			responseStatus = http.StatusNotFound
+		}
+		SendErrorResponse(w, buildErrorMessage(langRequest, err), responseStatus, 0)
+		return
+	}
+
+	SendResponse(w, http.StatusNoContent, nil)
+}
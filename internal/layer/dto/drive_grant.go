@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"assistant-go/pkg/vld"
+	"time"
+)
+
+// DriveGrantCreate - выдача доступа к директории. Role: 1 - просмотр, 2 - редактирование
+type DriveGrantCreate struct {
+	StructID int    `json:"struct_id" validate:"required"`
+	Login    string `json:"login" validate:"required,min=1,max=255"`
+	Role     int8   `json:"role" validate:"required,oneof=1 2"`
+}
+
+func (dto *DriveGrantCreate) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
+type DriveGrantItem struct {
+	ID           int       `json:"id"`
+	StructID     int       `json:"struct_id"`
+	GranteeID    int       `json:"grantee_id"`
+	GranteeLogin string    `json:"grantee_login"`
+	Role         int8      `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "assistant-go/pkg/keyring"
+
+type DriveKeyRotateIn struct {
+	SavePath      string
+	UseEncryption bool
+	Keyring       *keyring.Keyring
+	// Full - перешифровать все файлы новыми ключами данных, а не только перезавернуть ключи
+	Full bool
+	// Resume - продолжить прерванное перешифрование, не создавая ключи данных заново
+	Resume bool
+	// OnProgress вызывается периодически во время перешифрования и после его завершения
+	OnProgress func(progress DriveKeyRotateProgress)
+}
+
+// DriveKeyRotateProgress - состояние ротации. Rewrapped - ключи данных, перезавернутые текущим мастер-ключом
+type DriveKeyRotateProgress struct {
+	Rewrapped   int
+	Reencrypted int
+	// Skipped - объекты, запись которых изменилась во время перешифрования, или объекты, которых нет в хранилище
+	Skipped     []string
+	DeletedKeys int
+}
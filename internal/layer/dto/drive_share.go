@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"assistant-go/pkg/vld"
+	"time"
+)
+
+type DriveShareCreate struct {
+	StructID     int        `json:"struct_id" validate:"required"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Password     *string    `json:"password" validate:"omitempty,min=4,max=72"`
+	MaxDownloads *int       `json:"max_downloads" validate:"omitempty,min=1"`
+}
+
+func (dto *DriveShareCreate) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
+// DriveShareAccess - доступ к публичной ссылке по хешу и, если ссылка защищена, паролю
+type DriveShareAccess struct {
+	Hash     string
+	Password string
+}
+
+type DriveSharedFileIn struct {
+	DriveShareAccess
+	GetFile
+}
+
+type DriveSharedItem struct {
+	ID        int       `json:"id"`
+	ParentID  *int      `json:"parent_id"`
+	Name      string    `json:"name"`
+	Type      int8      `json:"type"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DriveSharedTree struct {
+	RootID    int                `json:"root_id"`
+	ExpiresAt *time.Time         `json:"expires_at"`
+	Items     []*DriveSharedItem `json:"items"`
+}
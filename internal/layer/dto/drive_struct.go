@@ -1,7 +1,9 @@
 package dto
 
 import (
+	"assistant-go/pkg/keyring"
 	"assistant-go/pkg/vld"
+	"io"
 	"mime/multipart"
 	"time"
 )
@@ -28,7 +30,10 @@ type DriveUploadFile struct {
 	ParentID              *int
 	SHA256                *string
 	UseEncryption         bool
-	EncryptionKey         string
+	Keyring               *keyring.Keyring
+	Replace               bool
+	// Path - путь файла относительно ParentID, недостающие директории создаются
+	Path string `validate:"max=4096"`
 }
 
 func (dto *DriveUploadFile) Validate(lang string) error {
@@ -51,21 +56,84 @@ func (dto *DriveRenameStruct) Validate(lang string) error {
 	return nil
 }
 
+// DriveSpace - занятое место и лимиты пользователя: общие и отдельно для диска и вложений заметок
 type DriveSpace struct {
+	Total int64           `json:"total"`
+	Used  int64           `json:"used"`
+	Plan  *string         `json:"plan"`
+	Drive DriveSpaceUsage `json:"drive"`
+	Notes DriveSpaceUsage `json:"notes"`
+}
+
+type DriveSpaceUsage struct {
 	Total int64 `json:"total"`
 	Used  int64 `json:"used"`
 }
 
 type DriveTree struct {
-	ID        int       `db:"id" json:"id"`
-	UserID    int       `db:"user_id" json:"user_id"`
-	Name      string    `db:"name" json:"name"`
-	Type      int8      `db:"type" json:"type"`
-	Size      int64     `db:"size" json:"size"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-	IsChunk   bool      `db:"is_chunk" json:"is_chunk"`
-	SHA256    *string   `db:"sha256" json:"sha256"`
+	ID          int       `db:"id" json:"id"`
+	UserID      int       `db:"user_id" json:"user_id"`
+	Name        string    `db:"name" json:"name"`
+	Type        int8      `db:"type" json:"type"`
+	Size        int64     `db:"size" json:"size"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	IsChunk     bool      `db:"is_chunk" json:"is_chunk"`
+	SHA256      *string   `db:"sha256" json:"sha256"`
+	UploadState int8      `db:"upload_state" json:"upload_state"`
+	// Role - роль в чужой директории, заполняется только для раздела "доступные мне"
+	Role *int8 `json:"role,omitempty"`
+}
+
+type DriveTrashItem struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Type      int8      `json:"type"`
+	ParentID  *int      `json:"parent_id"`
+	Size      int64     `json:"size"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type DriveTrashAction struct {
+	StructID int `json:"struct_id" validate:"required"`
+}
+
+func (dto *DriveTrashAction) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
+type DriveFileInfo struct {
+	OriginalFilename string
+	SizeBytes        int64
+	ETag             string
+	ModifiedAt       time.Time
+	MimeType         string
+}
+
+type DriveFileVersion struct {
+	ID         int        `json:"id"`
+	Size       int64      `json:"size"`
+	SHA256     *string    `json:"sha256"`
+	IsChunk    bool       `json:"is_chunk"`
+	IsCurrent  bool       `json:"is_current"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReplacedAt *time.Time `json:"replaced_at"`
+}
+
+type DriveVersionsPrune struct {
+	Keep int `json:"keep" validate:"min=0"`
+}
+
+func (dto *DriveVersionsPrune) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
 }
 
 type DriveRenMov struct {
@@ -81,11 +149,33 @@ func (dto *DriveRenMov) Validate(lang string) error {
 	return nil
 }
 
+type DriveCopy struct {
+	StructIDs []int `json:"struct_ids" validate:"required,max=1000"`
+	ParentID  *int  `json:"parent_id"`
+}
+
+func (dto *DriveCopy) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
+type DriveCopyIn struct {
+	DriveCopy
+	SavePath              string
+	StorageMaxSizePerUser int64
+}
+
 type DriveChunkPrepare struct {
-	Filename string  `json:"filename" validate:"required,min=1,max=300"`
-	FullSize int64   `json:"full_size" validate:"required"`
+	Filename string  `json:"filename" validate:"required_without=Path,max=300"`
+	FullSize int64   `json:"full_size" validate:"min=0"`
 	ParentID *int    `json:"parent_id"`
 	SHA256   *string `json:"sha256"`
+	Replace  bool    `json:"replace"`
+	// Path - путь файла относительно ParentID вместо Filename, недостающие директории создаются
+	Path string `json:"path" validate:"max=4096"`
 }
 
 func (dto *DriveChunkPrepare) Validate(lang string) error {
@@ -115,7 +205,7 @@ type DriveUploadChunk struct {
 	StorageMaxSizePerUser int64
 	SavePath              string
 	UseEncryption         bool
-	EncryptionKey         string
+	Keyring               *keyring.Keyring
 }
 
 type DriveChunkEnd struct {
@@ -130,7 +220,164 @@ func (dto *DriveChunkEnd) Validate(lang string) error {
 	return nil
 }
 
+type DriveChunkEndIn struct {
+	StructID      int
+	SavePath      string
+	UseEncryption bool
+	Keyring       *keyring.Keyring
+}
+
+type DriveUploadByHash struct {
+	SHA256   string `json:"sha256" validate:"required,len=64,hexadecimal"`
+	Filename string `json:"filename" validate:"required,min=1,max=300"`
+	ParentID *int   `json:"parent_id"`
+	Replace  bool   `json:"replace"`
+}
+
+func (dto *DriveUploadByHash) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
+type DriveHashExists struct {
+	Exists bool `json:"exists"`
+}
+
+type DriveZip struct {
+	StructIDs []int `json:"struct_ids" validate:"required,min=1,max=1000"`
+}
+
+func (dto *DriveZip) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
+type DriveZipIn struct {
+	StructIDs     []int
+	SavePath      string
+	UseEncryption bool
+	Keyring       *keyring.Keyring
+	MaxSizeBytes  int64
+	MaxEntries    int
+}
+
+type DriveImport struct {
+	ParentID *int
+	Conflict string `validate:"oneof=skip rename overwrite"`
+}
+
+func (dto *DriveImport) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
+type DriveImportIn struct {
+	File                  multipart.File
+	Size                  int64
+	Filename              string
+	ParentID              *int
+	Conflict              string
+	MaxEntries            int
+	MaxUnpackedSize       int64
+	MaxSizeBytes          int64
+	StorageMaxSizePerUser int64
+	SavePath              string
+	UseEncryption         bool
+	Keyring               *keyring.Keyring
+}
+
+type DriveImportFailure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+	Err   error  `json:"-"`
+}
+
+type DriveImportResult struct {
+	Created []string              `json:"created"`
+	Skipped []string              `json:"skipped"`
+	Failed  []*DriveImportFailure `json:"failed"`
+}
+
+type DriveTusAppend struct {
+	StructID              int
+	Offset                int64
+	Body                  io.Reader
+	ContentLength         int64
+	ChecksumAlgorithm     string
+	Checksum              []byte
+	MaxSizeBytes          int64
+	StorageMaxSizePerUser int64
+	SavePath              string
+	UseEncryption         bool
+	Keyring               *keyring.Keyring
+}
+
+type DriveTusUpload struct {
+	StructID  int
+	Offset    int64
+	Length    int64
+	Completed bool
+}
+
 type DriveChunksInfo struct {
 	StartNumber int `json:"start_number"`
 	EndNumber   int `json:"end_number"`
 }
+
+// DriveSearch - фильтры поиска, все необязательны. Type: 0 - директории, 1 - файлы
+type DriveSearch struct {
+	Query       string     `validate:"max=255"`
+	Ext         string     `validate:"max=50"`
+	Type        *int8      `validate:"omitempty,oneof=0 1"`
+	MinSize     *int64     `validate:"omitempty,min=0"`
+	MaxSize     *int64     `validate:"omitempty,min=0"`
+	CreatedFrom *time.Time `validate:"omitempty"`
+	CreatedTo   *time.Time `validate:"omitempty"`
+	UpdatedFrom *time.Time `validate:"omitempty"`
+	UpdatedTo   *time.Time `validate:"omitempty"`
+	Page        int        `validate:"min=1"`
+	Limit       int        `validate:"min=1,max=200"`
+}
+
+func (dto *DriveSearch) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
+type DriveSearchItem struct {
+	ID        int       `json:"id"`
+	ParentID  *int      `json:"parent_id"`
+	Name      string    `json:"name"`
+	Type      int8      `json:"type"`
+	Ext       *string   `json:"ext"`
+	Size      int64     `json:"size"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type DriveSearchResult struct {
+	Items []*DriveSearchItem `json:"items"`
+	Total int                `json:"total"`
+	Page  int                `json:"page"`
+	Limit int                `json:"limit"`
+}
+
+type DrivePathItem struct {
+	ID       int    `json:"id"`
+	ParentID *int   `json:"parent_id"`
+	Name     string `json:"name"`
+	Type     int8   `json:"type"`
+}
@@ -1,8 +1,8 @@
 package dto
 
 import (
+	"assistant-go/pkg/keyring"
 	"assistant-go/pkg/vld"
-	"bytes"
 	"io"
 	"mime/multipart"
 )
@@ -13,6 +13,8 @@ type UploadFile struct {
 	MaxSizeBytes     int64
 	StorageMaxSize   int64
 	SavePath         string
+	UseEncryption    bool
+	Keyring          *keyring.Keyring
 }
 
 func (dto *UploadFile) Validate(lang string) error {
@@ -32,6 +34,7 @@ type SaveFile struct {
 type GetFileByHash struct {
 	Hash     string `validate:"required,min=80,max=80"`
 	SavePath string
+	Keyring  *keyring.Keyring
 }
 
 func (dto *GetFileByHash) Validate(lang string) error {
@@ -42,12 +45,37 @@ func (dto *GetFileByHash) Validate(lang string) error {
 	return nil
 }
 
+// GetThumbnail - запрос превью файла диска (StructID) или вложения заметки (Hash)
+type GetThumbnail struct {
+	StructID      int
+	Hash          string `validate:"omitempty,len=80"`
+	Size          int    `validate:"oneof=128 256 512"`
+	SavePath      string
+	UseEncryption bool
+	Keyring       *keyring.Keyring
+}
+
+func (dto *GetThumbnail) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
+
 type GetFile struct {
 	StructID      int
 	SavePath      string
 	MaxSizeBytes  int64
 	UseEncryption bool
-	EncryptionKey string
+	Keyring       *keyring.Keyring
+	Range         *FileRange
+	VersionID     *int
+}
+
+type FileRange struct {
+	Offset int64
+	Length int64
 }
 
 type GetChunk struct {
@@ -56,19 +84,12 @@ type GetChunk struct {
 	ChunkNumber   int
 	MaxSizeBytes  int64
 	UseEncryption bool
-	EncryptionKey string
+	Keyring       *keyring.Keyring
 }
 
 type FileResponse struct {
 	File             io.Reader
 	OriginalFilename string
 	SizeBytes        int64
-}
-
-type MemoryMultipartFile struct {
-	*bytes.Reader
-}
-
-func (m *MemoryMultipartFile) Close() error {
-	return nil
+	MimeType         string
 }
@@ -0,0 +1,22 @@
+package dto
+
+import "assistant-go/pkg/keyring"
+
+type NoteEncryptIn struct {
+	// FilesSavePath - каталог вложений заметок
+	FilesSavePath string
+	EncryptFiles  bool
+	EncryptNotes  bool
+	Keyring       *keyring.Keyring
+	// OnProgress вызывается периодически во время шифрования и после его завершения
+	OnProgress func(progress NoteEncryptProgress)
+}
+
+// NoteEncryptProgress - состояние шифрования заметок и их вложений
+type NoteEncryptProgress struct {
+	Files int
+	Notes int
+	// Skipped - объекты, запись которых изменилась во время шифрования, или вложения, которых нет в хранилище
+	Skipped     []string
+	DeletedKeys int
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"assistant-go/pkg/keyring"
+	"time"
+)
+
+// StorageObjectInfo - объект, найденный при обходе хранилища
+type StorageObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+type StorageFsckIn struct {
+	DriveSavePath string
+	FilesSavePath string
+	UseEncryption bool
+	Keyring       *keyring.Keyring
+	// VerifyHash - перечитывать объекты с известным SHA-256 и сверять хэш
+	VerifyHash bool
+	// Repair - переносить объекты без записей в БД в карантин
+	Repair bool
+	// MinOrphanAge - объекты моложе этого возраста не считаются лишними: их запись в БД может быть еще не сохранена
+	MinOrphanAge time.Duration
+}
+
+// StorageFsckIssue - найденное расхождение. Kind и ID указывают запись в БД, для лишних объектов они пустые
+type StorageFsckIssue struct {
+	Problem string
+	Kind    string
+	ID      int
+	Key     string
+	Detail  string
+}
+
+type StorageFsckReport struct {
+	Objects     int
+	Records     int
+	Issues      []*StorageFsckIssue
+	Quarantined int
+}
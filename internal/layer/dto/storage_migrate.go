@@ -0,0 +1,26 @@
+package dto
+
+type StorageMigrateIn struct {
+	From          string
+	To            string
+	DriveSavePath string
+	FilesSavePath string
+	// DeleteSource - удалять объект из исходного хранилища после проверки копии
+	DeleteSource bool
+	// Restart - начать перенос заново, не учитывая сохраненный прогресс
+	Restart bool
+	// OnProgress вызывается периодически во время переноса и после его завершения
+	OnProgress func(progress StorageMigrateProgress)
+}
+
+// StorageMigrateProgress - состояние переноса. Total - количество объектов, оставшихся на момент запуска
+type StorageMigrateProgress struct {
+	Total       int
+	Checked     int
+	Copied      int
+	Skipped     int
+	Deleted     int
+	CopiedBytes int64
+	// Missing - объекты, на которые есть ссылки в БД, но которых нет ни в одном хранилище
+	Missing []string
+}
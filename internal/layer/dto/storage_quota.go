@@ -0,0 +1,8 @@
+package dto
+
+// StorageLimits - лимиты хранилища пользователя в байтах
+type StorageLimits struct {
+	PlanName   *string
+	DriveLimit int64
+	FilesLimit int64
+}
@@ -54,3 +54,15 @@ func (dto *UserChangePassword) Validate(lang string) error {
 	}
 	return nil
 }
+
+type UserAppTokenCreate struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+func (dto *UserAppTokenCreate) Validate(lang string) error {
+	err := vld.Validate.Struct(dto)
+	if err != nil {
+		return vld.TextFromFirstError(err, lang)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type DriveBlob struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	SHA256    string    `db:"sha256"`
+	Path      string    `db:"path"`
+	Size      int64     `db:"size"`
+	PlainSize int64     `db:"plain_size"`
+	RefCount  int       `db:"ref_count"`
+	CreatedAt time.Time `db:"created_at"`
+	DataKeyID *int      `db:"data_key_id"`
+}
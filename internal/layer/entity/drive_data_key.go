@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// DriveDataKey - ключ данных пользователя, которым шифруются его файлы на диске. Хранится
+// завернутым (зашифрованным) мастер-ключом с идентификатором MasterKeyID
+type DriveDataKey struct {
+	ID          int       `db:"id"`
+	UserID      *int      `db:"user_id"`
+	MasterKeyID string    `db:"master_key_id"`
+	WrappedKey  []byte    `db:"wrapped_key"`
+	IsActive    bool      `db:"is_active"`
+	CreatedAt   time.Time `db:"created_at"`
+}
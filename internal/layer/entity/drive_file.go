@@ -3,12 +3,21 @@ package entity
 import "time"
 
 type DriveFile struct {
-	ID            int       `db:"id"`
-	DriveStructID int       `db:"drive_struct_id"`
-	Path          *string   `db:"path"`
-	Ext           string    `db:"ext"`
-	Size          int64     `db:"size"`
-	CreatedAt     time.Time `db:"created_at"`
-	IsChunk       bool      `db:"is_chunk"`
-	SHA256        *string   `db:"sha256"`
+	ID             int        `db:"id"`
+	DriveStructID  int        `db:"drive_struct_id"`
+	Path           *string    `db:"path"`
+	Ext            string     `db:"ext"`
+	Size           int64      `db:"size"`
+	CreatedAt      time.Time  `db:"created_at"`
+	IsChunk        bool       `db:"is_chunk"`
+	SHA256         *string    `db:"sha256"`
+	PlainSize      *int64     `db:"plain_size"`
+	IsCurrent      bool       `db:"is_current"`
+	ReplacedAt     *time.Time `db:"replaced_at"`
+	BlobID         *int       `db:"blob_id"`
+	UploadState    int8       `db:"upload_state"`
+	ExpectedSize   *int64     `db:"expected_size"`
+	LastActivityAt *time.Time `db:"last_activity_at"`
+	MimeType       *string    `db:"mime_type"`
+	DataKeyID      *int       `db:"data_key_id"`
 }
@@ -6,4 +6,6 @@ type DriveFileChunk struct {
 	Path        string `db:"path"`
 	Size        int64  `db:"size"`
 	ChunkNumber int    `db:"chunk_number"`
+	PlainSize   *int64 `db:"plain_size"`
+	DataKeyID   *int   `db:"data_key_id"`
 }
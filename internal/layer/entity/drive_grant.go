@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+type DriveGrant struct {
+	ID            int       `db:"id"`
+	DriveStructID int       `db:"drive_struct_id"`
+	OwnerID       int       `db:"owner_id"`
+	GranteeID     int       `db:"grantee_id"`
+	Role          int8      `db:"role"`
+	CreatedAt     time.Time `db:"created_at"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type DriveShareLink struct {
+	ID            int        `db:"id"`
+	UserID        int        `db:"user_id"`
+	DriveStructID int        `db:"drive_struct_id"`
+	Hash          string     `db:"hash"`
+	Password      *string    `db:"password"`
+	ExpiresAt     *time.Time `db:"expires_at"`
+	MaxDownloads  *int       `db:"max_downloads"`
+	DownloadCount int        `db:"download_count"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
@@ -3,11 +3,13 @@ package entity
 import "time"
 
 type DriveStruct struct {
-	ID        int       `db:"id"`
-	UserID    int       `db:"user_id"`
-	Name      string    `db:"name"`
-	Type      int8      `db:"type"`
-	ParentID  *int      `db:"parent_id"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID          int        `db:"id"`
+	UserID      int        `db:"user_id"`
+	Name        string     `db:"name"`
+	Type        int8       `db:"type"`
+	ParentID    *int       `db:"parent_id"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	DeletedAt   *time.Time `db:"deleted_at"`
+	TrashRootID *int       `db:"trash_root_id"`
 }
@@ -11,4 +11,6 @@ type File struct {
 	Size             int       `db:"size"`
 	Hash             string    `db:"hash"`
 	CreatedAt        time.Time `db:"created_at"`
+	PlainSize        *int64    `db:"plain_size"`
+	DataKeyID        *int      `db:"data_key_id"`
 }
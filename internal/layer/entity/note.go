@@ -13,6 +13,17 @@ type Note struct {
 	UpdatedAt  time.Time       `db:"updated_at"`
 	Title      *string         `db:"title"`
 	Pinned     bool            `db:"pinned"`
+	// EncryptedBlocks и EncryptedTitle заполнены у зашифрованной заметки (DataKeyID != nil),
+	// NoteBlocks и Title у нее пустые до расшифровки
+	EncryptedBlocks []byte `db:"encrypted_blocks"`
+	EncryptedTitle  []byte `db:"encrypted_title"`
+	DataKeyID       *int   `db:"data_key_id"`
+}
+
+// NoteWithOwner - заметка вместе с владельцем ее категории
+type NoteWithOwner struct {
+	Note
+	UserID int
 }
 
 type NoteMinimal struct {
@@ -23,4 +34,7 @@ type NoteMinimal struct {
 	Title      *string   `db:"title"`
 	Pinned     bool      `db:"pinned"`
 	Shared     bool      `db:"shared"`
+	// EncryptedTitle заполнен у зашифрованной заметки (DataKeyID != nil)
+	EncryptedTitle []byte `db:"encrypted_title"`
+	DataKeyID      *int   `db:"data_key_id"`
 }
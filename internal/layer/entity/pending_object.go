@@ -0,0 +1,9 @@
+package entity
+
+import "time"
+
+// PendingObject - объект хранилища, который записывается, но еще не закреплен записью в БД
+type PendingObject struct {
+	StorageKey string    `db:"storage_key"`
+	CreatedAt  time.Time `db:"created_at"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+// StorageObject - объект хранилища, на который ссылается запись в БД. Kind - таблица записи,
+// Path - путь относительно каталога сохранения, Size и PlainSize - размер объекта в хранилище и исходный размер,
+// SHA256 - хэш исходного содержимого, если он известен, DataKeyID - ключ данных, которым
+// зашифрован объект (nil - объект не зашифрован или зашифрован старым способом).
+// UserID заполняется только при выборке объектов для перешифрования
+type StorageObject struct {
+	Kind      string
+	ID        int
+	UserID    int
+	Path      string
+	Size      int64
+	PlainSize *int64
+	SHA256    *string
+	DataKeyID *int
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+type StoragePlan struct {
+	ID         int       `db:"id"`
+	Name       string    `db:"name"`
+	DriveLimit int64     `db:"drive_limit"`
+	FilesLimit int64     `db:"files_limit"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// UserStorageQuota - квоты пользователя. Незаданный лимит берется из тарифа, а без тарифа - из конфигурации
+type UserStorageQuota struct {
+	UserID     int       `db:"user_id"`
+	PlanID     *int      `db:"plan_id"`
+	DriveLimit *int64    `db:"drive_limit"`
+	FilesLimit *int64    `db:"files_limit"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// StorageLimits - действующие лимиты пользователя после применения тарифа и персональных значений
+type StorageLimits struct {
+	PlanName   *string
+	DriveLimit *int64
+	FilesLimit *int64
+}
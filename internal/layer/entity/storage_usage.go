@@ -0,0 +1,9 @@
+package entity
+
+// StorageUsage - счетчики занятого пользователем места в байтах: диск (блобы, файлы без блоба и чанки)
+// и файлы заметок
+type StorageUsage struct {
+	UserID    int   `db:"user_id"`
+	DriveUsed int64 `db:"drive_used"`
+	FilesUsed int64 `db:"files_used"`
+}
@@ -29,7 +29,16 @@ type Repositories struct {
 	DriveStructRepository     DriveStructRepository
 	DriveFileRepository       DriveFileRepository
 	DriveFileChunkRepository  DriveFileChunkRepository
+	DriveBlobRepository       DriveBlobRepository
+	DriveShareLinkRepository  DriveShareLinkRepository
+	DriveGrantRepository      DriveGrantRepository
 	NoteShareHashesRepository NoteShareHashesRepository
+	UserAppTokenRepository    UserAppTokenRepository
+	StorageQuotaRepository    StorageQuotaRepository
+	StorageUsageRepository    StorageUsageRepository
+	StorageMigrateRepository  StorageMigrateRepository
+	PendingObjectRepository   PendingObjectRepository
+	DriveDataKeyRepository    DriveDataKeyRepository
 }
 
 func NewRepositories(cfg *config.Config, db *pgxpool.Pool, minio *minio.Client) *Repositories {
@@ -53,12 +62,31 @@ func NewRepositories(cfg *config.Config, db *pgxpool.Pool, minio *minio.Client)
 		DriveStructRepository:     NewDriveStructRepository(db),
 		DriveFileRepository:       NewDriveFileRepository(db),
 		DriveFileChunkRepository:  NewDriveFileChunkRepository(db),
+		DriveBlobRepository:       NewDriveBlobRepository(db),
+		DriveShareLinkRepository:  NewDriveShareLinkRepository(db),
+		DriveGrantRepository:      NewDriveGrantRepository(db),
 		NoteShareHashesRepository: NewNoteShareHashesRepository(db),
+		UserAppTokenRepository:    NewUserAppTokenRepository(db),
+		StorageQuotaRepository:    NewStorageQuotaRepository(db),
+		StorageUsageRepository:    NewStorageUsageRepository(db),
+		StorageMigrateRepository:  NewStorageMigrateRepository(db),
+		PendingObjectRepository:   NewPendingObjectRepository(db),
+		DriveDataKeyRepository:    NewDriveDataKeyRepository(db),
 	}
 }
 
+// WithTx возвращает репозитории, выполняющие запросы в транзакции tx.
+// Репозитории, работающие только через пул соединений, в транзакции недоступны
+func (r *Repositories) WithTx(tx pgx.Tx) *Repositories {
+	repositories := r.TransactionRepository.Repositories(tx)
+	repositories.StorageRepository = r.StorageRepository
+	repositories.TransactionRepository = r.TransactionRepository
+	return repositories
+}
+
 type TransactionRepository interface {
 	GetTransaction(ctx context.Context) (pgx.Tx, error)
+	Repositories(tx pgx.Tx) *Repositories
 }
 
 type transactionRepository struct {
@@ -73,6 +101,27 @@ func (r *transactionRepository) GetTransaction(ctx context.Context) (pgx.Tx, err
 	return tx, nil
 }
 
+func (r *transactionRepository) Repositories(tx pgx.Tx) *Repositories {
+	return &Repositories{
+		UserRepository:            NewUserRepository(tx),
+		NoteCategoryRepository:    NewNoteCategoryRepository(tx),
+		FileRepository:            NewFileRepository(tx),
+		DriveStructRepository:     NewDriveStructRepository(tx),
+		DriveFileRepository:       NewDriveFileRepository(tx),
+		DriveFileChunkRepository:  NewDriveFileChunkRepository(tx),
+		DriveBlobRepository:       NewDriveBlobRepository(tx),
+		DriveShareLinkRepository:  NewDriveShareLinkRepository(tx),
+		DriveGrantRepository:      NewDriveGrantRepository(tx),
+		NoteShareHashesRepository: NewNoteShareHashesRepository(tx),
+		UserAppTokenRepository:    NewUserAppTokenRepository(tx),
+		StorageQuotaRepository:    NewStorageQuotaRepository(tx),
+		StorageUsageRepository:    NewStorageUsageRepository(tx),
+		StorageMigrateRepository:  NewStorageMigrateRepository(tx),
+		PendingObjectRepository:   NewPendingObjectRepository(tx),
+		DriveDataKeyRepository:    NewDriveDataKeyRepository(tx),
+	}
+}
+
 func WithTransaction(ctx context.Context, tr TransactionRepository, fn func(tx pgx.Tx) error) error {
 	tx, err := tr.GetTransaction(ctx)
 	if err != nil {
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"context"
+)
+
+type DriveBlobRepository interface {
+	GetByHash(ctx context.Context, userID int, sha256 string) (*entity.DriveBlob, error)
+	Create(ctx context.Context, in *entity.DriveBlob) (*entity.DriveBlob, error)
+	IncrementRef(ctx context.Context, blobID int) error
+	DecrementRef(ctx context.Context, blobID int, count int) (*entity.DriveBlob, error)
+	Delete(ctx context.Context, blobID int) error
+}
+
+type driveBlobRepository struct {
+	db DBExecutor
+}
+
+func NewDriveBlobRepository(db DBExecutor) DriveBlobRepository {
+	return &driveBlobRepository{db: db}
+}
+
+func (r *driveBlobRepository) GetByHash(ctx context.Context, userID int, sha256 string) (*entity.DriveBlob, error) {
+	query := `SELECT * FROM drive_blobs WHERE user_id = $1 AND sha256 = $2`
+
+	var result entity.DriveBlob
+	err := r.db.QueryRow(ctx, query, userID, sha256).Scan(
+		&result.ID,
+		&result.UserID,
+		&result.SHA256,
+		&result.Path,
+		&result.Size,
+		&result.PlainSize,
+		&result.RefCount,
+		&result.CreatedAt,
+		&result.DataKeyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *driveBlobRepository) Create(ctx context.Context, in *entity.DriveBlob) (*entity.DriveBlob, error) {
+	query := `
+		INSERT INTO drive_blobs (user_id, sha256, path, size, plain_size, ref_count, created_at, data_key_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+	`
+
+	row := r.db.QueryRow(
+		ctx, query, in.UserID, in.SHA256, in.Path, in.Size, in.PlainSize, in.RefCount, in.CreatedAt, in.DataKeyID,
+	)
+
+	if err := row.Scan(&in.ID); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (r *driveBlobRepository) IncrementRef(ctx context.Context, blobID int) error {
+	query := `UPDATE drive_blobs SET ref_count = ref_count + 1 WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, blobID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DecrementRef уменьшает счетчик ссылок на count и возвращает блоб с новым значением счетчика
+func (r *driveBlobRepository) DecrementRef(ctx context.Context, blobID int, count int) (*entity.DriveBlob, error) {
+	query := `
+		UPDATE drive_blobs SET ref_count = ref_count - $2 WHERE id = $1
+		RETURNING id, user_id, sha256, path, size, plain_size, ref_count, created_at, data_key_id
+	`
+
+	var result entity.DriveBlob
+	err := r.db.QueryRow(ctx, query, blobID, count).Scan(
+		&result.ID,
+		&result.UserID,
+		&result.SHA256,
+		&result.Path,
+		&result.Size,
+		&result.PlainSize,
+		&result.RefCount,
+		&result.CreatedAt,
+		&result.DataKeyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *driveBlobRepository) Delete(ctx context.Context, blobID int) error {
+	query := `DELETE FROM drive_blobs WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, blobID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
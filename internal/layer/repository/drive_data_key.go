@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"context"
+)
+
+// staleObjectQueries выбирают объекты таблицы с id больше $1, зашифрованные не активным ключом данных
+// владельца: старым ключом, ключом другого пользователя (копии) или старым способом без ключа данных.
+// Вложения заметок без ключа данных не зашифрованы
+var staleObjectQueries = map[string]string{
+	StorageObjectDriveBlobs: `
+		SELECT b.id, b.user_id, b.path, b.size, b.plain_size, b.data_key_id
+		FROM drive_blobs b
+		LEFT JOIN drive_data_keys k ON k.user_id = b.user_id AND k.is_active
+		WHERE b.id > $1 AND (k.id IS NULL OR b.data_key_id IS DISTINCT FROM k.id)
+	`,
+	StorageObjectDriveFiles: `
+		SELECT df.id, ds.user_id, df.path, df.size, df.plain_size, df.data_key_id
+		FROM drive_files df
+		JOIN drive_structs ds ON ds.id = df.drive_struct_id
+		LEFT JOIN drive_data_keys k ON k.user_id = ds.user_id AND k.is_active
+		WHERE df.id > $1 AND df.blob_id IS NULL AND NOT df.is_chunk AND df.path IS NOT NULL
+			AND (k.id IS NULL OR df.data_key_id IS DISTINCT FROM k.id)
+	`,
+	StorageObjectDriveChunks: `
+		SELECT dfc.id, ds.user_id, dfc.path, dfc.size, dfc.plain_size, dfc.data_key_id
+		FROM drive_file_chunks dfc
+		JOIN drive_files df ON df.id = dfc.drive_file_id
+		JOIN drive_structs ds ON ds.id = df.drive_struct_id
+		LEFT JOIN drive_data_keys k ON k.user_id = ds.user_id AND k.is_active
+		WHERE dfc.id > $1 AND (k.id IS NULL OR dfc.data_key_id IS DISTINCT FROM k.id)
+	`,
+	StorageObjectFiles: `
+		SELECT f.id, f.user_id, f.file_path, f.size, f.plain_size, f.data_key_id
+		FROM files f
+		LEFT JOIN drive_data_keys k ON k.user_id = f.user_id AND k.is_active
+		WHERE f.id > $1 AND (k.id IS NULL OR f.data_key_id IS DISTINCT FROM k.id)
+	`,
+}
+
+// DriveEncryptedObjectKinds - таблицы, объекты которых шифруются ключами данных
+var DriveEncryptedObjectKinds = []string{
+	StorageObjectDriveBlobs,
+	StorageObjectDriveFiles,
+	StorageObjectDriveChunks,
+}
+
+type DriveDataKeyRepository interface {
+	GetByID(ctx context.Context, id int) (*entity.DriveDataKey, error)
+	GetActive(ctx context.Context, userID int) (*entity.DriveDataKey, error)
+	CreateActive(ctx context.Context, in *entity.DriveDataKey) (*entity.DriveDataKey, error)
+	DeactivateAll(ctx context.Context) (int, error)
+	GetWrappedByOther(ctx context.Context, masterKeyID string, afterID int, limit int) ([]*entity.DriveDataKey, error)
+	UpdateWrapped(ctx context.Context, id int, masterKeyID string, wrappedKey []byte) error
+	DeleteUnused(ctx context.Context) (int, error)
+	GetStaleObjects(ctx context.Context, kind string, afterID int, limit int) ([]*entity.StorageObject, error)
+	ReplaceObject(ctx context.Context, object *entity.StorageObject, previous *entity.StorageObject) (bool, error)
+}
+
+type driveDataKeyRepository struct {
+	db DBExecutor
+}
+
+func NewDriveDataKeyRepository(db DBExecutor) DriveDataKeyRepository {
+	return &driveDataKeyRepository{db: db}
+}
+
+func (r *driveDataKeyRepository) GetByID(ctx context.Context, id int) (*entity.DriveDataKey, error) {
+	query := `SELECT * FROM drive_data_keys WHERE id = $1`
+
+	return r.scanKey(ctx, query, id)
+}
+
+func (r *driveDataKeyRepository) GetActive(ctx context.Context, userID int) (*entity.DriveDataKey, error) {
+	query := `SELECT * FROM drive_data_keys WHERE user_id = $1 AND is_active`
+
+	return r.scanKey(ctx, query, userID)
+}
+
+// CreateActive создает активный ключ пользователя. Если активный ключ уже создан параллельным
+// запросом, новый ключ не сохраняется и возвращается pgx.ErrNoRows
+func (r *driveDataKeyRepository) CreateActive(ctx context.Context, in *entity.DriveDataKey) (*entity.DriveDataKey, error) {
+	query := `
+		INSERT INTO drive_data_keys (user_id, master_key_id, wrapped_key, is_active, created_at)
+		VALUES ($1, $2, $3, TRUE, $4)
+		ON CONFLICT (user_id) WHERE is_active DO NOTHING
+		RETURNING id
+	`
+
+	row := r.db.QueryRow(ctx, query, in.UserID, in.MasterKeyID, in.WrappedKey, in.CreatedAt)
+
+	if err := row.Scan(&in.ID); err != nil {
+		return nil, err
+	}
+	in.IsActive = true
+	return in, nil
+}
+
+// DeactivateAll снимает признак активности со всех ключей, новые файлы будут шифроваться новыми ключами
+func (r *driveDataKeyRepository) DeactivateAll(ctx context.Context) (int, error) {
+	query := `UPDATE drive_data_keys SET is_active = FALSE WHERE is_active`
+
+	tag, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// GetWrappedByOther возвращает до limit ключей с id больше afterID, завернутых не мастер-ключом masterKeyID
+func (r *driveDataKeyRepository) GetWrappedByOther(
+	ctx context.Context,
+	masterKeyID string,
+	afterID int,
+	limit int,
+) ([]*entity.DriveDataKey, error) {
+	query := `SELECT * FROM drive_data_keys WHERE master_key_id <> $1 AND id > $2 ORDER BY id LIMIT $3`
+
+	rows, err := r.db.Query(ctx, query, masterKeyID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.DriveDataKey, 0)
+	for rows.Next() {
+		var dataKey entity.DriveDataKey
+		err := rows.Scan(
+			&dataKey.ID,
+			&dataKey.UserID,
+			&dataKey.MasterKeyID,
+			&dataKey.WrappedKey,
+			&dataKey.IsActive,
+			&dataKey.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &dataKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *driveDataKeyRepository) UpdateWrapped(ctx context.Context, id int, masterKeyID string, wrappedKey []byte) error {
+	query := `UPDATE drive_data_keys SET master_key_id = $2, wrapped_key = $3 WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id, masterKeyID, wrappedKey)
+	return err
+}
+
+// DeleteUnused удаляет неактивные ключи, которыми не зашифрован ни один объект
+func (r *driveDataKeyRepository) DeleteUnused(ctx context.Context) (int, error) {
+	query := `
+		DELETE FROM drive_data_keys k
+		WHERE NOT k.is_active
+			AND NOT EXISTS (SELECT 1 FROM drive_blobs b WHERE b.data_key_id = k.id)
+			AND NOT EXISTS (SELECT 1 FROM drive_files df WHERE df.data_key_id = k.id)
+			AND NOT EXISTS (SELECT 1 FROM drive_file_chunks dfc WHERE dfc.data_key_id = k.id)
+			AND NOT EXISTS (SELECT 1 FROM files f WHERE f.data_key_id = k.id)
+			AND NOT EXISTS (SELECT 1 FROM notes n WHERE n.data_key_id = k.id)
+	`
+
+	tag, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// GetStaleObjects возвращает до limit объектов таблицы kind с id больше afterID,
+// которые нужно перешифровать активным ключом владельца
+func (r *driveDataKeyRepository) GetStaleObjects(
+	ctx context.Context,
+	kind string,
+	afterID int,
+	limit int,
+) ([]*entity.StorageObject, error) {
+	query := staleObjectQueries[kind] + ` ORDER BY 1 LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := make([]*entity.StorageObject, 0, limit)
+	for rows.Next() {
+		object := &entity.StorageObject{Kind: kind}
+		err := rows.Scan(&object.ID, &object.UserID, &object.Path, &object.Size, &object.PlainSize, &object.DataKeyID)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// ReplaceObject переводит запись с объекта previous на перешифрованный объект object, если запись
+// все еще ссылается на previous. Файлы с блобом получают те же путь, размер и ключ, что и блоб,
+// а размер завершенного чанкового файла меняется на разницу размеров чанка.
+// Возвращает false, если запись за это время изменилась или удалена
+func (r *driveDataKeyRepository) ReplaceObject(
+	ctx context.Context,
+	object *entity.StorageObject,
+	previous *entity.StorageObject,
+) (bool, error) {
+	args := []any{object.ID, previous.Path, object.Path, object.Size, object.PlainSize, object.DataKeyID}
+
+	var query string
+	switch object.Kind {
+	case StorageObjectDriveBlobs:
+		query = `
+			WITH updated AS (
+				UPDATE drive_blobs SET path = $3, size = $4, plain_size = $5, data_key_id = $6
+				WHERE id = $1 AND path = $2
+				RETURNING id
+			), files AS (
+				UPDATE drive_files df SET path = $3, size = $4, plain_size = $5, data_key_id = $6
+				FROM updated WHERE df.blob_id = updated.id
+			)
+			SELECT EXISTS (SELECT 1 FROM updated)
+		`
+	case StorageObjectDriveFiles:
+		query = `
+			WITH updated AS (
+				UPDATE drive_files SET path = $3, size = $4, plain_size = $5, data_key_id = $6
+				WHERE id = $1 AND path = $2
+				RETURNING id
+			)
+			SELECT EXISTS (SELECT 1 FROM updated)
+		`
+	case StorageObjectDriveChunks:
+		query = `
+			WITH updated AS (
+				UPDATE drive_file_chunks SET path = $3, size = $4, plain_size = $5, data_key_id = $6
+				WHERE id = $1 AND path = $2
+				RETURNING drive_file_id
+			), files AS (
+				UPDATE drive_files df SET size = df.size + $4::bigint - $7::bigint
+				FROM updated WHERE df.id = updated.drive_file_id AND df.upload_state = 1
+			)
+			SELECT EXISTS (SELECT 1 FROM updated)
+		`
+		args = append(args, previous.Size)
+	case StorageObjectFiles:
+		query = `
+			WITH updated AS (
+				UPDATE files SET file_path = $3, size = $4, plain_size = $5, data_key_id = $6
+				WHERE id = $1 AND file_path = $2
+				RETURNING id
+			)
+			SELECT EXISTS (SELECT 1 FROM updated)
+		`
+	}
+
+	var updated bool
+	err := r.db.QueryRow(ctx, query, args...).Scan(&updated)
+	if err != nil {
+		return false, err
+	}
+	return updated, nil
+}
+
+func (r *driveDataKeyRepository) scanKey(ctx context.Context, query string, arg any) (*entity.DriveDataKey, error) {
+	var dataKey entity.DriveDataKey
+	err := r.db.QueryRow(ctx, query, arg).Scan(
+		&dataKey.ID,
+		&dataKey.UserID,
+		&dataKey.MasterKeyID,
+		&dataKey.WrappedKey,
+		&dataKey.IsActive,
+		&dataKey.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &dataKey, nil
+}
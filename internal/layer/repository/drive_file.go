@@ -3,17 +3,27 @@ package repository
 import (
 	"assistant-go/internal/layer/entity"
 	"context"
+	"time"
 )
 
 type DriveFileRepository interface {
-	GetStorageSize(ctx context.Context, userID int) (int64, error)
 	GetByStructID(ctx context.Context, structID int) (*entity.DriveFile, error)
-	GetLastID(ctx context.Context) (int, error)
 	Create(ctx context.Context, in *entity.DriveFile) (*entity.DriveFile, error)
 	GetAllRecursive(ctx context.Context, structID int, userID int) ([]*entity.DriveFile, error)
 	CheckFileOwner(ctx context.Context, fileID int, userID int) (bool, error)
-	UpdateSize(ctx context.Context, fileID int, size int64) error
-	UpdateHash(ctx context.Context, fileID int, hash string) error
+	Complete(ctx context.Context, fileID int, size int64, plainSize int64, hash string, mimeType string) error
+	GetByID(ctx context.Context, fileID int) (*entity.DriveFile, error)
+	GetVersions(ctx context.Context, structID int) ([]*entity.DriveFile, error)
+	UnsetCurrent(ctx context.Context, structID int, replacedAt time.Time) error
+	MarkCurrent(ctx context.Context, fileID int) error
+	DeleteByID(ctx context.Context, fileID int) error
+	GetOutdatedVersionIDs(ctx context.Context, structID int, keep int) ([]int, error)
+	GetExpiredVersionIDs(ctx context.Context, maxCount int, replacedBefore *time.Time) ([]int, error)
+	AttachBlob(ctx context.Context, fileID int, blob *entity.DriveBlob, mimeType string) error
+	GetMimeTypeByBlobID(ctx context.Context, blobID int) (*string, error)
+	TouchActivity(ctx context.Context, fileID int, at time.Time) error
+	GetAbandonedUploads(ctx context.Context, inactiveSince time.Time) ([]*entity.DriveFile, error)
+	GetCompleteChunkedFiles(ctx context.Context, afterID int, limit int) ([]*entity.DriveFile, error)
 }
 
 type driveFileRepository struct {
@@ -24,24 +34,8 @@ func NewDriveFileRepository(db DBExecutor) DriveFileRepository {
 	return &driveFileRepository{db: db}
 }
 
-func (r *driveFileRepository) GetStorageSize(ctx context.Context, userID int) (int64, error) {
-	query := `SELECT 
-    		coalesce(sum(df.size), 0) 
-		FROM drive_structs ds 
-		JOIN drive_files df on df.drive_struct_id = ds.id
-		WHERE ds.user_id = $1
-	`
-
-	var result int64
-	err := r.db.QueryRow(ctx, query, userID).Scan(&result)
-	if err != nil {
-		return 0, err
-	}
-	return result, nil
-}
-
 func (r *driveFileRepository) GetByStructID(ctx context.Context, structID int) (*entity.DriveFile, error) {
-	query := `select * from drive_files where drive_struct_id = $1`
+	query := `select * from drive_files where drive_struct_id = $1 and is_current`
 
 	var result entity.DriveFile
 	err := r.db.QueryRow(ctx, query, structID).Scan(
@@ -53,6 +47,15 @@ func (r *driveFileRepository) GetByStructID(ctx context.Context, structID int) (
 		&result.CreatedAt,
 		&result.IsChunk,
 		&result.SHA256,
+		&result.PlainSize,
+		&result.IsCurrent,
+		&result.ReplacedAt,
+		&result.BlobID,
+		&result.UploadState,
+		&result.ExpectedSize,
+		&result.LastActivityAt,
+		&result.MimeType,
+		&result.DataKeyID,
 	)
 	if err != nil {
 		return nil, err
@@ -61,17 +64,6 @@ func (r *driveFileRepository) GetByStructID(ctx context.Context, structID int) (
 	return &result, nil
 }
 
-func (r *driveFileRepository) GetLastID(ctx context.Context) (int, error) {
-	query := `SELECT coalesce(max(id), 0) FROM drive_files`
-
-	var result int
-	err := r.db.QueryRow(ctx, query).Scan(&result)
-	if err != nil {
-		return 0, err
-	}
-	return result, nil
-}
-
 func (r *driveFileRepository) Create(ctx context.Context, in *entity.DriveFile) (*entity.DriveFile, error) {
 	var (
 		query string
@@ -80,16 +72,22 @@ func (r *driveFileRepository) Create(ctx context.Context, in *entity.DriveFile)
 
 	if in.SHA256 == nil {
 		query = `
-			INSERT INTO drive_files (drive_struct_id, path, ext, size, created_at, is_chunk) 
-			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+			INSERT INTO drive_files (drive_struct_id, path, ext, size, created_at, is_chunk, plain_size, blob_id, upload_state, expected_size, last_activity_at, mime_type, data_key_id) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id
 		`
-		args = []any{in.DriveStructID, in.Path, in.Ext, in.Size, in.CreatedAt, in.IsChunk}
+		args = []any{
+			in.DriveStructID, in.Path, in.Ext, in.Size, in.CreatedAt, in.IsChunk, in.PlainSize, in.BlobID,
+			in.UploadState, in.ExpectedSize, in.LastActivityAt, in.MimeType, in.DataKeyID,
+		}
 	} else {
 		query = `
-			INSERT INTO drive_files (drive_struct_id, path, ext, size, created_at, is_chunk, sha256) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+			INSERT INTO drive_files (drive_struct_id, path, ext, size, created_at, is_chunk, sha256, plain_size, blob_id, upload_state, expected_size, last_activity_at, mime_type, data_key_id) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id
 		`
-		args = []any{in.DriveStructID, in.Path, in.Ext, in.Size, in.CreatedAt, in.IsChunk, in.SHA256}
+		args = []any{
+			in.DriveStructID, in.Path, in.Ext, in.Size, in.CreatedAt, in.IsChunk, in.SHA256, in.PlainSize, in.BlobID,
+			in.UploadState, in.ExpectedSize, in.LastActivityAt, in.MimeType, in.DataKeyID,
+		}
 	}
 
 	row := r.db.QueryRow(ctx, query, args...)
@@ -139,6 +137,15 @@ func (r *driveFileRepository) GetAllRecursive(ctx context.Context, structID int,
 			&df.CreatedAt,
 			&df.IsChunk,
 			&df.SHA256,
+			&df.PlainSize,
+			&df.IsCurrent,
+			&df.ReplacedAt,
+			&df.BlobID,
+			&df.UploadState,
+			&df.ExpectedSize,
+			&df.LastActivityAt,
+			&df.MimeType,
+			&df.DataKeyID,
 		); err != nil {
 			return nil, err
 		}
@@ -156,7 +163,7 @@ func (r *driveFileRepository) CheckFileOwner(ctx context.Context, fileID int, us
 		SELECT EXISTS(
 			SELECT 1 FROM drive_files df
 		 	LEFT JOIN drive_structs ds ON ds.id = df.drive_struct_id
-		 	WHERE df.id = $1 and ds.user_id = $2
+		 	WHERE df.id = $1 and ds.user_id = $2 and ds.deleted_at is null
 	  	)
 	`
 
@@ -168,22 +175,318 @@ func (r *driveFileRepository) CheckFileOwner(ctx context.Context, fileID int, us
 	return exists, nil
 }
 
-func (r *driveFileRepository) UpdateSize(ctx context.Context, fileID int, size int64) error {
-	query := `UPDATE drive_files SET size = $1 WHERE id = $2`
+// Complete сохраняет итоговые размер и хэш собранного из чанков файла и помечает загрузку завершенной
+func (r *driveFileRepository) Complete(
+	ctx context.Context,
+	fileID int,
+	size int64,
+	plainSize int64,
+	hash string,
+	mimeType string,
+) error {
+	query := `UPDATE drive_files SET size = $1, plain_size = $2, sha256 = $3, mime_type = $4, upload_state = 1 WHERE id = $5`
+
+	_, err := r.db.Exec(ctx, query, size, plainSize, hash, mimeType, fileID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *driveFileRepository) GetByID(ctx context.Context, fileID int) (*entity.DriveFile, error) {
+	query := `select * from drive_files where id = $1`
+
+	var result entity.DriveFile
+	err := r.db.QueryRow(ctx, query, fileID).Scan(
+		&result.ID,
+		&result.DriveStructID,
+		&result.Path,
+		&result.Ext,
+		&result.Size,
+		&result.CreatedAt,
+		&result.IsChunk,
+		&result.SHA256,
+		&result.PlainSize,
+		&result.IsCurrent,
+		&result.ReplacedAt,
+		&result.BlobID,
+		&result.UploadState,
+		&result.ExpectedSize,
+		&result.LastActivityAt,
+		&result.MimeType,
+		&result.DataKeyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetVersions возвращает все версии файла: текущую первой, затем предыдущие от новых к старым
+func (r *driveFileRepository) GetVersions(ctx context.Context, structID int) ([]*entity.DriveFile, error) {
+	query := `
+		select * from drive_files 
+		where drive_struct_id = $1 
+		order by is_current desc, replaced_at desc nulls last, id desc
+	`
+
+	rows, err := r.db.Query(ctx, query, structID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.DriveFile, 0)
+
+	for rows.Next() {
+		df := &entity.DriveFile{}
+		if err := rows.Scan(
+			&df.ID,
+			&df.DriveStructID,
+			&df.Path,
+			&df.Ext,
+			&df.Size,
+			&df.CreatedAt,
+			&df.IsChunk,
+			&df.SHA256,
+			&df.PlainSize,
+			&df.IsCurrent,
+			&df.ReplacedAt,
+			&df.BlobID,
+			&df.UploadState,
+			&df.ExpectedSize,
+			&df.LastActivityAt,
+			&df.MimeType,
+			&df.DataKeyID,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, df)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *driveFileRepository) UnsetCurrent(ctx context.Context, structID int, replacedAt time.Time) error {
+	query := `UPDATE drive_files SET is_current = false, replaced_at = $1 WHERE drive_struct_id = $2 AND is_current`
+
+	_, err := r.db.Exec(ctx, query, replacedAt, structID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *driveFileRepository) MarkCurrent(ctx context.Context, fileID int) error {
+	query := `UPDATE drive_files SET is_current = true, replaced_at = NULL WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, fileID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *driveFileRepository) DeleteByID(ctx context.Context, fileID int) error {
+	query := `DELETE FROM drive_files WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, fileID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetOutdatedVersionIDs возвращает предыдущие версии файла, кроме keep самых новых
+func (r *driveFileRepository) GetOutdatedVersionIDs(ctx context.Context, structID int, keep int) ([]int, error) {
+	query := `
+		select id from drive_files 
+		where drive_struct_id = $1 and not is_current
+		order by replaced_at desc nulls last, id desc
+		offset $2
+	`
+
+	return r.queryIDs(ctx, query, structID, keep)
+}
+
+// GetExpiredVersionIDs возвращает предыдущие версии всех файлов, которые выходят за maxCount
+// или заменены раньше replacedBefore. maxCount <= 0 и replacedBefore = nil отключают соответствующее условие
+func (r *driveFileRepository) GetExpiredVersionIDs(
+	ctx context.Context,
+	maxCount int,
+	replacedBefore *time.Time,
+) ([]int, error) {
+	query := `
+		select v.id from (
+			select 
+			    df.id, df.replaced_at,
+			    row_number() over (partition by df.drive_struct_id order by df.replaced_at desc nulls last, df.id desc) as rn
+			from drive_files df
+			where not df.is_current
+		) v
+		where ($1 > 0 and v.rn > $1) or ($2::timestamp is not null and v.replaced_at < $2::timestamp)
+	`
 
-	_, err := r.db.Exec(ctx, query, size, fileID)
+	return r.queryIDs(ctx, query, maxCount, replacedBefore)
+}
+
+func (r *driveFileRepository) queryIDs(ctx context.Context, query string, args ...any) ([]int, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		result = append(result, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetMimeTypeByBlobID возвращает MIME-тип, определенный для содержимого блоба при одной из его загрузок
+func (r *driveFileRepository) GetMimeTypeByBlobID(ctx context.Context, blobID int) (*string, error) {
+	query := `SELECT mime_type FROM drive_files WHERE blob_id = $1 AND mime_type IS NOT NULL ORDER BY id LIMIT 1`
+
+	var result *string
+	err := r.db.QueryRow(ctx, query, blobID).Scan(&result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AttachBlob переводит файл на общий блоб: файл перестает быть чанковым и ссылается на путь блоба
+func (r *driveFileRepository) AttachBlob(ctx context.Context, fileID int, blob *entity.DriveBlob, mimeType string) error {
+	query := `
+		UPDATE drive_files 
+		SET blob_id = $1, path = $2, size = $3, plain_size = $4, sha256 = $5, mime_type = $6, is_chunk = false, upload_state = 1,
+			data_key_id = $8
+		WHERE id = $7
+	`
+
+	_, err := r.db.Exec(ctx, query, blob.ID, blob.Path, blob.Size, blob.PlainSize, blob.SHA256, mimeType, fileID, blob.DataKeyID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *driveFileRepository) UpdateHash(ctx context.Context, fileID int, hash string) error {
-	query := `UPDATE drive_files SET sha256 = $1 WHERE id = $2`
+func (r *driveFileRepository) TouchActivity(ctx context.Context, fileID int, at time.Time) error {
+	query := `UPDATE drive_files SET last_activity_at = $1 WHERE id = $2`
 
-	_, err := r.db.Exec(ctx, query, hash, fileID)
+	_, err := r.db.Exec(ctx, query, at, fileID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// GetAbandonedUploads возвращает незавершенные чанковые загрузки без активности с момента inactiveSince
+func (r *driveFileRepository) GetAbandonedUploads(ctx context.Context, inactiveSince time.Time) ([]*entity.DriveFile, error) {
+	query := `
+		select * from drive_files 
+		where upload_state = 0 and coalesce(last_activity_at, created_at) < $1
+		order by id
+	`
+
+	rows, err := r.db.Query(ctx, query, inactiveSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.DriveFile, 0)
+
+	for rows.Next() {
+		df := &entity.DriveFile{}
+		if err := rows.Scan(
+			&df.ID,
+			&df.DriveStructID,
+			&df.Path,
+			&df.Ext,
+			&df.Size,
+			&df.CreatedAt,
+			&df.IsChunk,
+			&df.SHA256,
+			&df.PlainSize,
+			&df.IsCurrent,
+			&df.ReplacedAt,
+			&df.BlobID,
+			&df.UploadState,
+			&df.ExpectedSize,
+			&df.LastActivityAt,
+			&df.MimeType,
+			&df.DataKeyID,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, df)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCompleteChunkedFiles возвращает до limit завершенных чанковых файлов с id больше afterID
+func (r *driveFileRepository) GetCompleteChunkedFiles(ctx context.Context, afterID int, limit int) ([]*entity.DriveFile, error) {
+	query := `
+		select * from drive_files 
+		where id > $1 and is_chunk and upload_state = 1
+		order by id
+		limit $2
+	`
+
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.DriveFile, 0, limit)
+
+	for rows.Next() {
+		df := &entity.DriveFile{}
+		if err := rows.Scan(
+			&df.ID,
+			&df.DriveStructID,
+			&df.Path,
+			&df.Ext,
+			&df.Size,
+			&df.CreatedAt,
+			&df.IsChunk,
+			&df.SHA256,
+			&df.PlainSize,
+			&df.IsCurrent,
+			&df.ReplacedAt,
+			&df.BlobID,
+			&df.UploadState,
+			&df.ExpectedSize,
+			&df.LastActivityAt,
+			&df.MimeType,
+			&df.DataKeyID,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, df)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
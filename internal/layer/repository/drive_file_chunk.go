@@ -8,6 +8,7 @@ import (
 
 type DriveFileChunkRepository interface {
 	GetChunksSize(ctx context.Context, fileID int) (int64, error)
+	GetChunksPlainSize(ctx context.Context, fileID int) (int64, error)
 	Create(ctx context.Context, in *entity.DriveFileChunk) (*entity.DriveFileChunk, error)
 	GetAllRecursive(
 		ctx context.Context,
@@ -16,6 +17,9 @@ type DriveFileChunkRepository interface {
 	) ([]*entity.DriveFileChunk, error)
 	GetChunksInfo(ctx context.Context, fileID int) (*dto.DriveChunksInfo, error)
 	GetByFileIDAndNumber(ctx context.Context, fileID int, chunkNumber int) (*entity.DriveFileChunk, error)
+	GetByFileID(ctx context.Context, fileID int) ([]*entity.DriveFileChunk, error)
+	DeleteByFileID(ctx context.Context, fileID int) error
+	DeleteByID(ctx context.Context, chunkID int) error
 }
 
 type driveFileChunkRepository struct {
@@ -41,10 +45,25 @@ func (r *driveFileChunkRepository) GetChunksSize(ctx context.Context, fileID int
 	return result, nil
 }
 
+func (r *driveFileChunkRepository) GetChunksPlainSize(ctx context.Context, fileID int) (int64, error) {
+	query := `SELECT 
+    		coalesce(sum(coalesce(dfc.plain_size, dfc.size)), 0) 
+		FROM drive_file_chunks dfc
+		WHERE dfc.drive_file_id = $1
+	`
+
+	var result int64
+	err := r.db.QueryRow(ctx, query, fileID).Scan(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
 func (r *driveFileChunkRepository) Create(ctx context.Context, in *entity.DriveFileChunk) (*entity.DriveFileChunk, error) {
 	query := `
-		INSERT INTO drive_file_chunks (drive_file_id, path, size, chunk_number) 
-		VALUES ($1, $2, $3, $4) RETURNING id
+		INSERT INTO drive_file_chunks (drive_file_id, path, size, chunk_number, plain_size, data_key_id) 
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
 	`
 
 	row := r.db.QueryRow(
@@ -54,6 +73,8 @@ func (r *driveFileChunkRepository) Create(ctx context.Context, in *entity.DriveF
 		in.Path,
 		in.Size,
 		in.ChunkNumber,
+		in.PlainSize,
+		in.DataKeyID,
 	)
 
 	if err := row.Scan(&in.ID); err != nil {
@@ -100,7 +121,7 @@ func (r *driveFileChunkRepository) GetAllRecursive(
 
 	for rows.Next() {
 		dfc := &entity.DriveFileChunk{}
-		if err := rows.Scan(&dfc.ID, &dfc.DriveFileID, &dfc.Path, &dfc.Size, &dfc.ChunkNumber); err != nil {
+		if err := rows.Scan(&dfc.ID, &dfc.DriveFileID, &dfc.Path, &dfc.Size, &dfc.ChunkNumber, &dfc.PlainSize, &dfc.DataKeyID); err != nil {
 			return nil, err
 		}
 		result = append(result, dfc)
@@ -145,6 +166,8 @@ func (r *driveFileChunkRepository) GetByFileIDAndNumber(
 		&result.Path,
 		&result.Size,
 		&result.ChunkNumber,
+		&result.PlainSize,
+		&result.DataKeyID,
 	)
 	if err != nil {
 		return nil, err
@@ -152,3 +175,48 @@ func (r *driveFileChunkRepository) GetByFileIDAndNumber(
 
 	return &result, nil
 }
+
+func (r *driveFileChunkRepository) GetByFileID(ctx context.Context, fileID int) ([]*entity.DriveFileChunk, error) {
+	query := `select * from drive_file_chunks where drive_file_id = $1 order by chunk_number`
+
+	rows, err := r.db.Query(ctx, query, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.DriveFileChunk, 0)
+
+	for rows.Next() {
+		dfc := &entity.DriveFileChunk{}
+		if err := rows.Scan(&dfc.ID, &dfc.DriveFileID, &dfc.Path, &dfc.Size, &dfc.ChunkNumber, &dfc.PlainSize, &dfc.DataKeyID); err != nil {
+			return nil, err
+		}
+		result = append(result, dfc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *driveFileChunkRepository) DeleteByFileID(ctx context.Context, fileID int) error {
+	query := `DELETE FROM drive_file_chunks WHERE drive_file_id = $1`
+
+	_, err := r.db.Exec(ctx, query, fileID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *driveFileChunkRepository) DeleteByID(ctx context.Context, chunkID int) error {
+	query := `DELETE FROM drive_file_chunks WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, chunkID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"context"
+	"github.com/jackc/pgx/v5"
+)
+
+type DriveGrantRepository interface {
+	Upsert(ctx context.Context, in *entity.DriveGrant) (*entity.DriveGrant, error)
+	GetByStructID(ctx context.Context, structID int) ([]*dto.DriveGrantItem, error)
+	DeleteByID(ctx context.Context, ID int, ownerID int) (bool, error)
+	GetRole(ctx context.Context, structID int, granteeID int) (int8, error)
+	SharedWithUser(ctx context.Context, granteeID int) ([]*dto.DriveTree, error)
+}
+
+type driveGrantRepository struct {
+	db DBExecutor
+}
+
+func NewDriveGrantRepository(db DBExecutor) DriveGrantRepository {
+	return &driveGrantRepository{db: db}
+}
+
+// Upsert создает грант или меняет роль существующего гранта на ту же структуру
+func (r *driveGrantRepository) Upsert(ctx context.Context, in *entity.DriveGrant) (*entity.DriveGrant, error) {
+	query := `
+		INSERT INTO drive_grants (drive_struct_id, owner_id, grantee_id, role, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (drive_struct_id, grantee_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING id, created_at
+	`
+
+	row := r.db.QueryRow(ctx, query, in.DriveStructID, in.OwnerID, in.GranteeID, in.Role, in.CreatedAt)
+
+	if err := row.Scan(&in.ID, &in.CreatedAt); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (r *driveGrantRepository) GetByStructID(ctx context.Context, structID int) ([]*dto.DriveGrantItem, error) {
+	query := `
+		SELECT dg.id, dg.drive_struct_id, dg.grantee_id, u.login, dg.role, dg.created_at
+		FROM drive_grants dg
+		INNER JOIN users u ON u.id = dg.grantee_id
+		WHERE dg.drive_struct_id = $1
+		ORDER BY u.login
+	`
+
+	rows, err := r.db.Query(ctx, query, structID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make([]*dto.DriveGrantItem, 0)
+	for rows.Next() {
+		grant := &dto.DriveGrantItem{}
+		if err := rows.Scan(
+			&grant.ID,
+			&grant.StructID,
+			&grant.GranteeID,
+			&grant.GranteeLogin,
+			&grant.Role,
+			&grant.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (r *driveGrantRepository) DeleteByID(ctx context.Context, ID int, ownerID int) (bool, error) {
+	query := `DELETE FROM drive_grants WHERE id = $1 AND owner_id = $2`
+
+	tag, err := r.db.Exec(ctx, query, ID, ownerID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetRole возвращает наибольшую роль пользователя, выданную на структуру или любого из ее родителей.
+// Структуры в корзине недоступны. Если гранта нет, возвращается pgx.ErrNoRows
+func (r *driveGrantRepository) GetRole(ctx context.Context, structID int, granteeID int) (int8, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id
+			FROM drive_structs
+			WHERE id = $1 AND deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT ds.id, ds.parent_id
+			FROM drive_structs ds
+			INNER JOIN chain c ON ds.id = c.parent_id
+			WHERE ds.deleted_at IS NULL
+		)
+		SELECT max(dg.role)
+		FROM drive_grants dg
+		INNER JOIN chain c ON dg.drive_struct_id = c.id
+		WHERE dg.grantee_id = $2
+	`
+
+	var role *int8
+	if err := r.db.QueryRow(ctx, query, structID, granteeID).Scan(&role); err != nil {
+		return 0, err
+	}
+	if role == nil {
+		return 0, pgx.ErrNoRows
+	}
+	return *role, nil
+}
+
+// SharedWithUser возвращает директории, на которые пользователю выданы гранты
+func (r *driveGrantRepository) SharedWithUser(ctx context.Context, granteeID int) ([]*dto.DriveTree, error) {
+	query := `
+		SELECT ds.id, ds.user_id, ds.name, ds.type, ds.created_at, ds.updated_at, dg.role
+		FROM drive_grants dg
+		INNER JOIN drive_structs ds ON ds.id = dg.drive_struct_id
+		WHERE dg.grantee_id = $1 AND ds.deleted_at IS NULL
+		ORDER BY ds.name
+	`
+
+	rows, err := r.db.Query(ctx, query, granteeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	structs := make([]*dto.DriveTree, 0)
+	for rows.Next() {
+		ds := &dto.DriveTree{UploadState: 1}
+		var role int8
+		if err := rows.Scan(
+			&ds.ID,
+			&ds.UserID,
+			&ds.Name,
+			&ds.Type,
+			&ds.CreatedAt,
+			&ds.UpdatedAt,
+			&role,
+		); err != nil {
+			return nil, err
+		}
+		ds.Role = &role
+		structs = append(structs, ds)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return structs, nil
+}
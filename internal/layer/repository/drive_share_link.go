@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type DriveShareLinkRepository interface {
+	Create(ctx context.Context, in *entity.DriveShareLink) (*entity.DriveShareLink, error)
+	ExistsByHash(ctx context.Context, hash string) (bool, error)
+	GetByHash(ctx context.Context, hash string) (*entity.DriveShareLink, error)
+	GetActiveByUserID(ctx context.Context, userID int, structID *int, now time.Time) ([]*entity.DriveShareLink, error)
+	DeleteByID(ctx context.Context, ID int, userID int) (bool, error)
+	IncrementDownloads(ctx context.Context, ID int) error
+	IsInSubtree(ctx context.Context, rootID int, structID int) (bool, error)
+	SubtreeByRootID(ctx context.Context, rootID int) ([]*dto.DriveSharedItem, error)
+}
+
+type driveShareLinkRepository struct {
+	db DBExecutor
+}
+
+func NewDriveShareLinkRepository(db DBExecutor) DriveShareLinkRepository {
+	return &driveShareLinkRepository{db: db}
+}
+
+func (r *driveShareLinkRepository) Create(ctx context.Context, in *entity.DriveShareLink) (*entity.DriveShareLink, error) {
+	query := `
+		INSERT INTO drive_share_links
+		    (user_id, drive_struct_id, hash, password, expires_at, max_downloads, download_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+	`
+
+	row := r.db.QueryRow(
+		ctx,
+		query,
+		in.UserID,
+		in.DriveStructID,
+		in.Hash,
+		in.Password,
+		in.ExpiresAt,
+		in.MaxDownloads,
+		in.DownloadCount,
+		in.CreatedAt,
+	)
+
+	if err := row.Scan(&in.ID); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (r *driveShareLinkRepository) ExistsByHash(ctx context.Context, hash string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM drive_share_links WHERE hash = $1)`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, hash).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *driveShareLinkRepository) GetByHash(ctx context.Context, hash string) (*entity.DriveShareLink, error) {
+	query := `SELECT * FROM drive_share_links WHERE hash = $1`
+
+	return r.scanOne(r.db.QueryRow(ctx, query, hash))
+}
+
+// GetActiveByUserID возвращает действующие ссылки пользователя: не истекшие и с неисчерпанным лимитом скачиваний
+func (r *driveShareLinkRepository) GetActiveByUserID(
+	ctx context.Context,
+	userID int,
+	structID *int,
+	now time.Time,
+) ([]*entity.DriveShareLink, error) {
+	query := `
+		SELECT dsl.* FROM drive_share_links dsl
+		INNER JOIN drive_structs ds ON ds.id = dsl.drive_struct_id
+		WHERE dsl.user_id = $1
+			AND ($2::int IS NULL OR dsl.drive_struct_id = $2)
+			AND (dsl.expires_at IS NULL OR dsl.expires_at > $3)
+			AND (dsl.max_downloads IS NULL OR dsl.download_count < dsl.max_downloads)
+			AND ds.deleted_at IS NULL
+		ORDER BY dsl.id DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, structID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]*entity.DriveShareLink, 0)
+	for rows.Next() {
+		link, err := r.scanOne(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *driveShareLinkRepository) DeleteByID(ctx context.Context, ID int, userID int) (bool, error) {
+	query := `DELETE FROM drive_share_links WHERE id = $1 AND user_id = $2`
+
+	tag, err := r.db.Exec(ctx, query, ID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// IncrementDownloads учитывает скачивание. Если лимит уже исчерпан, возвращается pgx.ErrNoRows
+func (r *driveShareLinkRepository) IncrementDownloads(ctx context.Context, ID int) error {
+	query := `
+		UPDATE drive_share_links SET download_count = download_count + 1
+		WHERE id = $1 AND (max_downloads IS NULL OR download_count < max_downloads)
+		RETURNING download_count
+	`
+
+	var downloadCount int
+	return r.db.QueryRow(ctx, query, ID).Scan(&downloadCount)
+}
+
+// IsInSubtree проверяет, что структура находится внутри rootID (или совпадает с ней),
+// и ни она, ни ее родители до rootID не находятся в корзине
+func (r *driveShareLinkRepository) IsInSubtree(ctx context.Context, rootID int, structID int) (bool, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id
+			FROM drive_structs
+			WHERE id = $1 AND deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT ds.id, ds.parent_id
+			FROM drive_structs ds
+			INNER JOIN chain c ON ds.id = c.parent_id
+			WHERE c.id <> $2 AND ds.deleted_at IS NULL
+		)
+		SELECT EXISTS(SELECT 1 FROM chain WHERE id = $2)
+	`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, structID, rootID).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// SubtreeByRootID возвращает корень и все вложенные элементы, кроме находящихся в корзине и недозагруженных файлов
+func (r *driveShareLinkRepository) SubtreeByRootID(ctx context.Context, rootID int) ([]*dto.DriveSharedItem, error) {
+	query := `
+		WITH RECURSIVE structs AS (
+			SELECT *
+			FROM drive_structs
+			WHERE id = $1 AND deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT ds.*
+			FROM drive_structs ds
+			INNER JOIN structs s ON ds.parent_id = s.id
+			WHERE ds.deleted_at IS NULL
+		)
+		SELECT
+			s.id, s.parent_id, s.name, s.type, s.updated_at,
+			coalesce(df.plain_size, df.size, 0) as size
+		FROM structs s
+		LEFT JOIN drive_files df ON s.id = df.drive_struct_id AND df.is_current
+		WHERE coalesce(df.upload_state, 1) = 1
+		ORDER BY s.type, s.name
+	`
+
+	rows, err := r.db.Query(ctx, query, rootID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*dto.DriveSharedItem, 0)
+	for rows.Next() {
+		item := &dto.DriveSharedItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.ParentID,
+			&item.Name,
+			&item.Type,
+			&item.UpdatedAt,
+			&item.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *driveShareLinkRepository) scanOne(row pgx.Row) (*entity.DriveShareLink, error) {
+	var link entity.DriveShareLink
+	if err := row.Scan(
+		&link.ID,
+		&link.UserID,
+		&link.DriveStructID,
+		&link.Hash,
+		&link.Password,
+		&link.ExpiresAt,
+		&link.MaxDownloads,
+		&link.DownloadCount,
+		&link.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
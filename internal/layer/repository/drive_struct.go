@@ -4,6 +4,9 @@ import (
 	"assistant-go/internal/layer/dto"
 	"assistant-go/internal/layer/entity"
 	"context"
+	"fmt"
+	"strings"
+	"time"
 )
 
 type DriveStructRepository interface {
@@ -16,6 +19,13 @@ type DriveStructRepository interface {
 	DeleteRecursive(ctx context.Context, userID int, structID int) error
 	StructCountByUserAndIDs(ctx context.Context, userID int, IDs []int) (int, error)
 	MassUpdateParentID(ctx context.Context, parentID *int, IDs []int) error
+	MoveToTrash(ctx context.Context, userID int, structID int, deletedAt time.Time) error
+	RestoreFromTrash(ctx context.Context, userID int, structID int) error
+	TrashByUserID(ctx context.Context, userID int) ([]*dto.DriveTrashItem, error)
+	GetTrashRootsBefore(ctx context.Context, before time.Time) ([]*entity.DriveStruct, error)
+	Search(ctx context.Context, userID int, in *dto.DriveSearch) ([]*dto.DriveSearchItem, int, error)
+	ResolvePath(ctx context.Context, userID int, names []string) (*dto.DrivePathItem, error)
+	Ancestors(ctx context.Context, userID int, structID int) ([]*dto.DrivePathItem, error)
 }
 
 type driveStructRepository struct {
@@ -40,6 +50,8 @@ func (r *driveStructRepository) GetByID(ctx context.Context, ID int) (*entity.Dr
 		&driveStruct.ParentID,
 		&driveStruct.CreatedAt,
 		&driveStruct.UpdatedAt,
+		&driveStruct.DeletedAt,
+		&driveStruct.TrashRootID,
 	); err != nil {
 		return nil, err
 	}
@@ -59,10 +71,10 @@ func (r *driveStructRepository) FindRow(
 	)
 
 	if parentID == nil {
-		query = `SELECT * FROM drive_structs WHERE user_id = $1 AND name = $2 AND type = $3 AND parent_id IS NULL`
+		query = `SELECT * FROM drive_structs WHERE user_id = $1 AND name = $2 AND type = $3 AND parent_id IS NULL AND deleted_at IS NULL`
 		args = []any{userID, name, rowType}
 	} else {
-		query = `SELECT * FROM drive_structs WHERE user_id = $1 AND name = $2 AND type = $3 AND parent_id = $4`
+		query = `SELECT * FROM drive_structs WHERE user_id = $1 AND name = $2 AND type = $3 AND parent_id = $4 AND deleted_at IS NULL`
 		args = []any{userID, name, rowType, parentID}
 	}
 
@@ -77,6 +89,8 @@ func (r *driveStructRepository) FindRow(
 		&driveStruct.ParentID,
 		&driveStruct.CreatedAt,
 		&driveStruct.UpdatedAt,
+		&driveStruct.DeletedAt,
+		&driveStruct.TrashRootID,
 	); err != nil {
 		return nil, err
 	}
@@ -121,24 +135,26 @@ func (r *driveStructRepository) TreeByUserID(ctx context.Context, userID int, pa
 		query = `
 			select 
 			    ds.id, ds.user_id, ds.name, ds.type, ds.created_at, ds.updated_at,
-			    coalesce(df.size, 0) as size,
+			    coalesce(df.plain_size, df.size, 0) as size,
 				coalesce(df.is_chunk, false) as is_chunk,
-				df.sha256
+				df.sha256,
+				coalesce(df.upload_state, 1) as upload_state
 			from drive_structs ds 
-			left join drive_files df on ds.id = df.drive_struct_id
-			where user_id = $1 and parent_id is null
+			left join drive_files df on ds.id = df.drive_struct_id and df.is_current
+			where user_id = $1 and parent_id is null and ds.deleted_at is null
 		`
 		args = []any{userID}
 	} else {
 		query = `
 			select 
 			    ds.id, ds.user_id, ds.name, ds.type, ds.created_at, ds.updated_at,
-			    coalesce(df.size, 0) as size,
+			    coalesce(df.plain_size, df.size, 0) as size,
 				coalesce(df.is_chunk, false) as is_chunk,
-				df.sha256
+				df.sha256,
+				coalesce(df.upload_state, 1) as upload_state
 			from drive_structs ds
-			left join drive_files df on ds.id = df.drive_struct_id
-			where user_id = $1 and parent_id = $2
+			left join drive_files df on ds.id = df.drive_struct_id and df.is_current
+			where user_id = $1 and parent_id = $2 and ds.deleted_at is null
 		`
 		args = []any{userID, parentID}
 	}
@@ -162,6 +178,7 @@ func (r *driveStructRepository) TreeByUserID(ctx context.Context, userID int, pa
 			&ds.Size,
 			&ds.IsChunk,
 			&ds.SHA256,
+			&ds.UploadState,
 		); err != nil {
 			return nil, err
 		}
@@ -207,6 +224,8 @@ func (r *driveStructRepository) GetAllRecursive(ctx context.Context, userID int,
 			&ds.ParentID,
 			&ds.CreatedAt,
 			&ds.UpdatedAt,
+			&ds.DeletedAt,
+			&ds.TrashRootID,
 		); err != nil {
 			return nil, err
 		}
@@ -250,7 +269,7 @@ func (r *driveStructRepository) StructCountByUserAndIDs(ctx context.Context, use
 			select 
 			    coalesce(count(ds.id), 0) as count
 			from drive_structs ds 
-			where user_id = $1 and id = ANY($2)
+			where user_id = $1 and id = ANY($2) and deleted_at is null
 		`
 
 	var result int
@@ -270,3 +289,316 @@ func (r *driveStructRepository) MassUpdateParentID(ctx context.Context, parentID
 	}
 	return nil
 }
+
+// MoveToTrash помечает удаленными структуру и все ее вложенные элементы, которые еще не в корзине
+func (r *driveStructRepository) MoveToTrash(ctx context.Context, userID int, structID int, deletedAt time.Time) error {
+	query := `
+		UPDATE drive_structs SET deleted_at = $3, trash_root_id = $1
+		WHERE deleted_at IS NULL AND id in (
+		    WITH RECURSIVE structs AS (
+				SELECT id
+				FROM drive_structs 
+				WHERE id = $1 and user_id = $2
+			
+				UNION ALL
+			
+				SELECT ds.id
+				FROM drive_structs ds
+				INNER JOIN structs s ON ds.parent_id = s.id
+			)
+			SELECT id FROM structs
+		)
+	`
+
+	_, err := r.db.Exec(ctx, query, structID, userID, deletedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *driveStructRepository) RestoreFromTrash(ctx context.Context, userID int, structID int) error {
+	query := `UPDATE drive_structs SET deleted_at = NULL, trash_root_id = NULL WHERE trash_root_id = $1 AND user_id = $2`
+
+	_, err := r.db.Exec(ctx, query, structID, userID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *driveStructRepository) TrashByUserID(ctx context.Context, userID int) ([]*dto.DriveTrashItem, error) {
+	query := `
+		select 
+		    ds.id, ds.name, ds.type, ds.parent_id, ds.deleted_at,
+		    (
+		        select coalesce(sum(coalesce(df.plain_size, df.size)), 0)
+		        from drive_structs t
+		        join drive_files df on df.drive_struct_id = t.id and df.is_current
+		        where t.trash_root_id = ds.id
+		    ) as size
+		from drive_structs ds
+		where ds.user_id = $1 and ds.trash_root_id = ds.id
+		order by ds.deleted_at desc, ds.id desc
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*dto.DriveTrashItem, 0)
+	for rows.Next() {
+		item := &dto.DriveTrashItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.Name,
+			&item.Type,
+			&item.ParentID,
+			&item.DeletedAt,
+			&item.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *driveStructRepository) GetTrashRootsBefore(ctx context.Context, before time.Time) ([]*entity.DriveStruct, error) {
+	query := `SELECT * FROM drive_structs WHERE trash_root_id = id AND deleted_at < $1`
+
+	rows, err := r.db.Query(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	structs := make([]*entity.DriveStruct, 0)
+	for rows.Next() {
+		ds := &entity.DriveStruct{}
+		if err := rows.Scan(
+			&ds.ID,
+			&ds.UserID,
+			&ds.Name,
+			&ds.Type,
+			&ds.ParentID,
+			&ds.CreatedAt,
+			&ds.UpdatedAt,
+			&ds.DeletedAt,
+			&ds.TrashRootID,
+		); err != nil {
+			return nil, err
+		}
+		structs = append(structs, ds)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return structs, nil
+}
+
+// Search ищет по имени (подстрока без учета регистра, использует trigram-индекс) и фильтрам.
+// Для каждого найденного элемента строится полный путь от корня по цепочке parent_id.
+// Возвращает страницу результатов и общее количество найденных элементов
+func (r *driveStructRepository) Search(ctx context.Context, userID int, in *dto.DriveSearch) ([]*dto.DriveSearchItem, int, error) {
+	conditions := []string{"ds.user_id = $1", "ds.deleted_at IS NULL", "coalesce(df.upload_state, 1) = 1"}
+	args := []any{userID}
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if in.Query != "" {
+		escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+		addCondition("ds.name ILIKE $%d", "%"+escaper.Replace(in.Query)+"%")
+	}
+	if in.Ext != "" {
+		addCondition("df.ext = $%d", strings.ToLower(strings.TrimPrefix(in.Ext, ".")))
+	}
+	if in.Type != nil {
+		addCondition("ds.type = $%d", *in.Type)
+	}
+	if in.MinSize != nil {
+		addCondition("coalesce(df.plain_size, df.size, 0) >= $%d", *in.MinSize)
+	}
+	if in.MaxSize != nil {
+		addCondition("coalesce(df.plain_size, df.size, 0) <= $%d", *in.MaxSize)
+	}
+	if in.CreatedFrom != nil {
+		addCondition("ds.created_at >= $%d", in.CreatedFrom.UTC())
+	}
+	if in.CreatedTo != nil {
+		addCondition("ds.created_at <= $%d", in.CreatedTo.UTC())
+	}
+	if in.UpdatedFrom != nil {
+		addCondition("ds.updated_at >= $%d", in.UpdatedFrom.UTC())
+	}
+	if in.UpdatedTo != nil {
+		addCondition("ds.updated_at <= $%d", in.UpdatedTo.UTC())
+	}
+
+	from := `
+		FROM drive_structs ds
+		LEFT JOIN drive_files df ON df.drive_struct_id = ds.id AND df.is_current
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT count(*) "+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, in.Limit, (in.Page-1)*in.Limit)
+	query := fmt.Sprintf(`
+		WITH RECURSIVE hits AS (
+			SELECT
+				ds.id, ds.parent_id, ds.name, ds.type, df.ext,
+				coalesce(df.plain_size, df.size, 0) as size,
+				ds.created_at, ds.updated_at
+			%s
+			ORDER BY ds.type, ds.name, ds.id
+			LIMIT $%d OFFSET $%d
+		), paths AS (
+			SELECT h.id as hit_id, h.parent_id as next_id, ARRAY[]::text[] as names, 0 as depth
+			FROM hits h
+
+			UNION ALL
+
+			SELECT p.hit_id, ds.parent_id, ds.name || p.names, p.depth + 1
+			FROM paths p
+			INNER JOIN drive_structs ds ON ds.id = p.next_id
+			WHERE p.depth < 256
+		), full_paths AS (
+			SELECT DISTINCT ON (hit_id) hit_id, names
+			FROM paths
+			ORDER BY hit_id, depth DESC
+		)
+		SELECT
+			h.id, h.parent_id, h.name, h.type, h.ext, h.size, h.created_at, h.updated_at,
+			'/' || array_to_string(fp.names || h.name, '/') as path
+		FROM hits h
+		INNER JOIN full_paths fp ON fp.hit_id = h.id
+		ORDER BY h.type, h.name, h.id
+	`, from, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]*dto.DriveSearchItem, 0)
+	for rows.Next() {
+		item := &dto.DriveSearchItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.ParentID,
+			&item.Name,
+			&item.Type,
+			&item.Ext,
+			&item.Size,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.Path,
+		); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// ResolvePath находит структуру по пути от корня пользователя. Промежуточные элементы - только директории,
+// если последний элемент совпадает по имени и с директорией, и с файлом, возвращается директория
+func (r *driveStructRepository) ResolvePath(ctx context.Context, userID int, names []string) (*dto.DrivePathItem, error) {
+	query := `
+		WITH RECURSIVE walk AS (
+			SELECT id, parent_id, name, type, 1 as depth
+			FROM drive_structs
+			WHERE user_id = $1 AND parent_id IS NULL AND deleted_at IS NULL
+				AND name = ($2::text[])[1]
+				AND (type = 0 OR cardinality($2::text[]) = 1)
+
+			UNION ALL
+
+			SELECT ds.id, ds.parent_id, ds.name, ds.type, w.depth + 1
+			FROM drive_structs ds
+			INNER JOIN walk w ON ds.parent_id = w.id
+			WHERE w.depth < cardinality($2::text[]) AND ds.deleted_at IS NULL
+				AND ds.name = ($2::text[])[w.depth + 1]
+				AND (ds.type = 0 OR w.depth + 1 = cardinality($2::text[]))
+		)
+		SELECT id, parent_id, name, type
+		FROM walk
+		WHERE depth = cardinality($2::text[])
+		ORDER BY type, id
+		LIMIT 1
+	`
+
+	var item dto.DrivePathItem
+	if err := r.db.QueryRow(ctx, query, userID, names).Scan(
+		&item.ID,
+		&item.ParentID,
+		&item.Name,
+		&item.Type,
+	); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Ancestors возвращает цепочку от корня до структуры включительно
+func (r *driveStructRepository) Ancestors(ctx context.Context, userID int, structID int) ([]*dto.DrivePathItem, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id, name, type, 0 as depth
+			FROM drive_structs
+			WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT ds.id, ds.parent_id, ds.name, ds.type, c.depth + 1
+			FROM drive_structs ds
+			INNER JOIN chain c ON ds.id = c.parent_id
+			WHERE c.depth < 256
+		)
+		SELECT id, parent_id, name, type
+		FROM chain
+		ORDER BY depth DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, structID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*dto.DrivePathItem, 0)
+	for rows.Next() {
+		item := &dto.DrivePathItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.ParentID,
+			&item.Name,
+			&item.Type,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -4,14 +4,11 @@ import (
 	"assistant-go/internal/layer/entity"
 	"assistant-go/internal/logging"
 	"context"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type FileRepository interface {
 	Create(ctx context.Context, in *entity.File) (*entity.File, error)
-	GetLastID(ctx context.Context) (int, error)
 	GetAllFilesSize(ctx context.Context) (int64, error)
-	GetFilesSizeByUser(ctx context.Context, userID int) (int64, error)
 	GetByHash(ctx context.Context, hash string) (*entity.File, error)
 	GetByID(ctx context.Context, fileID int) (*entity.File, error)
 	GetUnusedFileIDs(ctx context.Context) (<-chan int, error)
@@ -19,17 +16,17 @@ type FileRepository interface {
 }
 
 type fileRepository struct {
-	db *pgxpool.Pool
+	db DBExecutor
 }
 
-func NewFileRepository(db *pgxpool.Pool) FileRepository {
+func NewFileRepository(db DBExecutor) FileRepository {
 	return &fileRepository{db: db}
 }
 
 func (r *fileRepository) Create(ctx context.Context, in *entity.File) (*entity.File, error) {
 	query := `
-		INSERT INTO files (user_id, original_filename, file_path, ext, size, hash, created_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+		INSERT INTO files (user_id, original_filename, file_path, ext, size, hash, created_at, plain_size, data_key_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id
 	`
 
 	row := r.db.QueryRow(
@@ -42,6 +39,8 @@ func (r *fileRepository) Create(ctx context.Context, in *entity.File) (*entity.F
 		in.Size,
 		in.Hash,
 		in.CreatedAt,
+		in.PlainSize,
+		in.DataKeyID,
 	)
 
 	if err := row.Scan(&in.ID); err != nil {
@@ -50,17 +49,6 @@ func (r *fileRepository) Create(ctx context.Context, in *entity.File) (*entity.F
 	return in, nil
 }
 
-func (r *fileRepository) GetLastID(ctx context.Context) (int, error) {
-	query := `SELECT coalesce(max(id), 0) FROM files`
-
-	var result int
-	err := r.db.QueryRow(ctx, query).Scan(&result)
-	if err != nil {
-		return 0, err
-	}
-	return result, nil
-}
-
 func (r *fileRepository) GetAllFilesSize(ctx context.Context) (int64, error) {
 	query := `SELECT coalesce(sum(size), 0) FROM files`
 
@@ -72,17 +60,6 @@ func (r *fileRepository) GetAllFilesSize(ctx context.Context) (int64, error) {
 	return result, nil
 }
 
-func (r *fileRepository) GetFilesSizeByUser(ctx context.Context, userID int) (int64, error) {
-	query := `SELECT coalesce(sum(size), 0) FROM files where user_id = $1`
-
-	var result int64
-	err := r.db.QueryRow(ctx, query, userID).Scan(&result)
-	if err != nil {
-		return 0, err
-	}
-	return result, nil
-}
-
 func (r *fileRepository) GetByHash(ctx context.Context, hash string) (*entity.File, error) {
 	query := `select * from files where hash = $1`
 	row := r.db.QueryRow(ctx, query, hash)
@@ -96,6 +73,8 @@ func (r *fileRepository) GetByHash(ctx context.Context, hash string) (*entity.Fi
 		&file.Size,
 		&file.Hash,
 		&file.CreatedAt,
+		&file.PlainSize,
+		&file.DataKeyID,
 	); err != nil {
 		return nil, err
 	}
@@ -115,6 +94,8 @@ func (r *fileRepository) GetByID(ctx context.Context, fileID int) (*entity.File,
 		&file.Size,
 		&file.Hash,
 		&file.CreatedAt,
+		&file.PlainSize,
+		&file.DataKeyID,
 	); err != nil {
 		return nil, err
 	}
@@ -3,12 +3,15 @@ package repository
 import (
 	"assistant-go/internal/layer/dto"
 	"assistant-go/internal/logging"
+	"bytes"
 	"context"
 	"errors"
 	"github.com/minio/minio-go/v7"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var (
@@ -19,12 +22,21 @@ var (
 type FileStorageRepository interface {
 	Save(ctx context.Context, in *dto.SaveFile) error
 	GetFile(ctx context.Context, filePath string) (io.Reader, error)
+	GetFileRange(ctx context.Context, filePath string, offset int64, length int64) (io.Reader, error)
 	Delete(ctx context.Context, filePath string) error
 	DeleteAll(ctx context.Context, filePaths []string) error
+	Copy(ctx context.Context, srcPath string, dstPath string) error
+	List(ctx context.Context, prefix string, fn func(object *dto.StorageObjectInfo) error) error
 }
 
 type localStorageRepository struct {
 }
+
+// rangeReadCloser ограничивает чтение диапазоном, сохраняя возможность закрыть исходный файл
+type rangeReadCloser struct {
+	io.Reader
+	io.Closer
+}
 type s3StorageRepository struct {
 	minio      *minio.Client
 	bucketName string
@@ -79,6 +91,30 @@ func (r *localStorageRepository) GetFile(ctx context.Context, filePath string) (
 	return file, nil
 }
 
+// GetFileRange возвращает length байт файла начиная с offset. При length < 0 читается до конца файла
+func (r *localStorageRepository) GetFileRange(ctx context.Context, filePath string, offset int64, length int64) (io.Reader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrFileNotFoundInFilesystem
+		}
+		return nil, err
+	}
+
+	if offset > 0 {
+		_, err = file.Seek(offset, io.SeekStart)
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+
+	if length < 0 {
+		return file, nil
+	}
+	return &rangeReadCloser{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
 func (r *localStorageRepository) Delete(ctx context.Context, filePath string) error {
 	_, err := os.Stat(filePath)
 	if err != nil {
@@ -104,6 +140,50 @@ func (r *localStorageRepository) DeleteAll(ctx context.Context, filePaths []stri
 	return nil
 }
 
+// Copy копирует файл внутри хранилища без чтения содержимого в приложение
+func (r *localStorageRepository) Copy(ctx context.Context, srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrFileNotFoundInFilesystem
+		}
+		return err
+	}
+	defer func(src *os.File) {
+		_ = src.Close()
+	}(src)
+
+	return r.Save(ctx, &dto.SaveFile{File: src, SavePath: dstPath})
+}
+
+// List обходит файлы в каталоге prefix и его подкаталогах. Отсутствующий каталог считается пустым
+func (r *localStorageRepository) List(ctx context.Context, prefix string, fn func(object *dto.StorageObjectInfo) error) error {
+	err := filepath.WalkDir(prefix, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return ctx.Err()
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		return fn(&dto.StorageObjectInfo{Key: path, Size: info.Size(), ModTime: info.ModTime()})
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
 func (r *s3StorageRepository) Save(ctx context.Context, in *dto.SaveFile) error {
 	_, err := r.minio.PutObject(
 		ctx,
@@ -137,6 +217,40 @@ func (r *s3StorageRepository) GetFile(ctx context.Context, filePath string) (io.
 	return object, nil
 }
 
+// GetFileRange возвращает length байт объекта начиная с offset. При length < 0 читается до конца объекта
+func (r *s3StorageRepository) GetFileRange(ctx context.Context, filePath string, offset int64, length int64) (io.Reader, error) {
+	if length == 0 {
+		return bytes.NewReader(nil), nil
+	}
+
+	opts := minio.GetObjectOptions{}
+	if offset > 0 || length > 0 {
+		end := int64(0)
+		if length > 0 {
+			end = offset + length - 1
+		}
+		err := opts.SetRange(offset, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	object, err := r.minio.GetObject(ctx, r.bucketName, filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = object.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrFileNotFoundInFilesystem
+		}
+		return nil, err
+	}
+
+	return object, nil
+}
+
 func (r *s3StorageRepository) Delete(ctx context.Context, filePath string) error {
 	err := r.minio.RemoveObject(ctx, r.bucketName, filePath, minio.RemoveObjectOptions{})
 	if err != nil {
@@ -166,3 +280,40 @@ func (r *s3StorageRepository) DeleteAll(ctx context.Context, filePaths []string)
 
 	return nil
 }
+
+// Copy копирует объект на стороне S3 (CopyObject), данные не проходят через приложение
+func (r *s3StorageRepository) Copy(ctx context.Context, srcPath string, dstPath string) error {
+	_, err := r.minio.CopyObject(
+		ctx,
+		minio.CopyDestOptions{Bucket: r.bucketName, Object: dstPath},
+		minio.CopySrcOptions{Bucket: r.bucketName, Object: srcPath},
+	)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return ErrFileNotFoundInFilesystem
+		}
+		logging.GetLogger(ctx).Error(err)
+		return err
+	}
+	return nil
+}
+
+// List обходит объекты, ключи которых находятся "внутри" prefix как каталога
+func (r *s3StorageRepository) List(ctx context.Context, prefix string, fn func(object *dto.StorageObjectInfo) error) error {
+	// отмена контекста останавливает обход, если fn вернула ошибку
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	listPrefix := strings.TrimSuffix(prefix, "/") + "/"
+	objects := r.minio.ListObjects(ctx, r.bucketName, minio.ListObjectsOptions{Prefix: listPrefix, Recursive: true})
+
+	for object := range objects {
+		if object.Err != nil {
+			return object.Err
+		}
+		if err := fn(&dto.StorageObjectInfo{Key: object.Key, Size: object.Size, ModTime: object.LastModified}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ type NoteRepository interface {
 	UnPin(ctx context.Context, noteID int) error
 	BelongsToUser(ctx context.Context, noteID int, userID int) (bool, error)
 	GetByShareHash(ctx context.Context, hash string) (*entity.Note, error)
+	GetStale(ctx context.Context, afterID int, limit int) ([]*entity.NoteWithOwner, error)
+	ReplaceEncrypted(ctx context.Context, in *entity.Note, previousDataKeyID *int) (bool, error)
 }
 
 type noteRepository struct {
@@ -28,9 +30,24 @@ func NewNoteRepository(db *pgxpool.Pool) NoteRepository {
 }
 
 func (ur *noteRepository) Create(ctx context.Context, in entity.Note) (*entity.Note, error) {
-	query := `INSERT INTO notes (category_id, note_blocks, created_at, updated_at, title, pinned) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	query := `
+		INSERT INTO notes (category_id, note_blocks, created_at, updated_at, title, pinned, encrypted_blocks, encrypted_title, data_key_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id
+	`
 
-	row := ur.db.QueryRow(ctx, query, in.CategoryID, in.NoteBlocks, in.CreatedAt, in.UpdatedAt, in.Title, in.Pinned)
+	row := ur.db.QueryRow(
+		ctx,
+		query,
+		in.CategoryID,
+		in.NoteBlocks,
+		in.CreatedAt,
+		in.UpdatedAt,
+		in.Title,
+		in.Pinned,
+		in.EncryptedBlocks,
+		in.EncryptedTitle,
+		in.DataKeyID,
+	)
 
 	if err := row.Scan(&in.ID); err != nil {
 		return nil, err
@@ -39,9 +56,26 @@ func (ur *noteRepository) Create(ctx context.Context, in entity.Note) (*entity.N
 }
 
 func (ur *noteRepository) Update(ctx context.Context, in *entity.Note) error {
-	query := `UPDATE notes SET category_id = $2, note_blocks = $3, updated_at = $4, title = $5, pinned = $6 WHERE id = $1`
+	query := `
+		UPDATE notes
+		SET category_id = $2, note_blocks = $3, updated_at = $4, title = $5, pinned = $6,
+			encrypted_blocks = $7, encrypted_title = $8, data_key_id = $9
+		WHERE id = $1
+	`
 
-	_, err := ur.db.Exec(ctx, query, in.ID, in.CategoryID, in.NoteBlocks, in.UpdatedAt, in.Title, in.Pinned)
+	_, err := ur.db.Exec(
+		ctx,
+		query,
+		in.ID,
+		in.CategoryID,
+		in.NoteBlocks,
+		in.UpdatedAt,
+		in.Title,
+		in.Pinned,
+		in.EncryptedBlocks,
+		in.EncryptedTitle,
+		in.DataKeyID,
+	)
 	if err != nil {
 		return err
 	}
@@ -52,7 +86,7 @@ func (ur *noteRepository) GetById(ctx context.Context, ID int) (*entity.Note, er
 	query := `select * from notes where id = $1`
 	row := ur.db.QueryRow(ctx, query, ID)
 	var note entity.Note
-	if err := row.Scan(&note.ID, &note.CategoryID, &note.NoteBlocks, &note.CreatedAt, &note.UpdatedAt, &note.Title, &note.Pinned); err != nil {
+	if err := row.Scan(noteFields(&note)...); err != nil {
 		return nil, err
 	}
 	return &note, nil
@@ -67,7 +101,9 @@ func (ur *noteRepository) GetMinimalByCategoryIds(ctx context.Context, catIDs []
 		    n.updated_at, 
 		    n.title, 
 		    n.pinned,
-		    (SELECT EXISTS(SELECT 1 FROM note_share_hashes WHERE note_id = n.id)) as shared
+		    (SELECT EXISTS(SELECT 1 FROM note_share_hashes WHERE note_id = n.id)) as shared,
+		    n.encrypted_title,
+		    n.data_key_id
 		from notes n 
 		where n.category_id = ANY($1)
 	`
@@ -81,7 +117,18 @@ func (ur *noteRepository) GetMinimalByCategoryIds(ctx context.Context, catIDs []
 	notes := make([]*entity.NoteMinimal, 0)
 	for rows.Next() {
 		note := &entity.NoteMinimal{}
-		if err := rows.Scan(&note.ID, &note.CategoryID, &note.CreatedAt, &note.UpdatedAt, &note.Title, &note.Pinned, &note.Shared); err != nil {
+		err := rows.Scan(
+			&note.ID,
+			&note.CategoryID,
+			&note.CreatedAt,
+			&note.UpdatedAt,
+			&note.Title,
+			&note.Pinned,
+			&note.Shared,
+			&note.EncryptedTitle,
+			&note.DataKeyID,
+		)
+		if err != nil {
 			return nil, err
 		}
 		notes = append(notes, note)
@@ -159,8 +206,85 @@ func (ur *noteRepository) GetByShareHash(ctx context.Context, hash string) (*ent
 	query := `select * from notes where id = (select nsh.note_id from note_share_hashes nsh where nsh.hash = $1)`
 	row := ur.db.QueryRow(ctx, query, hash)
 	var note entity.Note
-	if err := row.Scan(&note.ID, &note.CategoryID, &note.NoteBlocks, &note.CreatedAt, &note.UpdatedAt, &note.Title, &note.Pinned); err != nil {
+	if err := row.Scan(noteFields(&note)...); err != nil {
 		return nil, err
 	}
 	return &note, nil
 }
+
+// GetStale возвращает до limit заметок с id больше afterID, которые не зашифрованы активным ключом
+// данных владельца: незашифрованные и зашифрованные прежним ключом
+func (ur *noteRepository) GetStale(ctx context.Context, afterID int, limit int) ([]*entity.NoteWithOwner, error) {
+	query := `
+		SELECT n.*, nc.user_id
+		FROM notes n
+		JOIN note_categories nc ON nc.id = n.category_id
+		LEFT JOIN drive_data_keys k ON k.user_id = nc.user_id AND k.is_active
+		WHERE n.id > $1 AND (k.id IS NULL OR n.data_key_id IS DISTINCT FROM k.id)
+		ORDER BY n.id
+		LIMIT $2
+	`
+
+	rows, err := ur.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make([]*entity.NoteWithOwner, 0, limit)
+	for rows.Next() {
+		note := &entity.NoteWithOwner{}
+		if err := rows.Scan(append(noteFields(&note.Note), &note.UserID)...); err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// ReplaceEncrypted сохраняет перешифрованное содержимое заметки, если заметка не изменилась после
+// чтения: не обновлялась и зашифрована ключом previousDataKeyID. Возвращает false, если изменилась
+func (ur *noteRepository) ReplaceEncrypted(ctx context.Context, in *entity.Note, previousDataKeyID *int) (bool, error) {
+	query := `
+		UPDATE notes
+		SET note_blocks = $2, title = $3, encrypted_blocks = $4, encrypted_title = $5, data_key_id = $6
+		WHERE id = $1 AND updated_at = $7 AND data_key_id IS NOT DISTINCT FROM $8
+	`
+
+	tag, err := ur.db.Exec(
+		ctx,
+		query,
+		in.ID,
+		in.NoteBlocks,
+		in.Title,
+		in.EncryptedBlocks,
+		in.EncryptedTitle,
+		in.DataKeyID,
+		in.UpdatedAt,
+		previousDataKeyID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// noteFields - поля заметки в порядке колонок таблицы notes
+func noteFields(note *entity.Note) []any {
+	return []any{
+		&note.ID,
+		&note.CategoryID,
+		&note.NoteBlocks,
+		&note.CreatedAt,
+		&note.UpdatedAt,
+		&note.Title,
+		&note.Pinned,
+		&note.EncryptedBlocks,
+		&note.EncryptedTitle,
+		&note.DataKeyID,
+	}
+}
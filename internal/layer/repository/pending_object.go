@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"context"
+	"time"
+)
+
+type PendingObjectRepository interface {
+	Create(ctx context.Context, storageKey string, createdAt time.Time) error
+	Delete(ctx context.Context, storageKeys ...string) error
+	GetOlder(ctx context.Context, before time.Time, limit int) ([]*entity.PendingObject, error)
+}
+
+type pendingObjectRepository struct {
+	db DBExecutor
+}
+
+func NewPendingObjectRepository(db DBExecutor) PendingObjectRepository {
+	return &pendingObjectRepository{db: db}
+}
+
+func (r *pendingObjectRepository) Create(ctx context.Context, storageKey string, createdAt time.Time) error {
+	query := `INSERT INTO storage_pending_objects (storage_key, created_at) VALUES ($1, $2)`
+
+	_, err := r.db.Exec(ctx, query, storageKey, createdAt)
+	return err
+}
+
+func (r *pendingObjectRepository) Delete(ctx context.Context, storageKeys ...string) error {
+	if len(storageKeys) == 0 {
+		return nil
+	}
+	query := `DELETE FROM storage_pending_objects WHERE storage_key = ANY($1)`
+
+	_, err := r.db.Exec(ctx, query, storageKeys)
+	return err
+}
+
+// GetOlder возвращает записи, начатые раньше before, начиная с самых старых
+func (r *pendingObjectRepository) GetOlder(ctx context.Context, before time.Time, limit int) ([]*entity.PendingObject, error) {
+	query := `SELECT * FROM storage_pending_objects WHERE created_at < $1 ORDER BY created_at LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]*entity.PendingObject, 0)
+	for rows.Next() {
+		var object entity.PendingObject
+		if err := rows.Scan(&object.StorageKey, &object.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, &object)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
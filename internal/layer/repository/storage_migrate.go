@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"context"
+	"time"
+)
+
+// Таблицы, записи которых ссылаются на объекты хранилища
+const (
+	StorageObjectDriveBlobs  = "drive_blobs"
+	StorageObjectDriveFiles  = "drive_files"
+	StorageObjectDriveChunks = "drive_file_chunks"
+	StorageObjectFiles       = "files"
+)
+
+// StorageObjectKinds - порядок обхода таблиц при переносе хранилища
+var StorageObjectKinds = []string{
+	StorageObjectDriveBlobs,
+	StorageObjectDriveFiles,
+	StorageObjectDriveChunks,
+	StorageObjectFiles,
+}
+
+// storageObjectQueries выбирают объекты таблицы с id больше $1. Файлы диска с блобом
+// ссылаются на объект блоба, а чанковые файлы - на объекты чанков, поэтому они не выбираются
+var storageObjectQueries = map[string]string{
+	StorageObjectDriveBlobs:  `SELECT id, path, size, plain_size, sha256, data_key_id FROM drive_blobs WHERE id > $1`,
+	StorageObjectDriveFiles:  `SELECT id, path, size, plain_size, sha256, data_key_id FROM drive_files WHERE id > $1 AND blob_id IS NULL AND NOT is_chunk AND path IS NOT NULL`,
+	StorageObjectDriveChunks: `SELECT id, path, size, plain_size, NULL::text, data_key_id FROM drive_file_chunks WHERE id > $1`,
+	StorageObjectFiles:       `SELECT id, file_path, size, plain_size, NULL::text, data_key_id FROM files WHERE id > $1`,
+}
+
+type StorageMigrateRepository interface {
+	GetObjects(ctx context.Context, kind string, afterID int, limit int) ([]*entity.StorageObject, error)
+	CountObjects(ctx context.Context, kind string, afterID int) (int, error)
+	GetProgress(ctx context.Context, source string, target string) (map[string]int, error)
+	SaveProgress(ctx context.Context, source string, target string, kind string, lastID int, at time.Time) error
+	ResetProgress(ctx context.Context, source string, target string) error
+}
+
+type storageMigrateRepository struct {
+	db DBExecutor
+}
+
+func NewStorageMigrateRepository(db DBExecutor) StorageMigrateRepository {
+	return &storageMigrateRepository{db: db}
+}
+
+// GetObjects возвращает до limit объектов таблицы kind с id больше afterID в порядке возрастания id
+func (r *storageMigrateRepository) GetObjects(ctx context.Context, kind string, afterID int, limit int) ([]*entity.StorageObject, error) {
+	query := storageObjectQueries[kind] + ` ORDER BY id LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := make([]*entity.StorageObject, 0, limit)
+	for rows.Next() {
+		object := &entity.StorageObject{Kind: kind}
+		if err := rows.Scan(&object.ID, &object.Path, &object.Size, &object.PlainSize, &object.SHA256, &object.DataKeyID); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (r *storageMigrateRepository) CountObjects(ctx context.Context, kind string, afterID int) (int, error) {
+	query := `SELECT count(*) FROM (` + storageObjectQueries[kind] + `) o`
+
+	var result int
+	err := r.db.QueryRow(ctx, query, afterID).Scan(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// GetProgress возвращает id последнего перенесенного объекта по каждой таблице
+func (r *storageMigrateRepository) GetProgress(ctx context.Context, source string, target string) (map[string]int, error) {
+	query := `SELECT kind, last_id FROM storage_migration_progress WHERE source = $1 AND target = $2`
+
+	rows, err := r.db.Query(ctx, query, source, target)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := make(map[string]int)
+	for rows.Next() {
+		var (
+			kind   string
+			lastID int
+		)
+		if err := rows.Scan(&kind, &lastID); err != nil {
+			return nil, err
+		}
+		progress[kind] = lastID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func (r *storageMigrateRepository) SaveProgress(
+	ctx context.Context,
+	source string,
+	target string,
+	kind string,
+	lastID int,
+	at time.Time,
+) error {
+	query := `
+		INSERT INTO storage_migration_progress (source, target, kind, last_id, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (source, target, kind) DO UPDATE SET last_id = excluded.last_id, updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.Exec(ctx, query, source, target, kind, lastID, at)
+	return err
+}
+
+func (r *storageMigrateRepository) ResetProgress(ctx context.Context, source string, target string) error {
+	query := `DELETE FROM storage_migration_progress WHERE source = $1 AND target = $2`
+
+	_, err := r.db.Exec(ctx, query, source, target)
+	return err
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"context"
+	"github.com/jackc/pgx/v5"
+)
+
+type StorageQuotaRepository interface {
+	SavePlan(ctx context.Context, in *entity.StoragePlan) (*entity.StoragePlan, error)
+	GetPlanByName(ctx context.Context, name string) (*entity.StoragePlan, error)
+	GetPlans(ctx context.Context) ([]*entity.StoragePlan, error)
+	GetUserQuota(ctx context.Context, userID int) (*entity.UserStorageQuota, error)
+	SaveUserQuota(ctx context.Context, in *entity.UserStorageQuota) error
+	GetLimits(ctx context.Context, userID int) (*entity.StorageLimits, error)
+}
+
+type storageQuotaRepository struct {
+	db DBExecutor
+}
+
+func NewStorageQuotaRepository(db DBExecutor) StorageQuotaRepository {
+	return &storageQuotaRepository{db: db}
+}
+
+// SavePlan создает тариф или обновляет лимиты тарифа с тем же названием
+func (r *storageQuotaRepository) SavePlan(ctx context.Context, in *entity.StoragePlan) (*entity.StoragePlan, error) {
+	query := `
+		INSERT INTO storage_plans (name, drive_limit, files_limit, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (name) DO UPDATE SET drive_limit = excluded.drive_limit, files_limit = excluded.files_limit, updated_at = excluded.updated_at
+		RETURNING id, created_at
+	`
+
+	row := r.db.QueryRow(ctx, query, in.Name, in.DriveLimit, in.FilesLimit, in.UpdatedAt)
+	if err := row.Scan(&in.ID, &in.CreatedAt); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (r *storageQuotaRepository) GetPlanByName(ctx context.Context, name string) (*entity.StoragePlan, error) {
+	query := `SELECT * FROM storage_plans WHERE name = $1`
+
+	return r.scanPlan(r.db.QueryRow(ctx, query, name))
+}
+
+func (r *storageQuotaRepository) GetPlans(ctx context.Context) ([]*entity.StoragePlan, error) {
+	query := `SELECT * FROM storage_plans ORDER BY name`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := make([]*entity.StoragePlan, 0)
+	for rows.Next() {
+		plan, err := r.scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (r *storageQuotaRepository) GetUserQuota(ctx context.Context, userID int) (*entity.UserStorageQuota, error) {
+	query := `SELECT * FROM user_storage_quotas WHERE user_id = $1`
+
+	var quota entity.UserStorageQuota
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&quota.UserID,
+		&quota.PlanID,
+		&quota.DriveLimit,
+		&quota.FilesLimit,
+		&quota.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+func (r *storageQuotaRepository) SaveUserQuota(ctx context.Context, in *entity.UserStorageQuota) error {
+	query := `
+		INSERT INTO user_storage_quotas (user_id, plan_id, drive_limit, files_limit, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE 
+		SET plan_id = excluded.plan_id, drive_limit = excluded.drive_limit, files_limit = excluded.files_limit, updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.Exec(ctx, query, in.UserID, in.PlanID, in.DriveLimit, in.FilesLimit, in.UpdatedAt)
+	return err
+}
+
+// GetLimits возвращает лимиты пользователя: персональное значение важнее лимита тарифа
+func (r *storageQuotaRepository) GetLimits(ctx context.Context, userID int) (*entity.StorageLimits, error) {
+	query := `
+		SELECT p.name, coalesce(q.drive_limit, p.drive_limit), coalesce(q.files_limit, p.files_limit)
+		FROM user_storage_quotas q
+		LEFT JOIN storage_plans p ON p.id = q.plan_id
+		WHERE q.user_id = $1
+	`
+
+	var limits entity.StorageLimits
+	err := r.db.QueryRow(ctx, query, userID).Scan(&limits.PlanName, &limits.DriveLimit, &limits.FilesLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+func (r *storageQuotaRepository) scanPlan(row pgx.Row) (*entity.StoragePlan, error) {
+	var plan entity.StoragePlan
+	if err := row.Scan(
+		&plan.ID,
+		&plan.Name,
+		&plan.DriveLimit,
+		&plan.FilesLimit,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
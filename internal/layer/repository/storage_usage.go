@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"context"
+)
+
+type StorageUsageRepository interface {
+	Get(ctx context.Context, userID int) (*entity.StorageUsage, error)
+	Lock(ctx context.Context, userID int) (*entity.StorageUsage, error)
+	Add(ctx context.Context, userID int, driveDelta int64, filesDelta int64) error
+	Recalculate(ctx context.Context, userID *int) (int, error)
+}
+
+type storageUsageRepository struct {
+	db DBExecutor
+}
+
+func NewStorageUsageRepository(db DBExecutor) StorageUsageRepository {
+	return &storageUsageRepository{db: db}
+}
+
+func (r *storageUsageRepository) Get(ctx context.Context, userID int) (*entity.StorageUsage, error) {
+	query := `SELECT * FROM user_storage_usage WHERE user_id = $1`
+
+	return r.scanUsage(ctx, query, userID)
+}
+
+// Lock блокирует счетчики пользователя до конца транзакции, при отсутствии строки она создается с нулями
+func (r *storageUsageRepository) Lock(ctx context.Context, userID int) (*entity.StorageUsage, error) {
+	query := `
+		INSERT INTO user_storage_usage (user_id) VALUES ($1)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID); err != nil {
+		return nil, err
+	}
+	return r.scanUsage(ctx, `SELECT * FROM user_storage_usage WHERE user_id = $1 FOR UPDATE`, userID)
+}
+
+// Add изменяет счетчики на переданные величины. Отрицательный результат обрезается до нуля
+func (r *storageUsageRepository) Add(ctx context.Context, userID int, driveDelta int64, filesDelta int64) error {
+	query := `
+		INSERT INTO user_storage_usage AS u (user_id, drive_used, files_used)
+		VALUES ($1, greatest($2::bigint, 0), greatest($3::bigint, 0))
+		ON CONFLICT (user_id) DO UPDATE
+		SET drive_used = greatest(u.drive_used + $2, 0), files_used = greatest(u.files_used + $3, 0)
+	`
+
+	_, err := r.db.Exec(ctx, query, userID, driveDelta, filesDelta)
+	return err
+}
+
+// Recalculate пересчитывает счетчики по фактическим данным для пользователя userID, а при nil - для всех.
+// Подсчет выполняет функция storage_usage_totals, ею же заполнена таблица при миграции.
+// Возвращает количество пересчитанных пользователей
+func (r *storageUsageRepository) Recalculate(ctx context.Context, userID *int) (int, error) {
+	query := `
+		INSERT INTO user_storage_usage (user_id, drive_used, files_used)
+		SELECT * FROM storage_usage_totals($1::int)
+		ON CONFLICT (user_id) DO UPDATE
+		SET drive_used = excluded.drive_used, files_used = excluded.files_used
+	`
+
+	tag, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *storageUsageRepository) scanUsage(ctx context.Context, query string, userID int) (*entity.StorageUsage, error) {
+	var usage entity.StorageUsage
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&usage.UserID,
+		&usage.DriveUsed,
+		&usage.FilesUsed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
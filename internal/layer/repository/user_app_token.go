@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+type UserAppTokenRepository interface {
+	Create(ctx context.Context, in *entity.UserAppToken) (*entity.UserAppToken, error)
+	GetByUserID(ctx context.Context, userID int) ([]*entity.UserAppToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*entity.UserAppToken, error)
+	DeleteByID(ctx context.Context, ID int, userID int) (bool, error)
+	Touch(ctx context.Context, ID int, usedAt time.Time) error
+}
+
+type userAppTokenRepository struct {
+	db DBExecutor
+}
+
+func NewUserAppTokenRepository(db DBExecutor) UserAppTokenRepository {
+	return &userAppTokenRepository{db: db}
+}
+
+func (r *userAppTokenRepository) Create(ctx context.Context, in *entity.UserAppToken) (*entity.UserAppToken, error) {
+	query := `
+		INSERT INTO user_app_tokens (user_id, name, token_hash, created_at)
+		VALUES ($1, $2, $3, $4) RETURNING id
+	`
+
+	row := r.db.QueryRow(ctx, query, in.UserID, in.Name, in.TokenHash, in.CreatedAt)
+	if err := row.Scan(&in.ID); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (r *userAppTokenRepository) GetByUserID(ctx context.Context, userID int) ([]*entity.UserAppToken, error) {
+	query := `SELECT * FROM user_app_tokens WHERE user_id = $1 ORDER BY id DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]*entity.UserAppToken, 0)
+	for rows.Next() {
+		token, err := r.scanOne(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *userAppTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entity.UserAppToken, error) {
+	query := `SELECT * FROM user_app_tokens WHERE token_hash = $1`
+
+	return r.scanOne(r.db.QueryRow(ctx, query, tokenHash))
+}
+
+func (r *userAppTokenRepository) DeleteByID(ctx context.Context, ID int, userID int) (bool, error) {
+	query := `DELETE FROM user_app_tokens WHERE id = $1 AND user_id = $2`
+
+	tag, err := r.db.Exec(ctx, query, ID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Touch запоминает время последнего использования токена
+func (r *userAppTokenRepository) Touch(ctx context.Context, ID int, usedAt time.Time) error {
+	query := `UPDATE user_app_tokens SET last_used_at = $2 WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, ID, usedAt)
+	return err
+}
+
+func (r *userAppTokenRepository) scanOne(row pgx.Row) (*entity.UserAppToken, error) {
+	var token entity.UserAppToken
+	if err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.CreatedAt,
+		&token.LastUsedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -2,6 +2,7 @@ package service
 
 type File interface {
 	FileService() FileService
+	ThumbnailService() ThumbnailService
 }
 
 type file struct{}
@@ -13,3 +14,7 @@ func NewFile() File {
 func (ps *file) FileService() FileService {
 	return &fileService{}
 }
+
+func (ps *file) ThumbnailService() ThumbnailService {
+	return &thumbnailService{}
+}
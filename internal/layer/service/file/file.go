@@ -1,7 +1,6 @@
 package service
 
 import (
-	"assistant-go/internal/layer/dto"
 	"assistant-go/pkg/utils"
 	"bytes"
 	"crypto/aes"
@@ -10,18 +9,33 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"path/filepath"
 	"time"
 )
 
 type FileService interface {
 	GetMiddlePathByFileId(fileId int) string
 	GenerateNewFileName(fileExt string) (string, error)
+	GenerateNewFilePath(fileExt string) (string, error)
 	GenerateFileHash() (string, error)
-	EncryptFile(file multipart.File, encryptionKey string) (multipart.File, error)
+	EncryptStream(file io.Reader, encryptionKey string) (io.Reader, error)
+	DecryptStream(file io.Reader, encryptionKey string) (io.Reader, error)
+	DecryptStreamRange(open RangeOpener, encryptionKey string, offset int64, length int64) (io.Reader, error)
+	EncryptStreamWithKey(file io.Reader, key []byte) (io.Reader, error)
+	DecryptStreamWithKey(file io.Reader, key []byte) (io.Reader, error)
+	DecryptStreamRangeWithKey(open RangeOpener, key []byte, offset int64, length int64) (io.Reader, error)
+	EncryptedSize(plainSize int64) int64
 	DecryptFile(file io.Reader, encryptionKey string) (io.Reader, error)
+	DecryptedSize(encryptedSize int64) int64
+	DetectMimeType(head []byte, ext string) string
+	IsActiveMimeType(mimeType string) bool
 }
 
+const (
+	gcmNonceSize = 12
+	gcmTagSize   = 16
+)
+
 type fileService struct{}
 
 func (s *fileService) GetMiddlePathByFileId(fileId int) string {
@@ -39,55 +53,42 @@ func (s *fileService) GenerateNewFileName(fileExt string) (string, error) {
 	return fmt.Sprintf("%d_%s.%s", time.Now().UnixNano(), hashForNewName, fileExt), nil
 }
 
-func (s *fileService) GenerateFileHash() (string, error) {
-	stringUtils := utils.NewStringUtils()
-	fileHash, err := stringUtils.GenerateRandomString(80)
+// GenerateNewFilePath возвращает путь нового объекта относительно каталога сохранения. Каталоги
+// выбираются случайно, поэтому путь не зависит от ID записи, которая будет создана позже
+func (s *fileService) GenerateNewFilePath(fileExt string) (string, error) {
+	newFilename, err := s.GenerateNewFileName(fileExt)
 	if err != nil {
 		return "", err
 	}
-	return fileHash, nil
-}
 
-func (s *fileService) EncryptFile(file multipart.File, encryptionKey string) (multipart.File, error) {
-	plaintext, err := io.ReadAll(file)
-	if err != nil {
-		return nil, err
-	}
-
-	block, err := aes.NewCipher(s.deriveAESKeyFromEnv(encryptionKey))
-	if err != nil {
-		return nil, err
+	var levels [2]byte
+	if _, err = rand.Read(levels[:]); err != nil {
+		return "", err
 	}
+	return filepath.Join(fmt.Sprintf("%02x", levels[0]), fmt.Sprintf("%02x", levels[1]), newFilename), nil
+}
 
-	aesgcm, err := cipher.NewGCM(block)
+func (s *fileService) GenerateFileHash() (string, error) {
+	stringUtils := utils.NewStringUtils()
+	fileHash, err := stringUtils.GenerateRandomString(80)
 	if err != nil {
-		return nil, err
-	}
-
-	// Never use more than 2^32 random nonces with a given key because of the risk of a repeat.
-	nonce := make([]byte, aesgcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
+		return "", err
 	}
-
-	ciphertext := aesgcm.Seal(nil, nonce, plaintext, nil)
-
-	buf := make([]byte, 0, len(nonce)+len(ciphertext))
-	buf = append(buf, nonce...)
-	buf = append(buf, ciphertext...)
-
-	return &dto.MemoryMultipartFile{
-		Reader: bytes.NewReader(buf),
-	}, nil
+	return fileHash, nil
 }
 
+// DecryptFile расшифровывает файл старого формата (v0), зашифрованный целиком одним блоком GCM
 func (s *fileService) DecryptFile(file io.Reader, encryptionKey string) (io.Reader, error) {
+	return s.decryptLegacy(file, s.deriveAESKeyFromEnv(encryptionKey))
+}
+
+func (s *fileService) decryptLegacy(file io.Reader, key []byte) (io.Reader, error) {
 	data, err := io.ReadAll(file)
 	if err != nil {
 		return nil, err
 	}
 
-	block, err := aes.NewCipher(s.deriveAESKeyFromEnv(encryptionKey))
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -113,6 +114,15 @@ func (s *fileService) DecryptFile(file io.Reader, encryptionKey string) (io.Read
 	return bytes.NewReader(plaintext), nil
 }
 
+// DecryptedSize вычисляет размер исходного файла старого формата (v0) по размеру зашифрованного: nonce + ciphertext + tag
+func (s *fileService) DecryptedSize(encryptedSize int64) int64 {
+	size := encryptedSize - gcmNonceSize - gcmTagSize
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
 func (s *fileService) deriveAESKeyFromEnv(envKey string) []byte {
 	hash := sha256.Sum256([]byte(envKey))
 	return hash[:]
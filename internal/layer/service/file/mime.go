@@ -0,0 +1,63 @@
+package service
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MimeSniffLen - сколько первых байт содержимого нужно для определения MIME-типа
+const MimeSniffLen = 512
+
+// DetectMimeType определяет MIME-тип по первым байтам содержимого и расширению файла.
+// Тип по расширению уточняет сигнатуру, только если ей не противоречит: docx распознается как zip,
+// json и svg - как текст. Без содержимого (head пустой) тип определяется только по расширению
+func (s *fileService) DetectMimeType(head []byte, ext string) string {
+	byExt := mime.TypeByExtension("." + strings.TrimPrefix(strings.ToLower(ext), "."))
+	if len(head) == 0 {
+		if byExt == "" {
+			return "application/octet-stream"
+		}
+		return byExt
+	}
+
+	sniffed := http.DetectContentType(head)
+	if byExt == "" {
+		return sniffed
+	}
+
+	sniffedBase, extBase := mimeBase(sniffed), mimeBase(byExt)
+	switch {
+	case sniffedBase == extBase:
+		return sniffed
+	case sniffedBase == "application/octet-stream":
+		return byExt
+	case sniffedBase == "application/zip" && strings.HasPrefix(extBase, "application/"):
+		return byExt
+	case (sniffedBase == "text/plain" || sniffedBase == "text/xml") && isTextMimeType(extBase):
+		return byExt
+	}
+	return sniffed
+}
+
+// IsActiveMimeType сообщает, может ли браузер исполнить скрипты из содержимого такого типа
+func (s *fileService) IsActiveMimeType(mimeType string) bool {
+	switch mimeBase(mimeType) {
+	case "text/html", "application/xhtml+xml", "image/svg+xml", "text/xml", "application/xml":
+		return true
+	}
+	return false
+}
+
+func isTextMimeType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "text/") ||
+		strings.HasSuffix(mimeType, "+xml") ||
+		strings.HasSuffix(mimeType, "/xml") ||
+		strings.HasSuffix(mimeType, "/json") ||
+		strings.HasSuffix(mimeType, "/javascript")
+}
+
+func mimeBase(mimeType string) string {
+	base, _, _ := strings.Cut(mimeType, ";")
+	return strings.TrimSpace(strings.ToLower(base))
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Формат потокового шифрования (версия 1):
+//
+//	header:  magic "ASTENC" | version (1 байт) | размер сегмента (uint32 BE) | префикс nonce (7 байт)
+//	body:    сегменты AES-GCM, каждый - до streamSegmentSize байт открытого текста + 16 байт тега
+//
+// nonce сегмента = префикс (7) | номер сегмента (uint32 BE) | флаг последнего сегмента (1).
+// Заголовок передается как additional data в каждый сегмент, поэтому его подмена обнаруживается.
+// Файлы старого формата (nonce + весь файл одним блоком GCM) определяются по отсутствию magic.
+const (
+	streamVersion1       = 1
+	streamSegmentSize    = 64 << 10
+	streamNoncePrefixLen = 7
+	streamHeaderSize     = len(streamMagic) + 1 + 4 + streamNoncePrefixLen
+)
+
+const streamMagic = "ASTENC"
+
+var (
+	ErrStreamCorrupted    = errors.New("encrypted stream is corrupted")
+	ErrStreamTooLarge     = errors.New("encrypted stream is too large")
+	ErrStreamInvalidRange = errors.New("invalid range of encrypted stream")
+)
+
+type streamHeader struct {
+	raw         []byte
+	segmentSize int
+	noncePrefix []byte
+}
+
+// RangeOpener открывает length байт хранимого (зашифрованного) файла начиная с offset, length < 0 - до конца
+type RangeOpener func(offset int64, length int64) (io.Reader, error)
+
+func (s *fileService) EncryptStream(file io.Reader, encryptionKey string) (io.Reader, error) {
+	return s.EncryptStreamWithKey(file, s.deriveAESKeyFromEnv(encryptionKey))
+}
+
+func (s *fileService) DecryptStream(file io.Reader, encryptionKey string) (io.Reader, error) {
+	return s.DecryptStreamWithKey(file, s.deriveAESKeyFromEnv(encryptionKey))
+}
+
+func (s *fileService) DecryptStreamRange(
+	open RangeOpener,
+	encryptionKey string,
+	offset int64,
+	length int64,
+) (io.Reader, error) {
+	return s.DecryptStreamRangeWithKey(open, s.deriveAESKeyFromEnv(encryptionKey), offset, length)
+}
+
+// EncryptStreamWithKey шифрует поток готовым 256-битным ключом (например, ключом данных пользователя)
+func (s *fileService) EncryptStreamWithKey(file io.Reader, key []byte) (io.Reader, error) {
+	aead, err := s.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, streamHeaderSize)
+	copy(header, streamMagic)
+	header[len(streamMagic)] = streamVersion1
+	binary.BigEndian.PutUint32(header[len(streamMagic)+1:], streamSegmentSize)
+	if _, err := io.ReadFull(rand.Reader, header[len(streamMagic)+5:]); err != nil {
+		return nil, err
+	}
+
+	return &encryptReader{
+		src:    file,
+		aead:   aead,
+		header: parsedHeader(header),
+		buf:    make([]byte, streamSegmentSize+1),
+		out:    append([]byte{}, header...),
+	}, nil
+}
+
+func (s *fileService) DecryptStreamWithKey(file io.Reader, key []byte) (io.Reader, error) {
+	aead, err := s.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	headerBytes := make([]byte, streamHeaderSize)
+	n, err := io.ReadFull(file, headerBytes)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	header, ok := readStreamHeader(headerBytes[:n])
+	if !ok {
+		// файл зашифрован целиком старым способом
+		legacy, err := s.decryptLegacy(io.MultiReader(bytes.NewReader(headerBytes[:n]), file), key)
+		if err != nil {
+			return nil, err
+		}
+		return withCloser(legacy, file), nil
+	}
+
+	return newDecryptReader(file, aead, header, 0), nil
+}
+
+// DecryptStreamRangeWithKey расшифровывает length байт исходного файла начиная с offset,
+// читая из хранилища только сегменты, покрывающие запрошенный диапазон
+func (s *fileService) DecryptStreamRangeWithKey(
+	open RangeOpener,
+	key []byte,
+	offset int64,
+	length int64,
+) (io.Reader, error) {
+	if offset < 0 || length < 0 {
+		return nil, ErrStreamInvalidRange
+	}
+
+	aead, err := s.newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	headerReader, err := open(0, int64(streamHeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	headerBytes := make([]byte, streamHeaderSize)
+	n, err := io.ReadFull(headerReader, headerBytes)
+	closeReader(headerReader)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	header, ok := readStreamHeader(headerBytes[:n])
+	if !ok {
+		// старый формат нельзя читать частями: расшифровываем целиком и вырезаем диапазон
+		file, err := open(0, -1)
+		if err != nil {
+			return nil, err
+		}
+		defer closeReader(file)
+
+		legacy, err := s.decryptLegacy(file, key)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = io.CopyN(io.Discard, legacy, offset); err != nil {
+			return nil, ErrStreamInvalidRange
+		}
+		return io.LimitReader(legacy, length), nil
+	}
+
+	segmentSize := int64(header.segmentSize)
+	segmentIndex := offset / segmentSize
+	if segmentIndex > int64(^uint32(0)) {
+		return nil, ErrStreamTooLarge
+	}
+	cipherOffset := int64(streamHeaderSize) + segmentIndex*(segmentSize+int64(aead.Overhead()))
+
+	// читаем до конца файла: признак последнего сегмента определяется упреждающим чтением
+	file, err := open(cipherOffset, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := newDecryptReader(file, aead, header, uint32(segmentIndex))
+	if _, err = io.CopyN(io.Discard, decrypted, offset-segmentIndex*segmentSize); err != nil {
+		closeReader(file)
+		if errors.Is(err, io.EOF) {
+			return nil, ErrStreamInvalidRange
+		}
+		return nil, err
+	}
+
+	return withCloser(io.LimitReader(decrypted, length), file), nil
+}
+
+// EncryptedSize возвращает размер файла в хранилище после потокового шифрования
+func (s *fileService) EncryptedSize(plainSize int64) int64 {
+	segments := (plainSize + streamSegmentSize - 1) / streamSegmentSize
+	if segments == 0 {
+		segments = 1
+	}
+	return int64(streamHeaderSize) + plainSize + segments*gcmTagSize
+}
+
+func (s *fileService) newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func parsedHeader(raw []byte) *streamHeader {
+	return &streamHeader{
+		raw:         raw,
+		segmentSize: int(binary.BigEndian.Uint32(raw[len(streamMagic)+1:])),
+		noncePrefix: raw[len(streamMagic)+5:],
+	}
+}
+
+func readStreamHeader(raw []byte) (*streamHeader, bool) {
+	if len(raw) < streamHeaderSize {
+		return nil, false
+	}
+	if string(raw[:len(streamMagic)]) != streamMagic || raw[len(streamMagic)] != streamVersion1 {
+		return nil, false
+	}
+	header := parsedHeader(append([]byte{}, raw[:streamHeaderSize]...))
+	if header.segmentSize <= 0 || header.segmentSize > 16<<20 {
+		return nil, false
+	}
+	return header, true
+}
+
+func segmentNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, gcmNonceSize)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefixLen:], counter)
+	if last {
+		nonce[gcmNonceSize-1] = 1
+	}
+	return nonce
+}
+
+type encryptReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	header  *streamHeader
+	buf     []byte
+	carry   []byte
+	out     []byte
+	counter uint32
+	done    bool
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealNext(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encryptReader) sealNext() error {
+	segmentSize := r.header.segmentSize
+
+	n := copy(r.buf, r.carry)
+	m, err := io.ReadFull(r.src, r.buf[n:])
+	n += m
+
+	last := false
+	if err != nil {
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		last = true
+	}
+
+	segment := r.buf[:n]
+	r.carry = nil
+	if !last {
+		// прочитан лишний байт - он начинает следующий сегмент
+		r.carry = []byte{r.buf[segmentSize]}
+		segment = r.buf[:segmentSize]
+	}
+
+	nonce := segmentNonce(r.header.noncePrefix, r.counter, last)
+	r.out = r.aead.Seal(r.out[:0], nonce, segment, r.header.raw)
+
+	if last {
+		r.done = true
+		return nil
+	}
+	if r.counter == ^uint32(0) {
+		return ErrStreamTooLarge
+	}
+	r.counter++
+	return nil
+}
+
+func (r *encryptReader) Close() error {
+	return closeReader(r.src)
+}
+
+type decryptReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	header  *streamHeader
+	buf     []byte
+	carry   []byte
+	out     []byte
+	counter uint32
+	done    bool
+}
+
+func newDecryptReader(src io.Reader, aead cipher.AEAD, header *streamHeader, counter uint32) *decryptReader {
+	return &decryptReader{
+		src:     src,
+		aead:    aead,
+		header:  header,
+		buf:     make([]byte, header.segmentSize+aead.Overhead()+1),
+		counter: counter,
+	}
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.openNext(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) openNext() error {
+	encryptedSegmentSize := r.header.segmentSize + r.aead.Overhead()
+
+	n := copy(r.buf, r.carry)
+	m, err := io.ReadFull(r.src, r.buf[n:])
+	n += m
+
+	last := false
+	if err != nil {
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		last = true
+	}
+
+	segment := r.buf[:n]
+	r.carry = nil
+	if !last {
+		r.carry = []byte{r.buf[encryptedSegmentSize]}
+		segment = r.buf[:encryptedSegmentSize]
+	}
+	if len(segment) < r.aead.Overhead() {
+		return ErrStreamCorrupted
+	}
+
+	nonce := segmentNonce(r.header.noncePrefix, r.counter, last)
+	plain, err := r.aead.Open(r.out[:0], nonce, segment, r.header.raw)
+	if err != nil {
+		return ErrStreamCorrupted
+	}
+	r.out = plain
+
+	if last {
+		r.done = true
+		return nil
+	}
+	if r.counter == ^uint32(0) {
+		return ErrStreamTooLarge
+	}
+	r.counter++
+	return nil
+}
+
+func (r *decryptReader) Close() error {
+	return closeReader(r.src)
+}
+
+type readCloser struct {
+	io.Reader
+	closer io.Reader
+}
+
+func (r *readCloser) Close() error {
+	return closeReader(r.closer)
+}
+
+// withCloser оборачивает reader так, чтобы Close закрывал исходный поток хранилища
+func withCloser(reader io.Reader, source io.Reader) io.Reader {
+	return &readCloser{Reader: reader, closer: source}
+}
+
+func closeReader(reader io.Reader) error {
+	if closer, ok := reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+)
+
+var (
+	ErrThumbnailUnsupported = errors.New("thumbnail unsupported")
+	ErrThumbnailTooLarge    = errors.New("thumbnail source too large")
+)
+
+const (
+	// ThumbnailMaxSourceSize - максимальный размер исходного изображения, из которого строится превью
+	ThumbnailMaxSourceSize = 32 << 20
+	// thumbnailMaxPixels защищает от изображений, которые при декодировании занимают гигабайты памяти
+	thumbnailMaxPixels = 50_000_000
+	thumbnailQuality   = 80
+)
+
+type ThumbnailService interface {
+	Supports(ext string) bool
+	Make(src io.Reader, size int) ([]byte, error)
+}
+
+type thumbnailService struct{}
+
+// Supports сообщает, умеет ли сервис строить превью для файла с таким расширением
+func (s *thumbnailService) Supports(ext string) bool {
+	switch strings.TrimPrefix(strings.ToLower(ext), ".") {
+	case "jpg", "jpeg", "png", "gif":
+		return true
+	}
+	return false
+}
+
+// Make строит JPEG-превью, вписанное в квадрат size x size. Изображения меньше size не увеличиваются,
+// у анимированного GIF используется первый кадр
+func (s *thumbnailService) Make(src io.Reader, size int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(src, ThumbnailMaxSourceSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > ThumbnailMaxSourceSize {
+		return nil, ErrThumbnailTooLarge
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrThumbnailUnsupported
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > thumbnailMaxPixels {
+		return nil, ErrThumbnailTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrThumbnailUnsupported
+	}
+
+	var out bytes.Buffer
+	err = jpeg.Encode(&out, s.resize(img, size), &jpeg.Options{Quality: thumbnailQuality})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// resize уменьшает изображение усреднением по площади: каждый пиксель результата - среднее
+// покрываемого им прямоугольника исходника. Прозрачные области заливаются белым
+func (s *thumbnailService) resize(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW, dstH = size, max(1, srcH*size/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*size/srcH), size
+		}
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+	if dstW == srcW && dstH == srcH {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(row[sx*4])
+					g += uint64(row[sx*4+1])
+					b += uint64(row[sx*4+2])
+					n++
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = 0xff
+		}
+	}
+	return dst
+}
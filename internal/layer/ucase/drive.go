@@ -1,20 +1,28 @@
 package ucase
 
 import (
+	"archive/zip"
 	"assistant-go/internal/layer/dto"
 	"assistant-go/internal/layer/entity"
 	"assistant-go/internal/layer/repository"
 	service "assistant-go/internal/layer/service/file"
 	"assistant-go/internal/logging"
 	"assistant-go/internal/storage/postgres"
+	"assistant-go/pkg/tus"
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"hash"
 	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -24,6 +32,12 @@ const (
 	typeFile      = 1
 )
 
+// состояние загрузки файла: чанковый файл доступен для скачивания только после ChunkEnd
+const (
+	uploadStateUploading int8 = 0
+	uploadStateComplete  int8 = 1
+)
+
 var (
 	ErrDriveFileTooLarge                    = errors.New("drive file too large")
 	ErrDriveFileTooLargeUseChunks           = errors.New("drive file too large use chunks")
@@ -40,23 +54,60 @@ var (
 	ErrDriveUnavailableForChunks            = errors.New("drive unavailable for chunks")
 	ErrDriveEncrypting                      = errors.New("error encrypting file")
 	ErrDriveDecrypting                      = errors.New("error decrypting file")
+	ErrDriveTrashItemNotFound               = errors.New("drive trash item not found")
+	ErrDriveFileHashMismatch                = errors.New("drive file hash mismatch")
+	ErrDriveBlobNotFound                    = errors.New("drive blob not found")
+	ErrDriveChunksSequenceBroken            = errors.New("drive chunks sequence is broken")
+	ErrDriveFileSizeMismatch                = errors.New("drive file size mismatch")
+	ErrDriveUploadIncomplete                = errors.New("drive upload is incomplete")
+	ErrDriveUploadCompleted                 = errors.New("drive upload is already completed")
+	ErrDriveUploadOffsetMismatch            = errors.New("drive upload offset mismatch")
+	ErrDriveChecksumMismatch                = errors.New("drive upload checksum mismatch")
+	ErrDriveZipTooLarge                     = errors.New("drive zip archive is too large")
+	ErrDriveZipTooManyEntries               = errors.New("drive zip archive has too many entries")
+	ErrDrivePathInvalid                     = errors.New("drive path is invalid")
+	ErrDrivePathNotFound                    = errors.New("drive path not found")
 )
 
 type DriveUseCase interface {
 	CreateDirectory(ctx context.Context, dto *dto.DriveCreateDirectory, user *entity.User) ([]*dto.DriveTree, error)
 	GetTree(ctx context.Context, parentID *int, user *entity.User) ([]*dto.DriveTree, error)
+	GetSharedWithMe(ctx context.Context, user *entity.User) ([]*dto.DriveTree, error)
+	Search(ctx context.Context, in *dto.DriveSearch, user *entity.User) (*dto.DriveSearchResult, error)
+	ResolvePath(ctx context.Context, path string, user *entity.User) (*dto.DrivePathItem, error)
+	GetAncestors(ctx context.Context, structID int, user *entity.User) ([]*dto.DrivePathItem, error)
 	UploadFile(ctx context.Context, in dto.DriveUploadFile, user *entity.User) ([]*dto.DriveTree, error)
-	Delete(ctx context.Context, structID int, savePath string, user *entity.User) error
+	Delete(ctx context.Context, structID int, user *entity.User) error
+	GetTrash(ctx context.Context, user *entity.User) ([]*dto.DriveTrashItem, error)
+	RestoreFromTrash(ctx context.Context, structID int, user *entity.User) error
+	DeleteFromTrash(ctx context.Context, structID int, savePath string, user *entity.User) error
+	EmptyTrash(ctx context.Context, savePath string, user *entity.User) error
+	PurgeTrash(ctx context.Context, savePath string, retention time.Duration) (int, error)
+	GetFileInfo(ctx context.Context, in *dto.GetFile, user *entity.User) (*dto.DriveFileInfo, error)
 	GetFile(ctx context.Context, in *dto.GetFile, user *entity.User) (*dto.FileResponse, error)
+	GetThumbnail(ctx context.Context, in *dto.GetThumbnail, user *entity.User) (*dto.FileResponse, error)
 	Rename(ctx context.Context, structID int, newName string, user *entity.User) error
-	Space(ctx context.Context, user *entity.User, totalSpace int64) (*dto.DriveSpace, error)
+	Space(ctx context.Context, user *entity.User, defaults dto.StorageLimits) (*dto.DriveSpace, error)
 	RenMov(ctx context.Context, user *entity.User, in dto.DriveRenMov) error
+	Copy(ctx context.Context, user *entity.User, in dto.DriveCopyIn) ([]*dto.DriveTree, error)
 	ChunkPrepare(ctx context.Context, user *entity.User, in dto.DriveChunkPrepareIn) (*dto.DriveChunkPrepareResponse, error)
 	ChunkUpload(ctx context.Context, user *entity.User, in dto.DriveUploadChunk) error
-	ChunkEnd(ctx context.Context, structID int) error
+	ChunkEnd(ctx context.Context, user *entity.User, in dto.DriveChunkEndIn) error
 	ChunksInfo(ctx context.Context, structID int) (*dto.DriveChunksInfo, error)
 	GetChunkBytes(ctx context.Context, in *dto.GetChunk, user *entity.User) (*dto.FileResponse, error)
 	UpdateFileHash(ctx context.Context, structID int, hash string, user *entity.User) error
+	GetFileVersions(ctx context.Context, structID int, useEncryption bool, user *entity.User) ([]*dto.DriveFileVersion, error)
+	RestoreFileVersion(ctx context.Context, structID int, versionID int, user *entity.User) error
+	PruneFileVersions(ctx context.Context, structID int, keep int, savePath string, user *entity.User) error
+	PruneVersions(ctx context.Context, savePath string, maxCount int, maxAge time.Duration) (int, error)
+	ReapUploads(ctx context.Context, savePath string, ttl time.Duration) (int, int64, error)
+	HashExists(ctx context.Context, hash string, user *entity.User) (bool, error)
+	UploadByHash(ctx context.Context, in dto.DriveUploadByHash, user *entity.User) ([]*dto.DriveTree, error)
+	TusUploadInfo(ctx context.Context, structID int, useEncryption bool, user *entity.User) (*dto.DriveTusUpload, error)
+	TusAppend(ctx context.Context, user *entity.User, in dto.DriveTusAppend) (*dto.DriveTusUpload, error)
+	TusTerminate(ctx context.Context, structID int, savePath string, user *entity.User) error
+	GetZip(ctx context.Context, in dto.DriveZipIn, user *entity.User) (*dto.FileResponse, error)
+	ImportArchive(ctx context.Context, in dto.DriveImportIn, user *entity.User) (*dto.DriveImportResult, error)
 }
 
 type driveUseCase struct {
@@ -70,6 +121,11 @@ func NewDriveUseCase(repositories *repository.Repositories) DriveUseCase {
 }
 
 func (uc *driveUseCase) GetTree(ctx context.Context, parentID *int, user *entity.User) ([]*dto.DriveTree, error) {
+	user, err := uc.parentOwner(ctx, parentID, user, DriveRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
 	list, err := uc.repositories.DriveStructRepository.TreeByUserID(ctx, user.ID, parentID)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
@@ -80,14 +136,62 @@ func (uc *driveUseCase) GetTree(ctx context.Context, parentID *int, user *entity
 	return list, nil
 }
 
-func (uc *driveUseCase) CreateDirectory(ctx context.Context, dto *dto.DriveCreateDirectory, user *entity.User) ([]*dto.DriveTree, error) {
-	if dto.ParentID != nil {
-		err := uc.checkParentOwner(ctx, *dto.ParentID, user.ID)
-		if err != nil {
-			return nil, err
+func (uc *driveUseCase) Search(ctx context.Context, in *dto.DriveSearch, user *entity.User) (*dto.DriveSearchResult, error) {
+	items, total, err := uc.repositories.DriveStructRepository.Search(ctx, user.ID, in)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	return &dto.DriveSearchResult{
+		Items: items,
+		Total: total,
+		Page:  in.Page,
+		Limit: in.Limit,
+	}, nil
+}
+
+// ResolvePath находит структуру по пути вида /Projects/2026/report.pdf от корня пользователя
+func (uc *driveUseCase) ResolvePath(ctx context.Context, path string, user *entity.User) (*dto.DrivePathItem, error) {
+	names, err := splitDrivePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, ErrDrivePathInvalid
+	}
+
+	item, err := uc.repositories.DriveStructRepository.ResolvePath(ctx, user.ID, names)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDrivePathNotFound
 		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return item, nil
+}
+
+// GetAncestors возвращает цепочку директорий от корня до структуры включительно (хлебные крошки)
+func (uc *driveUseCase) GetAncestors(ctx context.Context, structID int, user *entity.User) ([]*dto.DrivePathItem, error) {
+	items, err := uc.repositories.DriveStructRepository.Ancestors(ctx, user.ID, structID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
 	}
-	_, err := uc.repositories.DriveStructRepository.FindRow(ctx, user.ID, dto.Name, typeDirectory, dto.ParentID)
+	if len(items) == 0 {
+		return nil, ErrDriveStructNotFound
+	}
+	return items, nil
+}
+
+func (uc *driveUseCase) CreateDirectory(ctx context.Context, dto *dto.DriveCreateDirectory, user *entity.User) ([]*dto.DriveTree, error) {
+	user, err := uc.parentOwner(ctx, dto.ParentID, user, DriveRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uc.repositories.DriveStructRepository.FindRow(ctx, user.ID, dto.Name, typeDirectory, dto.ParentID)
 
 	if err == nil {
 		return nil, ErrDriveDirectoryExists
@@ -113,38 +217,52 @@ func (uc *driveUseCase) CreateDirectory(ctx context.Context, dto *dto.DriveCreat
 }
 
 func (uc *driveUseCase) UploadFile(ctx context.Context, in dto.DriveUploadFile, user *entity.User) ([]*dto.DriveTree, error) {
-	fileService := service.NewFile().FileService()
-
-	if in.UseEncryption {
-		var encryptErr error
-		in.File, encryptErr = fileService.EncryptFile(in.File, in.EncryptionKey)
-		if encryptErr != nil {
-			logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveEncrypting, encryptErr))
-			return nil, ErrDriveEncrypting
+	user, err := uc.parentOwner(ctx, in.ParentID, user, DriveRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if in.Path != "" {
+		in.ParentID, in.OriginalFilename, err = uc.resolveUploadPath(ctx, user, in.ParentID, in.Path)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	size, err := uc.getFileSize(in.File, in.MaxSizeBytes)
+	fileService := service.NewFile().FileService()
+
+	plainSize, err := uc.getFileSize(in.File, in.MaxSizeBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	if size > 64<<20 {
+	if plainSize > 64<<20 {
 		return nil, ErrDriveFileTooLargeUseChunks
 	}
 
-	if size > in.MaxSizeBytes {
+	if plainSize > in.MaxSizeBytes {
 		return nil, ErrDriveFileTooLarge
 	}
 
-	allStorageSize, err := uc.repositories.DriveFileRepository.GetStorageSize(ctx, user.ID)
-	if err != nil {
-		logging.GetLogger(ctx).Error(err)
-		return nil, postgres.ErrUnexpectedDBError
+	// размер файла в хранилище известен заранее и для зашифрованного файла
+	size := plainSize
+	if in.UseEncryption {
+		size = fileService.EncryptedSize(plainSize)
+	}
+
+	// если такое содержимое у пользователя уже есть, место не расходуется
+	clientHash := normalizeSHA256(in.SHA256)
+	var blob *entity.DriveBlob
+	if clientHash != "" {
+		blob, err = uc.findUserBlob(ctx, user.ID, clientHash)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if (allStorageSize + size) > in.StorageMaxSizePerUser {
-		return nil, ErrDriveFileSystemIsFull
+	if blob == nil {
+		if err = uc.checkStorageQuota(ctx, user.ID, size, in.StorageMaxSizePerUser); err != nil {
+			return nil, err
+		}
 	}
 
 	fileExt := strings.ToLower(filepath.Ext(in.OriginalFilename))
@@ -155,68 +273,61 @@ func (uc *driveUseCase) UploadFile(ctx context.Context, in dto.DriveUploadFile,
 		return nil, ErrDriveFileNotSafeFilename
 	}
 
-	_, err = uc.repositories.DriveStructRepository.FindRow(ctx, user.ID, in.OriginalFilename, typeFile, in.ParentID)
-
-	if err == nil {
-		return nil, ErrDriveFilenameExists
-	}
-
-	newFilename, err := fileService.GenerateNewFileName(fileExt)
+	existingStruct, err := uc.findReplaceableFile(ctx, user.ID, in.OriginalFilename, in.ParentID, in.Replace)
 	if err != nil {
 		return nil, err
 	}
 
-	maxFileID, err := uc.repositories.DriveFileRepository.GetLastID(ctx)
-	if err != nil {
+	head := make([]byte, service.MimeSniffLen)
+	headSize, err := in.File.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
 		logging.GetLogger(ctx).Error(err)
-		return nil, postgres.ErrUnexpectedDBError
-	}
-
-	middleFilePath := filepath.Join(fileService.GetMiddlePathByFileId(maxFileID+1), newFilename)
-	fullFilePath := filepath.Join(in.SavePath, middleFilePath)
-
-	limitedReader := io.LimitReader(in.File, in.MaxSizeBytes+1)
-	saveDto := &dto.SaveFile{
-		File:      limitedReader,
-		SavePath:  fullFilePath,
-		SizeBytes: size,
-	}
-
-	saveErr := uc.repositories.StorageRepository.Save(ctx, saveDto)
-	if saveErr != nil {
-		return nil, ErrDriveFileSave
+		return nil, ErrFileReading
 	}
+	mimeType := fileService.DetectMimeType(head[:headSize], fileExt)
 
-	// сохраняем 2 записи в БД
-	driveStruct := &entity.DriveStruct{
-		UserID:    user.ID,
-		Name:      in.OriginalFilename,
-		Type:      typeFile,
-		ParentID:  in.ParentID,
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
+	if blob != nil {
+		// содержимое уже хранится: присланные байты только хэшируются для проверки
+		hash, err := uc.hashReader(io.LimitReader(in.File, plainSize))
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, ErrFileReading
+		}
+		if hash != clientHash {
+			return nil, ErrDriveFileHashMismatch
+		}
+	} else {
+		blob, err = uc.saveBlob(ctx, in, user, fileExt, plainSize, size, clientHash)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	driveStruct, err = uc.repositories.DriveStructRepository.Create(ctx, driveStruct)
-	if err != nil {
-		return nil, err
+	var pendingKeys []string
+	if blob.ID == 0 {
+		pendingKeys = append(pendingKeys, filepath.Join(in.SavePath, blob.Path))
 	}
-
 	driveFile := &entity.DriveFile{
-		DriveStructID: driveStruct.ID,
-		Path:          &middleFilePath,
-		Ext:           fileExt,
-		Size:          size,
-		CreatedAt:     time.Now().UTC(),
-		IsChunk:       false,
-		SHA256:        in.SHA256,
+		Ext:         fileExt,
+		CreatedAt:   time.Now().UTC(),
+		IsChunk:     false,
+		IsCurrent:   true,
+		UploadState: uploadStateComplete,
+		MimeType:    &mimeType,
 	}
 
-	_, err = uc.repositories.DriveFileRepository.Create(ctx, driveFile)
+	driveStruct, err := uc.attachFile(
+		ctx, user, in.OriginalFilename, in.ParentID, existingStruct, driveFile, blob, in.StorageMaxSizePerUser, pendingKeys...,
+	)
 	if err != nil {
+		abortStorageWrite(ctx, uc.repositories, pendingKeys...)
+		if errors.Is(err, ErrDriveFileSystemIsFull) {
+			return nil, err
+		}
 		logging.GetLogger(ctx).Error(err)
 		return nil, postgres.ErrUnexpectedDBError
 	}
+	uc.warmDriveThumbnail(ctx, driveStruct, driveFile, user, in.SavePath, in.UseEncryption, in.Keyring)
 
 	treeList, err := uc.GetTree(ctx, in.ParentID, user)
 	if err != nil {
@@ -226,103 +337,213 @@ func (uc *driveUseCase) UploadFile(ctx context.Context, in dto.DriveUploadFile,
 	return treeList, nil
 }
 
-func (uc *driveUseCase) Delete(ctx context.Context, structID int, savePath string, user *entity.User) error {
+// Delete перемещает структуру со всем содержимым в корзину, файлы удаляются из хранилища только при очистке
+func (uc *driveUseCase) Delete(ctx context.Context, structID int, user *entity.User) error {
+	user, err := uc.entryOwner(ctx, structID, user)
+	if err != nil {
+		return err
+	}
+
 	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrDriveStructNotFound
 		}
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
 	}
-	if driveStruct.UserID != user.ID {
+	if driveStruct.UserID != user.ID || driveStruct.DeletedAt != nil {
 		return ErrDriveStructNotFound
 	}
 
-	deleteChunkList, err := uc.repositories.DriveFileChunkRepository.GetAllRecursive(ctx, structID, user.ID)
+	err = uc.repositories.DriveStructRepository.MoveToTrash(ctx, user.ID, structID, time.Now().UTC())
 	if err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			return err
-		}
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
 	}
+	return nil
+}
 
-	deleteFileList, err := uc.repositories.DriveFileRepository.GetAllRecursive(ctx, structID, user.ID)
+func (uc *driveUseCase) GetTrash(ctx context.Context, user *entity.User) ([]*dto.DriveTrashItem, error) {
+	list, err := uc.repositories.DriveStructRepository.TrashByUserID(ctx, user.ID)
 	if err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			return err
-		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
 	}
+	return list, nil
+}
 
-	var keys []string
-	if len(deleteChunkList) > 0 {
-		for _, fileChunk := range deleteChunkList {
-			keys = append(keys, filepath.Join(savePath, fileChunk.Path))
-		}
+// RestoreFromTrash возвращает элемент корзины на прежнее место. Если родительская директория
+// удалена, элемент восстанавливается в корень, а при совпадении имени получает суффикс " (N)"
+func (uc *driveUseCase) RestoreFromTrash(ctx context.Context, structID int, user *entity.User) error {
+	driveStruct, err := uc.getTrashRoot(ctx, structID, user)
+	if err != nil {
+		return err
 	}
-	if len(deleteFileList) > 0 {
-		for _, file := range deleteFileList {
-			if !file.IsChunk {
-				keys = append(keys, filepath.Join(savePath, *file.Path))
-			}
+
+	if driveStruct.ParentID != nil {
+		parentStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, *driveStruct.ParentID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+		if parentStruct == nil || parentStruct.DeletedAt != nil {
+			driveStruct.ParentID = nil
 		}
 	}
 
-	if len(keys) > 0 {
-		_ = uc.repositories.StorageRepository.DeleteAll(ctx, keys)
+	name, err := uc.getFreeName(ctx, user.ID, driveStruct.Name, driveStruct.Type, driveStruct.ParentID)
+	if err != nil {
+		return err
 	}
+	driveStruct.Name = name
+	driveStruct.UpdatedAt = time.Now().UTC()
 
-	// удаление записей из БД из трех таблиц (через cascade fk)
-	err = uc.repositories.DriveStructRepository.DeleteRecursive(ctx, user.ID, structID)
+	err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		driveStructRepoTx := uc.repositories.WithTx(tx).DriveStructRepository
+
+		if err := driveStructRepoTx.RestoreFromTrash(ctx, user.ID, driveStruct.ID); err != nil {
+			return err
+		}
+		return driveStructRepoTx.Update(ctx, driveStruct)
+	})
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+	return nil
+}
+
+func (uc *driveUseCase) DeleteFromTrash(ctx context.Context, structID int, savePath string, user *entity.User) error {
+	driveStruct, err := uc.getTrashRoot(ctx, structID, user)
 	if err != nil {
 		return err
 	}
+	return uc.purge(ctx, driveStruct.UserID, driveStruct.ID, savePath)
+}
+
+func (uc *driveUseCase) EmptyTrash(ctx context.Context, savePath string, user *entity.User) error {
+	list, err := uc.repositories.DriveStructRepository.TrashByUserID(ctx, user.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
 
+	for _, item := range list {
+		if err = uc.purge(ctx, user.ID, item.ID, savePath); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (uc *driveUseCase) GetFile(ctx context.Context, in *dto.GetFile, user *entity.User) (*dto.FileResponse, error) {
-	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, in.StructID)
+// PurgeTrash окончательно удаляет элементы, которые находятся в корзине дольше retention
+func (uc *driveUseCase) PurgeTrash(ctx context.Context, savePath string, retention time.Duration) (int, error) {
+	list, err := uc.repositories.DriveStructRepository.GetTrashRootsBefore(ctx, time.Now().UTC().Add(-retention))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrFileNotFound
+		logging.GetLogger(ctx).Error(err)
+		return 0, postgres.ErrUnexpectedDBError
+	}
+
+	for i, driveStruct := range list {
+		if err = uc.purge(ctx, driveStruct.UserID, driveStruct.ID, savePath); err != nil {
+			return i, err
 		}
+	}
+	return len(list), nil
+}
+
+func (uc *driveUseCase) GetFileInfo(ctx context.Context, in *dto.GetFile, user *entity.User) (*dto.DriveFileInfo, error) {
+	user, err := uc.structOwner(ctx, in.StructID, user, DriveRoleViewer)
+	if err != nil {
 		return nil, err
 	}
-	if driveStruct.UserID != user.ID {
-		return nil, ErrFileNotFound
+
+	driveStruct, driveFile, err := uc.getUserFile(ctx, in.StructID, in.VersionID, user)
+	if err != nil {
+		return nil, err
 	}
-	if driveStruct.Type != typeFile {
-		return nil, ErrFileNotFound
+	if driveFile.UploadState != uploadStateComplete {
+		return nil, ErrDriveUploadIncomplete
 	}
 
-	driveFile, err := uc.repositories.DriveFileRepository.GetByStructID(ctx, driveStruct.ID)
+	return &dto.DriveFileInfo{
+		OriginalFilename: driveStruct.Name,
+		SizeBytes:        uc.getPlainSize(driveFile.Size, driveFile.PlainSize, in.UseEncryption),
+		ETag:             uc.getFileETag(driveFile),
+		ModifiedAt:       driveFile.CreatedAt,
+		MimeType:         uc.getMimeType(driveFile),
+	}, nil
+}
+
+func (uc *driveUseCase) GetFile(ctx context.Context, in *dto.GetFile, user *entity.User) (*dto.FileResponse, error) {
+	user, err := uc.structOwner(ctx, in.StructID, user, DriveRoleViewer)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrFileNotFound
-		}
-	}
-	if driveFile.IsChunk {
-		return nil, ErrDriveUnavailableForChunks
+		return nil, err
 	}
 
-	fullPath := filepath.Join(in.SavePath, *driveFile.Path)
-	fileReader, err := uc.repositories.StorageRepository.GetFile(ctx, fullPath)
+	driveStruct, driveFile, err := uc.getUserFile(ctx, in.StructID, in.VersionID, user)
 	if err != nil {
-		logging.GetLogger(ctx).Error(err)
 		return nil, err
 	}
+	if driveFile.UploadState != uploadStateComplete {
+		return nil, ErrDriveUploadIncomplete
+	}
+	if driveFile.IsChunk {
+		return uc.getChunkedFile(ctx, in, driveStruct, driveFile)
+	}
 
-	realSize := driveFile.Size
-	if in.UseEncryption {
-		fileService := service.NewFile().FileService()
-		fileReader, err = fileService.DecryptFile(fileReader, in.EncryptionKey)
-		if err != nil {
-			logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveDecrypting, err))
-			return nil, ErrDriveDecrypting
+	fullPath := filepath.Join(in.SavePath, *driveFile.Path)
+	realSize := uc.getPlainSize(driveFile.Size, driveFile.PlainSize, in.UseEncryption)
+
+	cipher := newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring)
+	if !cipher.encrypted(driveFile.DataKeyID, driveFile.Size, driveFile.PlainSize) {
+		var fileReader io.Reader
+		if in.Range != nil {
+			fileReader, err = uc.repositories.StorageRepository.GetFileRange(ctx, fullPath, in.Range.Offset, in.Range.Length)
+			realSize = in.Range.Length
+		} else {
+			fileReader, err = uc.repositories.StorageRepository.GetFile(ctx, fullPath)
 		}
-
-		realSize, err = uc.getFileSizeInReader(fileReader, in.MaxSizeBytes)
 		if err != nil {
+			logging.GetLogger(ctx).Error(err)
 			return nil, err
 		}
+
+		return &dto.FileResponse{
+			File:             fileReader,
+			OriginalFilename: driveStruct.Name,
+			SizeBytes:        realSize,
+		}, nil
+	}
+
+	var (
+		fileReader io.Reader
+		storageErr error
+	)
+
+	if in.Range != nil {
+		// читаем из хранилища только сегменты, которые покрывают запрошенный диапазон
+		openRange := func(offset int64, length int64) (io.Reader, error) {
+			reader, err := uc.repositories.StorageRepository.GetFileRange(ctx, fullPath, offset, length)
+			storageErr = err
+			return reader, err
+		}
+		fileReader, err = cipher.decryptRange(ctx, openRange, driveFile.DataKeyID, in.Range.Offset, in.Range.Length)
+		realSize = in.Range.Length
+	} else {
+		fileReader, storageErr = uc.repositories.StorageRepository.GetFile(ctx, fullPath)
+		if storageErr == nil {
+			fileReader, err = cipher.decryptObject(ctx, fileReader, driveFile.DataKeyID)
+		}
+	}
+	if storageErr != nil {
+		logging.GetLogger(ctx).Error(storageErr)
+		return nil, storageErr
+	}
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveDecrypting, err))
+		return nil, ErrDriveDecrypting
 	}
 
 	fileResponse := &dto.FileResponse{
@@ -334,6 +555,11 @@ func (uc *driveUseCase) GetFile(ctx context.Context, in *dto.GetFile, user *enti
 }
 
 func (uc *driveUseCase) Rename(ctx context.Context, structID int, newName string, user *entity.User) error {
+	user, err := uc.entryOwner(ctx, structID, user)
+	if err != nil {
+		return err
+	}
+
 	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -341,7 +567,7 @@ func (uc *driveUseCase) Rename(ctx context.Context, structID int, newName string
 		}
 		return err
 	}
-	if driveStruct.UserID != user.ID {
+	if driveStruct.UserID != user.ID || driveStruct.DeletedAt != nil {
 		return ErrFileNotFound
 	}
 
@@ -354,20 +580,47 @@ func (uc *driveUseCase) Rename(ctx context.Context, structID int, newName string
 	return nil
 }
 
-func (uc *driveUseCase) Space(ctx context.Context, user *entity.User, totalSpace int64) (*dto.DriveSpace, error) {
-	result := &dto.DriveSpace{}
-	result.Total = totalSpace
+// Space возвращает занятое место и лимиты пользователя. defaults - лимиты из конфигурации
+// для пользователей без персональной квоты и тарифа
+func (uc *driveUseCase) Space(ctx context.Context, user *entity.User, defaults dto.StorageLimits) (*dto.DriveSpace, error) {
+	limits, err := getStorageLimits(ctx, uc.repositories, user.ID, defaults)
+	if err != nil {
+		return nil, err
+	}
 
-	usedSpace, err := uc.repositories.DriveFileRepository.GetStorageSize(ctx, user.ID)
+	usage, err := getStorageUsage(ctx, uc.repositories, user.ID)
 	if err != nil {
 		return nil, err
 	}
-	result.Used = usedSpace
 
-	return result, nil
+	return &dto.DriveSpace{
+		Total: limits.DriveLimit + limits.FilesLimit,
+		Used:  usage.DriveUsed + usage.FilesUsed,
+		Plan:  limits.PlanName,
+		Drive: dto.DriveSpaceUsage{Total: limits.DriveLimit, Used: usage.DriveUsed},
+		Notes: dto.DriveSpaceUsage{Total: limits.FilesLimit, Used: usage.FilesUsed},
+	}, nil
 }
 
 func (uc *driveUseCase) RenMov(ctx context.Context, user *entity.User, in dto.DriveRenMov) error {
+	owner, err := uc.parentOwner(ctx, in.ParentID, user, DriveRoleEditor)
+	if err != nil {
+		return err
+	}
+	// в чужую директорию можно переместить только элементы того же владельца, доступные для редактирования
+	if owner.ID != user.ID {
+		for _, structID := range in.StructIDs {
+			entryUser, err := uc.entryOwner(ctx, structID, user)
+			if err != nil {
+				return err
+			}
+			if entryUser.ID != owner.ID {
+				return ErrDriveRelocatableStructureNotFound
+			}
+		}
+		user = owner
+	}
+
 	if in.ParentID != nil {
 		parentStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, *in.ParentID)
 		if err != nil {
@@ -377,7 +630,7 @@ func (uc *driveUseCase) RenMov(ctx context.Context, user *entity.User, in dto.Dr
 			return err
 		}
 
-		if parentStruct.UserID != user.ID {
+		if parentStruct.UserID != user.ID || parentStruct.DeletedAt != nil {
 			return ErrDriveParentIdNotFound
 		}
 		if parentStruct.Type != typeDirectory {
@@ -415,8 +668,8 @@ func (uc *driveUseCase) RenMov(ctx context.Context, user *entity.User, in dto.Dr
 		}
 	}
 
-	err := repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
-		driveStructRepoTx := repository.NewDriveStructRepository(tx)
+	err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		driveStructRepoTx := uc.repositories.WithTx(tx).DriveStructRepository
 
 		for _, batch := range batches {
 			err := driveStructRepoTx.MassUpdateParentID(ctx, in.ParentID, batch)
@@ -434,27 +687,27 @@ func (uc *driveUseCase) RenMov(ctx context.Context, user *entity.User, in dto.Dr
 }
 
 func (uc *driveUseCase) ChunkPrepare(ctx context.Context, user *entity.User, in dto.DriveChunkPrepareIn) (*dto.DriveChunkPrepareResponse, error) {
-	if in.FullSize > in.MaxSizeBytes {
-		return nil, ErrDriveFileTooLarge
-	}
-
-	allStorageSize, err := uc.repositories.DriveFileRepository.GetStorageSize(ctx, user.ID)
+	user, err := uc.parentOwner(ctx, in.ParentID, user, DriveRoleEditor)
 	if err != nil {
-		logging.GetLogger(ctx).Error(err)
-		return nil, postgres.ErrUnexpectedDBError
-	}
-
-	if (allStorageSize + in.FullSize) > in.StorageMaxSizePerUser {
-		return nil, ErrDriveFileSystemIsFull
+		return nil, err
 	}
-
-	if in.ParentID != nil {
-		err := uc.checkParentOwner(ctx, *in.ParentID, user.ID)
+	if in.Path != "" {
+		in.ParentID, in.Filename, err = uc.resolveUploadPath(ctx, user, in.ParentID, in.Path)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	if in.FullSize > in.MaxSizeBytes {
+		return nil, ErrDriveFileTooLarge
+	}
+
+	// при подготовке место не резервируется: параллельно подготовленные загрузки могут вместе
+	// превышать квоту. Квоту гарантирует saveChunk, резервирующий каждый чанк под блокировкой счетчика
+	if err = uc.checkStorageQuota(ctx, user.ID, in.FullSize, in.StorageMaxSizePerUser); err != nil {
+		return nil, err
+	}
+
 	fileExt := strings.ToLower(filepath.Ext(in.Filename))
 	fileExt = strings.TrimPrefix(fileExt, ".")
 
@@ -463,82 +716,59 @@ func (uc *driveUseCase) ChunkPrepare(ctx context.Context, user *entity.User, in
 		return nil, ErrDriveFileNotSafeFilename
 	}
 
-	_, err = uc.repositories.DriveStructRepository.FindRow(ctx, user.ID, in.Filename, typeFile, in.ParentID)
+	existingStruct, err := uc.findReplaceableFile(ctx, user.ID, in.Filename, in.ParentID, in.Replace)
+	if err != nil {
+		return nil, err
+	}
 
-	if err == nil {
-		return nil, ErrDriveFilenameExists
+	driveFile := &entity.DriveFile{
+		Path:         nil,
+		Ext:          fileExt,
+		Size:         0,
+		CreatedAt:    time.Now().UTC(),
+		IsChunk:      true,
+		IsCurrent:    true,
+		UploadState:  uploadStateUploading,
+		ExpectedSize: &in.FullSize,
+	}
+	driveFile.LastActivityAt = &driveFile.CreatedAt
+	if clientHash := normalizeSHA256(in.DriveChunkPrepare.SHA256); clientHash != "" {
+		driveFile.SHA256 = &clientHash
+	}
+
+	driveStruct, err := uc.attachFile(
+		ctx, user, in.Filename, in.ParentID, existingStruct, driveFile, nil, in.StorageMaxSizePerUser,
+	)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
 	}
 
-	driveStruct := &entity.DriveStruct{
-		UserID:    user.ID,
-		Name:      in.Filename,
-		Type:      typeFile,
-		ParentID:  in.ParentID,
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
+	return &dto.DriveChunkPrepareResponse{StructID: driveStruct.ID}, nil
+}
+
+func (uc *driveUseCase) ChunkUpload(ctx context.Context, user *entity.User, in dto.DriveUploadChunk) error {
+	user, err := uc.entryOwner(ctx, in.StructID, user)
+	if err != nil {
+		return err
 	}
 
-	driveStructResult, err := repository.WithTransactionResult(
-		ctx,
-		uc.repositories.TransactionRepository,
-		func(tx pgx.Tx) (*entity.DriveStruct, error) {
-			driveStructRepo := repository.NewDriveStructRepository(tx)
+	fileService := service.NewFile().FileService()
 
-			driveStruct, err = driveStructRepo.Create(ctx, driveStruct)
-			if err != nil {
-				return nil, err
-			}
-
-			driveFile := &entity.DriveFile{
-				DriveStructID: driveStruct.ID,
-				Path:          nil,
-				Ext:           fileExt,
-				Size:          0,
-				CreatedAt:     time.Now().UTC(),
-				IsChunk:       true,
-				SHA256:        in.DriveChunkPrepare.SHA256,
-			}
-
-			driveFileRepo := repository.NewDriveFileRepository(tx)
-
-			driveFile, err = driveFileRepo.Create(ctx, driveFile)
-			if err != nil {
-				logging.GetLogger(ctx).Error(err)
-				return nil, postgres.ErrUnexpectedDBError
-			}
-
-			return driveStruct, nil
-		})
-
-	if err != nil {
-		logging.GetLogger(ctx).Error(err)
-		return nil, err
-	}
-
-	return &dto.DriveChunkPrepareResponse{StructID: driveStructResult.ID}, nil
-}
-
-func (uc *driveUseCase) ChunkUpload(ctx context.Context, user *entity.User, in dto.DriveUploadChunk) error {
-	fileService := service.NewFile().FileService()
-
-	if in.UseEncryption {
-		var encryptErr error
-		in.File, encryptErr = fileService.EncryptFile(in.File, in.EncryptionKey)
-		if encryptErr != nil {
-			logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveEncrypting, encryptErr))
-			return ErrDriveEncrypting
-		}
-	}
-
-	size, err := uc.getFileSize(in.File, in.MaxSizeBytes)
+	plainSize, err := uc.getFileSize(in.File, in.MaxSizeBytes)
 	if err != nil {
 		return err
 	}
 
-	if size > 64<<20 {
+	if plainSize > 64<<20 {
 		return ErrDriveFileTooLargeUseChunks
 	}
 
+	size := plainSize
+	if in.UseEncryption {
+		size = fileService.EncryptedSize(plainSize)
+	}
+
 	fileEntity, err := uc.repositories.DriveFileRepository.GetByStructID(ctx, in.StructID)
 	if err != nil {
 		return err
@@ -552,6 +782,9 @@ func (uc *driveUseCase) ChunkUpload(ctx context.Context, user *entity.User, in d
 	if !checkFileOwner {
 		return ErrDriveStructNotFound
 	}
+	if !fileEntity.IsChunk || fileEntity.UploadState != uploadStateUploading {
+		return ErrDriveUploadCompleted
+	}
 
 	chunksSize, err := uc.repositories.DriveFileChunkRepository.GetChunksSize(ctx, fileEntity.ID)
 	if err != nil {
@@ -563,69 +796,217 @@ func (uc *driveUseCase) ChunkUpload(ctx context.Context, user *entity.User, in d
 		return ErrDriveFileTooLarge
 	}
 
-	allStorageSize, err := uc.repositories.DriveFileRepository.GetStorageSize(ctx, user.ID)
-	if err != nil {
-		logging.GetLogger(ctx).Error(err)
-		return postgres.ErrUnexpectedDBError
+	if err = uc.checkStorageQuota(ctx, user.ID, size, in.StorageMaxSizePerUser); err != nil {
+		return err
 	}
 
-	if (allStorageSize + chunksSize + size) > in.StorageMaxSizePerUser {
-		return ErrDriveFileSystemIsFull
+	_, err = uc.saveChunk(
+		ctx, user.ID, fileEntity.ID, in.ChunkNumber, in.File, plainSize, size,
+		in.SavePath, newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring), in.StorageMaxSizePerUser,
+	)
+	return err
+}
+
+// saveChunk сохраняет очередной чанк файла в хранилище и в БД и отмечает активность загрузки.
+// Размер чанка учитывается в счетчике занятого места в одной транзакции с записью чанка
+func (uc *driveUseCase) saveChunk(
+	ctx context.Context,
+	userID int,
+	fileID int,
+	chunkNumber int,
+	file io.Reader,
+	plainSize int64,
+	size int64,
+	savePath string,
+	cipher *driveCipher,
+	storageLimit int64,
+) (*entity.DriveFileChunk, error) {
+	fileService := service.NewFile().FileService()
+
+	newFilename, err := fileService.GenerateNewFileName(fmt.Sprintf("%s_%d", "part", chunkNumber))
+	if err != nil {
+		return nil, err
 	}
 
-	newFilename, err := fileService.GenerateNewFileName(fmt.Sprintf("%s_%d", "part", in.ChunkNumber))
+	middleFilePath := filepath.Join(fileService.GetMiddlePathByFileId(fileID), newFilename)
+	fullFilePath := filepath.Join(savePath, middleFilePath)
+
+	// клиент может оборвать соединение раньше: такой чанк не сохраняется
+	plainReader := &countingReader{reader: io.LimitReader(file, plainSize)}
+	fileReader, dataKeyID, err := cipher.encrypt(ctx, plainReader, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	middleFilePath := filepath.Join(fileService.GetMiddlePathByFileId(fileEntity.ID), newFilename)
-	fullFilePath := filepath.Join(in.SavePath, middleFilePath)
+	if err = beginStorageWrite(ctx, uc.repositories, fullFilePath); err != nil {
+		return nil, err
+	}
 
-	limitedReader := io.LimitReader(in.File, 65<<20)
 	saveDto := &dto.SaveFile{
-		File:      limitedReader,
+		File:      fileReader,
 		SavePath:  fullFilePath,
 		SizeBytes: size,
 	}
 
 	saveErr := uc.repositories.StorageRepository.Save(ctx, saveDto)
 	if saveErr != nil {
-		return ErrDriveFileSave
+		abortStorageWrite(ctx, uc.repositories, fullFilePath)
+		return nil, ErrDriveFileSave
+	}
+	if plainReader.count != plainSize {
+		abortStorageWrite(ctx, uc.repositories, fullFilePath)
+		return nil, ErrFileReading
 	}
 
 	driveFileChunk := &entity.DriveFileChunk{
-		DriveFileID: fileEntity.ID,
+		DriveFileID: fileID,
 		Path:        middleFilePath,
 		Size:        size,
-		ChunkNumber: in.ChunkNumber,
+		ChunkNumber: chunkNumber,
+		PlainSize:   &plainSize,
+		DataKeyID:   dataKeyID,
 	}
 
-	_, err = uc.repositories.DriveFileChunkRepository.Create(ctx, driveFileChunk)
+	err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		repositoriesTx := uc.repositories.WithTx(tx)
+
+		if err := uc.reserveStorage(ctx, repositoriesTx, userID, size, storageLimit); err != nil {
+			return err
+		}
+		if _, err := repositoriesTx.DriveFileChunkRepository.Create(ctx, driveFileChunk); err != nil {
+			return err
+		}
+		return commitStorageWrite(ctx, repositoriesTx, fullFilePath)
+	})
 	if err != nil {
+		abortStorageWrite(ctx, uc.repositories, fullFilePath)
+		if errors.Is(err, ErrDriveFileSystemIsFull) {
+			return nil, err
+		}
 		logging.GetLogger(ctx).Error(err)
-		return postgres.ErrUnexpectedDBError
+		return nil, postgres.ErrUnexpectedDBError
 	}
 
-	return nil
+	err = uc.repositories.DriveFileRepository.TouchActivity(ctx, fileID, time.Now().UTC())
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	return driveFileChunk, nil
 }
 
-func (uc *driveUseCase) ChunkEnd(ctx context.Context, structID int) error {
-	fileEntity, err := uc.repositories.DriveFileRepository.GetByStructID(ctx, structID)
+// ChunkEnd завершает чанковую загрузку: проверяет непрерывность номеров чанков и итоговый размер,
+// хэширует собранный файл и сверяет с хэшем клиента, после чего помечает загрузку завершенной.
+// Если у пользователя уже есть блоб с таким содержимым, чанки удаляются и файл ссылается на блоб
+func (uc *driveUseCase) ChunkEnd(ctx context.Context, user *entity.User, in dto.DriveChunkEndIn) error {
+	user, err := uc.entryOwner(ctx, in.StructID, user)
 	if err != nil {
 		return err
 	}
 
-	chunksSize, err := uc.repositories.DriveFileChunkRepository.GetChunksSize(ctx, fileEntity.ID)
+	driveStruct, fileEntity, err := uc.getUserFile(ctx, in.StructID, nil, user)
 	if err != nil {
-		logging.GetLogger(ctx).Error(err)
 		return err
 	}
+	if !fileEntity.IsChunk || fileEntity.UploadState != uploadStateUploading {
+		return ErrDriveUploadCompleted
+	}
+
+	chunks, err := uc.repositories.DriveFileChunkRepository.GetByFileID(ctx, fileEntity.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+
+	// нумерация чанков начинается с 0 или 1 и не должна иметь пропусков и повторов.
+	// Без чанков завершается только загрузка пустого файла
+	if len(chunks) == 0 && (fileEntity.ExpectedSize == nil || *fileEntity.ExpectedSize != 0) {
+		return ErrDriveChunksSequenceBroken
+	}
+	if len(chunks) > 0 && (chunks[0].ChunkNumber < 0 || chunks[0].ChunkNumber > 1) {
+		return ErrDriveChunksSequenceBroken
+	}
+	var chunksSize, chunksPlainSize int64
+	for i, fileChunk := range chunks {
+		if fileChunk.ChunkNumber != chunks[0].ChunkNumber+i {
+			return ErrDriveChunksSequenceBroken
+		}
+		chunksSize += fileChunk.Size
+		chunksPlainSize += uc.getPlainSize(fileChunk.Size, fileChunk.PlainSize, in.UseEncryption)
+	}
+	if fileEntity.ExpectedSize != nil && *fileEntity.ExpectedSize != chunksPlainSize {
+		return ErrDriveFileSizeMismatch
+	}
 
-	err = uc.repositories.DriveFileRepository.UpdateSize(ctx, fileEntity.ID, chunksSize)
+	// начало файла нужно для определения MIME-типа, поэтому оно читается до хэширования
+	cipher := newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring)
+	chunkReader := uc.openChunks(ctx, chunks, in.SavePath, cipher, 0, -1)
+	bufReader := bufio.NewReaderSize(chunkReader, service.MimeSniffLen)
+	head, _ := bufReader.Peek(service.MimeSniffLen)
+	mimeType := service.NewFile().FileService().DetectMimeType(head, fileEntity.Ext)
+	hash, err := uc.hashReader(bufReader)
+	_ = chunkReader.Close()
 	if err != nil {
 		logging.GetLogger(ctx).Error(err)
+		return ErrFileReading
+	}
+
+	if clientHash := normalizeSHA256(fileEntity.SHA256); clientHash != "" && clientHash != hash {
+		if err = uc.discardUpload(ctx, driveStruct, fileEntity, in.SavePath); err != nil {
+			logging.GetLogger(ctx).Error(err)
+		}
+		return ErrDriveFileHashMismatch
+	}
+
+	blob, err := uc.findUserBlob(ctx, driveStruct.UserID, hash)
+	if err != nil {
 		return err
 	}
+	if blob != nil {
+		err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+			repositoriesTx := uc.repositories.WithTx(tx)
+
+			if err := repositoriesTx.DriveBlobRepository.IncrementRef(ctx, blob.ID); err != nil {
+				return err
+			}
+			if err := repositoriesTx.DriveFileRepository.AttachBlob(ctx, fileEntity.ID, blob, mimeType); err != nil {
+				return err
+			}
+			if err := repositoriesTx.DriveFileChunkRepository.DeleteByFileID(ctx, fileEntity.ID); err != nil {
+				return err
+			}
+			return repositoriesTx.StorageUsageRepository.Add(ctx, driveStruct.UserID, -chunksSize, 0)
+		})
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+
+		var keys []string
+		for _, fileChunk := range chunks {
+			keys = append(keys, filepath.Join(in.SavePath, fileChunk.Path))
+		}
+		if len(keys) > 0 {
+			_ = uc.repositories.StorageRepository.DeleteAll(ctx, keys)
+		}
+
+		fileEntity.Size = blob.Size
+		fileEntity.PlainSize = &blob.PlainSize
+		fileEntity.DataKeyID = blob.DataKeyID
+		uc.warmDriveThumbnail(ctx, driveStruct, fileEntity, user, in.SavePath, in.UseEncryption, in.Keyring)
+		return nil
+	}
+
+	err = uc.repositories.DriveFileRepository.Complete(ctx, fileEntity.ID, chunksSize, chunksPlainSize, hash, mimeType)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+
+	fileEntity.Size = chunksSize
+	fileEntity.PlainSize = &chunksPlainSize
+	uc.warmDriveThumbnail(ctx, driveStruct, fileEntity, user, in.SavePath, in.UseEncryption, in.Keyring)
 	return nil
 }
 
@@ -650,6 +1031,11 @@ func (uc *driveUseCase) ChunksInfo(ctx context.Context, structID int) (*dto.Driv
 }
 
 func (uc *driveUseCase) GetChunkBytes(ctx context.Context, in *dto.GetChunk, user *entity.User) (*dto.FileResponse, error) {
+	user, err := uc.structOwner(ctx, in.StructID, user, DriveRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
 	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, in.StructID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -657,7 +1043,7 @@ func (uc *driveUseCase) GetChunkBytes(ctx context.Context, in *dto.GetChunk, use
 		}
 		return nil, err
 	}
-	if driveStruct.UserID != user.ID {
+	if driveStruct.UserID != user.ID || driveStruct.DeletedAt != nil {
 		return nil, ErrFileNotFound
 	}
 	if driveStruct.Type != typeFile {
@@ -687,30 +1073,29 @@ func (uc *driveUseCase) GetChunkBytes(ctx context.Context, in *dto.GetChunk, use
 		return nil, err
 	}
 
-	realSize := driveFileChunk.Size
-	if in.UseEncryption {
-		fileService := service.NewFile().FileService()
-		fileReader, err = fileService.DecryptFile(fileReader, in.EncryptionKey)
-		if err != nil {
-			logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveDecrypting, err))
-			return nil, ErrDriveDecrypting
-		}
-
-		realSize, err = uc.getFileSizeInReader(fileReader, in.MaxSizeBytes)
-		if err != nil {
-			return nil, err
-		}
+	realSize := uc.getPlainSize(driveFileChunk.Size, driveFileChunk.PlainSize, in.UseEncryption)
+	cipher := newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring)
+	fileReader, err = cipher.decrypt(ctx, fileReader, driveFileChunk.DataKeyID, driveFileChunk.Size, driveFileChunk.PlainSize)
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveDecrypting, err))
+		return nil, ErrDriveDecrypting
 	}
 
 	fileResponse := &dto.FileResponse{
 		File:             fileReader,
 		OriginalFilename: driveStruct.Name,
 		SizeBytes:        realSize,
+		MimeType:         uc.getMimeType(driveFile),
 	}
 	return fileResponse, nil
 }
 
 func (uc *driveUseCase) UpdateFileHash(ctx context.Context, structID int, hash string, user *entity.User) error {
+	user, err := uc.entryOwner(ctx, structID, user)
+	if err != nil {
+		return err
+	}
+
 	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -718,7 +1103,7 @@ func (uc *driveUseCase) UpdateFileHash(ctx context.Context, structID int, hash s
 		}
 		return err
 	}
-	if driveStruct.UserID != user.ID {
+	if driveStruct.UserID != user.ID || driveStruct.DeletedAt != nil {
 		return ErrFileNotFound
 	}
 	if driveStruct.Type != typeFile {
@@ -733,77 +1118,1533 @@ func (uc *driveUseCase) UpdateFileHash(ctx context.Context, structID int, hash s
 		return err
 	}
 
-	err = uc.repositories.DriveFileRepository.UpdateHash(ctx, driveFile.ID, hash)
+	// хеш вычисляется сервером при загрузке и используется для дедупликации и ETag,
+	// клиент может только подтвердить его
+	if driveFile.SHA256 == nil || !strings.EqualFold(*driveFile.SHA256, hash) {
+		return ErrDriveFileHashMismatch
+	}
+	return nil
+}
+
+func (uc *driveUseCase) GetFileVersions(
+	ctx context.Context,
+	structID int,
+	useEncryption bool,
+	user *entity.User,
+) ([]*dto.DriveFileVersion, error) {
+	driveStruct, err := uc.getUserFileStruct(ctx, structID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := uc.repositories.DriveFileRepository.GetVersions(ctx, driveStruct.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	result := make([]*dto.DriveFileVersion, 0, len(files))
+	for _, driveFile := range files {
+		result = append(result, &dto.DriveFileVersion{
+			ID:         driveFile.ID,
+			Size:       uc.getPlainSize(driveFile.Size, driveFile.PlainSize, useEncryption),
+			SHA256:     driveFile.SHA256,
+			IsChunk:    driveFile.IsChunk,
+			IsCurrent:  driveFile.IsCurrent,
+			CreatedAt:  driveFile.CreatedAt,
+			ReplacedAt: driveFile.ReplacedAt,
+		})
+	}
+	return result, nil
+}
+
+// RestoreFileVersion делает выбранную версию текущей, текущая при этом становится предыдущей версией
+func (uc *driveUseCase) RestoreFileVersion(ctx context.Context, structID int, versionID int, user *entity.User) error {
+	driveStruct, driveFile, err := uc.getUserFile(ctx, structID, &versionID, user)
+	if err != nil {
+		return err
+	}
+	if driveFile.IsCurrent {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	driveStruct.UpdatedAt = now
+
+	err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		repositoriesTx := uc.repositories.WithTx(tx)
+		driveFileRepoTx := repositoriesTx.DriveFileRepository
+
+		if err := driveFileRepoTx.UnsetCurrent(ctx, driveStruct.ID, now); err != nil {
+			return err
+		}
+		if err := driveFileRepoTx.MarkCurrent(ctx, driveFile.ID); err != nil {
+			return err
+		}
+		return repositoriesTx.DriveStructRepository.Update(ctx, driveStruct)
+	})
 	if err != nil {
 		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+	return nil
+}
+
+// PruneFileVersions удаляет предыдущие версии файла, оставляя keep самых новых
+func (uc *driveUseCase) PruneFileVersions(
+	ctx context.Context,
+	structID int,
+	keep int,
+	savePath string,
+	user *entity.User,
+) error {
+	driveStruct, err := uc.getUserFileStruct(ctx, structID, user)
+	if err != nil {
 		return err
 	}
+
+	versionIDs, err := uc.repositories.DriveFileRepository.GetOutdatedVersionIDs(ctx, driveStruct.ID, keep)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+
+	for _, versionID := range versionIDs {
+		if err = uc.deleteFileVersion(ctx, versionID, savePath); err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+	}
 	return nil
 }
 
-func (uc *driveUseCase) getFileSize(file multipart.File, maxSize int64) (int64, error) {
-	var size int64
-	// Если файл поддерживает Stat():
-	if statter, ok := file.(interface{ Stat() (os.FileInfo, error) }); ok {
-		if fi, err := statter.Stat(); err == nil {
-			size = fi.Size()
+// PruneVersions применяет политику хранения версий ко всем файлам: не больше maxCount
+// предыдущих версий и не старше maxAge. Нулевое значение отключает соответствующее ограничение
+func (uc *driveUseCase) PruneVersions(ctx context.Context, savePath string, maxCount int, maxAge time.Duration) (int, error) {
+	var replacedBefore *time.Time
+	if maxAge > 0 {
+		before := time.Now().UTC().Add(-maxAge)
+		replacedBefore = &before
+	}
+	if maxCount <= 0 && replacedBefore == nil {
+		return 0, nil
+	}
+
+	versionIDs, err := uc.repositories.DriveFileRepository.GetExpiredVersionIDs(ctx, maxCount, replacedBefore)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return 0, postgres.ErrUnexpectedDBError
+	}
+
+	for i, versionID := range versionIDs {
+		if err = uc.deleteFileVersion(ctx, versionID, savePath); err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return i, postgres.ErrUnexpectedDBError
 		}
 	}
+	return len(versionIDs), nil
+}
 
-	// Если не получилось через Stat() — считаем вручную через LimitedReader:
-	if size == 0 {
-		lr := io.LimitReader(file, maxSize+1)
-		n, err := io.Copy(io.Discard, lr)
+// ReapUploads удаляет чанковые загрузки, не завершенные и не получавшие чанков дольше ttl.
+// Возвращает количество удаленных загрузок и освобожденный в хранилище объем
+func (uc *driveUseCase) ReapUploads(ctx context.Context, savePath string, ttl time.Duration) (int, int64, error) {
+	list, err := uc.repositories.DriveFileRepository.GetAbandonedUploads(ctx, time.Now().UTC().Add(-ttl))
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return 0, 0, postgres.ErrUnexpectedDBError
+	}
+
+	var reclaimed int64
+	for i, driveFile := range list {
+		driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, driveFile.DriveStructID)
 		if err != nil {
-			return 0, err
+			logging.GetLogger(ctx).Error(err)
+			return i, reclaimed, postgres.ErrUnexpectedDBError
 		}
-		size = n
-	}
 
-	if seeker, ok := file.(io.Seeker); ok {
-		_, err := seeker.Seek(0, io.SeekStart)
+		chunksSize, err := uc.repositories.DriveFileChunkRepository.GetChunksSize(ctx, driveFile.ID)
 		if err != nil {
-			return 0, ErrFileResettingPointer
+			logging.GetLogger(ctx).Error(err)
+			return i, reclaimed, postgres.ErrUnexpectedDBError
 		}
-	} else {
-		return 0, ErrFileUnableToSeek
-	}
 
-	return size, nil
+		if err = uc.discardUpload(ctx, driveStruct, driveFile, savePath); err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return i, reclaimed, err
+		}
+		reclaimed += chunksSize
+	}
+	return len(list), reclaimed, nil
 }
 
-func (uc *driveUseCase) getFileSizeInReader(file io.Reader, maxSize int64) (int64, error) {
-	var size int64
+// TusUploadInfo возвращает текущее смещение и полный размер загрузки для HEAD-запроса tus
+func (uc *driveUseCase) TusUploadInfo(
+	ctx context.Context,
+	structID int,
+	useEncryption bool,
+	user *entity.User,
+) (*dto.DriveTusUpload, error) {
+	user, err := uc.entryOwner(ctx, structID, user)
+	if err != nil {
+		return nil, err
+	}
 
-	lr := io.LimitReader(file, maxSize+1)
-	n, err := io.Copy(io.Discard, lr)
+	_, driveFile, err := uc.getUserFile(ctx, structID, nil, user)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	size = n
 
-	if seeker, ok := file.(io.Seeker); ok {
-		_, err := seeker.Seek(0, io.SeekStart)
-		if err != nil {
-			return 0, ErrFileResettingPointer
-		}
-	} else {
-		return 0, ErrFileUnableToSeek
+	if driveFile.UploadState == uploadStateComplete {
+		size := uc.getPlainSize(driveFile.Size, driveFile.PlainSize, useEncryption)
+		return &dto.DriveTusUpload{StructID: structID, Offset: size, Length: size, Completed: true}, nil
 	}
 
-	return size, nil
+	offset, err := uc.repositories.DriveFileChunkRepository.GetChunksPlainSize(ctx, driveFile.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	length := offset
+	if driveFile.ExpectedSize != nil {
+		length = *driveFile.ExpectedSize
+	}
+	return &dto.DriveTusUpload{StructID: structID, Offset: offset, Length: length}, nil
 }
 
-func (uc *driveUseCase) checkParentOwner(ctx context.Context, parentID int, userID int) error {
-	parentStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, parentID)
+// TusAppend дописывает тело PATCH-запроса tus в конец загрузки. Тело разбивается на чанки
+// не больше 64 МБ, поэтому оборванный запрос можно продолжить с последнего сохраненного чанка.
+// Если передана контрольная сумма и она не совпала, все чанки этого запроса удаляются.
+// Когда смещение достигает полного размера, загрузка завершается как в ChunkEnd
+func (uc *driveUseCase) TusAppend(ctx context.Context, user *entity.User, in dto.DriveTusAppend) (*dto.DriveTusUpload, error) {
+	user, err := uc.entryOwner(ctx, in.StructID, user)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrDriveParentIdNotFound
-		}
-		return err
+		return nil, err
 	}
-	if parentStruct.UserID != userID {
-		return ErrDriveDirectoryExists
+
+	fileService := service.NewFile().FileService()
+
+	_, fileEntity, err := uc.getUserFile(ctx, in.StructID, nil, user)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if !fileEntity.IsChunk || fileEntity.UploadState != uploadStateUploading || fileEntity.ExpectedSize == nil {
+		return nil, ErrDriveUploadCompleted
+	}
+
+	chunks, err := uc.repositories.DriveFileChunkRepository.GetByFileID(ctx, fileEntity.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	var offset int64
+	nextNumber := 1
+	for _, fileChunk := range chunks {
+		offset += uc.getPlainSize(fileChunk.Size, fileChunk.PlainSize, in.UseEncryption)
+		nextNumber = fileChunk.ChunkNumber + 1
+	}
+
+	if in.Offset != offset {
+		return nil, ErrDriveUploadOffsetMismatch
+	}
+	if offset+in.ContentLength > *fileEntity.ExpectedSize {
+		return nil, ErrDriveFileSizeMismatch
+	}
+	if offset+in.ContentLength > in.MaxSizeBytes {
+		return nil, ErrDriveFileTooLarge
+	}
+
+	// квота считается по размеру в хранилище: при шифровании каждый фрагмент увеличивается отдельно
+	storedLength := in.ContentLength
+	if in.UseEncryption {
+		storedLength = 0
+		for remaining := in.ContentLength; remaining > 0; remaining -= min(remaining, 64<<20) {
+			storedLength += fileService.EncryptedSize(min(remaining, 64<<20))
+		}
+	}
+	if err = uc.checkStorageQuota(ctx, user.ID, storedLength, in.StorageMaxSizePerUser); err != nil {
+		return nil, err
+	}
+
+	body := in.Body
+	var hasher hash.Hash
+	if in.ChecksumAlgorithm != "" {
+		hasher, err = tus.NewChecksumHash(in.ChecksumAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		body = io.TeeReader(in.Body, hasher)
+	}
+
+	var saved []*entity.DriveFileChunk
+	cipher := newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring)
+	for remaining := in.ContentLength; remaining > 0; {
+		plainSize := min(remaining, 64<<20)
+		size := plainSize
+		if in.UseEncryption {
+			size = fileService.EncryptedSize(plainSize)
+		}
+
+		driveFileChunk, err := uc.saveChunk(
+			ctx, user.ID, fileEntity.ID, nextNumber, body, plainSize, size,
+			in.SavePath, cipher, in.StorageMaxSizePerUser,
+		)
+		if err != nil {
+			if hasher != nil {
+				uc.deleteChunks(ctx, user.ID, saved, in.SavePath)
+			}
+			return nil, err
+		}
+
+		saved = append(saved, driveFileChunk)
+		remaining -= plainSize
+		nextNumber++
+	}
+
+	if hasher != nil && !bytes.Equal(hasher.Sum(nil), in.Checksum) {
+		uc.deleteChunks(ctx, user.ID, saved, in.SavePath)
+		return nil, ErrDriveChecksumMismatch
+	}
+
+	result := &dto.DriveTusUpload{
+		StructID: in.StructID,
+		Offset:   offset + in.ContentLength,
+		Length:   *fileEntity.ExpectedSize,
+	}
+	if result.Offset == result.Length {
+		chunkEndIn := dto.DriveChunkEndIn{
+			StructID:      in.StructID,
+			SavePath:      in.SavePath,
+			UseEncryption: in.UseEncryption,
+			Keyring:       in.Keyring,
+		}
+		if err = uc.ChunkEnd(ctx, user, chunkEndIn); err != nil {
+			return nil, err
+		}
+		result.Completed = true
+	}
+	return result, nil
+}
+
+// TusTerminate отменяет незавершенную загрузку вместе с уже сохраненными чанками
+func (uc *driveUseCase) TusTerminate(ctx context.Context, structID int, savePath string, user *entity.User) error {
+	user, err := uc.entryOwner(ctx, structID, user)
+	if err != nil {
+		return err
+	}
+
+	driveStruct, driveFile, err := uc.getUserFile(ctx, structID, nil, user)
+	if err != nil {
+		return err
+	}
+	if driveFile.UploadState != uploadStateUploading {
+		return ErrDriveUploadCompleted
+	}
+
+	if err = uc.discardUpload(ctx, driveStruct, driveFile, savePath); err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+	return nil
+}
+
+// GetZip отдает выбранные структуры ZIP-архивом с сохранением относительных путей.
+// Состав архива и ограничения проверяются заранее, а сам архив пишется в поток по мере чтения:
+// чанковые файлы склеиваются, зашифрованные расшифровываются без буферизации файлов в памяти
+func (uc *driveUseCase) GetZip(ctx context.Context, in dto.DriveZipIn, user *entity.User) (*dto.FileResponse, error) {
+	var (
+		entries   []*zipEntry
+		totalSize int64
+		visited   = make(map[int]bool)
+		usedPaths = make(map[string]bool)
+		names     = make([]string, 0, len(in.StructIDs))
+	)
+
+	for _, structID := range in.StructIDs {
+		if visited[structID] {
+			continue
+		}
+
+		rootEntries, err := uc.collectZipEntries(ctx, structID, user, visited, usedPaths, in.UseEncryption)
+		if err != nil {
+			return nil, err
+		}
+		if len(rootEntries) > 0 {
+			names = append(names, rootEntries[0].path)
+		}
+
+		for _, entry := range rootEntries {
+			totalSize += entry.size
+		}
+		entries = append(entries, rootEntries...)
+
+		if in.MaxEntries > 0 && len(entries) > in.MaxEntries {
+			return nil, ErrDriveZipTooManyEntries
+		}
+		if in.MaxSizeBytes > 0 && totalSize > in.MaxSizeBytes {
+			return nil, ErrDriveZipTooLarge
+		}
+	}
+
+	archiveName := "drive.zip"
+	if len(names) == 1 {
+		archiveName = strings.TrimSuffix(names[0], "/") + ".zip"
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		err := uc.writeZip(ctx, pipeWriter, entries, in)
+		if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+			logging.GetLogger(ctx).Error(err)
+		}
+		_ = pipeWriter.CloseWithError(err)
+	}()
+
+	return &dto.FileResponse{
+		File:             pipeReader,
+		OriginalFilename: archiveName,
+		SizeBytes:        -1,
+	}, nil
+}
+
+func (uc *driveUseCase) HashExists(ctx context.Context, hash string, user *entity.User) (bool, error) {
+	blob, err := uc.findUserBlob(ctx, user.ID, normalizeSHA256(&hash))
+	if err != nil {
+		return false, err
+	}
+	return blob != nil, nil
+}
+
+// UploadByHash создает файл из уже хранящегося у пользователя содержимого без передачи байт
+func (uc *driveUseCase) UploadByHash(ctx context.Context, in dto.DriveUploadByHash, user *entity.User) ([]*dto.DriveTree, error) {
+	owner, err := uc.parentOwner(ctx, in.ParentID, user, DriveRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	// файл ссылается на блоб владельца директории. Байты не передаются, поэтому в чужой директории
+	// содержимое должно быть и у самого пользователя, иначе по хэшу можно получить чужие файлы
+	hash := normalizeSHA256(&in.SHA256)
+	if owner.ID != user.ID {
+		userBlob, err := uc.findUserBlob(ctx, user.ID, hash)
+		if err != nil {
+			return nil, err
+		}
+		if userBlob == nil {
+			return nil, ErrDriveBlobNotFound
+		}
+		user = owner
+	}
+
+	blob, err := uc.findUserBlob(ctx, user.ID, hash)
+	if err != nil {
+		return nil, err
+	}
+	if blob == nil {
+		return nil, ErrDriveBlobNotFound
+	}
+
+	fileExt := strings.ToLower(filepath.Ext(in.Filename))
+	fileExt = strings.TrimPrefix(fileExt, ".")
+
+	safeName := filepath.Base(in.Filename)
+	if strings.Contains(safeName, "..") {
+		return nil, ErrDriveFileNotSafeFilename
+	}
+
+	existingStruct, err := uc.findReplaceableFile(ctx, user.ID, in.Filename, in.ParentID, in.Replace)
+	if err != nil {
+		return nil, err
+	}
+
+	// содержимое не передается, поэтому тип берется у уже загруженного файла с тем же блобом
+	mimeType, err := uc.repositories.DriveFileRepository.GetMimeTypeByBlobID(ctx, blob.ID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+		detected := service.NewFile().FileService().DetectMimeType(nil, fileExt)
+		mimeType = &detected
+	}
+
+	driveFile := &entity.DriveFile{
+		Ext:         fileExt,
+		CreatedAt:   time.Now().UTC(),
+		IsChunk:     false,
+		IsCurrent:   true,
+		UploadState: uploadStateComplete,
+		MimeType:    mimeType,
+	}
+
+	// блоб уже существует, поэтому место не расходуется и лимит не проверяется
+	_, err = uc.attachFile(ctx, user, in.Filename, in.ParentID, existingStruct, driveFile, blob, 0)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	treeList, err := uc.GetTree(ctx, in.ParentID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return treeList, nil
+}
+
+func (uc *driveUseCase) getFileSize(file multipart.File, maxSize int64) (int64, error) {
+	var size int64
+	// Если файл поддерживает Stat():
+	if statter, ok := file.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if fi, err := statter.Stat(); err == nil {
+			size = fi.Size()
+		}
+	}
+
+	// Если не получилось через Stat() — считаем вручную через LimitedReader:
+	if size == 0 {
+		lr := io.LimitReader(file, maxSize+1)
+		n, err := io.Copy(io.Discard, lr)
+		if err != nil {
+			return 0, err
+		}
+		size = n
+	}
+
+	if seeker, ok := file.(io.Seeker); ok {
+		_, err := seeker.Seek(0, io.SeekStart)
+		if err != nil {
+			return 0, ErrFileResettingPointer
+		}
+	} else {
+		return 0, ErrFileUnableToSeek
+	}
+
+	return size, nil
+}
+
+// getPlainSize возвращает размер исходного файла. Запись без plain_size при включенном шифровании
+// считается файлом старого формата (весь файл одним блоком GCM), как и в driveCipher.encrypted,
+// поэтому размер в HEAD, Range и при отдаче файла целиком совпадает
+func (uc *driveUseCase) getPlainSize(storedSize int64, plainSize *int64, useEncryption bool) int64 {
+	if plainSize != nil {
+		return *plainSize
+	}
+	if useEncryption {
+		fileService := service.NewFile().FileService()
+		return fileService.DecryptedSize(storedSize)
+	}
+	return storedSize
+}
+
+// getTrashRoot возвращает элемент, который был удален в корзину пользователем напрямую
+func (uc *driveUseCase) getTrashRoot(ctx context.Context, structID int, user *entity.User) (*entity.DriveStruct, error) {
+	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDriveTrashItemNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if driveStruct.UserID != user.ID || driveStruct.TrashRootID == nil || *driveStruct.TrashRootID != driveStruct.ID {
+		return nil, ErrDriveTrashItemNotFound
+	}
+	return driveStruct, nil
+}
+
+// getFreeName подбирает имя, не занятое в директории parentID: "name.ext", "name (1).ext", ...
+func (uc *driveUseCase) getFreeName(ctx context.Context, userID int, name string, rowType int8, parentID *int) (string, error) {
+	ext := ""
+	if rowType == typeFile {
+		ext = filepath.Ext(name)
+	}
+	base := strings.TrimSuffix(name, ext)
+
+	candidate := name
+	for i := 1; i <= 1000; i++ {
+		_, err := uc.repositories.DriveStructRepository.FindRow(ctx, userID, candidate, rowType, parentID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return "", postgres.ErrUnexpectedDBError
+		}
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	return "", ErrDriveFilenameExists
+}
+
+// purge окончательно удаляет структуру со всем содержимым: файлы и чанки в хранилище и записи в БД
+func (uc *driveUseCase) purge(ctx context.Context, userID int, structID int, savePath string) error {
+	deleteChunkList, err := uc.repositories.DriveFileChunkRepository.GetAllRecursive(ctx, structID, userID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+	}
+
+	deleteFileList, err := uc.repositories.DriveFileRepository.GetAllRecursive(ctx, structID, userID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+	}
+
+	var (
+		keys  []string
+		freed int64
+	)
+	for _, fileChunk := range deleteChunkList {
+		keys = append(keys, filepath.Join(savePath, fileChunk.Path))
+		freed += fileChunk.Size
+	}
+
+	blobRefs := make(map[int]int)
+	var thumbnailFileIDs []int
+	for _, file := range deleteFileList {
+		if service.NewFile().ThumbnailService().Supports(file.Ext) {
+			thumbnailFileIDs = append(thumbnailFileIDs, file.ID)
+		}
+		if file.BlobID != nil {
+			blobRefs[*file.BlobID]++
+		} else if !file.IsChunk {
+			keys = append(keys, filepath.Join(savePath, *file.Path))
+			freed += file.Size
+		}
+	}
+
+	err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		repositoriesTx := uc.repositories.WithTx(tx)
+
+		// удаление записей из БД из трех таблиц (через cascade fk)
+		err := repositoriesTx.DriveStructRepository.DeleteRecursive(ctx, userID, structID)
+		if err != nil {
+			return err
+		}
+
+		blobKeys, blobsFreed, err := uc.releaseBlobs(ctx, repositoriesTx.DriveBlobRepository, blobRefs, savePath)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, blobKeys...)
+		return repositoriesTx.StorageUsageRepository.Add(ctx, userID, -(freed + blobsFreed), 0)
+	})
+	if err != nil {
+		return err
+	}
+
+	// объекты хранилища удаляются только после фиксации транзакции
+	if len(keys) > 0 {
+		_ = uc.repositories.StorageRepository.DeleteAll(ctx, keys)
+	}
+	deleteThumbnails(ctx, uc.repositories, savePath, thumbnailKindDrive, thumbnailFileIDs)
+
+	return nil
+}
+
+// releaseBlobs уменьшает счетчики ссылок блобов и удаляет блобы без ссылок, возвращая их пути в хранилище
+// и освобожденный ими объем
+func (uc *driveUseCase) releaseBlobs(
+	ctx context.Context,
+	blobRepo repository.DriveBlobRepository,
+	blobRefs map[int]int,
+	savePath string,
+) ([]string, int64, error) {
+	var (
+		keys  []string
+		freed int64
+	)
+	for blobID, count := range blobRefs {
+		blob, err := blobRepo.DecrementRef(ctx, blobID, count)
+		if err != nil {
+			return nil, 0, err
+		}
+		if blob.RefCount > 0 {
+			continue
+		}
+		if err = blobRepo.Delete(ctx, blob.ID); err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, filepath.Join(savePath, blob.Path))
+		freed += blob.Size
+	}
+	return keys, freed, nil
+}
+
+// getUserFile возвращает структуру и файл пользователя по ID структуры.
+// Если versionID не задан, возвращается текущая версия файла
+func (uc *driveUseCase) getUserFile(
+	ctx context.Context,
+	structID int,
+	versionID *int,
+	user *entity.User,
+) (*entity.DriveStruct, *entity.DriveFile, error) {
+	driveStruct, err := uc.getUserFileStruct(ctx, structID, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var driveFile *entity.DriveFile
+	if versionID != nil {
+		driveFile, err = uc.repositories.DriveFileRepository.GetByID(ctx, *versionID)
+		if err == nil && driveFile.DriveStructID != driveStruct.ID {
+			return nil, nil, ErrFileNotFound
+		}
+	} else {
+		driveFile, err = uc.repositories.DriveFileRepository.GetByStructID(ctx, driveStruct.ID)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrFileNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, nil, postgres.ErrUnexpectedDBError
+	}
+	return driveStruct, driveFile, nil
+}
+
+// getUserFileStruct возвращает структуру типа "файл", принадлежащую пользователю и не находящуюся в корзине
+func (uc *driveUseCase) getUserFileStruct(ctx context.Context, structID int, user *entity.User) (*entity.DriveStruct, error) {
+	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFileNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if driveStruct.UserID != user.ID || driveStruct.DeletedAt != nil {
+		return nil, ErrFileNotFound
+	}
+	if driveStruct.Type != typeFile {
+		return nil, ErrFileNotFound
+	}
+	return driveStruct, nil
+}
+
+// resolveUploadPath создает недостающие директории пути загрузки относительно parentID
+// и возвращает директорию и имя файла
+func (uc *driveUseCase) resolveUploadPath(
+	ctx context.Context,
+	user *entity.User,
+	parentID *int,
+	path string,
+) (*int, string, error) {
+	names, err := splitDrivePath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(names) == 0 {
+		return nil, "", ErrDrivePathInvalid
+	}
+
+	dirID, err := uc.ensureDirectories(ctx, user, parentID, names[:len(names)-1], make(map[string]*int), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	return dirID, names[len(names)-1], nil
+}
+
+// ensureDirectories возвращает id директории по пути относительно rootID, создавая недостающие (как mkdir -p).
+// directories кеширует уже найденные пути, created вызывается для каждой созданной директории
+func (uc *driveUseCase) ensureDirectories(
+	ctx context.Context,
+	user *entity.User,
+	rootID *int,
+	parts []string,
+	directories map[string]*int,
+	created func(dirPath string),
+) (*int, error) {
+	parentID := rootID
+	for i, name := range parts {
+		dirPath := strings.Join(parts[:i+1], "/")
+		if cachedID, ok := directories[dirPath]; ok {
+			parentID = cachedID
+			continue
+		}
+
+		existing, err := uc.repositories.DriveStructRepository.FindRow(ctx, user.ID, name, typeDirectory, parentID)
+		if err == nil {
+			parentID = &existing.ID
+			directories[dirPath] = parentID
+			continue
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+
+		createEntity := &entity.DriveStruct{
+			UserID:    user.ID,
+			Name:      name,
+			Type:      typeDirectory,
+			ParentID:  parentID,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+		}
+		if _, err = uc.repositories.DriveStructRepository.Create(ctx, createEntity); err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+
+		parentID = &createEntity.ID
+		directories[dirPath] = parentID
+		if created != nil {
+			created(dirPath)
+		}
+	}
+	return parentID, nil
+}
+
+// findReplaceableFile ищет файл с таким же именем в директории. Без режима замены совпадение имени - ошибка
+func (uc *driveUseCase) findReplaceableFile(
+	ctx context.Context,
+	userID int,
+	name string,
+	parentID *int,
+	replace bool,
+) (*entity.DriveStruct, error) {
+	existingStruct, err := uc.repositories.DriveStructRepository.FindRow(ctx, userID, name, typeFile, parentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if !replace {
+		return nil, ErrDriveFilenameExists
+	}
+	return existingStruct, nil
+}
+
+// attachFile в одной транзакции сохраняет запись о файле: новую структуру либо, в режиме замены,
+// новую текущую версию существующей. Если передан blob, файл ссылается на него (блоб с ID = 0 создается,
+// и его размер учитывается в квоте; storageLimit - лимит диска из конфигурации)
+func (uc *driveUseCase) attachFile(
+	ctx context.Context,
+	user *entity.User,
+	name string,
+	parentID *int,
+	existingStruct *entity.DriveStruct,
+	driveFile *entity.DriveFile,
+	blob *entity.DriveBlob,
+	storageLimit int64,
+	pendingKeys ...string,
+) (*entity.DriveStruct, error) {
+	now := time.Now().UTC()
+
+	return repository.WithTransactionResult(
+		ctx,
+		uc.repositories.TransactionRepository,
+		func(tx pgx.Tx) (*entity.DriveStruct, error) {
+			repositoriesTx := uc.repositories.WithTx(tx)
+			driveStructRepoTx := repositoriesTx.DriveStructRepository
+			driveFileRepoTx := repositoriesTx.DriveFileRepository
+
+			if blob != nil {
+				blobRepoTx := repositoriesTx.DriveBlobRepository
+				if blob.ID == 0 {
+					if err := uc.reserveStorage(ctx, repositoriesTx, blob.UserID, blob.Size, storageLimit); err != nil {
+						return nil, err
+					}
+					blob.RefCount = 1
+					if _, err := blobRepoTx.Create(ctx, blob); err != nil {
+						return nil, err
+					}
+				} else if err := blobRepoTx.IncrementRef(ctx, blob.ID); err != nil {
+					return nil, err
+				}
+				driveFile.BlobID = &blob.ID
+				driveFile.Path = &blob.Path
+				driveFile.Size = blob.Size
+				driveFile.PlainSize = &blob.PlainSize
+				driveFile.SHA256 = &blob.SHA256
+				driveFile.DataKeyID = blob.DataKeyID
+			}
+
+			driveStruct := existingStruct
+			if driveStruct != nil {
+				// режим замены: текущий файл становится предыдущей версией
+				if err := driveFileRepoTx.UnsetCurrent(ctx, driveStruct.ID, now); err != nil {
+					return nil, err
+				}
+				driveStruct.UpdatedAt = now
+				if err := driveStructRepoTx.Update(ctx, driveStruct); err != nil {
+					return nil, err
+				}
+			} else {
+				driveStruct = &entity.DriveStruct{
+					UserID:    user.ID,
+					Name:      name,
+					Type:      typeFile,
+					ParentID:  parentID,
+					CreatedAt: now,
+					UpdatedAt: now,
+				}
+				if _, err := driveStructRepoTx.Create(ctx, driveStruct); err != nil {
+					return nil, err
+				}
+			}
+
+			driveFile.DriveStructID = driveStruct.ID
+			if _, err := driveFileRepoTx.Create(ctx, driveFile); err != nil {
+				return nil, err
+			}
+			if err := commitStorageWrite(ctx, repositoriesTx, pendingKeys...); err != nil {
+				return nil, err
+			}
+			return driveStruct, nil
+		})
+}
+
+// saveBlob сохраняет содержимое в хранилище, параллельно вычисляя SHA-256 исходных байт.
+// Возвращает еще не записанный в БД блоб (ID = 0) либо уже существующий блоб с тем же хэшем
+func (uc *driveUseCase) saveBlob(
+	ctx context.Context,
+	in dto.DriveUploadFile,
+	user *entity.User,
+	fileExt string,
+	plainSize int64,
+	size int64,
+	clientHash string,
+) (*entity.DriveBlob, error) {
+	fileService := service.NewFile().FileService()
+
+	middleFilePath, err := fileService.GenerateNewFilePath(fileExt)
+	if err != nil {
+		return nil, err
+	}
+	fullFilePath := filepath.Join(in.SavePath, middleFilePath)
+
+	hasher := sha256.New()
+	plainReader := io.TeeReader(io.LimitReader(in.File, plainSize), hasher)
+
+	cipher := newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring)
+	fileReader, dataKeyID, err := cipher.encrypt(ctx, plainReader, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = beginStorageWrite(ctx, uc.repositories, fullFilePath); err != nil {
+		return nil, err
+	}
+
+	saveDto := &dto.SaveFile{
+		File:      fileReader,
+		SavePath:  fullFilePath,
+		SizeBytes: size,
+	}
+
+	saveErr := uc.repositories.StorageRepository.Save(ctx, saveDto)
+	if saveErr != nil {
+		abortStorageWrite(ctx, uc.repositories, fullFilePath)
+		return nil, ErrDriveFileSave
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	if clientHash != "" && clientHash != hash {
+		abortStorageWrite(ctx, uc.repositories, fullFilePath)
+		return nil, ErrDriveFileHashMismatch
+	}
+
+	if clientHash == "" {
+		existingBlob, err := uc.findUserBlob(ctx, user.ID, hash)
+		if err != nil {
+			abortStorageWrite(ctx, uc.repositories, fullFilePath)
+			return nil, err
+		}
+		if existingBlob != nil {
+			abortStorageWrite(ctx, uc.repositories, fullFilePath)
+			return existingBlob, nil
+		}
+	}
+
+	return &entity.DriveBlob{
+		UserID:    user.ID,
+		SHA256:    hash,
+		Path:      middleFilePath,
+		Size:      size,
+		PlainSize: plainSize,
+		CreatedAt: time.Now().UTC(),
+		DataKeyID: dataKeyID,
+	}, nil
+}
+
+// findUserBlob ищет блоб среди блобов владельца диска: содержимое дедуплицируется только в пределах
+// одного пользователя, поэтому в чужой директории по гранту ищется блоб владельца
+func (uc *driveUseCase) findUserBlob(ctx context.Context, userID int, hash string) (*entity.DriveBlob, error) {
+	blob, err := uc.repositories.DriveBlobRepository.GetByHash(ctx, userID, hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return blob, nil
+}
+
+func (uc *driveUseCase) hashReader(reader io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// deleteFileVersion удаляет предыдущую версию файла из БД (чанки удаляются через cascade fk) и из хранилища
+func (uc *driveUseCase) deleteFileVersion(ctx context.Context, fileID int, savePath string) error {
+	driveFile, err := uc.repositories.DriveFileRepository.GetByID(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if driveFile.IsCurrent {
+		return nil
+	}
+
+	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, driveFile.DriveStructID)
+	if err != nil {
+		return err
+	}
+	return uc.deleteFileRecord(ctx, driveStruct.UserID, driveFile, nil, savePath)
+}
+
+// discardUpload отменяет загруженную версию файла. Если других версий нет, удаляется и сама структура
+func (uc *driveUseCase) discardUpload(
+	ctx context.Context,
+	driveStruct *entity.DriveStruct,
+	driveFile *entity.DriveFile,
+	savePath string,
+) error {
+	versions, err := uc.repositories.DriveFileRepository.GetVersions(ctx, driveStruct.ID)
+	if err != nil {
+		return err
+	}
+	if len(versions) <= 1 {
+		return uc.purge(ctx, driveStruct.UserID, driveStruct.ID, savePath)
+	}
+
+	// первой идет текущая версия, второй - последняя замененная
+	return uc.deleteFileRecord(ctx, driveStruct.UserID, driveFile, &versions[1].ID, savePath)
+}
+
+// deleteFileRecord удаляет запись файла вместе с чанками и освобождает блоб.
+// Если задан makeCurrentID, в той же транзакции эта версия становится текущей
+func (uc *driveUseCase) deleteFileRecord(
+	ctx context.Context,
+	userID int,
+	driveFile *entity.DriveFile,
+	makeCurrentID *int,
+	savePath string,
+) error {
+	chunks, err := uc.repositories.DriveFileChunkRepository.GetByFileID(ctx, driveFile.ID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		keys  []string
+		freed int64
+	)
+	for _, fileChunk := range chunks {
+		keys = append(keys, filepath.Join(savePath, fileChunk.Path))
+		freed += fileChunk.Size
+	}
+
+	blobRefs := make(map[int]int)
+	if driveFile.BlobID != nil {
+		blobRefs[*driveFile.BlobID]++
+	} else if !driveFile.IsChunk && driveFile.Path != nil {
+		keys = append(keys, filepath.Join(savePath, *driveFile.Path))
+		freed += driveFile.Size
+	}
+
+	err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		repositoriesTx := uc.repositories.WithTx(tx)
+		driveFileRepoTx := repositoriesTx.DriveFileRepository
+
+		if err := driveFileRepoTx.DeleteByID(ctx, driveFile.ID); err != nil {
+			return err
+		}
+		if makeCurrentID != nil {
+			if err := driveFileRepoTx.MarkCurrent(ctx, *makeCurrentID); err != nil {
+				return err
+			}
+		}
+
+		blobKeys, blobsFreed, err := uc.releaseBlobs(ctx, repositoriesTx.DriveBlobRepository, blobRefs, savePath)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, blobKeys...)
+		return repositoriesTx.StorageUsageRepository.Add(ctx, userID, -(freed + blobsFreed), 0)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		_ = uc.repositories.StorageRepository.DeleteAll(ctx, keys)
+	}
+	if service.NewFile().ThumbnailService().Supports(driveFile.Ext) {
+		deleteThumbnails(ctx, uc.repositories, savePath, thumbnailKindDrive, []int{driveFile.ID})
+	}
+	return nil
+}
+
+// getChunkedFile отдает чанковый файл одним потоком, последовательно читая и расшифровывая чанки
+func (uc *driveUseCase) getChunkedFile(
+	ctx context.Context,
+	in *dto.GetFile,
+	driveStruct *entity.DriveStruct,
+	driveFile *entity.DriveFile,
+) (*dto.FileResponse, error) {
+	chunks, err := uc.repositories.DriveFileChunkRepository.GetByFileID(ctx, driveFile.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	var (
+		offset   int64
+		length   int64 = -1
+		realSize       = uc.getPlainSize(driveFile.Size, driveFile.PlainSize, in.UseEncryption)
+		cipher         = newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring)
+	)
+	if in.Range != nil {
+		offset = in.Range.Offset
+		length = in.Range.Length
+		realSize = in.Range.Length
+	}
+
+	return &dto.FileResponse{
+		File:             uc.openChunks(ctx, chunks, in.SavePath, cipher, offset, length),
+		OriginalFilename: driveStruct.Name,
+		SizeBytes:        realSize,
+	}, nil
+}
+
+// checkStorageQuota проверяет, что после добавления extra байт пользователь уложится в свой лимит диска.
+// Это предварительная проверка до записи в хранилище, окончательно место резервирует reserveStorage.
+// defaultLimit - лимит из конфигурации для пользователей без персональной квоты и тарифа
+func (uc *driveUseCase) checkStorageQuota(ctx context.Context, userID int, extra int64, defaultLimit int64) error {
+	limits, err := getStorageLimits(ctx, uc.repositories, userID, dto.StorageLimits{DriveLimit: defaultLimit})
+	if err != nil {
+		return err
+	}
+
+	usage, err := getStorageUsage(ctx, uc.repositories, userID)
+	if err != nil {
+		return err
+	}
+
+	if usage.DriveUsed+extra > limits.DriveLimit {
+		return ErrDriveFileSystemIsFull
+	}
+	return nil
+}
+
+// reserveStorage в транзакции репозиториев repositoriesTx учитывает size новых байт на диске пользователя,
+// если они укладываются в лимит
+func (uc *driveUseCase) reserveStorage(
+	ctx context.Context,
+	repositoriesTx *repository.Repositories,
+	userID int,
+	size int64,
+	defaultLimit int64,
+) error {
+	limits, err := getStorageLimits(ctx, uc.repositories, userID, dto.StorageLimits{DriveLimit: defaultLimit})
+	if err != nil {
+		return err
+	}
+	return reserveStorage(ctx, repositoriesTx, userID, size, 0, limits)
+}
+
+// getMimeType возвращает MIME-тип, определенный при загрузке. Для файлов, загруженных
+// до появления mime_type, тип определяется по расширению
+func (uc *driveUseCase) getMimeType(driveFile *entity.DriveFile) string {
+	if driveFile.MimeType != nil && *driveFile.MimeType != "" {
+		return *driveFile.MimeType
+	}
+	return service.NewFile().FileService().DetectMimeType(nil, driveFile.Ext)
+}
+
+// getFileETag строит ETag по sha256 файла, а при его отсутствии - по неизменяемым атрибутам записи
+func (uc *driveUseCase) getFileETag(driveFile *entity.DriveFile) string {
+	if driveFile.SHA256 != nil && *driveFile.SHA256 != "" && !strings.ContainsAny(*driveFile.SHA256, "\"\r\n") {
+		return fmt.Sprintf("\"%s\"", *driveFile.SHA256)
+	}
+	return fmt.Sprintf("\"%d-%d-%d\"", driveFile.ID, driveFile.Size, driveFile.CreatedAt.Unix())
+}
+
+func (uc *driveUseCase) checkParentOwner(ctx context.Context, parentID int, userID int) error {
+	parentStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDriveParentIdNotFound
+		}
+		return err
+	}
+	if parentStruct.UserID != userID {
+		return ErrDriveDirectoryExists
+	}
+	if parentStruct.DeletedAt != nil {
+		return ErrDriveParentIdNotFound
+	}
+	return nil
+}
+
+// openChunks возвращает поток, последовательно читающий (и расшифровывающий) length байт
+// собранного из чанков файла начиная с offset, length < 0 - до конца файла
+func (uc *driveUseCase) openChunks(
+	ctx context.Context,
+	chunks []*entity.DriveFileChunk,
+	savePath string,
+	cipher *driveCipher,
+	offset int64,
+	length int64,
+) io.ReadCloser {
+	parts := make([]chunkPart, 0, len(chunks))
+	for _, fileChunk := range chunks {
+		if length == 0 {
+			break
+		}
+		encrypted := cipher.encrypted(fileChunk.DataKeyID, fileChunk.Size, fileChunk.PlainSize)
+		chunkSize := uc.getPlainSize(fileChunk.Size, fileChunk.PlainSize, encrypted)
+		if offset >= chunkSize {
+			offset -= chunkSize
+			continue
+		}
+
+		partLength := chunkSize - offset
+		if length > 0 && partLength > length {
+			partLength = length
+		}
+		parts = append(parts, chunkPart{
+			path:      filepath.Join(savePath, fileChunk.Path),
+			offset:    offset,
+			length:    partLength,
+			whole:     offset == 0 && partLength == chunkSize,
+			encrypted: encrypted,
+			dataKeyID: fileChunk.DataKeyID,
+		})
+
+		if length > 0 {
+			length -= partLength
+		}
+		offset = 0
+	}
+
+	return &chunkedFileReader{
+		parts: parts,
+		open: func(part chunkPart) (io.Reader, error) {
+			if part.whole {
+				fileReader, err := uc.repositories.StorageRepository.GetFile(ctx, part.path)
+				if err != nil || !part.encrypted {
+					return fileReader, err
+				}
+				decrypted, err := cipher.decryptObject(ctx, fileReader, part.dataKeyID)
+				if err != nil {
+					closeReader(fileReader)
+					return nil, err
+				}
+				return decrypted, nil
+			}
+
+			if !part.encrypted {
+				return uc.repositories.StorageRepository.GetFileRange(ctx, part.path, part.offset, part.length)
+			}
+			openRange := func(offset int64, length int64) (io.Reader, error) {
+				return uc.repositories.StorageRepository.GetFileRange(ctx, part.path, offset, length)
+			}
+			return cipher.decryptRange(ctx, openRange, part.dataKeyID, part.offset, part.length)
+		},
+	}
+}
+
+// chunkPart - часть чанка, попадающая в читаемый диапазон файла
+type chunkPart struct {
+	path      string
+	offset    int64
+	length    int64
+	whole     bool
+	encrypted bool
+	dataKeyID *int
+}
+
+// chunkedFileReader открывает следующий чанк только после полного чтения предыдущего
+type chunkedFileReader struct {
+	parts   []chunkPart
+	open    func(part chunkPart) (io.Reader, error)
+	current io.Reader
+}
+
+func (r *chunkedFileReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			current, err := r.open(r.parts[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current = current
+			r.parts = r.parts[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			closeReader(r.current)
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkedFileReader) Close() error {
+	if r.current != nil {
+		closeReader(r.current)
+		r.current = nil
+	}
+	return nil
+}
+
+// zipEntry - элемент ZIP-архива: директория или текущая версия файла
+type zipEntry struct {
+	path     string
+	dir      bool
+	modified time.Time
+	size     int64
+	file     *entity.DriveFile
+	chunks   []*entity.DriveFileChunk
+}
+
+// collectZipEntries обходит дерево структуры и возвращает элементы архива, корень идет первым.
+// Незавершенные загрузки пропускаются, уже попавшие в архив структуры отмечаются в visited.
+// Структура, доступная пользователю по приглашению, обходится от имени ее владельца
+func (uc *driveUseCase) collectZipEntries(
+	ctx context.Context,
+	structID int,
+	user *entity.User,
+	visited map[int]bool,
+	usedPaths map[string]bool,
+	useEncryption bool,
+) ([]*zipEntry, error) {
+	user, err := uc.structOwner(ctx, structID, user, DriveRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDriveStructNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if root.UserID != user.ID || root.DeletedAt != nil {
+		return nil, ErrDriveStructNotFound
+	}
+
+	structs, err := uc.repositories.DriveStructRepository.GetAllRecursive(ctx, user.ID, structID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	files, err := uc.repositories.DriveFileRepository.GetAllRecursive(ctx, structID, user.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	chunks, err := uc.repositories.DriveFileChunkRepository.GetAllRecursive(ctx, structID, user.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	currentFiles := make(map[int]*entity.DriveFile)
+	for _, driveFile := range files {
+		if driveFile.IsCurrent {
+			currentFiles[driveFile.DriveStructID] = driveFile
+		}
+	}
+	fileChunks := make(map[int][]*entity.DriveFileChunk)
+	for _, fileChunk := range chunks {
+		fileChunks[fileChunk.DriveFileID] = append(fileChunks[fileChunk.DriveFileID], fileChunk)
+	}
+
+	// корень называется по имени структуры, при совпадении имен разных корней добавляется суффикс
+	rootName := root.Name
+	for i := 2; usedPaths[rootName]; i++ {
+		rootName = fmt.Sprintf("%s (%d)", root.Name, i)
+	}
+	usedPaths[rootName] = true
+
+	paths := map[int]string{root.ID: rootName}
+	entries := make([]*zipEntry, 0, len(structs))
+	// запрос возвращает родителя раньше потомков, поэтому путь родителя уже известен
+	for _, driveStruct := range structs {
+		if driveStruct.DeletedAt != nil || visited[driveStruct.ID] {
+			continue
+		}
+		if driveStruct.ID != root.ID {
+			parentPath, ok := paths[*driveStruct.ParentID]
+			if !ok {
+				continue
+			}
+			paths[driveStruct.ID] = parentPath + "/" + driveStruct.Name
+		}
+		visited[driveStruct.ID] = true
+
+		entry := &zipEntry{path: paths[driveStruct.ID], modified: driveStruct.UpdatedAt}
+		if driveStruct.Type == typeDirectory {
+			entry.dir = true
+			entry.path += "/"
+			entries = append(entries, entry)
+			continue
+		}
+
+		driveFile, ok := currentFiles[driveStruct.ID]
+		if !ok || driveFile.UploadState != uploadStateComplete {
+			continue
+		}
+		entry.file = driveFile
+		entry.size = uc.getPlainSize(driveFile.Size, driveFile.PlainSize, useEncryption)
+		if driveFile.IsChunk {
+			entry.chunks = fileChunks[driveFile.ID]
+			sort.Slice(entry.chunks, func(i, j int) bool {
+				return entry.chunks[i].ChunkNumber < entry.chunks[j].ChunkNumber
+			})
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// writeZip последовательно пишет элементы архива в w
+func (uc *driveUseCase) writeZip(ctx context.Context, w io.Writer, entries []*zipEntry, in dto.DriveZipIn) error {
+	cipher := newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring)
+	zipWriter := zip.NewWriter(w)
+
+	for _, entry := range entries {
+		header := &zip.FileHeader{
+			Name:     entry.path,
+			Method:   zip.Deflate,
+			Modified: entry.modified,
+		}
+		if entry.dir {
+			header.Method = zip.Store
+		}
+
+		entryWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if entry.dir {
+			continue
+		}
+
+		var fileReader io.Reader
+		if entry.file.IsChunk {
+			fileReader = uc.openChunks(ctx, entry.chunks, in.SavePath, cipher, 0, -1)
+		} else {
+			fileReader, err = uc.repositories.StorageRepository.GetFile(ctx, filepath.Join(in.SavePath, *entry.file.Path))
+			if err != nil {
+				return err
+			}
+			decrypted, err := cipher.decrypt(ctx, fileReader, entry.file.DataKeyID, entry.file.Size, entry.file.PlainSize)
+			if err != nil {
+				closeReader(fileReader)
+				return fmt.Errorf("%w: %w", ErrDriveDecrypting, err)
+			}
+			fileReader = decrypted
+		}
+
+		_, err = io.Copy(entryWriter, fileReader)
+		closeReader(fileReader)
+		if err != nil {
+			return err
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+// deleteChunks удаляет чанки из БД и хранилища, ошибки только логируются
+func (uc *driveUseCase) deleteChunks(ctx context.Context, userID int, chunks []*entity.DriveFileChunk, savePath string) {
+	var keys []string
+	for _, fileChunk := range chunks {
+		err := repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+			repositoriesTx := uc.repositories.WithTx(tx)
+
+			if err := repositoriesTx.DriveFileChunkRepository.DeleteByID(ctx, fileChunk.ID); err != nil {
+				return err
+			}
+			return repositoriesTx.StorageUsageRepository.Add(ctx, userID, -fileChunk.Size, 0)
+		})
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			continue
+		}
+		keys = append(keys, filepath.Join(savePath, fileChunk.Path))
+	}
+	if len(keys) > 0 {
+		_ = uc.repositories.StorageRepository.DeleteAll(ctx, keys)
+	}
+}
+
+// countingReader считает прочитанные байты
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+func closeReader(reader io.Reader) {
+	if closer, ok := reader.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
+// normalizeSHA256 приводит хэш, присланный клиентом, к виду hex в нижнем регистре
+func normalizeSHA256(hash *string) string {
+	if hash == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(*hash))
+}
+
+// splitDrivePath разбивает путь на имена элементов. Пустые сегменты и "." пропускаются, ".." запрещен
+func splitDrivePath(path string) ([]string, error) {
+	names := make([]string, 0)
+	for _, name := range strings.Split(path, "/") {
+		switch {
+		case name == "" || name == ".":
+			continue
+		case name == "..":
+			return nil, ErrDrivePathInvalid
+		case len(name) > 300 || strings.ContainsAny(name, "\x00\r\n"):
+			return nil, ErrDrivePathInvalid
+		}
+		names = append(names, name)
+	}
+	return names, nil
 }
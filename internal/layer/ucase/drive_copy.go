@@ -0,0 +1,394 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	service "assistant-go/internal/layer/service/file"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"path/filepath"
+	"time"
+)
+
+var ErrDriveCopyIntoOneself = errors.New("drive copy into oneself")
+
+// copyEntry - элемент копируемого поддерева. Для файла blob - блоб, на который будет ссылаться копия
+// без записи байт (shareBlob), либо исходный блоб, содержимое которого копируется
+type copyEntry struct {
+	name      string
+	dir       bool
+	file      *entity.DriveFile
+	chunks    []*entity.DriveFileChunk
+	blob      *entity.DriveBlob
+	shareBlob bool
+	children  []*copyEntry
+}
+
+// Copy копирует файлы и директории в директорию ParentID. При совпадении имени копия получает имя "name (1)".
+// Сначала собирается все поддерево и проверяется квота, только потом копируются объекты хранилища и записи.
+// Внутри диска одного владельца файлы с блобом ссылаются на тот же блоб, остальные копируются в хранилище
+// как есть (зашифрованные остаются зашифрованными тем же ключом)
+func (uc *driveUseCase) Copy(ctx context.Context, user *entity.User, in dto.DriveCopyIn) ([]*dto.DriveTree, error) {
+	owner, err := uc.parentOwner(ctx, in.ParentID, user, DriveRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	var ancestors []*dto.DrivePathItem
+	if in.ParentID != nil {
+		ancestors, err = uc.repositories.DriveStructRepository.Ancestors(ctx, owner.ID, *in.ParentID)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+	}
+
+	entries := make([]*copyEntry, 0, len(in.StructIDs))
+	var required int64
+	for _, structID := range in.StructIDs {
+		for _, ancestor := range ancestors {
+			if ancestor.ID == structID {
+				return nil, ErrDriveCopyIntoOneself
+			}
+		}
+
+		sourceOwner, err := uc.structOwner(ctx, structID, user, DriveRoleViewer)
+		if err != nil {
+			return nil, err
+		}
+		driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrDriveStructNotFound
+			}
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+		if driveStruct.UserID != sourceOwner.ID || driveStruct.DeletedAt != nil {
+			return nil, ErrDriveStructNotFound
+		}
+
+		entry, err := uc.collectCopyEntry(ctx, sourceOwner, owner, structID, driveStruct.Name, driveStruct.Type, &required)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return nil, ErrFileNotFound
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = uc.checkStorageQuota(ctx, owner.ID, required, in.StorageMaxSizePerUser); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		rowType := int8(typeFile)
+		if entry.dir {
+			rowType = typeDirectory
+		}
+		name, err := uc.getFreeName(ctx, owner.ID, entry.name, rowType, in.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		entry.name = name
+
+		if err = uc.writeCopyEntry(ctx, owner, entry, in.ParentID, in.SavePath, in.StorageMaxSizePerUser); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.GetTree(ctx, in.ParentID, user)
+}
+
+// collectCopyEntry читает структуру и ее содержимое и добавляет к required размер, который займет копия.
+// Незавершенные загрузки не копируются: для них возвращается nil
+func (uc *driveUseCase) collectCopyEntry(
+	ctx context.Context,
+	sourceOwner *entity.User,
+	owner *entity.User,
+	structID int,
+	name string,
+	rowType int8,
+	required *int64,
+) (*copyEntry, error) {
+	entry := &copyEntry{name: name, dir: rowType == typeDirectory}
+
+	if entry.dir {
+		list, err := uc.repositories.DriveStructRepository.TreeByUserID(ctx, sourceOwner.ID, &structID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+		for _, item := range list {
+			child, err := uc.collectCopyEntry(ctx, sourceOwner, owner, item.ID, item.Name, item.Type, required)
+			if err != nil {
+				return nil, err
+			}
+			if child != nil {
+				entry.children = append(entry.children, child)
+			}
+		}
+		return entry, nil
+	}
+
+	driveFile, err := uc.repositories.DriveFileRepository.GetByStructID(ctx, structID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if driveFile.UploadState != uploadStateComplete {
+		return nil, nil
+	}
+	entry.file = driveFile
+
+	switch {
+	case driveFile.BlobID != nil && driveFile.SHA256 != nil:
+		// у владельца копии уже есть такое содержимое - место не расходуется
+		blob, err := uc.findUserBlob(ctx, owner.ID, *driveFile.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		if blob != nil {
+			entry.blob = blob
+			entry.shareBlob = true
+			return entry, nil
+		}
+		blob, err = uc.findUserBlob(ctx, sourceOwner.ID, *driveFile.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		if blob == nil {
+			return nil, ErrFileNotFound
+		}
+		entry.blob = blob
+		*required += blob.Size
+	case driveFile.IsChunk:
+		chunks, err := uc.repositories.DriveFileChunkRepository.GetByFileID(ctx, driveFile.ID)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+		entry.chunks = chunks
+		for _, fileChunk := range chunks {
+			*required += fileChunk.Size
+		}
+	default:
+		if driveFile.Path == nil {
+			return nil, ErrFileNotFound
+		}
+		*required += driveFile.Size
+	}
+	return entry, nil
+}
+
+// writeCopyEntry создает копию элемента в директории parentID. Если запись в БД не удалась
+// (в том числе из-за квоты), скопированные для файла объекты удаляются из хранилища
+func (uc *driveUseCase) writeCopyEntry(
+	ctx context.Context,
+	owner *entity.User,
+	entry *copyEntry,
+	parentID *int,
+	savePath string,
+	storageLimit int64,
+) error {
+	now := time.Now().UTC()
+
+	if entry.dir {
+		driveStruct := &entity.DriveStruct{
+			UserID:    owner.ID,
+			Name:      entry.name,
+			Type:      typeDirectory,
+			ParentID:  parentID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if _, err := uc.repositories.DriveStructRepository.Create(ctx, driveStruct); err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+		for _, child := range entry.children {
+			if err := uc.writeCopyEntry(ctx, owner, child, &driveStruct.ID, savePath, storageLimit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	driveFile := &entity.DriveFile{
+		Ext:         entry.file.Ext,
+		Size:        entry.file.Size,
+		CreatedAt:   now,
+		IsChunk:     entry.file.IsChunk,
+		SHA256:      entry.file.SHA256,
+		PlainSize:   entry.file.PlainSize,
+		IsCurrent:   true,
+		UploadState: uploadStateComplete,
+		MimeType:    entry.file.MimeType,
+		DataKeyID:   entry.file.DataKeyID,
+	}
+
+	if entry.blob != nil {
+		var pendingKeys []string
+		blob := entry.blob
+		if !entry.shareBlob {
+			// блоб мог появиться у владельца при копировании предыдущего файла с тем же содержимым
+			ownerBlob, err := uc.findUserBlob(ctx, owner.ID, blob.SHA256)
+			if err != nil {
+				return err
+			}
+			if ownerBlob != nil {
+				entry.blob = ownerBlob
+				entry.shareBlob = true
+				return uc.writeCopyEntry(ctx, owner, entry, parentID, savePath, storageLimit)
+			}
+
+			copiedPath, err := uc.copyStorageObject(ctx, blob.Path, entry.file.Ext, savePath)
+			if err != nil {
+				return err
+			}
+			pendingKeys = append(pendingKeys, filepath.Join(savePath, copiedPath))
+			blob = &entity.DriveBlob{
+				UserID:    owner.ID,
+				SHA256:    blob.SHA256,
+				Path:      copiedPath,
+				Size:      blob.Size,
+				PlainSize: blob.PlainSize,
+				CreatedAt: now,
+				DataKeyID: blob.DataKeyID,
+			}
+		}
+
+		_, err := uc.attachFile(ctx, owner, entry.name, parentID, nil, driveFile, blob, storageLimit, pendingKeys...)
+		if err != nil {
+			abortStorageWrite(ctx, uc.repositories, pendingKeys...)
+			if errors.Is(err, ErrDriveFileSystemIsFull) {
+				return err
+			}
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+		return nil
+	}
+
+	var (
+		copiedPaths []string
+		copiedSize  int64
+	)
+	chunks := make([]*entity.DriveFileChunk, 0, len(entry.chunks))
+	if entry.file.IsChunk {
+		for _, fileChunk := range entry.chunks {
+			copiedPath, err := uc.copyStorageObject(ctx, fileChunk.Path, fmt.Sprintf("part_%d", fileChunk.ChunkNumber), savePath)
+			if err != nil {
+				uc.deleteCopiedObjects(ctx, copiedPaths, savePath)
+				return err
+			}
+			copiedPaths = append(copiedPaths, copiedPath)
+			copiedSize += fileChunk.Size
+			chunks = append(chunks, &entity.DriveFileChunk{
+				Path:        copiedPath,
+				Size:        fileChunk.Size,
+				ChunkNumber: fileChunk.ChunkNumber,
+				PlainSize:   fileChunk.PlainSize,
+				DataKeyID:   fileChunk.DataKeyID,
+			})
+		}
+	} else {
+		copiedPath, err := uc.copyStorageObject(ctx, *entry.file.Path, entry.file.Ext, savePath)
+		if err != nil {
+			return err
+		}
+		copiedPaths = append(copiedPaths, copiedPath)
+		copiedSize = entry.file.Size
+		driveFile.Path = &copiedPath
+	}
+
+	err := repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		repositoriesTx := uc.repositories.WithTx(tx)
+
+		if err := uc.reserveStorage(ctx, repositoriesTx, owner.ID, copiedSize, storageLimit); err != nil {
+			return err
+		}
+
+		driveStruct := &entity.DriveStruct{
+			UserID:    owner.ID,
+			Name:      entry.name,
+			Type:      typeFile,
+			ParentID:  parentID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if _, err := repositoriesTx.DriveStructRepository.Create(ctx, driveStruct); err != nil {
+			return err
+		}
+
+		driveFile.DriveStructID = driveStruct.ID
+		if _, err := repositoriesTx.DriveFileRepository.Create(ctx, driveFile); err != nil {
+			return err
+		}
+
+		chunkRepoTx := repositoriesTx.DriveFileChunkRepository
+		for _, fileChunk := range chunks {
+			fileChunk.DriveFileID = driveFile.ID
+			if _, err := chunkRepoTx.Create(ctx, fileChunk); err != nil {
+				return err
+			}
+		}
+		return commitStorageWrite(ctx, repositoriesTx, copiedObjectKeys(copiedPaths, savePath)...)
+	})
+	if err != nil {
+		uc.deleteCopiedObjects(ctx, copiedPaths, savePath)
+		if errors.Is(err, ErrDriveFileSystemIsFull) {
+			return err
+		}
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+	return nil
+}
+
+// copyStorageObject копирует объект хранилища под новым именем и возвращает путь относительно savePath
+func (uc *driveUseCase) copyStorageObject(ctx context.Context, srcPath string, nameSuffix string, savePath string) (string, error) {
+	fileService := service.NewFile().FileService()
+
+	middleFilePath, err := fileService.GenerateNewFilePath(nameSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	fullFilePath := filepath.Join(savePath, middleFilePath)
+	if err = beginStorageWrite(ctx, uc.repositories, fullFilePath); err != nil {
+		return "", err
+	}
+
+	err = uc.repositories.StorageRepository.Copy(ctx, filepath.Join(savePath, srcPath), fullFilePath)
+	if err != nil {
+		abortStorageWrite(ctx, uc.repositories, fullFilePath)
+		if errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
+			return "", err
+		}
+		logging.GetLogger(ctx).Error(err)
+		return "", ErrDriveFileSave
+	}
+	return middleFilePath, nil
+}
+
+func (uc *driveUseCase) deleteCopiedObjects(ctx context.Context, paths []string, savePath string) {
+	abortStorageWrite(ctx, uc.repositories, copiedObjectKeys(paths, savePath)...)
+}
+
+func copiedObjectKeys(paths []string, savePath string) []string {
+	keys := make([]string, 0, len(paths))
+	for _, middlePath := range paths {
+		keys = append(keys, filepath.Join(savePath, middlePath))
+	}
+	return keys
+}
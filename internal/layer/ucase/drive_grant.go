@@ -0,0 +1,196 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// роли гранта на директорию: просмотр содержимого или его изменение
+const (
+	DriveRoleViewer int8 = 1
+	DriveRoleEditor int8 = 2
+)
+
+var (
+	ErrDriveAccessDenied         = errors.New("drive access denied")
+	ErrDriveGrantNotFound        = errors.New("drive grant not found")
+	ErrDriveGrantSelf            = errors.New("drive grant to oneself")
+	ErrDriveGrantOnlyDirectories = errors.New("drive grant only for directories")
+)
+
+type DriveGrantUseCase interface {
+	Create(ctx context.Context, in dto.DriveGrantCreate, user *entity.User) (*entity.DriveGrant, error)
+	GetByStruct(ctx context.Context, structID int, user *entity.User) ([]*dto.DriveGrantItem, error)
+	Delete(ctx context.Context, grantID int, user *entity.User) error
+}
+
+type driveGrantUseCase struct {
+	repositories *repository.Repositories
+}
+
+func NewDriveGrantUseCase(repositories *repository.Repositories) DriveGrantUseCase {
+	return &driveGrantUseCase{
+		repositories: repositories,
+	}
+}
+
+func (uc *driveGrantUseCase) Create(ctx context.Context, in dto.DriveGrantCreate, user *entity.User) (*entity.DriveGrant, error) {
+	driveStruct, err := uc.getOwnDirectory(ctx, in.StructID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	grantee, err := uc.repositories.UserRepository.Find(ctx, in.Login)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if grantee.ID == user.ID {
+		return nil, ErrDriveGrantSelf
+	}
+
+	grant, err := uc.repositories.DriveGrantRepository.Upsert(ctx, &entity.DriveGrant{
+		DriveStructID: driveStruct.ID,
+		OwnerID:       user.ID,
+		GranteeID:     grantee.ID,
+		Role:          in.Role,
+		CreatedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return grant, nil
+}
+
+func (uc *driveGrantUseCase) GetByStruct(ctx context.Context, structID int, user *entity.User) ([]*dto.DriveGrantItem, error) {
+	driveStruct, err := uc.getOwnDirectory(ctx, structID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := uc.repositories.DriveGrantRepository.GetByStructID(ctx, driveStruct.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return grants, nil
+}
+
+func (uc *driveGrantUseCase) Delete(ctx context.Context, grantID int, user *entity.User) error {
+	deleted, err := uc.repositories.DriveGrantRepository.DeleteByID(ctx, grantID, user.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+	if !deleted {
+		return ErrDriveGrantNotFound
+	}
+	return nil
+}
+
+// getOwnDirectory возвращает директорию пользователя: выдавать доступ может только владелец
+func (uc *driveGrantUseCase) getOwnDirectory(ctx context.Context, structID int, user *entity.User) (*entity.DriveStruct, error) {
+	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDriveStructNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if driveStruct.UserID != user.ID || driveStruct.DeletedAt != nil {
+		return nil, ErrDriveStructNotFound
+	}
+	if driveStruct.Type != typeDirectory {
+		return nil, ErrDriveGrantOnlyDirectories
+	}
+	return driveStruct, nil
+}
+
+// GetSharedWithMe - корень "доступные мне": директории других пользователей, на которые выданы гранты
+func (uc *driveUseCase) GetSharedWithMe(ctx context.Context, user *entity.User) ([]*dto.DriveTree, error) {
+	list, err := uc.repositories.DriveGrantRepository.SharedWithUser(ctx, user.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return list, nil
+}
+
+// structOwner возвращает пользователя, от имени которого выполняется операция над структурой.
+// Если структура чужая и у user есть грант с ролью не ниже role на нее или на одного из ее родителей,
+// возвращается владелец: файлы создаются в его дереве и учитываются в его квоте.
+// Без гранта возвращается сам user, и дальнейшие проверки владельца отклонят операцию как раньше
+func (uc *driveUseCase) structOwner(ctx context.Context, structID int, user *entity.User, role int8) (*entity.User, error) {
+	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if driveStruct.UserID == user.ID {
+		return user, nil
+	}
+
+	grantedRole, err := uc.repositories.DriveGrantRepository.GetRole(ctx, structID, user.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if grantedRole < role {
+		return nil, ErrDriveAccessDenied
+	}
+	return &entity.User{ID: driveStruct.UserID}, nil
+}
+
+// parentOwner - structOwner для директории, в которой выполняется операция, nil - корень пользователя.
+// Для изменения директория должна существовать, принадлежать владельцу и не находиться в корзине
+func (uc *driveUseCase) parentOwner(ctx context.Context, parentID *int, user *entity.User, role int8) (*entity.User, error) {
+	if parentID == nil {
+		return user, nil
+	}
+	owner, err := uc.structOwner(ctx, *parentID, user, role)
+	if err != nil {
+		return nil, err
+	}
+	if role >= DriveRoleEditor {
+		if err = uc.checkParentOwner(ctx, *parentID, owner.ID); err != nil {
+			return nil, err
+		}
+	}
+	return owner, nil
+}
+
+// entryOwner - structOwner для изменения самой структуры (переименование, перемещение, удаление, дозагрузка).
+// Изменение элемента - это изменение родительской директории, поэтому сама директория гранта
+// остается под управлением владельца
+func (uc *driveUseCase) entryOwner(ctx context.Context, structID int, user *entity.User) (*entity.User, error) {
+	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, structID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if driveStruct.UserID == user.ID || driveStruct.ParentID == nil {
+		return user, nil
+	}
+	return uc.structOwner(ctx, *driveStruct.ParentID, user, DriveRoleEditor)
+}
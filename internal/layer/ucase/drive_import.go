@@ -0,0 +1,319 @@
+package ucase
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ImportConflictSkip      = "skip"
+	ImportConflictRename    = "rename"
+	ImportConflictOverwrite = "overwrite"
+)
+
+var (
+	ErrDriveImportUnsupportedArchive = errors.New("drive import archive format is not supported")
+	ErrDriveImportInvalidArchive     = errors.New("drive import archive is damaged")
+	ErrDriveImportTooManyEntries     = errors.New("drive import archive has too many entries")
+	ErrDriveImportTooLarge           = errors.New("drive import archive unpacked size is too large")
+	ErrDriveImportUnsafePath         = errors.New("drive import entry path is unsafe")
+	ErrDriveImportUnsupportedEntry   = errors.New("drive import entry type is not supported")
+)
+
+// archiveEntry - элемент архива. open возвращает содержимое файла, для tar - только во время обхода
+type archiveEntry struct {
+	name string
+	dir  bool
+	size int64
+	err  error
+	open func() (io.Reader, error)
+}
+
+// ImportArchive распаковывает ZIP или tar.gz в директорию. Директории создаются или переиспользуются,
+// файлы сохраняются через чанковую загрузку (квота, шифрование, проверка имени, дедупликация).
+// Сначала архив проверяется целиком: количество элементов и суммарный размер распаковки ограничены,
+// при этом из каждого элемента читается не больше заявленного в заголовке размера
+func (uc *driveUseCase) ImportArchive(ctx context.Context, in dto.DriveImportIn, user *entity.User) (*dto.DriveImportResult, error) {
+	user, err := uc.parentOwner(ctx, in.ParentID, user, DriveRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.DriveImportResult{
+		Created: make([]string, 0),
+		Skipped: make([]string, 0),
+		Failed:  make([]*dto.DriveImportFailure, 0),
+	}
+
+	var (
+		entriesCount int
+		unpackedSize int64
+	)
+	err = uc.walkArchive(in, func(entry *archiveEntry) error {
+		entriesCount++
+		if in.MaxEntries > 0 && entriesCount > in.MaxEntries {
+			return ErrDriveImportTooManyEntries
+		}
+		if !entry.dir && entry.err == nil {
+			unpackedSize += entry.size
+			if in.MaxUnpackedSize > 0 && unpackedSize > in.MaxUnpackedSize {
+				return ErrDriveImportTooLarge
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	directories := make(map[string]*int)
+	err = uc.walkArchive(in, func(entry *archiveEntry) error {
+		if entry.err != nil {
+			result.Failed = append(result.Failed, &dto.DriveImportFailure{Path: entry.name, Err: entry.err})
+			return nil
+		}
+		// служебные файлы архиватора macOS
+		if entry.name == "__MACOSX" || strings.HasPrefix(entry.name, "__MACOSX/") {
+			result.Skipped = append(result.Skipped, entry.name)
+			return nil
+		}
+
+		parts := strings.Split(entry.name, "/")
+		dirParts := parts
+		if !entry.dir {
+			dirParts = parts[:len(parts)-1]
+		}
+
+		parentID, err := uc.ensureDirectories(ctx, user, in.ParentID, dirParts, directories, func(dirPath string) {
+			result.Created = append(result.Created, dirPath+"/")
+		})
+		if err != nil {
+			if errors.Is(err, postgres.ErrUnexpectedDBError) {
+				return err
+			}
+			result.Failed = append(result.Failed, &dto.DriveImportFailure{Path: entry.name, Err: err})
+			return nil
+		}
+		if entry.dir {
+			return nil
+		}
+
+		created, err := uc.importFile(ctx, in, user, parentID, parts[len(parts)-1], entry)
+		switch {
+		case err != nil:
+			result.Failed = append(result.Failed, &dto.DriveImportFailure{Path: entry.name, Err: err})
+		case created:
+			result.Created = append(result.Created, entry.name)
+		default:
+			result.Skipped = append(result.Skipped, entry.name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// importFile сохраняет файл архива с учетом политики конфликтов. false без ошибки - файл пропущен
+func (uc *driveUseCase) importFile(
+	ctx context.Context,
+	in dto.DriveImportIn,
+	user *entity.User,
+	parentID *int,
+	name string,
+	entry *archiveEntry,
+) (bool, error) {
+	replace := false
+	_, err := uc.repositories.DriveStructRepository.FindRow(ctx, user.ID, name, typeFile, parentID)
+	if err == nil {
+		switch in.Conflict {
+		case ImportConflictOverwrite:
+			replace = true
+		case ImportConflictRename:
+			name, err = uc.getFreeName(ctx, user.ID, name, typeFile, parentID)
+			if err != nil {
+				return false, err
+			}
+		default:
+			return false, nil
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		logging.GetLogger(ctx).Error(err)
+		return false, postgres.ErrUnexpectedDBError
+	}
+
+	prepareIn := dto.DriveChunkPrepareIn{
+		DriveChunkPrepare: dto.DriveChunkPrepare{
+			Filename: name,
+			FullSize: entry.size,
+			ParentID: parentID,
+			Replace:  replace,
+		},
+		MaxSizeBytes:          in.MaxSizeBytes,
+		StorageMaxSizePerUser: in.StorageMaxSizePerUser,
+	}
+	prepared, err := uc.ChunkPrepare(ctx, user, prepareIn)
+	if err != nil {
+		return false, err
+	}
+
+	body, err := entry.open()
+	if err == nil {
+		_, err = uc.TusAppend(ctx, user, dto.DriveTusAppend{
+			StructID:              prepared.StructID,
+			Offset:                0,
+			Body:                  body,
+			ContentLength:         entry.size,
+			MaxSizeBytes:          in.MaxSizeBytes,
+			StorageMaxSizePerUser: in.StorageMaxSizePerUser,
+			SavePath:              in.SavePath,
+			UseEncryption:         in.UseEncryption,
+			Keyring:               in.Keyring,
+		})
+	}
+	if err != nil {
+		// незавершенная загрузка отменяется, при перезаписи снова становится текущей прежняя версия
+		if terminateErr := uc.TusTerminate(ctx, prepared.StructID, in.SavePath, user); terminateErr != nil &&
+			!errors.Is(terminateErr, ErrDriveUploadCompleted) && !errors.Is(terminateErr, ErrFileNotFound) {
+			logging.GetLogger(ctx).Error(terminateErr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// walkArchive обходит элементы архива по порядку. Формат определяется по имени загруженного файла
+func (uc *driveUseCase) walkArchive(in dto.DriveImportIn, visit func(entry *archiveEntry) error) error {
+	if _, err := in.File.Seek(0, io.SeekStart); err != nil {
+		return ErrFileResettingPointer
+	}
+
+	lowerName := strings.ToLower(in.Filename)
+	switch {
+	case strings.HasSuffix(lowerName, ".zip"):
+		return walkZip(in.File, in.Size, visit)
+	case strings.HasSuffix(lowerName, ".tar.gz"), strings.HasSuffix(lowerName, ".tgz"):
+		return walkTarGz(in.File, visit)
+	default:
+		return ErrDriveImportUnsupportedArchive
+	}
+}
+
+func walkZip(file io.ReaderAt, size int64, visit func(entry *archiveEntry) error) error {
+	zipReader, err := zip.NewReader(file, size)
+	if err != nil {
+		return ErrDriveImportInvalidArchive
+	}
+
+	for _, zipFile := range zipReader.File {
+		entry := &archiveEntry{
+			dir:  zipFile.FileInfo().IsDir(),
+			size: int64(zipFile.UncompressedSize64),
+		}
+		entry.name, entry.err = sanitizeArchivePath(zipFile.Name)
+		if entry.err == nil && !entry.dir && !zipFile.Mode().IsRegular() {
+			entry.err = ErrDriveImportUnsupportedEntry
+		}
+		if entry.size < 0 {
+			entry.err = ErrDriveImportInvalidArchive
+		}
+
+		var opened io.ReadCloser
+		entry.open = func() (io.Reader, error) {
+			reader, openErr := zipFile.Open()
+			if openErr != nil {
+				return nil, ErrDriveImportInvalidArchive
+			}
+			opened = reader
+			return reader, nil
+		}
+
+		err = visit(entry)
+		if opened != nil {
+			_ = opened.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTarGz(file io.Reader, visit func(entry *archiveEntry) error) error {
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return ErrDriveImportInvalidArchive
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return ErrDriveImportInvalidArchive
+		}
+
+		entry := &archiveEntry{
+			dir:  header.Typeflag == tar.TypeDir,
+			size: header.Size,
+			open: func() (io.Reader, error) {
+				return tarReader, nil
+			},
+		}
+		// служебные заголовки pax/gnu обрабатывает tar.Reader, остальные типы (ссылки, устройства) не поддерживаются
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeDir {
+			entry.err = ErrDriveImportUnsupportedEntry
+		}
+		name, pathErr := sanitizeArchivePath(header.Name)
+		entry.name = name
+		if pathErr != nil {
+			entry.err = pathErr
+		}
+
+		if err = visit(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// sanitizeArchivePath приводит путь элемента архива к виду "dir/sub/name" и отклоняет пути,
+// выходящие за пределы директории импорта (zip slip), абсолютные пути и некорректные имена
+func sanitizeArchivePath(name string) (string, error) {
+	original := strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(original, "/") || (len(original) > 1 && original[1] == ':') {
+		return original, ErrDriveImportUnsafePath
+	}
+
+	parts := make([]string, 0)
+	for _, part := range strings.Split(original, "/") {
+		switch {
+		case part == "" || part == ".":
+			continue
+		case part == "..":
+			return original, ErrDriveImportUnsafePath
+		case len(part) > 300 || strings.ContainsAny(part, "\x00\r\n"):
+			return original, ErrDriveImportUnsafePath
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return original, ErrDriveImportUnsafePath
+	}
+	return filepath.ToSlash(strings.Join(parts, "/")), nil
+}
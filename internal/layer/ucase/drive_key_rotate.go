@@ -0,0 +1,275 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	service "assistant-go/internal/layer/service/file"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"assistant-go/pkg/keyring"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrDriveKeyRotateNoMasterKey   = errors.New("current drive master key is not configured")
+	ErrDriveKeyRotateEncryptionOff = errors.New("drive encryption is disabled, there is nothing to re-encrypt")
+	ErrDriveKeyRotateSizeMismatch  = errors.New("decrypted object size does not match the database")
+)
+
+const (
+	driveKeyRotateBatchSize     = 500
+	driveKeyRotateProgressEvery = 100
+)
+
+type DriveKeyRotateUseCase interface {
+	Rotate(ctx context.Context, in dto.DriveKeyRotateIn) (*dto.DriveKeyRotateProgress, error)
+}
+
+type driveKeyRotateUseCase struct {
+	repositories *repository.Repositories
+}
+
+func NewDriveKeyRotateUseCase(repositories *repository.Repositories) DriveKeyRotateUseCase {
+	return &driveKeyRotateUseCase{repositories: repositories}
+}
+
+// Rotate переводит шифрование диска на текущий мастер-ключ. Ключи данных, завернутые прежними
+// мастер-ключами, перезаворачиваются текущим: файлы при этом не перечитываются. В режиме Full
+// ключи данных всех пользователей заменяются новыми и каждый объект перешифровывается ключом
+// владельца (файлы старого формата без ключа данных - тоже). Перешифрованный объект сохраняется
+// под новым ключом хранилища, поэтому прерванная ротация не оставляет поврежденных файлов и
+// продолжается с флагом Resume. После ротации неиспользуемые ключи данных удаляются.
+// Превью перешифровывать не нужно: ключ превью производный от мастер-ключа, и они строятся заново.
+// Вложения и содержимое заметок после полной ротации перешифровывает NoteEncryptUseCase
+func (uc *driveKeyRotateUseCase) Rotate(ctx context.Context, in dto.DriveKeyRotateIn) (*dto.DriveKeyRotateProgress, error) {
+	if in.Keyring.CurrentID() == "" {
+		return nil, ErrDriveKeyRotateNoMasterKey
+	}
+	if in.Full && !in.UseEncryption {
+		return nil, ErrDriveKeyRotateEncryptionOff
+	}
+
+	progress := &dto.DriveKeyRotateProgress{}
+	report := func() {
+		if in.OnProgress != nil {
+			in.OnProgress(*progress)
+		}
+	}
+
+	if in.Full {
+		if err := uc.reencrypt(ctx, in, progress, report); err != nil {
+			report()
+			return progress, err
+		}
+	}
+
+	// ключи, оставшиеся у пропущенных объектов, и ключи, созданные во время перешифрования
+	// сервером со старой конфигурацией, тоже переводятся на текущий мастер-ключ
+	if err := uc.rewrap(ctx, in.Keyring, progress); err != nil {
+		report()
+		return progress, err
+	}
+
+	deleted, err := uc.repositories.DriveDataKeyRepository.DeleteUnused(ctx)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return progress, postgres.ErrUnexpectedDBError
+	}
+	progress.DeletedKeys = deleted
+
+	report()
+	return progress, nil
+}
+
+// rewrap перезаворачивает текущим мастер-ключом ключи данных, завернутые другими мастер-ключами
+func (uc *driveKeyRotateUseCase) rewrap(ctx context.Context, keys *keyring.Keyring, progress *dto.DriveKeyRotateProgress) error {
+	dataKeyRepo := uc.repositories.DriveDataKeyRepository
+
+	afterID := 0
+	for {
+		dataKeys, err := dataKeyRepo.GetWrappedByOther(ctx, keys.CurrentID(), afterID, driveKeyRotateBatchSize)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+		if len(dataKeys) == 0 {
+			return nil
+		}
+
+		for _, dataKey := range dataKeys {
+			afterID = dataKey.ID
+
+			key, err := keys.Unwrap(dataKey.MasterKeyID, dataKey.WrappedKey)
+			if err != nil {
+				return fmt.Errorf("data key %d (master key %s): %w", dataKey.ID, dataKey.MasterKeyID, err)
+			}
+			masterKeyID, wrapped, err := keys.Wrap(key)
+			if err != nil {
+				return fmt.Errorf("data key %d: %w", dataKey.ID, err)
+			}
+
+			if err = dataKeyRepo.UpdateWrapped(ctx, dataKey.ID, masterKeyID, wrapped); err != nil {
+				logging.GetLogger(ctx).Error(err)
+				return postgres.ErrUnexpectedDBError
+			}
+			progress.Rewrapped++
+		}
+	}
+}
+
+// reencrypt перешифровывает объекты, зашифрованные не активным ключом данных владельца
+func (uc *driveKeyRotateUseCase) reencrypt(
+	ctx context.Context,
+	in dto.DriveKeyRotateIn,
+	progress *dto.DriveKeyRotateProgress,
+	report func(),
+) error {
+	dataKeyRepo := uc.repositories.DriveDataKeyRepository
+
+	if !in.Resume {
+		// при первом обращении после этого каждому пользователю будет создан новый ключ данных
+		if _, err := dataKeyRepo.DeactivateAll(ctx); err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+	}
+
+	cipher := newDriveCipher(uc.repositories, true, in.Keyring)
+	for _, kind := range repository.DriveEncryptedObjectKinds {
+		afterID := 0
+		for {
+			objects, err := dataKeyRepo.GetStaleObjects(ctx, kind, afterID, driveKeyRotateBatchSize)
+			if err != nil {
+				logging.GetLogger(ctx).Error(err)
+				return postgres.ErrUnexpectedDBError
+			}
+			if len(objects) == 0 {
+				break
+			}
+
+			for _, object := range objects {
+				afterID = object.ID
+
+				key := filepath.Join(in.SavePath, object.Path)
+				replaced, err := reencryptObject(ctx, uc.repositories, in.SavePath, cipher, object)
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				if !replaced {
+					progress.Skipped = append(progress.Skipped, key)
+					continue
+				}
+
+				progress.Reencrypted++
+				if progress.Reencrypted%driveKeyRotateProgressEvery == 0 {
+					report()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// reencryptObject сохраняет перешифрованную активным ключом владельца копию объекта под новым ключом
+// хранилища и переводит на нее запись в БД. Незашифрованный объект шифруется.
+// Возвращает false, если объекта нет в хранилище или запись за это время изменилась
+func reencryptObject(
+	ctx context.Context,
+	repositories *repository.Repositories,
+	savePath string,
+	cipher *driveCipher,
+	object *entity.StorageObject,
+) (bool, error) {
+	fileService := service.NewFile().FileService()
+	plainSize := (&driveUseCase{}).getPlainSize(object.Size, object.PlainSize, cipher.encrypted(object.DataKeyID, object.Size, object.PlainSize))
+	size := fileService.EncryptedSize(plainSize)
+
+	oldKey := filepath.Join(savePath, object.Path)
+	reader, err := repositories.StorageRepository.GetFile(ctx, oldKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer closeReader(reader)
+
+	decrypted, err := cipher.decrypt(ctx, reader, object.DataKeyID, object.Size, object.PlainSize)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrDriveDecrypting, err)
+	}
+	plainReader := &countingReader{reader: decrypted}
+	encrypted, dataKeyID, err := cipher.encrypt(ctx, plainReader, object.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	newPath, err := fileService.GenerateNewFilePath(strings.TrimPrefix(filepath.Ext(object.Path), "."))
+	if err != nil {
+		return false, err
+	}
+	newKey := filepath.Join(savePath, newPath)
+	if err = beginStorageWrite(ctx, repositories, newKey); err != nil {
+		return false, err
+	}
+
+	err = repositories.StorageRepository.Save(ctx, &dto.SaveFile{File: encrypted, SavePath: newKey, SizeBytes: size})
+	if err != nil {
+		abortStorageWrite(ctx, repositories, newKey)
+		return false, err
+	}
+	if plainReader.count != plainSize {
+		abortStorageWrite(ctx, repositories, newKey)
+		return false, ErrDriveKeyRotateSizeMismatch
+	}
+
+	replacement := &entity.StorageObject{
+		Kind:      object.Kind,
+		ID:        object.ID,
+		Path:      newPath,
+		Size:      size,
+		PlainSize: &plainSize,
+		DataKeyID: dataKeyID,
+	}
+
+	var replaced bool
+	driveDelta, filesDelta := size-object.Size, int64(0)
+	if object.Kind == repository.StorageObjectFiles {
+		driveDelta, filesDelta = 0, driveDelta
+	}
+
+	err = repository.WithTransaction(ctx, repositories.TransactionRepository, func(tx pgx.Tx) error {
+		repositoriesTx := repositories.WithTx(tx)
+
+		var err error
+		replaced, err = repositoriesTx.DriveDataKeyRepository.ReplaceObject(ctx, replacement, object)
+		if err != nil || !replaced {
+			return err
+		}
+		// размер файла старого формата или незашифрованного файла меняется,
+		// разница учитывается в занятом месте владельца
+		if err = repositoriesTx.StorageUsageRepository.Add(ctx, object.UserID, driveDelta, filesDelta); err != nil {
+			return err
+		}
+		return commitStorageWrite(ctx, repositoriesTx, newKey)
+	})
+	if err != nil || !replaced {
+		abortStorageWrite(ctx, repositories, newKey)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return false, postgres.ErrUnexpectedDBError
+		}
+		return false, nil
+	}
+
+	if err = repositories.StorageRepository.Delete(ctx, oldKey); err != nil {
+		logging.GetLogger(ctx).Error(err)
+	}
+	return true, nil
+}
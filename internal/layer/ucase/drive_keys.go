@@ -0,0 +1,190 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	service "assistant-go/internal/layer/service/file"
+	"assistant-go/internal/logging"
+	"assistant-go/pkg/keyring"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"time"
+)
+
+var ErrDriveDataKey = errors.New("drive data key is not available")
+
+// driveCipher шифрует и расшифровывает объекты диска и заметок ключами данных пользователей.
+// Объект с data_key_id зашифрован этим ключом данных (ключ хранится в БД завернутым мастер-ключом).
+// Объект диска без него, исходный размер которого совпадает с размером в хранилище, сохранен без шифрования,
+// иначе он зашифрован ключом старого формата. Формат записи без исходного размера (созданной до его появления)
+// определяется настройкой шифрования.
+// Развернутые ключи кэшируются на время одного запроса
+type driveCipher struct {
+	repositories  *repository.Repositories
+	useEncryption bool
+	legacy        bool
+	keys          *keyring.Keyring
+	dataKeys      map[int][]byte
+}
+
+func newDriveCipher(repositories *repository.Repositories, useEncryption bool, keys *keyring.Keyring) *driveCipher {
+	return &driveCipher{
+		repositories:  repositories,
+		useEncryption: useEncryption,
+		legacy:        true,
+		keys:          keys,
+		dataKeys:      make(map[int][]byte),
+	}
+}
+
+// newNoteCipher - шифрование вложений и содержимого заметок: у них нет старого формата,
+// объект без ключа данных не зашифрован
+func newNoteCipher(repositories *repository.Repositories, useEncryption bool, keys *keyring.Keyring) *driveCipher {
+	cipher := newDriveCipher(repositories, useEncryption, keys)
+	cipher.legacy = false
+	return cipher
+}
+
+// encrypted сообщает, зашифрован ли объект с ключом данных dataKeyID, размером в хранилище storedSize
+// и исходным размером plainSize. Решение принимается только по записи в БД: объект, который не удалось
+// расшифровать, не отдается как незашифрованный
+func (c *driveCipher) encrypted(dataKeyID *int, storedSize int64, plainSize *int64) bool {
+	if dataKeyID != nil {
+		return true
+	}
+	if !c.legacy {
+		return false
+	}
+	if plainSize == nil {
+		return c.useEncryption
+	}
+	return *plainSize != storedSize
+}
+
+// key возвращает ключ, которым зашифрован объект
+func (c *driveCipher) key(ctx context.Context, dataKeyID *int) ([]byte, error) {
+	if dataKeyID == nil {
+		return c.keys.LegacyKey()
+	}
+	if key, ok := c.dataKeys[*dataKeyID]; ok {
+		return key, nil
+	}
+
+	dataKey, err := c.repositories.DriveDataKeyRepository.GetByID(ctx, *dataKeyID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.keys.Unwrap(dataKey.MasterKeyID, dataKey.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("data key %d: %w", dataKey.ID, err)
+	}
+	c.dataKeys[dataKey.ID] = key
+	return key, nil
+}
+
+// userKey возвращает активный ключ данных пользователя, при первом обращении ключ создается
+func (c *driveCipher) userKey(ctx context.Context, userID int) (int, []byte, error) {
+	dataKey, err := c.repositories.DriveDataKeyRepository.GetActive(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		dataKey, err = c.createUserKey(ctx, userID)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	key, err := c.key(ctx, &dataKey.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return dataKey.ID, key, nil
+}
+
+func (c *driveCipher) createUserKey(ctx context.Context, userID int) (*entity.DriveDataKey, error) {
+	key, err := keyring.NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+	masterKeyID, wrapped, err := c.keys.Wrap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := c.repositories.DriveDataKeyRepository.CreateActive(ctx, &entity.DriveDataKey{
+		UserID:      &userID,
+		MasterKeyID: masterKeyID,
+		WrappedKey:  wrapped,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// ключ успел создать параллельный запрос
+		return c.repositories.DriveDataKeyRepository.GetActive(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.dataKeys[dataKey.ID] = key
+	return dataKey, nil
+}
+
+// encrypt возвращает поток для сохранения в хранилище и ID ключа данных, которым он зашифрован.
+// При выключенном шифровании поток сохраняется как есть
+func (c *driveCipher) encrypt(ctx context.Context, file io.Reader, userID int) (io.Reader, *int, error) {
+	if !c.useEncryption {
+		return file, nil, nil
+	}
+
+	dataKeyID, key, err := c.userKey(ctx, userID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveDataKey, err))
+		return nil, nil, ErrDriveEncrypting
+	}
+
+	encrypted, err := service.NewFile().FileService().EncryptStreamWithKey(file, key)
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveEncrypting, err))
+		return nil, nil, ErrDriveEncrypting
+	}
+	return encrypted, &dataKeyID, nil
+}
+
+// decrypt расшифровывает объект, незашифрованный объект возвращается как есть
+func (c *driveCipher) decrypt(
+	ctx context.Context,
+	file io.Reader,
+	dataKeyID *int,
+	storedSize int64,
+	plainSize *int64,
+) (io.Reader, error) {
+	if !c.encrypted(dataKeyID, storedSize, plainSize) {
+		return file, nil
+	}
+	return c.decryptObject(ctx, file, dataKeyID)
+}
+
+// decryptObject расшифровывает объект, зашифрованный по записи в БД. Ошибка расшифровки не означает,
+// что объект не зашифрован
+func (c *driveCipher) decryptObject(ctx context.Context, file io.Reader, dataKeyID *int) (io.Reader, error) {
+	key, err := c.key(ctx, dataKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDriveDataKey, err)
+	}
+	return service.NewFile().FileService().DecryptStreamWithKey(file, key)
+}
+
+// decryptRange расшифровывает диапазон зашифрованного объекта
+func (c *driveCipher) decryptRange(
+	ctx context.Context,
+	open service.RangeOpener,
+	dataKeyID *int,
+	offset int64,
+	length int64,
+) (io.Reader, error) {
+	key, err := c.key(ctx, dataKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDriveDataKey, err)
+	}
+	return service.NewFile().FileService().DecryptStreamRangeWithKey(open, key, offset, length)
+}
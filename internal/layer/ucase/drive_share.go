@@ -0,0 +1,247 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"assistant-go/pkg/utils"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+var (
+	ErrDriveShareNotFound         = errors.New("drive share link not found")
+	ErrDriveShareExpired          = errors.New("drive share link expired")
+	ErrDriveShareDownloadLimit    = errors.New("drive share link download limit reached")
+	ErrDriveSharePasswordRequired = errors.New("drive share link password required")
+	ErrDriveShareWrongPassword    = errors.New("drive share link wrong password")
+	ErrDriveShareInvalidExpiry    = errors.New("drive share link expiry must be in the future")
+	ErrDriveShareHashGeneration   = errors.New("drive share link hash generation failed")
+)
+
+type DriveShareUseCase interface {
+	Create(ctx context.Context, in dto.DriveShareCreate, user *entity.User) (*entity.DriveShareLink, error)
+	GetActive(ctx context.Context, structID *int, user *entity.User) ([]*entity.DriveShareLink, error)
+	Delete(ctx context.Context, linkID int, user *entity.User) error
+	GetSharedTree(ctx context.Context, access dto.DriveShareAccess) (*dto.DriveSharedTree, error)
+	GetSharedFileInfo(ctx context.Context, in *dto.DriveSharedFileIn) (*dto.DriveFileInfo, error)
+	GetSharedFile(ctx context.Context, in *dto.DriveSharedFileIn) (*dto.FileResponse, error)
+}
+
+type driveShareUseCase struct {
+	repositories *repository.Repositories
+	drive        *driveUseCase
+}
+
+func NewDriveShareUseCase(repositories *repository.Repositories) DriveShareUseCase {
+	return &driveShareUseCase{
+		repositories: repositories,
+		drive:        &driveUseCase{repositories: repositories},
+	}
+}
+
+func (uc *driveShareUseCase) Create(ctx context.Context, in dto.DriveShareCreate, user *entity.User) (*entity.DriveShareLink, error) {
+	driveStruct, err := uc.repositories.DriveStructRepository.GetByID(ctx, in.StructID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDriveStructNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if driveStruct.UserID != user.ID || driveStruct.DeletedAt != nil {
+		return nil, ErrDriveStructNotFound
+	}
+
+	now := time.Now().UTC()
+	link := &entity.DriveShareLink{
+		UserID:        user.ID,
+		DriveStructID: driveStruct.ID,
+		MaxDownloads:  in.MaxDownloads,
+		CreatedAt:     now,
+	}
+	if in.ExpiresAt != nil {
+		expiresAt := in.ExpiresAt.UTC()
+		if !expiresAt.After(now) {
+			return nil, ErrDriveShareInvalidExpiry
+		}
+		link.ExpiresAt = &expiresAt
+	}
+	if in.Password != nil && *in.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*in.Password), 11)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, err
+		}
+		password := string(hashedPassword)
+		link.Password = &password
+	}
+
+	stringUtils := utils.NewStringUtils()
+	for i := 1; i < 10; i++ {
+		h, err := stringUtils.GenerateRandomString(80)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, err
+		}
+		existsByHash, err := uc.repositories.DriveShareLinkRepository.ExistsByHash(ctx, h)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+		if !existsByHash {
+			link.Hash = h
+			break
+		}
+	}
+	if link.Hash == "" {
+		return nil, ErrDriveShareHashGeneration
+	}
+
+	link, err = uc.repositories.DriveShareLinkRepository.Create(ctx, link)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return link, nil
+}
+
+// GetActive возвращает действующие ссылки пользователя, при structID - только на эту структуру
+func (uc *driveShareUseCase) GetActive(ctx context.Context, structID *int, user *entity.User) ([]*entity.DriveShareLink, error) {
+	links, err := uc.repositories.DriveShareLinkRepository.GetActiveByUserID(ctx, user.ID, structID, time.Now().UTC())
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return links, nil
+}
+
+func (uc *driveShareUseCase) Delete(ctx context.Context, linkID int, user *entity.User) error {
+	deleted, err := uc.repositories.DriveShareLinkRepository.DeleteByID(ctx, linkID, user.ID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+	if !deleted {
+		return ErrDriveShareNotFound
+	}
+	return nil
+}
+
+// GetSharedTree возвращает расшаренную структуру: для директории - все вложенные элементы плоским списком с parent_id
+func (uc *driveShareUseCase) GetSharedTree(ctx context.Context, access dto.DriveShareAccess) (*dto.DriveSharedTree, error) {
+	link, err := uc.getActiveLink(ctx, access)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := uc.repositories.DriveShareLinkRepository.SubtreeByRootID(ctx, link.DriveStructID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if len(items) == 0 {
+		return nil, ErrDriveShareNotFound
+	}
+	// родитель корня не раскрывается
+	for _, item := range items {
+		if item.ID == link.DriveStructID {
+			item.ParentID = nil
+		}
+	}
+
+	return &dto.DriveSharedTree{
+		RootID:    link.DriveStructID,
+		ExpiresAt: link.ExpiresAt,
+		Items:     items,
+	}, nil
+}
+
+func (uc *driveShareUseCase) GetSharedFileInfo(ctx context.Context, in *dto.DriveSharedFileIn) (*dto.DriveFileInfo, error) {
+	link, err := uc.getSharedFileLink(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return uc.drive.GetFileInfo(ctx, &in.GetFile, &entity.User{ID: link.UserID})
+}
+
+// GetSharedFile отдает файл по ссылке. Скачиванием считается запрос файла целиком или с его начала,
+// чтобы докачка и чтение частями не расходовали лимит
+func (uc *driveShareUseCase) GetSharedFile(ctx context.Context, in *dto.DriveSharedFileIn) (*dto.FileResponse, error) {
+	link, err := uc.getSharedFileLink(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := &entity.User{ID: link.UserID}
+	if _, err = uc.drive.GetFileInfo(ctx, &in.GetFile, owner); err != nil {
+		return nil, err
+	}
+
+	if in.Range == nil || in.Range.Offset == 0 {
+		err = uc.repositories.DriveShareLinkRepository.IncrementDownloads(ctx, link.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrDriveShareDownloadLimit
+			}
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+	}
+
+	return uc.drive.GetFile(ctx, &in.GetFile, owner)
+}
+
+// getSharedFileLink проверяет ссылку и то, что запрошенный файл находится внутри расшаренной структуры
+func (uc *driveShareUseCase) getSharedFileLink(ctx context.Context, in *dto.DriveSharedFileIn) (*entity.DriveShareLink, error) {
+	link, err := uc.getActiveLink(ctx, in.DriveShareAccess)
+	if err != nil {
+		return nil, err
+	}
+	// по ссылке доступна только текущая версия
+	in.VersionID = nil
+
+	inSubtree, err := uc.repositories.DriveShareLinkRepository.IsInSubtree(ctx, link.DriveStructID, in.StructID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	if !inSubtree {
+		return nil, ErrFileNotFound
+	}
+	return link, nil
+}
+
+func (uc *driveShareUseCase) getActiveLink(ctx context.Context, access dto.DriveShareAccess) (*entity.DriveShareLink, error) {
+	link, err := uc.repositories.DriveShareLinkRepository.GetByHash(ctx, access.Hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDriveShareNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now().UTC()) {
+		return nil, ErrDriveShareExpired
+	}
+	if link.MaxDownloads != nil && link.DownloadCount >= *link.MaxDownloads {
+		return nil, ErrDriveShareDownloadLimit
+	}
+
+	if link.Password != nil {
+		if access.Password == "" {
+			return nil, ErrDriveSharePasswordRequired
+		}
+		err = bcrypt.CompareHashAndPassword([]byte(*link.Password), []byte(access.Password))
+		if err != nil {
+			return nil, ErrDriveShareWrongPassword
+		}
+	}
+	return link, nil
+}
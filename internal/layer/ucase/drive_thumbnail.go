@@ -0,0 +1,93 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	service "assistant-go/internal/layer/service/file"
+	"assistant-go/pkg/keyring"
+	"context"
+	"io"
+)
+
+// GetThumbnail отдает превью изображения с диска. Превью строится при первом запросе
+// (или в фоне после загрузки) и хранится рядом с файлами под ключом, производным от ID версии файла
+func (uc *driveUseCase) GetThumbnail(ctx context.Context, in *dto.GetThumbnail, user *entity.User) (*dto.FileResponse, error) {
+	user, err := uc.structOwner(ctx, in.StructID, user, DriveRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	driveStruct, driveFile, err := uc.getUserFile(ctx, in.StructID, nil, user)
+	if err != nil {
+		return nil, err
+	}
+	if driveFile.UploadState != uploadStateComplete {
+		return nil, ErrDriveUploadIncomplete
+	}
+	if !uc.thumbnailAvailable(driveFile, in.UseEncryption) {
+		return nil, ErrThumbnailUnsupported
+	}
+
+	key := thumbnailKey(in.SavePath, thumbnailKindDrive, driveFile.ID, in.Size)
+	fileResponse, err := loadThumbnail(ctx, uc.repositories, key, in, uc.thumbnailSource(in, driveStruct, driveFile, user))
+	if err != nil {
+		return nil, err
+	}
+	fileResponse.OriginalFilename = driveStruct.Name + ".jpg"
+	return fileResponse, nil
+}
+
+// warmDriveThumbnail запускает фоновое построение превью только что загруженного изображения
+func (uc *driveUseCase) warmDriveThumbnail(
+	ctx context.Context,
+	driveStruct *entity.DriveStruct,
+	driveFile *entity.DriveFile,
+	user *entity.User,
+	savePath string,
+	useEncryption bool,
+	keys *keyring.Keyring,
+) {
+	if !uc.thumbnailAvailable(driveFile, useEncryption) {
+		return
+	}
+
+	in := &dto.GetThumbnail{
+		StructID:      driveStruct.ID,
+		Size:          ThumbnailDefaultSize,
+		SavePath:      savePath,
+		UseEncryption: useEncryption,
+		Keyring:       keys,
+	}
+	key := thumbnailKey(savePath, thumbnailKindDrive, driveFile.ID, in.Size)
+	warmThumbnail(ctx, uc.repositories, key, in, uc.thumbnailSource(in, driveStruct, driveFile, user))
+}
+
+func (uc *driveUseCase) thumbnailAvailable(driveFile *entity.DriveFile, useEncryption bool) bool {
+	thumbnailService := service.NewFile().ThumbnailService()
+	if !thumbnailService.Supports(driveFile.Ext) {
+		return false
+	}
+	return uc.getPlainSize(driveFile.Size, driveFile.PlainSize, useEncryption) <= service.ThumbnailMaxSourceSize
+}
+
+// thumbnailSource открывает содержимое конкретной версии файла, из которой строится превью
+func (uc *driveUseCase) thumbnailSource(
+	in *dto.GetThumbnail,
+	driveStruct *entity.DriveStruct,
+	driveFile *entity.DriveFile,
+	user *entity.User,
+) func(ctx context.Context) (io.Reader, error) {
+	return func(ctx context.Context) (io.Reader, error) {
+		fileResponse, err := uc.GetFile(ctx, &dto.GetFile{
+			StructID:      driveStruct.ID,
+			SavePath:      in.SavePath,
+			UseEncryption: in.UseEncryption,
+			Keyring:       in.Keyring,
+			VersionID:     &driveFile.ID,
+		}, user)
+		if err != nil {
+			return nil, err
+		}
+		return fileResponse.File, nil
+	}
+}
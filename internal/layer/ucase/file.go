@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5"
 	"io"
 	"net/http"
@@ -34,6 +35,7 @@ var (
 type FileUseCase interface {
 	Upload(ctx context.Context, in dto.UploadFile, userEntity *entity.User) (*entity.File, error)
 	GetFileByHash(ctx context.Context, in dto.GetFileByHash) (*dto.FileResponse, error)
+	GetThumbnailByHash(ctx context.Context, in dto.GetThumbnail) (*dto.FileResponse, error)
 	DeleteByID(ctx context.Context, fileID int, generalPath string) error
 	CleanUnused(ctx context.Context, generalPath string) error
 	GetAllowedMimeTypes() map[string][]string
@@ -93,13 +95,24 @@ func (uc *fileUseCase) Upload(ctx context.Context, in dto.UploadFile, userEntity
 		return nil, ErrFileTooLarge
 	}
 
-	allFilesSize, err := uc.repositories.FileRepository.GetFilesSizeByUser(ctx, userEntity.ID)
+	// квота учитывает размер файла в хранилище, зашифрованный файл немного больше исходного
+	plainSize := int64(len(data))
+	storedSize := plainSize
+	if in.UseEncryption {
+		storedSize = fileService.EncryptedSize(plainSize)
+	}
+
+	limits, err := getStorageLimits(ctx, uc.repositories, userEntity.ID, dto.StorageLimits{FilesLimit: in.StorageMaxSize})
 	if err != nil {
-		logging.GetLogger(ctx).Error(err)
-		return nil, postgres.ErrUnexpectedDBError
+		return nil, err
+	}
+
+	usage, err := getStorageUsage(ctx, uc.repositories, userEntity.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	if (allFilesSize + int64(len(data))) > in.StorageMaxSize {
+	if (usage.FilesUsed + storedSize) > limits.FilesLimit {
 		return nil, ErrFileSystemIsFull
 	}
 
@@ -141,48 +154,68 @@ func (uc *fileUseCase) Upload(ctx context.Context, in dto.UploadFile, userEntity
 		return nil, ErrFileNotSafeFilename
 	}
 
-	newFilename, err := fileService.GenerateNewFileName(fileExt)
+	middleFilePath, err := fileService.GenerateNewFilePath(fileExt)
 	if err != nil {
 		return nil, err
 	}
+	fullFilePath := filepath.Join(in.SavePath, middleFilePath)
 
-	maxFileID, err := uc.repositories.FileRepository.GetLastID(ctx)
+	fileHash, err := fileService.GenerateFileHash()
 	if err != nil {
-		logging.GetLogger(ctx).Error(err)
-		return nil, postgres.ErrUnexpectedDBError
+		return nil, err
 	}
 
-	middleFilePath := filepath.Join(fileService.GetMiddlePathByFileId(maxFileID+1), newFilename)
-	fullFilePath := filepath.Join(in.SavePath, middleFilePath)
+	cipher := newNoteCipher(uc.repositories, in.UseEncryption, in.Keyring)
+	fileReader, dataKeyID, err := cipher.encrypt(ctx, bytes.NewReader(data), userEntity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = beginStorageWrite(ctx, uc.repositories, fullFilePath); err != nil {
+		return nil, err
+	}
 
 	saveDto := &dto.SaveFile{
-		File:      bytes.NewReader(data),
+		File:      fileReader,
 		SavePath:  fullFilePath,
-		SizeBytes: int64(len(data)),
+		SizeBytes: storedSize,
 	}
 
 	saveErr := uc.repositories.StorageRepository.Save(ctx, saveDto)
 	if saveErr != nil {
+		abortStorageWrite(ctx, uc.repositories, fullFilePath)
 		return nil, ErrFileSave
 	}
 
-	fileHash, err := fileService.GenerateFileHash()
-	if err != nil {
-		return nil, err
-	}
-
 	fileEntity := &entity.File{
 		UserID:           userEntity.ID,
 		OriginalFilename: in.OriginalFilename,
 		FilePath:         middleFilePath,
 		Ext:              fileExt,
-		Size:             len(data),
+		Size:             int(storedSize),
 		Hash:             fileHash,
 		CreatedAt:        time.Now().UTC(),
+		PlainSize:        &plainSize,
+		DataKeyID:        dataKeyID,
 	}
 
-	_, err = uc.repositories.FileRepository.Create(ctx, fileEntity)
+	// место резервируется под блокировкой счетчика, чтобы параллельные загрузки не превысили квоту
+	err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		repositoriesTx := uc.repositories.WithTx(tx)
+
+		if err := reserveStorage(ctx, repositoriesTx, userEntity.ID, 0, storedSize, limits); err != nil {
+			return err
+		}
+		if _, err := repositoriesTx.FileRepository.Create(ctx, fileEntity); err != nil {
+			return err
+		}
+		return commitStorageWrite(ctx, repositoriesTx, fullFilePath)
+	})
 	if err != nil {
+		abortStorageWrite(ctx, uc.repositories, fullFilePath)
+		if errors.Is(err, ErrFileSystemIsFull) {
+			return nil, err
+		}
 		logging.GetLogger(ctx).Error(err)
 		return nil, postgres.ErrUnexpectedDBError
 	}
@@ -207,13 +240,63 @@ func (uc *fileUseCase) GetFileByHash(ctx context.Context, in dto.GetFileByHash)
 		return nil, err
 	}
 
+	cipher := newNoteCipher(uc.repositories, false, in.Keyring)
+	decrypted, err := cipher.decrypt(ctx, fileReader, fileEntity.DataKeyID, int64(fileEntity.Size), fileEntity.PlainSize)
+	if err != nil {
+		closeReader(fileReader)
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveDecrypting, err))
+		return nil, ErrDriveDecrypting
+	}
+
 	fileResponse := &dto.FileResponse{
-		File:             fileReader,
+		File:             decrypted,
 		OriginalFilename: fileEntity.OriginalFilename,
 	}
 	return fileResponse, nil
 }
 
+// GetThumbnailByHash отдает превью изображения, прикрепленного к заметке. Превью строится при первом запросе
+func (uc *fileUseCase) GetThumbnailByHash(ctx context.Context, in dto.GetThumbnail) (*dto.FileResponse, error) {
+	fileEntity, err := uc.repositories.FileRepository.GetByHash(ctx, in.Hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFileNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	plainSize := int64(fileEntity.Size)
+	if fileEntity.PlainSize != nil {
+		plainSize = *fileEntity.PlainSize
+	}
+
+	thumbnailService := service.NewFile().ThumbnailService()
+	if !thumbnailService.Supports(fileEntity.Ext) || plainSize > service.ThumbnailMaxSourceSize {
+		return nil, ErrThumbnailUnsupported
+	}
+
+	cipher := newNoteCipher(uc.repositories, false, in.Keyring)
+	key := thumbnailKey(in.SavePath, thumbnailKindFile, fileEntity.ID, in.Size)
+	fileResponse, err := loadThumbnail(ctx, uc.repositories, key, &in, func(ctx context.Context) (io.Reader, error) {
+		fileReader, err := uc.repositories.StorageRepository.GetFile(ctx, filepath.Join(in.SavePath, fileEntity.FilePath))
+		if err != nil {
+			return nil, err
+		}
+		decrypted, err := cipher.decrypt(ctx, fileReader, fileEntity.DataKeyID, int64(fileEntity.Size), fileEntity.PlainSize)
+		if err != nil {
+			closeReader(fileReader)
+			return nil, fmt.Errorf("%w: %w", ErrDriveDecrypting, err)
+		}
+		return decrypted, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	fileResponse.OriginalFilename = fileEntity.OriginalFilename + ".jpg"
+	return fileResponse, nil
+}
+
 func (uc *fileUseCase) DeleteByID(ctx context.Context, fileID int, generalPath string) error {
 	fileEntity, err := uc.repositories.FileRepository.GetByID(ctx, fileID)
 	if err != nil {
@@ -231,12 +314,22 @@ func (uc *fileUseCase) DeleteByID(ctx context.Context, fileID int, generalPath s
 		return err
 	}
 
-	err = uc.repositories.FileRepository.DeleteByID(ctx, fileID)
+	err = repository.WithTransaction(ctx, uc.repositories.TransactionRepository, func(tx pgx.Tx) error {
+		repositoriesTx := uc.repositories.WithTx(tx)
+
+		if err := repositoriesTx.FileRepository.DeleteByID(ctx, fileID); err != nil {
+			return err
+		}
+		return repositoriesTx.StorageUsageRepository.Add(ctx, fileEntity.UserID, 0, -int64(fileEntity.Size))
+	})
 	if err != nil {
 		logging.GetLogger(ctx).Error(err)
 		return err
 	}
 
+	if service.NewFile().ThumbnailService().Supports(fileEntity.Ext) {
+		deleteThumbnails(ctx, uc.repositories, generalPath, thumbnailKindFile, []int{fileEntity.ID})
+	}
 	return nil
 }
 
@@ -6,16 +6,26 @@ import (
 	"assistant-go/internal/layer/repository"
 	"assistant-go/internal/logging"
 	"assistant-go/internal/storage/postgres"
+	"assistant-go/pkg/keyring"
 	"assistant-go/pkg/utils"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/tidwall/gjson"
 	"time"
 )
 
 var (
-	ErrNoteNotFound = errors.New("note not found")
+	ErrNoteNotFound   = errors.New("note not found")
+	ErrNoteEncrypting = errors.New("error encrypting note")
+	ErrNoteDecrypting = errors.New("error decrypting note")
+)
+
+// дополнительные данные AEAD не дают подставить зашифрованный заголовок вместо содержимого и наоборот
+const (
+	noteBlocksAAD = "notes.note_blocks"
+	noteTitleAAD  = "notes.title"
 )
 
 type NoteUseCase interface {
@@ -30,15 +40,24 @@ type NoteUseCase interface {
 }
 
 type noteUseCase struct {
-	repositories repository.Repositories
+	repositories  repository.Repositories
+	useEncryption bool
+	keys          *keyring.Keyring
 }
 
-func NewNoteUseCase(repositories *repository.Repositories) NoteUseCase {
+func NewNoteUseCase(repositories *repository.Repositories, useEncryption bool, keys *keyring.Keyring) NoteUseCase {
 	return &noteUseCase{
-		repositories: *repositories,
+		repositories:  *repositories,
+		useEncryption: useEncryption,
+		keys:          keys,
 	}
 }
 
+// cipher создается на каждый запрос: развернутые ключи данных кэшируются только на время запроса
+func (uc *noteUseCase) cipher() *driveCipher {
+	return newNoteCipher(&uc.repositories, uc.useEncryption, uc.keys)
+}
+
 func (uc *noteUseCase) Create(ctx context.Context, in dto.NoteCreate, userEntity *entity.User) (*entity.Note, error) {
 	_, err := uc.repositories.NoteCategoryRepository.FindByIDAndUser(ctx, userEntity.ID, in.CategoryID)
 	if err != nil {
@@ -67,11 +86,18 @@ func (uc *noteUseCase) Create(ctx context.Context, in dto.NoteCreate, userEntity
 		Pinned:     pinned,
 	}
 
-	data, err := uc.repositories.NoteRepository.Create(ctx, noteEntity)
+	// заголовок и вложения определяются по содержимому до шифрования
+	sealed, err := sealNote(ctx, uc.cipher(), noteEntity, userEntity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := uc.repositories.NoteRepository.Create(ctx, *sealed)
 	if err != nil {
 		logging.GetLogger(ctx).Error(err)
 		return nil, postgres.ErrUnexpectedDBError
 	}
+	noteEntity.ID = data.ID
 
 	fileIDs, _ := getFileIDsByBlocks(string(in.NoteBlocks))
 	err = uc.repositories.FileNoteLinkRepository.Upsert(ctx, data.ID, fileIDs)
@@ -79,7 +105,7 @@ func (uc *noteUseCase) Create(ctx context.Context, in dto.NoteCreate, userEntity
 		return nil, err
 	}
 
-	return data, nil
+	return &noteEntity, nil
 }
 
 func (uc *noteUseCase) GetAll(ctx context.Context, catIdStruct dto.RequiredID, userEntity *entity.User) ([]*entity.NoteMinimal, error) {
@@ -107,6 +133,23 @@ func (uc *noteUseCase) GetAll(ctx context.Context, catIdStruct dto.RequiredID, u
 			return nil, postgres.ErrUnexpectedDBError
 		}
 	}
+
+	cipher := uc.cipher()
+	for _, note := range notes {
+		if note.DataKeyID == nil {
+			continue
+		}
+		key, err := cipher.key(ctx, note.DataKeyID)
+		if err != nil {
+			logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w: %w", ErrNoteDecrypting, ErrDriveDataKey, err))
+			return nil, ErrNoteDecrypting
+		}
+		if note.Title, err = openNoteTitle(key, note.EncryptedTitle); err != nil {
+			logging.GetLogger(ctx).Error(fmt.Errorf("%w: note %d: %w", ErrNoteDecrypting, note.ID, err))
+			return nil, ErrNoteDecrypting
+		}
+		note.EncryptedTitle = nil
+	}
 	return notes, nil
 }
 
@@ -155,7 +198,12 @@ func (uc *noteUseCase) Update(ctx context.Context, in dto.NoteUpdate, userEntity
 	currentNote.UpdatedAt = time.Now().UTC()
 	currentNote.Pinned = pinned
 
-	err = uc.repositories.NoteRepository.Update(ctx, currentNote)
+	sealed, err := sealNote(ctx, uc.cipher(), *currentNote, userEntity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.repositories.NoteRepository.Update(ctx, sealed)
 	if err != nil {
 		logging.GetLogger(ctx).Error(err)
 		return nil, postgres.ErrUnexpectedDBError
@@ -167,6 +215,7 @@ func (uc *noteUseCase) Update(ctx context.Context, in dto.NoteUpdate, userEntity
 		return nil, err
 	}
 
+	currentNote.EncryptedBlocks, currentNote.EncryptedTitle, currentNote.DataKeyID = nil, nil, sealed.DataKeyID
 	return currentNote, nil
 }
 
@@ -213,6 +262,9 @@ func (uc *noteUseCase) GetOne(ctx context.Context, noteIdStruct dto.RequiredID,
 		return nil, postgres.ErrUnexpectedDBError
 	}
 
+	if err = openNote(ctx, uc.cipher(), currentNote); err != nil {
+		return nil, err
+	}
 	return currentNote, nil
 }
 
@@ -334,5 +386,81 @@ func (uc *noteUseCase) GetOneByShareHash(ctx context.Context, hash string) (*ent
 		return nil, postgres.ErrUnexpectedDBError
 	}
 
+	if err = openNote(ctx, uc.cipher(), note); err != nil {
+		return nil, err
+	}
 	return note, nil
 }
+
+// sealNote возвращает копию заметки для сохранения в БД. При включенном шифровании содержимое
+// и заголовок шифруются активным ключом данных владельца, открытые колонки остаются пустыми
+func sealNote(ctx context.Context, cipher *driveCipher, note entity.Note, userID int) (*entity.Note, error) {
+	note.EncryptedBlocks, note.EncryptedTitle, note.DataKeyID = nil, nil, nil
+	if !cipher.useEncryption {
+		return &note, nil
+	}
+
+	dataKeyID, key, err := cipher.userKey(ctx, userID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w: %w", ErrNoteEncrypting, ErrDriveDataKey, err))
+		return nil, ErrNoteEncrypting
+	}
+
+	blocks, err := keyring.Seal(key, note.NoteBlocks, []byte(noteBlocksAAD))
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrNoteEncrypting, err))
+		return nil, ErrNoteEncrypting
+	}
+	var title []byte
+	if note.Title != nil {
+		title, err = keyring.Seal(key, []byte(*note.Title), []byte(noteTitleAAD))
+		if err != nil {
+			logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrNoteEncrypting, err))
+			return nil, ErrNoteEncrypting
+		}
+	}
+
+	note.NoteBlocks, note.Title = nil, nil
+	note.EncryptedBlocks, note.EncryptedTitle, note.DataKeyID = blocks, title, &dataKeyID
+	return &note, nil
+}
+
+// openNote расшифровывает содержимое и заголовок зашифрованной заметки
+func openNote(ctx context.Context, cipher *driveCipher, note *entity.Note) error {
+	if note.DataKeyID == nil {
+		return nil
+	}
+
+	key, err := cipher.key(ctx, note.DataKeyID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w: %w", ErrNoteDecrypting, ErrDriveDataKey, err))
+		return ErrNoteDecrypting
+	}
+
+	blocks, err := keyring.Open(key, note.EncryptedBlocks, []byte(noteBlocksAAD))
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: note %d: %w", ErrNoteDecrypting, note.ID, err))
+		return ErrNoteDecrypting
+	}
+	title, err := openNoteTitle(key, note.EncryptedTitle)
+	if err != nil {
+		logging.GetLogger(ctx).Error(fmt.Errorf("%w: note %d: %w", ErrNoteDecrypting, note.ID, err))
+		return ErrNoteDecrypting
+	}
+
+	note.NoteBlocks, note.Title = blocks, title
+	note.EncryptedBlocks, note.EncryptedTitle = nil, nil
+	return nil
+}
+
+func openNoteTitle(key []byte, encrypted []byte) (*string, error) {
+	if encrypted == nil {
+		return nil, nil
+	}
+	title, err := keyring.Open(key, encrypted, []byte(noteTitleAAD))
+	if err != nil {
+		return nil, err
+	}
+	result := string(title)
+	return &result, nil
+}
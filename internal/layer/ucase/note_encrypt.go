@@ -0,0 +1,163 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+var ErrNoteEncryptDisabled = errors.New("encryption of notes and note attachments is disabled, there is nothing to encrypt")
+
+type NoteEncryptUseCase interface {
+	Encrypt(ctx context.Context, in dto.NoteEncryptIn) (*dto.NoteEncryptProgress, error)
+}
+
+type noteEncryptUseCase struct {
+	repositories *repository.Repositories
+}
+
+func NewNoteEncryptUseCase(repositories *repository.Repositories) NoteEncryptUseCase {
+	return &noteEncryptUseCase{repositories: repositories}
+}
+
+// Encrypt шифрует активным ключом данных владельца вложения и содержимое заметок, сохраненные
+// до включения шифрования или зашифрованные прежним ключом (после drive-rotate-key --full).
+// Вложение сохраняется под новым ключом хранилища, поэтому прерванный запуск можно просто повторить.
+// Заметка, измененная во время шифрования, пропускается: при сохранении она уже зашифрована
+func (uc *noteEncryptUseCase) Encrypt(ctx context.Context, in dto.NoteEncryptIn) (*dto.NoteEncryptProgress, error) {
+	if !in.EncryptFiles && !in.EncryptNotes {
+		return nil, ErrNoteEncryptDisabled
+	}
+	if in.Keyring.CurrentID() == "" {
+		return nil, ErrDriveKeyRotateNoMasterKey
+	}
+
+	progress := &dto.NoteEncryptProgress{}
+	report := func() {
+		if in.OnProgress != nil {
+			in.OnProgress(*progress)
+		}
+	}
+
+	cipher := newNoteCipher(uc.repositories, true, in.Keyring)
+	if in.EncryptFiles {
+		if err := uc.encryptFiles(ctx, in.FilesSavePath, cipher, progress, report); err != nil {
+			report()
+			return progress, err
+		}
+	}
+	if in.EncryptNotes {
+		if err := uc.encryptNotes(ctx, cipher, progress, report); err != nil {
+			report()
+			return progress, err
+		}
+	}
+
+	deleted, err := uc.repositories.DriveDataKeyRepository.DeleteUnused(ctx)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return progress, postgres.ErrUnexpectedDBError
+	}
+	progress.DeletedKeys = deleted
+
+	report()
+	return progress, nil
+}
+
+func (uc *noteEncryptUseCase) encryptFiles(
+	ctx context.Context,
+	savePath string,
+	cipher *driveCipher,
+	progress *dto.NoteEncryptProgress,
+	report func(),
+) error {
+	afterID := 0
+	for {
+		objects, err := uc.repositories.DriveDataKeyRepository.GetStaleObjects(
+			ctx,
+			repository.StorageObjectFiles,
+			afterID,
+			driveKeyRotateBatchSize,
+		)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+
+		for _, object := range objects {
+			afterID = object.ID
+
+			key := filepath.Join(savePath, object.Path)
+			replaced, err := reencryptObject(ctx, uc.repositories, savePath, cipher, object)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			if !replaced {
+				progress.Skipped = append(progress.Skipped, key)
+				continue
+			}
+
+			progress.Files++
+			if progress.Files%driveKeyRotateProgressEvery == 0 {
+				report()
+			}
+		}
+	}
+}
+
+func (uc *noteEncryptUseCase) encryptNotes(
+	ctx context.Context,
+	cipher *driveCipher,
+	progress *dto.NoteEncryptProgress,
+	report func(),
+) error {
+	noteRepo := uc.repositories.NoteRepository
+
+	afterID := 0
+	for {
+		notes, err := noteRepo.GetStale(ctx, afterID, driveKeyRotateBatchSize)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+		if len(notes) == 0 {
+			return nil
+		}
+
+		for _, note := range notes {
+			afterID = note.ID
+
+			previousDataKeyID := note.DataKeyID
+			if err = openNote(ctx, cipher, &note.Note); err != nil {
+				return fmt.Errorf("note %d: %w", note.ID, err)
+			}
+			sealed, err := sealNote(ctx, cipher, note.Note, note.UserID)
+			if err != nil {
+				return fmt.Errorf("note %d: %w", note.ID, err)
+			}
+
+			replaced, err := noteRepo.ReplaceEncrypted(ctx, sealed, previousDataKeyID)
+			if err != nil {
+				logging.GetLogger(ctx).Error(err)
+				return postgres.ErrUnexpectedDBError
+			}
+			if !replaced {
+				progress.Skipped = append(progress.Skipped, fmt.Sprintf("note %d", note.ID))
+				continue
+			}
+
+			progress.Notes++
+			if progress.Notes%driveKeyRotateProgressEvery == 0 {
+				report()
+			}
+		}
+	}
+}
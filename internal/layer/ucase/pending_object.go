@@ -0,0 +1,95 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrStorageWriteAbortFailed = errors.New("failed to delete abandoned storage objects")
+
+const pendingObjectsBatchSize = 500
+
+type PendingObjectUseCase interface {
+	ReapAbandoned(ctx context.Context, ttl time.Duration) (int, error)
+}
+
+type pendingObjectUseCase struct {
+	repositories *repository.Repositories
+}
+
+func NewPendingObjectUseCase(repositories *repository.Repositories) PendingObjectUseCase {
+	return &pendingObjectUseCase{
+		repositories: repositories,
+	}
+}
+
+// ReapAbandoned удаляет объекты, запись которых началась раньше ttl назад, но так и не была
+// закреплена в БД: процесс упал между сохранением объекта и транзакцией или не смог удалить объект после ошибки
+func (uc *pendingObjectUseCase) ReapAbandoned(ctx context.Context, ttl time.Duration) (int, error) {
+	before := time.Now().UTC().Add(-ttl)
+
+	reaped := 0
+	for {
+		list, err := uc.repositories.PendingObjectRepository.GetOlder(ctx, before, pendingObjectsBatchSize)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return reaped, postgres.ErrUnexpectedDBError
+		}
+		if len(list) == 0 {
+			return reaped, nil
+		}
+
+		keys := make([]string, 0, len(list))
+		for _, object := range list {
+			keys = append(keys, object.StorageKey)
+		}
+		removed := abortStorageWrite(ctx, uc.repositories, keys...)
+		if removed == 0 {
+			// хранилище недоступно: отметки остаются до следующего запуска
+			return reaped, ErrStorageWriteAbortFailed
+		}
+		reaped += removed
+	}
+}
+
+// beginStorageWrite отмечает ключи как незавершенную запись перед сохранением объектов. Отметка снимается
+// в транзакции, создающей ссылающиеся на объекты строки (commitStorageWrite), а при ошибке - вместе
+// с удалением объектов (abortStorageWrite). Объекты, оставшиеся после сбоя, удаляет ReapAbandoned
+func beginStorageWrite(ctx context.Context, repositories *repository.Repositories, keys ...string) error {
+	now := time.Now().UTC()
+	for _, key := range keys {
+		if err := repositories.PendingObjectRepository.Create(ctx, key, now); err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+	}
+	return nil
+}
+
+func commitStorageWrite(ctx context.Context, repositoriesTx *repository.Repositories, keys ...string) error {
+	return repositoriesTx.PendingObjectRepository.Delete(ctx, keys...)
+}
+
+// abortStorageWrite удаляет объекты незавершенной записи и их отметки. Отметка объекта, который
+// не удалось удалить, остается, чтобы его удалил ReapAbandoned. Возвращает количество снятых отметок
+func abortStorageWrite(ctx context.Context, repositories *repository.Repositories, keys ...string) int {
+	removed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		err := repositories.StorageRepository.Delete(ctx, key)
+		if err != nil && !errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
+			logging.GetLogger(ctx).Error(err)
+			continue
+		}
+		removed = append(removed, key)
+	}
+
+	if err := repositories.PendingObjectRepository.Delete(ctx, removed...); err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return 0
+	}
+	return len(removed)
+}
@@ -0,0 +1,368 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Виды расхождений, которые находит проверка хранилища
+const (
+	StorageFsckMissing        = "missing"
+	StorageFsckSizeMismatch   = "size_mismatch"
+	StorageFsckHashMismatch   = "hash_mismatch"
+	StorageFsckChunksMismatch = "chunks_mismatch"
+	StorageFsckUnreadable     = "unreadable"
+	StorageFsckOrphan         = "orphan"
+)
+
+// storageQuarantineDir - каталог внутри каталога сохранения, куда переносятся лишние объекты
+const storageQuarantineDir = "quarantine"
+
+type StorageFsckUseCase interface {
+	Check(ctx context.Context, in dto.StorageFsckIn) (*dto.StorageFsckReport, error)
+}
+
+type storageFsckUseCase struct {
+	repositories *repository.Repositories
+}
+
+func NewStorageFsckUseCase(repositories *repository.Repositories) StorageFsckUseCase {
+	return &storageFsckUseCase{
+		repositories: repositories,
+	}
+}
+
+// fsckRecord - объект, на который ссылается запись в БД, и найден ли он при обходе хранилища
+type fsckRecord struct {
+	object *entity.StorageObject
+	seen   bool
+}
+
+// fsckOrphan - лишний объект и каталог обхода, в котором он найден
+type fsckOrphan struct {
+	root string
+	key  string
+}
+
+// Check сверяет содержимое хранилища с drive_blobs, drive_files, drive_file_chunks и files:
+// ищет записи без объектов, объекты без записей (превью - без файла), расхождения размеров
+// и, если включено, перечитывает объекты с известным SHA-256. При Repair лишние объекты переносятся
+// в каталог quarantine внутри каталога сохранения, откуда их можно вернуть или удалить вручную
+func (uc *storageFsckUseCase) Check(ctx context.Context, in dto.StorageFsckIn) (*dto.StorageFsckReport, error) {
+	report := &dto.StorageFsckReport{}
+	cipher := newDriveCipher(uc.repositories, in.UseEncryption, in.Keyring)
+
+	records, err := uc.loadRecords(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	report.Records = len(records)
+
+	var orphans []*fsckOrphan
+	now := time.Now()
+	for _, root := range storageFsckRoots(in.DriveSavePath, in.FilesSavePath) {
+		quarantinePrefix := filepath.Join(root, storageQuarantineDir) + "/"
+
+		err = uc.repositories.StorageRepository.List(ctx, root, func(object *dto.StorageObjectInfo) error {
+			key := filepath.Clean(object.Key)
+			if strings.HasPrefix(key, quarantinePrefix) {
+				return nil
+			}
+			report.Objects++
+
+			if record := records[key]; record != nil {
+				record.seen = true
+				if object.Size != record.object.Size {
+					report.Issues = append(report.Issues, uc.recordIssue(
+						StorageFsckSizeMismatch, key, record.object,
+						fmt.Sprintf("expected %d bytes, found %d", record.object.Size, object.Size),
+					))
+				}
+				return nil
+			}
+
+			if now.Sub(object.ModTime) < in.MinOrphanAge {
+				return nil
+			}
+			valid, err := uc.isThumbnailOfExistingFile(ctx, in, key)
+			if err != nil || valid {
+				return err
+			}
+
+			orphans = append(orphans, &fsckOrphan{root: root, key: key})
+			report.Issues = append(report.Issues, &dto.StorageFsckIssue{
+				Problem: StorageFsckOrphan,
+				Key:     key,
+				Detail:  fmt.Sprintf("%d bytes", object.Size),
+			})
+			return nil
+		})
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, err
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(records)) {
+		record := records[key]
+		if !record.seen {
+			report.Issues = append(report.Issues, uc.recordIssue(StorageFsckMissing, key, record.object, ""))
+			continue
+		}
+		if in.VerifyHash && record.object.Kind != repository.StorageObjectFiles && isSHA256Hex(record.object.SHA256) {
+			if issue := uc.verifyObjectHash(ctx, cipher, key, record.object); issue != nil {
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+	}
+
+	chunkIssues, err := uc.checkChunkedFiles(ctx, in, cipher, records)
+	if err != nil {
+		return nil, err
+	}
+	report.Issues = append(report.Issues, chunkIssues...)
+
+	if in.Repair {
+		for _, orphan := range orphans {
+			quarantineKey := filepath.Join(orphan.root, storageQuarantineDir, strings.TrimPrefix(orphan.key, orphan.root+"/"))
+			if err = uc.repositories.StorageRepository.Copy(ctx, orphan.key, quarantineKey); err != nil {
+				logging.GetLogger(ctx).Error(err)
+				return report, err
+			}
+			if err = uc.repositories.StorageRepository.Delete(ctx, orphan.key); err != nil {
+				logging.GetLogger(ctx).Error(err)
+				return report, err
+			}
+			report.Quarantined++
+		}
+	}
+
+	return report, nil
+}
+
+// loadRecords собирает объекты, на которые ссылаются записи в БД, по их ключам в хранилище
+func (uc *storageFsckUseCase) loadRecords(ctx context.Context, in dto.StorageFsckIn) (map[string]*fsckRecord, error) {
+	records := make(map[string]*fsckRecord)
+	for _, kind := range repository.StorageObjectKinds {
+		savePath := in.DriveSavePath
+		if kind == repository.StorageObjectFiles {
+			savePath = in.FilesSavePath
+		}
+
+		lastID := 0
+		for {
+			objects, err := uc.repositories.StorageMigrateRepository.GetObjects(ctx, kind, lastID, storageMigrateBatchSize)
+			if err != nil {
+				logging.GetLogger(ctx).Error(err)
+				return nil, postgres.ErrUnexpectedDBError
+			}
+			if len(objects) == 0 {
+				break
+			}
+			for _, object := range objects {
+				records[filepath.Join(savePath, object.Path)] = &fsckRecord{object: object}
+				lastID = object.ID
+			}
+		}
+	}
+	return records, nil
+}
+
+// checkChunkedFiles сверяет размер завершенных чанковых файлов с суммой размеров чанков
+// и, если включено, хэш склеенного содержимого. Файлы с отсутствующими чанками не хэшируются
+func (uc *storageFsckUseCase) checkChunkedFiles(
+	ctx context.Context,
+	in dto.StorageFsckIn,
+	cipher *driveCipher,
+	records map[string]*fsckRecord,
+) ([]*dto.StorageFsckIssue, error) {
+	drive := &driveUseCase{repositories: uc.repositories}
+
+	var issues []*dto.StorageFsckIssue
+	lastID := 0
+	for {
+		files, err := uc.repositories.DriveFileRepository.GetCompleteChunkedFiles(ctx, lastID, storageMigrateBatchSize)
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+		if len(files) == 0 {
+			return issues, nil
+		}
+
+		for _, driveFile := range files {
+			lastID = driveFile.ID
+
+			chunks, err := uc.repositories.DriveFileChunkRepository.GetByFileID(ctx, driveFile.ID)
+			if err != nil {
+				logging.GetLogger(ctx).Error(err)
+				return nil, postgres.ErrUnexpectedDBError
+			}
+
+			var chunksSize int64
+			complete := true
+			for _, fileChunk := range chunks {
+				chunksSize += fileChunk.Size
+				if record := records[filepath.Join(in.DriveSavePath, fileChunk.Path)]; record == nil || !record.seen {
+					complete = false
+				}
+			}
+
+			issue := &dto.StorageFsckIssue{Kind: repository.StorageObjectDriveFiles, ID: driveFile.ID}
+			if chunksSize != driveFile.Size {
+				issue.Problem = StorageFsckChunksMismatch
+				issue.Detail = fmt.Sprintf("expected %d bytes, chunks hold %d", driveFile.Size, chunksSize)
+				issues = append(issues, issue)
+				continue
+			}
+			if !in.VerifyHash || !complete || !isSHA256Hex(driveFile.SHA256) {
+				continue
+			}
+
+			reader := drive.openChunks(ctx, chunks, in.DriveSavePath, cipher, 0, -1)
+			hash, err := drive.hashReader(reader)
+			_ = reader.Close()
+			if err != nil {
+				issue.Problem = StorageFsckUnreadable
+				issue.Detail = err.Error()
+				issues = append(issues, issue)
+			} else if hash != normalizeSHA256(driveFile.SHA256) {
+				issue.Problem = StorageFsckHashMismatch
+				issue.Detail = fmt.Sprintf("expected %s, found %s", normalizeSHA256(driveFile.SHA256), hash)
+				issues = append(issues, issue)
+			}
+		}
+	}
+}
+
+// verifyObjectHash перечитывает объект (расшифровывая его, если он зашифрован) и сверяет SHA-256
+func (uc *storageFsckUseCase) verifyObjectHash(
+	ctx context.Context,
+	cipher *driveCipher,
+	key string,
+	object *entity.StorageObject,
+) *dto.StorageFsckIssue {
+	hash, err := func() (string, error) {
+		var reader io.Reader
+		reader, err := uc.repositories.StorageRepository.GetFile(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		if closer, ok := reader.(io.Closer); ok {
+			defer func() { _ = closer.Close() }()
+		}
+
+		reader, err = cipher.decrypt(ctx, reader, object.DataKeyID, object.Size, object.PlainSize)
+		if err != nil {
+			return "", err
+		}
+		return (&driveUseCase{}).hashReader(reader)
+	}()
+	if err != nil {
+		return uc.recordIssue(StorageFsckUnreadable, key, object, err.Error())
+	}
+
+	expected := normalizeSHA256(object.SHA256)
+	if hash != expected {
+		return uc.recordIssue(StorageFsckHashMismatch, key, object, fmt.Sprintf("expected %s, found %s", expected, hash))
+	}
+	return nil
+}
+
+// isThumbnailOfExistingFile сообщает, что объект - превью файла, запись которого есть в БД
+func (uc *storageFsckUseCase) isThumbnailOfExistingFile(ctx context.Context, in dto.StorageFsckIn, key string) (bool, error) {
+	var (
+		fileID int
+		err    error
+	)
+	switch {
+	case strings.HasPrefix(key, filepath.Join(in.DriveSavePath, "thumbnails", thumbnailKindDrive)+"/"):
+		if fileID, err = parseThumbnailFileID(key); err != nil {
+			return false, nil
+		}
+		_, err = uc.repositories.DriveFileRepository.GetByID(ctx, fileID)
+	case strings.HasPrefix(key, filepath.Join(in.FilesSavePath, "thumbnails", thumbnailKindFile)+"/"):
+		if fileID, err = parseThumbnailFileID(key); err != nil {
+			return false, nil
+		}
+		_, err = uc.repositories.FileRepository.GetByID(ctx, fileID)
+	default:
+		return false, nil
+	}
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return false, postgres.ErrUnexpectedDBError
+	}
+	return true, nil
+}
+
+func (uc *storageFsckUseCase) recordIssue(problem string, key string, object *entity.StorageObject, detail string) *dto.StorageFsckIssue {
+	return &dto.StorageFsckIssue{
+		Problem: problem,
+		Kind:    object.Kind,
+		ID:      object.ID,
+		Key:     key,
+		Detail:  detail,
+	}
+}
+
+// parseThumbnailFileID извлекает ID файла из имени превью вида "ID_размер.jpg" (см. thumbnailKey)
+func parseThumbnailFileID(key string) (int, error) {
+	name := strings.TrimSuffix(filepath.Base(key), ".jpg")
+	fileID, _, found := strings.Cut(name, "_")
+	if !found {
+		return 0, ErrThumbnailUnsupported
+	}
+	return strconv.Atoi(fileID)
+}
+
+// storageFsckRoots возвращает каталоги сохранения для обхода, исключая совпадающие и вложенные друг в друга
+func storageFsckRoots(savePaths ...string) []string {
+	var roots []string
+	for _, savePath := range savePaths {
+		savePath = filepath.Clean(savePath)
+
+		nested := false
+		for i, root := range roots {
+			switch {
+			case savePath == root || strings.HasPrefix(savePath, root+"/"):
+				nested = true
+			case strings.HasPrefix(root, savePath+"/"):
+				roots[i] = savePath
+				nested = true
+			}
+		}
+		if !nested {
+			roots = append(roots, savePath)
+		}
+	}
+	return slices.Compact(roots)
+}
+
+func isSHA256Hex(hash *string) bool {
+	normalized := normalizeSHA256(hash)
+	if len(normalized) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(normalized)
+	return err == nil
+}
@@ -0,0 +1,250 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrStorageMigrateSamePlace    = errors.New("source and target storage must differ")
+	ErrStorageMigrateUnknownPlace = errors.New("storage place is unknown or not configured")
+	ErrStorageMigrateVerifyFailed = errors.New("copied object does not match the source")
+)
+
+const (
+	storageMigrateBatchSize     = 500
+	storageMigrateProgressEvery = 100
+)
+
+type StorageMigrateUseCase interface {
+	Migrate(ctx context.Context, in dto.StorageMigrateIn) (*dto.StorageMigrateProgress, error)
+}
+
+type storageMigrateUseCase struct {
+	repositories *repository.Repositories
+	storages     map[string]repository.FileStorageRepository
+}
+
+// NewStorageMigrateUseCase принимает доступные хранилища по названию места загрузки (config.FileUpload*Place)
+func NewStorageMigrateUseCase(
+	repositories *repository.Repositories,
+	storages map[string]repository.FileStorageRepository,
+) StorageMigrateUseCase {
+	return &storageMigrateUseCase{
+		repositories: repositories,
+		storages:     storages,
+	}
+}
+
+// Migrate переносит в другое хранилище все объекты, на которые ссылаются drive_blobs, drive_files,
+// drive_file_chunks и files. Ключи объектов не меняются, поэтому пути в БД остаются верными.
+// Каждая копия сверяется с источником по размеру и SHA-256. Прогресс сохраняется после каждого объекта,
+// поэтому прерванный перенос продолжается с места остановки, а повторный запуск переносит только новые объекты.
+// Превью не переносятся: они будут построены заново при первом запросе
+func (uc *storageMigrateUseCase) Migrate(ctx context.Context, in dto.StorageMigrateIn) (*dto.StorageMigrateProgress, error) {
+	if in.From == in.To {
+		return nil, ErrStorageMigrateSamePlace
+	}
+	source, target := uc.storages[in.From], uc.storages[in.To]
+	if source == nil || target == nil {
+		return nil, ErrStorageMigrateUnknownPlace
+	}
+
+	migrateRepo := uc.repositories.StorageMigrateRepository
+	if in.Restart {
+		if err := migrateRepo.ResetProgress(ctx, in.From, in.To); err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+	}
+
+	lastIDs, err := migrateRepo.GetProgress(ctx, in.From, in.To)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	progress := &dto.StorageMigrateProgress{}
+	for _, kind := range repository.StorageObjectKinds {
+		count, err := migrateRepo.CountObjects(ctx, kind, lastIDs[kind])
+		if err != nil {
+			logging.GetLogger(ctx).Error(err)
+			return nil, postgres.ErrUnexpectedDBError
+		}
+		progress.Total += count
+	}
+
+	report := func() {
+		if in.OnProgress != nil {
+			in.OnProgress(*progress)
+		}
+	}
+
+	for _, kind := range repository.StorageObjectKinds {
+		savePath := in.DriveSavePath
+		if kind == repository.StorageObjectFiles {
+			savePath = in.FilesSavePath
+		}
+
+		for {
+			objects, err := migrateRepo.GetObjects(ctx, kind, lastIDs[kind], storageMigrateBatchSize)
+			if err != nil {
+				logging.GetLogger(ctx).Error(err)
+				return progress, postgres.ErrUnexpectedDBError
+			}
+			if len(objects) == 0 {
+				break
+			}
+
+			for _, object := range objects {
+				key := filepath.Join(savePath, object.Path)
+				if err = uc.migrateObject(ctx, source, target, key, object, in.DeleteSource, progress); err != nil {
+					report()
+					return progress, fmt.Errorf("%s: %w", key, err)
+				}
+
+				lastIDs[kind] = object.ID
+				if err = migrateRepo.SaveProgress(ctx, in.From, in.To, kind, object.ID, time.Now().UTC()); err != nil {
+					logging.GetLogger(ctx).Error(err)
+					return progress, postgres.ErrUnexpectedDBError
+				}
+
+				progress.Checked++
+				if progress.Checked%storageMigrateProgressEvery == 0 {
+					report()
+				}
+			}
+		}
+	}
+
+	report()
+	return progress, nil
+}
+
+// migrateObject переносит один объект. Если в целевом хранилище уже лежит такая же копия
+// (например, после прерванного запуска), объект повторно не копируется
+func (uc *storageMigrateUseCase) migrateObject(
+	ctx context.Context,
+	source repository.FileStorageRepository,
+	target repository.FileStorageRepository,
+	key string,
+	object *entity.StorageObject,
+	deleteSource bool,
+	progress *dto.StorageMigrateProgress,
+) error {
+	targetSize, targetHash, err := hashStorageObject(ctx, target, key)
+	targetExists := err == nil
+	if err != nil && !errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
+		return err
+	}
+
+	var (
+		sourceSize int64
+		sourceHash string
+	)
+	if targetExists {
+		sourceSize, sourceHash, err = hashStorageObject(ctx, source, key)
+	} else {
+		sourceSize, sourceHash, err = uc.copyObject(ctx, source, target, key, object.Size)
+	}
+	if err != nil {
+		if !errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
+			return err
+		}
+		// источник мог удалить предыдущий запуск с DeleteSource уже после проверки копии
+		if targetExists && targetSize == object.Size {
+			progress.Skipped++
+		} else {
+			progress.Missing = append(progress.Missing, key)
+		}
+		return nil
+	}
+
+	if targetExists && targetSize == sourceSize && targetHash == sourceHash {
+		progress.Skipped++
+	} else {
+		if targetExists {
+			if sourceSize, sourceHash, err = uc.copyObject(ctx, source, target, key, object.Size); err != nil {
+				return err
+			}
+		}
+		if targetSize, targetHash, err = hashStorageObject(ctx, target, key); err != nil {
+			return err
+		}
+		if targetSize != sourceSize || targetHash != sourceHash {
+			return ErrStorageMigrateVerifyFailed
+		}
+		progress.Copied++
+		progress.CopiedBytes += sourceSize
+	}
+
+	if deleteSource {
+		if err = source.Delete(ctx, key); err != nil {
+			return err
+		}
+		progress.Deleted++
+	}
+	return nil
+}
+
+// copyObject копирует объект и возвращает размер и SHA-256 прочитанного из источника содержимого
+func (uc *storageMigrateUseCase) copyObject(
+	ctx context.Context,
+	source repository.FileStorageRepository,
+	target repository.FileStorageRepository,
+	key string,
+	size int64,
+) (int64, string, error) {
+	reader, err := source.GetFile(ctx, key)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		if closer, ok := reader.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}()
+
+	hasher := sha256.New()
+	sourceReader := &countingReader{reader: io.TeeReader(reader, hasher)}
+	err = target.Save(ctx, &dto.SaveFile{File: sourceReader, SavePath: key, SizeBytes: size})
+	if err != nil {
+		return 0, "", err
+	}
+
+	// хранилище может прочитать ровно size байт, остаток дочитывается, чтобы расхождение выявила проверка
+	if _, err = io.Copy(io.Discard, sourceReader); err != nil {
+		return 0, "", err
+	}
+	return sourceReader.count, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func hashStorageObject(ctx context.Context, storage repository.FileStorageRepository, key string) (int64, string, error) {
+	reader, err := storage.GetFile(ctx, key)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		if closer, ok := reader.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, reader)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,218 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrStoragePlanNotFound = errors.New("storage plan not found")
+	ErrStorageQuotaInvalid = errors.New("storage quota must not be negative")
+)
+
+type StorageQuotaUseCase interface {
+	SavePlan(ctx context.Context, name string, driveLimit int64, filesLimit int64) (*entity.StoragePlan, error)
+	GetPlans(ctx context.Context) ([]*entity.StoragePlan, error)
+	SetUserPlan(ctx context.Context, login string, planName *string) error
+	SetUserQuota(ctx context.Context, login string, driveLimit *int64, filesLimit *int64) error
+	ResetUserQuota(ctx context.Context, login string) error
+	GetUserLimits(ctx context.Context, login string, defaults dto.StorageLimits) (*dto.StorageLimits, error)
+	RecalculateUsage(ctx context.Context, login *string) (int, error)
+}
+
+type storageQuotaUseCase struct {
+	repositories *repository.Repositories
+}
+
+func NewStorageQuotaUseCase(repositories *repository.Repositories) StorageQuotaUseCase {
+	return &storageQuotaUseCase{
+		repositories: repositories,
+	}
+}
+
+// SavePlan создает тариф или меняет лимиты существующего тарифа с тем же названием
+func (uc *storageQuotaUseCase) SavePlan(ctx context.Context, name string, driveLimit int64, filesLimit int64) (*entity.StoragePlan, error) {
+	if driveLimit < 0 || filesLimit < 0 {
+		return nil, ErrStorageQuotaInvalid
+	}
+
+	plan, err := uc.repositories.StorageQuotaRepository.SavePlan(ctx, &entity.StoragePlan{
+		Name:       name,
+		DriveLimit: driveLimit,
+		FilesLimit: filesLimit,
+		UpdatedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return plan, nil
+}
+
+func (uc *storageQuotaUseCase) GetPlans(ctx context.Context) ([]*entity.StoragePlan, error) {
+	plans, err := uc.repositories.StorageQuotaRepository.GetPlans(ctx)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return plans, nil
+}
+
+// SetUserPlan назначает пользователю тариф. При planName = nil тариф снимается
+func (uc *storageQuotaUseCase) SetUserPlan(ctx context.Context, login string, planName *string) error {
+	quota, err := uc.getUserQuota(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	quota.PlanID = nil
+	if planName != nil {
+		plan, err := uc.repositories.StorageQuotaRepository.GetPlanByName(ctx, *planName)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrStoragePlanNotFound
+			}
+			logging.GetLogger(ctx).Error(err)
+			return postgres.ErrUnexpectedDBError
+		}
+		quota.PlanID = &plan.ID
+	}
+
+	return uc.saveUserQuota(ctx, quota)
+}
+
+// SetUserQuota задает персональные лимиты в байтах. Лимит nil не меняется
+func (uc *storageQuotaUseCase) SetUserQuota(ctx context.Context, login string, driveLimit *int64, filesLimit *int64) error {
+	if (driveLimit != nil && *driveLimit < 0) || (filesLimit != nil && *filesLimit < 0) {
+		return ErrStorageQuotaInvalid
+	}
+
+	quota, err := uc.getUserQuota(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	if driveLimit != nil {
+		quota.DriveLimit = driveLimit
+	}
+	if filesLimit != nil {
+		quota.FilesLimit = filesLimit
+	}
+	return uc.saveUserQuota(ctx, quota)
+}
+
+// ResetUserQuota убирает персональные лимиты: действуют лимиты тарифа или конфигурации
+func (uc *storageQuotaUseCase) ResetUserQuota(ctx context.Context, login string) error {
+	quota, err := uc.getUserQuota(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	quota.DriveLimit = nil
+	quota.FilesLimit = nil
+	return uc.saveUserQuota(ctx, quota)
+}
+
+func (uc *storageQuotaUseCase) GetUserLimits(ctx context.Context, login string, defaults dto.StorageLimits) (*dto.StorageLimits, error) {
+	user, err := uc.repositories.UserRepository.Find(ctx, login)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return getStorageLimits(ctx, uc.repositories, user.ID, defaults)
+}
+
+// RecalculateUsage пересчитывает счетчики занятого места по фактическим данным для пользователя login,
+// а при login = nil - для всех пользователей. Возвращает количество пересчитанных пользователей
+func (uc *storageQuotaUseCase) RecalculateUsage(ctx context.Context, login *string) (int, error) {
+	var userID *int
+	if login != nil {
+		user, err := uc.repositories.UserRepository.Find(ctx, *login)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, ErrUserNotFound
+			}
+			logging.GetLogger(ctx).Error(err)
+			return 0, postgres.ErrUnexpectedDBError
+		}
+		userID = &user.ID
+	}
+
+	count, err := uc.repositories.StorageUsageRepository.Recalculate(ctx, userID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return 0, postgres.ErrUnexpectedDBError
+	}
+	return count, nil
+}
+
+// getUserQuota возвращает текущие настройки квоты пользователя, для пользователя без настроек - пустые
+func (uc *storageQuotaUseCase) getUserQuota(ctx context.Context, login string) (*entity.UserStorageQuota, error) {
+	user, err := uc.repositories.UserRepository.Find(ctx, login)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	quota, err := uc.repositories.StorageQuotaRepository.GetUserQuota(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &entity.UserStorageQuota{UserID: user.ID}, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return quota, nil
+}
+
+func (uc *storageQuotaUseCase) saveUserQuota(ctx context.Context, quota *entity.UserStorageQuota) error {
+	quota.UpdatedAt = time.Now().UTC()
+	err := uc.repositories.StorageQuotaRepository.SaveUserQuota(ctx, quota)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+	return nil
+}
+
+// getStorageLimits возвращает действующие лимиты пользователя: персональные значения, затем лимиты тарифа,
+// затем значения из конфигурации (defaults)
+func getStorageLimits(
+	ctx context.Context,
+	repositories *repository.Repositories,
+	userID int,
+	defaults dto.StorageLimits,
+) (*dto.StorageLimits, error) {
+	limits := defaults
+
+	userLimits, err := repositories.StorageQuotaRepository.GetLimits(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &limits, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	limits.PlanName = userLimits.PlanName
+	if userLimits.DriveLimit != nil {
+		limits.DriveLimit = *userLimits.DriveLimit
+	}
+	if userLimits.FilesLimit != nil {
+		limits.FilesLimit = *userLimits.FilesLimit
+	}
+	return &limits, nil
+}
@@ -0,0 +1,53 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	"assistant-go/internal/storage/postgres"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// getStorageUsage возвращает счетчики занятого пользователем места. Пользователь без строки счетчиков
+// еще ничего не загружал
+func getStorageUsage(ctx context.Context, repositories *repository.Repositories, userID int) (*entity.StorageUsage, error) {
+	usage, err := repositories.StorageUsageRepository.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &entity.StorageUsage{UserID: userID}, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return usage, nil
+}
+
+// reserveStorage в транзакции репозиториев repositoriesTx проверяет лимиты и учитывает в счетчиках
+// новые байты диска и файлов заметок.
+// Строка счетчиков блокируется до конца транзакции, поэтому параллельные загрузки одного пользователя
+// проверяют квоту по очереди и вместе не выходят за лимит
+func reserveStorage(
+	ctx context.Context,
+	repositoriesTx *repository.Repositories,
+	userID int,
+	driveSize int64,
+	filesSize int64,
+	limits *dto.StorageLimits,
+) error {
+	usageRepoTx := repositoriesTx.StorageUsageRepository
+
+	usage, err := usageRepoTx.Lock(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if driveSize > 0 && usage.DriveUsed+driveSize > limits.DriveLimit {
+		return ErrDriveFileSystemIsFull
+	}
+	if filesSize > 0 && usage.FilesUsed+filesSize > limits.FilesLimit {
+		return ErrFileSystemIsFull
+	}
+	return usageRepoTx.Add(ctx, userID, driveSize, filesSize)
+}
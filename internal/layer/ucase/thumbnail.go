@@ -0,0 +1,185 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/repository"
+	service "assistant-go/internal/layer/service/file"
+	"assistant-go/internal/logging"
+	"assistant-go/pkg/keyring"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/sync/singleflight"
+	"io"
+	"path/filepath"
+	"strconv"
+)
+
+var ErrThumbnailUnsupported = errors.New("thumbnail is not available for this file")
+
+const (
+	ThumbnailDefaultSize = 256
+
+	thumbnailKindDrive = "drive"
+	thumbnailKindFile  = "files"
+)
+
+// ThumbnailSizes - допустимые размеры превью (сторона квадрата, в который вписывается изображение)
+var ThumbnailSizes = []int{128, 256, 512}
+
+var (
+	// thumbnailGroup не дает строить одно и то же превью параллельно
+	thumbnailGroup singleflight.Group
+	// thumbnailSlots ограничивает число превью, которые строятся в фоне после загрузки
+	thumbnailSlots = make(chan struct{}, 2)
+)
+
+// thumbnailKey возвращает путь превью в хранилище, производный от ID записи файла и размера
+func thumbnailKey(savePath string, kind string, fileID int, size int) string {
+	fileService := service.NewFile().FileService()
+	name := fmt.Sprintf("%d_%d.jpg", fileID, size)
+	return filepath.Join(savePath, "thumbnails", kind, fileService.GetMiddlePathByFileId(fileID), name)
+}
+
+// loadThumbnail отдает сохраненное превью. Если его еще нет, превью строится из исходного файла,
+// который открывает open, и сохраняется в хранилище (зашифрованным, если включено шифрование).
+// Превью шифруется ключом, производным от текущего мастер-ключа: после смены мастер-ключа
+// прежнее превью не расшифровывается и строится заново
+func loadThumbnail(
+	ctx context.Context,
+	repositories *repository.Repositories,
+	key string,
+	in *dto.GetThumbnail,
+	open func(ctx context.Context) (io.Reader, error),
+) (*dto.FileResponse, error) {
+	data, err := readThumbnail(ctx, repositories, key, in)
+	if err != nil {
+		if !errors.Is(err, repository.ErrFileNotFoundInFilesystem) {
+			logging.GetLogger(ctx).Error(err)
+		}
+
+		result, err, _ := thumbnailGroup.Do(key, func() (any, error) {
+			return makeThumbnail(ctx, repositories, key, in, open)
+		})
+		if err != nil {
+			return nil, err
+		}
+		data = result.([]byte)
+	}
+
+	return &dto.FileResponse{
+		File:             bytes.NewReader(data),
+		OriginalFilename: strconv.Itoa(in.Size) + ".jpg",
+		SizeBytes:        int64(len(data)),
+	}, nil
+}
+
+func readThumbnail(ctx context.Context, repositories *repository.Repositories, key string, in *dto.GetThumbnail) ([]byte, error) {
+	reader, err := repositories.StorageRepository.GetFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closer, ok := reader.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}()
+
+	if in.UseEncryption {
+		key, err := in.Keyring.Derived(keyring.PurposeThumbnails)
+		if err != nil {
+			return nil, err
+		}
+		reader, err = service.NewFile().FileService().DecryptStreamWithKey(reader, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return io.ReadAll(reader)
+}
+
+func makeThumbnail(
+	ctx context.Context,
+	repositories *repository.Repositories,
+	key string,
+	in *dto.GetThumbnail,
+	open func(ctx context.Context) (io.Reader, error),
+) ([]byte, error) {
+	source, err := open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closer, ok := source.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}()
+
+	data, err := service.NewFile().ThumbnailService().Make(source, in.Size)
+	if err != nil {
+		if errors.Is(err, service.ErrThumbnailUnsupported) || errors.Is(err, service.ErrThumbnailTooLarge) {
+			return nil, ErrThumbnailUnsupported
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, ErrFileReading
+	}
+
+	var (
+		fileService           = service.NewFile().FileService()
+		stored      io.Reader = bytes.NewReader(data)
+		storedSize            = int64(len(data))
+	)
+	if in.UseEncryption {
+		key, err := in.Keyring.Derived(keyring.PurposeThumbnails)
+		if err == nil {
+			stored, err = fileService.EncryptStreamWithKey(stored, key)
+		}
+		if err != nil {
+			logging.GetLogger(ctx).Error(fmt.Errorf("%w: %w", ErrDriveEncrypting, err))
+			return nil, ErrDriveEncrypting
+		}
+		storedSize = fileService.EncryptedSize(storedSize)
+	}
+
+	// превью отдается и при ошибке сохранения, тогда оно будет построено заново при следующем запросе
+	err = repositories.StorageRepository.Save(ctx, &dto.SaveFile{File: stored, SavePath: key, SizeBytes: storedSize})
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+	}
+	return data, nil
+}
+
+// warmThumbnail строит превью размера по умолчанию в фоне, не задерживая ответ на загрузку.
+// Если все фоновые слоты заняты, превью будет построено при первом запросе
+func warmThumbnail(
+	ctx context.Context,
+	repositories *repository.Repositories,
+	key string,
+	in *dto.GetThumbnail,
+	open func(ctx context.Context) (io.Reader, error),
+) {
+	select {
+	case thumbnailSlots <- struct{}{}:
+	default:
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() { <-thumbnailSlots }()
+
+		_, _, _ = thumbnailGroup.Do(key, func() (any, error) {
+			return makeThumbnail(ctx, repositories, key, in, open)
+		})
+	}()
+}
+
+// deleteThumbnails удаляет превью всех размеров для перечисленных файлов. Отсутствующие превью не считаются ошибкой
+func deleteThumbnails(ctx context.Context, repositories *repository.Repositories, savePath string, kind string, fileIDs []int) {
+	for _, fileID := range fileIDs {
+		for _, size := range ThumbnailSizes {
+			_ = repositories.StorageRepository.Delete(ctx, thumbnailKey(savePath, kind, fileID, size))
+		}
+	}
+}
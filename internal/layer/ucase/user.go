@@ -8,7 +8,9 @@ import (
 	"assistant-go/internal/storage/postgres"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -23,6 +25,7 @@ var (
 	ErrRefreshTokenNotFound            = errors.New("refresh token not found")
 	ErrUserNotFound                    = errors.New("user not found")
 	ErrUserPasswordsAreNotIdentical    = errors.New("passwords are not identical")
+	ErrUserAppTokenNotFound            = errors.New("user app token not found")
 )
 
 type UserUseCase interface {
@@ -33,6 +36,11 @@ type UserUseCase interface {
 	ChangePassword(ctx context.Context, userID int, in dto.UserChangePassword) error
 	CleanOldTokens(ctx context.Context) error
 	ChangePasswordWithoutCurrent(ctx context.Context, login string, password string) error
+	CreateAppToken(ctx context.Context, userID int, in dto.UserAppTokenCreate) (*entity.UserAppToken, string, error)
+	GetAppTokens(ctx context.Context, userID int) ([]*entity.UserAppToken, error)
+	DeleteAppToken(ctx context.Context, userID int, tokenID int) error
+	AuthenticateBasic(ctx context.Context, login string, token string) (*entity.User, error)
+	AuthenticateToken(ctx context.Context, token string) (*entity.User, error)
 }
 
 type userUseCase struct {
@@ -271,3 +279,129 @@ func (uc *userUseCase) ChangePasswordWithoutCurrent(ctx context.Context, login s
 	}
 	return nil
 }
+
+// CreateAppToken выпускает токен приложения для клиентов без интерактивного входа (WebDAV, rclone).
+// В базе хранится только sha256 токена, значение возвращается один раз
+func (uc *userUseCase) CreateAppToken(
+	ctx context.Context,
+	userID int,
+	in dto.UserAppTokenCreate,
+) (*entity.UserAppToken, string, error) {
+	token, err := generateAPIToken()
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, "", ErrUnexpectedError
+	}
+
+	appToken, err := uc.repositories.UserAppTokenRepository.Create(ctx, &entity.UserAppToken{
+		UserID:    userID,
+		Name:      in.Name,
+		TokenHash: hashAppToken(token),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, "", postgres.ErrUnexpectedDBError
+	}
+	return appToken, token, nil
+}
+
+func (uc *userUseCase) GetAppTokens(ctx context.Context, userID int) ([]*entity.UserAppToken, error) {
+	tokens, err := uc.repositories.UserAppTokenRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return tokens, nil
+}
+
+func (uc *userUseCase) DeleteAppToken(ctx context.Context, userID int, tokenID int) error {
+	deleted, err := uc.repositories.UserAppTokenRepository.DeleteByID(ctx, tokenID, userID)
+	if err != nil {
+		logging.GetLogger(ctx).Error(err)
+		return postgres.ErrUnexpectedDBError
+	}
+	if !deleted {
+		return ErrUserAppTokenNotFound
+	}
+	return nil
+}
+
+// AuthenticateBasic проверяет пару логин/токен приложения из HTTP Basic. Пароль учетной записи не принимается:
+// клиенты сохраняют его и передают в каждом запросе, а токен приложения можно отозвать отдельно
+func (uc *userUseCase) AuthenticateBasic(ctx context.Context, login string, token string) (*entity.User, error) {
+	user, err := uc.repositories.UserRepository.Find(ctx, login)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserIncorrectUsernameOrPassword
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	appToken, err := uc.findAppToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if appToken == nil || appToken.UserID != user.ID {
+		return nil, ErrUserIncorrectUsernameOrPassword
+	}
+	return user, nil
+}
+
+// AuthenticateToken принимает действующий токен доступа или токен приложения
+func (uc *userUseCase) AuthenticateToken(ctx context.Context, token string) (*entity.User, error) {
+	userID := 0
+	userToken, err := uc.repositories.UserRepository.FindUserToken(ctx, token)
+	switch {
+	case err == nil:
+		if userToken.ExpiredTo < int(time.Now().Unix()) {
+			return nil, ErrUserIncorrectUsernameOrPassword
+		}
+		userID = userToken.UserId
+	case errors.Is(err, pgx.ErrNoRows):
+		appToken, err := uc.findAppToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if appToken == nil {
+			return nil, ErrUserIncorrectUsernameOrPassword
+		}
+		userID = appToken.UserID
+	default:
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	user, err := uc.repositories.UserRepository.FindById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserIncorrectUsernameOrPassword
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+	return user, nil
+}
+
+// findAppToken ищет токен приложения по значению и отмечает его использование. nil - токен не найден
+func (uc *userUseCase) findAppToken(ctx context.Context, token string) (*entity.UserAppToken, error) {
+	appToken, err := uc.repositories.UserAppTokenRepository.GetByHash(ctx, hashAppToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		logging.GetLogger(ctx).Error(err)
+		return nil, postgres.ErrUnexpectedDBError
+	}
+
+	if err = uc.repositories.UserAppTokenRepository.Touch(ctx, appToken.ID, time.Now().UTC()); err != nil {
+		logging.GetLogger(ctx).Error(err)
+	}
+	return appToken, nil
+}
+
+func hashAppToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
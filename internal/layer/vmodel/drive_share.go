@@ -0,0 +1,38 @@
+package vmodel
+
+import (
+	"assistant-go/internal/layer/entity"
+	"time"
+)
+
+type DriveShareLink struct {
+	ID            int        `json:"id"`
+	StructID      int        `json:"struct_id"`
+	Hash          string     `json:"hash"`
+	HasPassword   bool       `json:"has_password"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	MaxDownloads  *int       `json:"max_downloads"`
+	DownloadCount int        `json:"download_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func DriveShareLinkFromEntity(entity *entity.DriveShareLink) *DriveShareLink {
+	return &DriveShareLink{
+		ID:            entity.ID,
+		StructID:      entity.DriveStructID,
+		Hash:          entity.Hash,
+		HasPassword:   entity.Password != nil,
+		ExpiresAt:     entity.ExpiresAt,
+		MaxDownloads:  entity.MaxDownloads,
+		DownloadCount: entity.DownloadCount,
+		CreatedAt:     entity.CreatedAt,
+	}
+}
+
+func DriveShareLinksFromEntities(entities []*entity.DriveShareLink) []*DriveShareLink {
+	links := make([]*DriveShareLink, 0, len(entities))
+	for _, e := range entities {
+		links = append(links, DriveShareLinkFromEntity(e))
+	}
+	return links
+}
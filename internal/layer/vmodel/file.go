@@ -15,11 +15,17 @@ type File struct {
 }
 
 func FileFromEntity(entity *entity.File, url string) *File {
+	// у зашифрованного файла в size хранится размер в хранилище
+	size := entity.Size
+	if entity.PlainSize != nil {
+		size = int(*entity.PlainSize)
+	}
+
 	return &File{
 		ID:               entity.ID,
 		OriginalFilename: entity.OriginalFilename,
 		Ext:              entity.Ext,
-		SizeBytes:        entity.Size,
+		SizeBytes:        size,
 		Url:              url,
 		CreatedAt:        entity.CreatedAt,
 	}
@@ -36,3 +36,33 @@ func UserTokenFromEnity(entity *entity.UserToken) *UserToken {
 		ExpiredTo:    entity.ExpiredTo,
 	}
 }
+
+type UserAppToken struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func UserAppTokenFromEntity(entity *entity.UserAppToken) *UserAppToken {
+	return &UserAppToken{
+		ID:         entity.ID,
+		Name:       entity.Name,
+		CreatedAt:  entity.CreatedAt,
+		LastUsedAt: entity.LastUsedAt,
+	}
+}
+
+func UserAppTokensFromEntities(entities []*entity.UserAppToken) []*UserAppToken {
+	tokens := make([]*UserAppToken, 0, len(entities))
+	for _, e := range entities {
+		tokens = append(tokens, UserAppTokenFromEntity(e))
+	}
+	return tokens
+}
+
+// UserAppTokenCreated - созданный токен, значение показывается только один раз
+type UserAppTokenCreated struct {
+	*UserAppToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE drive_files ADD COLUMN plain_size BIGINT;
+ALTER TABLE drive_file_chunks ADD COLUMN plain_size BIGINT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE drive_file_chunks DROP COLUMN plain_size;
+ALTER TABLE drive_files DROP COLUMN plain_size;
+-- +goose StatementEnd
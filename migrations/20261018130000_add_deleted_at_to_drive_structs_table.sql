@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE drive_structs ADD COLUMN deleted_at TIMESTAMP(0) WITHOUT TIME ZONE;
+ALTER TABLE drive_structs ADD COLUMN trash_root_id INT;
+CREATE INDEX idx_drive_structs_trash_root_id ON drive_structs (trash_root_id) WHERE trash_root_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_drive_structs_trash_root_id;
+ALTER TABLE drive_structs DROP COLUMN trash_root_id;
+ALTER TABLE drive_structs DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE drive_files ADD COLUMN is_current BOOLEAN NOT NULL DEFAULT (true);
+ALTER TABLE drive_files ADD COLUMN replaced_at TIMESTAMP(0) WITHOUT TIME ZONE;
+DROP INDEX idx_drive_files_drive_struct_id;
+CREATE UNIQUE INDEX idx_drive_files_drive_struct_id_current ON drive_files (drive_struct_id) WHERE is_current;
+CREATE INDEX idx_drive_files_drive_struct_id ON drive_files (drive_struct_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM drive_files WHERE NOT is_current;
+DROP INDEX idx_drive_files_drive_struct_id;
+DROP INDEX idx_drive_files_drive_struct_id_current;
+CREATE UNIQUE INDEX idx_drive_files_drive_struct_id ON drive_files (drive_struct_id);
+ALTER TABLE drive_files DROP COLUMN replaced_at;
+ALTER TABLE drive_files DROP COLUMN is_current;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE drive_blobs(
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    sha256 TEXT NOT NULL,
+    path TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    plain_size BIGINT NOT NULL,
+    ref_count INT NOT NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL
+);
+CREATE UNIQUE INDEX idx_drive_blobs_user_id_sha256 ON drive_blobs (user_id, sha256);
+ALTER TABLE drive_files ADD COLUMN blob_id INT REFERENCES drive_blobs (id);
+CREATE INDEX idx_drive_files_blob_id ON drive_files (blob_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_drive_files_blob_id;
+ALTER TABLE drive_files DROP COLUMN blob_id;
+DROP TABLE IF EXISTS drive_blobs;
+-- +goose StatementEnd
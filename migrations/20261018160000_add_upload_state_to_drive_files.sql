@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE drive_files ADD COLUMN upload_state SMALLINT NOT NULL DEFAULT (1);
+ALTER TABLE drive_files ADD COLUMN expected_size BIGINT;
+UPDATE drive_files SET upload_state = 0 WHERE is_chunk AND size = 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE drive_files DROP COLUMN expected_size;
+ALTER TABLE drive_files DROP COLUMN upload_state;
+-- +goose StatementEnd
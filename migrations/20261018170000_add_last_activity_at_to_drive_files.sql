@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE drive_files ADD COLUMN last_activity_at TIMESTAMP(0) WITHOUT TIME ZONE;
+UPDATE drive_files SET last_activity_at = created_at WHERE upload_state = 0;
+CREATE INDEX idx_drive_files_uploading ON drive_files (last_activity_at) WHERE upload_state = 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_drive_files_uploading;
+ALTER TABLE drive_files DROP COLUMN last_activity_at;
+-- +goose StatementEnd
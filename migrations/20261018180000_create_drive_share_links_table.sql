@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE drive_share_links(
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    drive_struct_id INT NOT NULL,
+    hash VARCHAR(80) NOT NULL,
+    password TEXT,
+    expires_at TIMESTAMP(0) WITHOUT TIME ZONE,
+    max_downloads INT,
+    download_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT drive_share_links_drive_struct_id_fkey
+        FOREIGN KEY (drive_struct_id)
+            REFERENCES drive_structs(id)
+            ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_drive_share_links_hash ON drive_share_links (hash);
+CREATE INDEX idx_drive_share_links_user_id ON drive_share_links (user_id);
+CREATE INDEX idx_drive_share_links_drive_struct_id ON drive_share_links (drive_struct_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_drive_share_links_drive_struct_id;
+DROP INDEX idx_drive_share_links_user_id;
+DROP INDEX idx_drive_share_links_hash;
+DROP TABLE IF EXISTS drive_share_links;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE drive_grants(
+    id SERIAL PRIMARY KEY,
+    drive_struct_id INT NOT NULL,
+    owner_id INT NOT NULL,
+    grantee_id INT NOT NULL,
+    role SMALLINT NOT NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT drive_grants_drive_struct_id_fkey
+        FOREIGN KEY (drive_struct_id)
+            REFERENCES drive_structs(id)
+            ON DELETE CASCADE,
+    CONSTRAINT drive_grants_grantee_id_fkey
+        FOREIGN KEY (grantee_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_drive_grants_drive_struct_id_grantee_id ON drive_grants (drive_struct_id, grantee_id);
+CREATE INDEX idx_drive_grants_grantee_id ON drive_grants (grantee_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_drive_grants_grantee_id;
+DROP INDEX idx_drive_grants_drive_struct_id_grantee_id;
+DROP TABLE IF EXISTS drive_grants;
+-- +goose StatementEnd
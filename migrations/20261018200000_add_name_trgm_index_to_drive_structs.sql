@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_drive_structs_name_trgm ON drive_structs USING gin (name gin_trgm_ops);
+CREATE INDEX idx_drive_files_ext ON drive_files (ext) WHERE is_current;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_drive_files_ext;
+DROP INDEX idx_drive_structs_name_trgm;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_app_tokens(
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP(0) WITHOUT TIME ZONE,
+    CONSTRAINT user_app_tokens_user_id_fkey
+        FOREIGN KEY (user_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_user_app_tokens_token_hash ON user_app_tokens (token_hash);
+CREATE INDEX idx_user_app_tokens_user_id ON user_app_tokens (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_user_app_tokens_user_id;
+DROP INDEX idx_user_app_tokens_token_hash;
+DROP TABLE IF EXISTS user_app_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE drive_files ADD COLUMN mime_type VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE drive_files DROP COLUMN mime_type;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE storage_plans(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    drive_limit BIGINT NOT NULL,
+    files_limit BIGINT NOT NULL,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL
+);
+CREATE UNIQUE INDEX idx_storage_plans_name ON storage_plans (name);
+
+CREATE TABLE user_storage_quotas(
+    user_id INT PRIMARY KEY,
+    plan_id INT,
+    drive_limit BIGINT,
+    files_limit BIGINT,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    CONSTRAINT user_storage_quotas_user_id_fkey
+        FOREIGN KEY (user_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE,
+    CONSTRAINT user_storage_quotas_plan_id_fkey
+        FOREIGN KEY (plan_id)
+            REFERENCES storage_plans(id)
+            ON DELETE SET NULL
+);
+CREATE INDEX idx_user_storage_quotas_plan_id ON user_storage_quotas (plan_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_user_storage_quotas_plan_id;
+DROP TABLE IF EXISTS user_storage_quotas;
+DROP INDEX idx_storage_plans_name;
+DROP TABLE IF EXISTS storage_plans;
+-- +goose StatementEnd
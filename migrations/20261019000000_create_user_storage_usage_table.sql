@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_storage_usage(
+    user_id INT PRIMARY KEY,
+    drive_used BIGINT NOT NULL DEFAULT 0,
+    files_used BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT user_storage_usage_user_id_fkey
+        FOREIGN KEY (user_id)
+            REFERENCES users(id)
+            ON DELETE CASCADE
+);
+
+-- фактически занятое место пользователя p_user_id, а при NULL - всех пользователей.
+-- Используется и для начального заполнения, и командой recalculate-usage
+CREATE FUNCTION storage_usage_totals(p_user_id INT)
+RETURNS TABLE(user_id INT, drive_used BIGINT, files_used BIGINT)
+LANGUAGE sql STABLE
+AS $$
+SELECT
+    u.id,
+    (coalesce((
+        SELECT sum(df.size)
+        FROM drive_structs ds
+        JOIN drive_files df ON df.drive_struct_id = ds.id
+        WHERE ds.user_id = u.id AND df.blob_id IS NULL AND NOT df.is_chunk
+    ), 0) + coalesce((
+        SELECT sum(dfc.size)
+        FROM drive_structs ds
+        JOIN drive_files df ON df.drive_struct_id = ds.id
+        JOIN drive_file_chunks dfc ON dfc.drive_file_id = df.id
+        WHERE ds.user_id = u.id
+    ), 0) + coalesce((
+        SELECT sum(db.size) FROM drive_blobs db WHERE db.user_id = u.id
+    ), 0))::bigint,
+    coalesce((SELECT sum(f.size) FROM files f WHERE f.user_id = u.id), 0)::bigint
+FROM users u
+WHERE p_user_id IS NULL OR u.id = p_user_id
+$$;
+
+INSERT INTO user_storage_usage (user_id, drive_used, files_used)
+SELECT * FROM storage_usage_totals(NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS storage_usage_totals(INT);
+DROP TABLE IF EXISTS user_storage_usage;
+-- +goose StatementEnd
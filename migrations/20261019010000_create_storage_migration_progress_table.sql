@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE storage_migration_progress(
+    source VARCHAR(20) NOT NULL,
+    target VARCHAR(20) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    last_id INT NOT NULL,
+    updated_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL,
+    PRIMARY KEY (source, target, kind)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS storage_migration_progress;
+-- +goose StatementEnd
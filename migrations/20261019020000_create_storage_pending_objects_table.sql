@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE storage_pending_objects(
+    storage_key TEXT PRIMARY KEY,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE INDEX storage_pending_objects_created_at_idx ON storage_pending_objects (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS storage_pending_objects;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE drive_data_keys(
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    master_key_id VARCHAR(64) NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP(0) WITHOUT TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX drive_data_keys_active_user_idx ON drive_data_keys (user_id) WHERE is_active;
+CREATE INDEX drive_data_keys_master_key_id_idx ON drive_data_keys (master_key_id);
+
+ALTER TABLE drive_blobs ADD COLUMN data_key_id INT NULL REFERENCES drive_data_keys(id);
+ALTER TABLE drive_files ADD COLUMN data_key_id INT NULL REFERENCES drive_data_keys(id);
+ALTER TABLE drive_file_chunks ADD COLUMN data_key_id INT NULL REFERENCES drive_data_keys(id);
+
+CREATE INDEX drive_blobs_data_key_id_idx ON drive_blobs (data_key_id);
+CREATE INDEX drive_files_data_key_id_idx ON drive_files (data_key_id);
+CREATE INDEX drive_file_chunks_data_key_id_idx ON drive_file_chunks (data_key_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE drive_file_chunks DROP COLUMN IF EXISTS data_key_id;
+ALTER TABLE drive_files DROP COLUMN IF EXISTS data_key_id;
+ALTER TABLE drive_blobs DROP COLUMN IF EXISTS data_key_id;
+DROP TABLE IF EXISTS drive_data_keys;
+-- +goose StatementEnd
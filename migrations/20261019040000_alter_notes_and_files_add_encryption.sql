@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE files ADD COLUMN plain_size BIGINT NULL;
+ALTER TABLE files ADD COLUMN data_key_id INT NULL REFERENCES drive_data_keys(id);
+CREATE INDEX files_data_key_id_idx ON files (data_key_id);
+
+-- у зашифрованной заметки note_blocks и title пустые, содержимое хранится в encrypted_*
+ALTER TABLE notes ALTER COLUMN note_blocks DROP NOT NULL;
+ALTER TABLE notes ADD COLUMN encrypted_blocks BYTEA NULL;
+ALTER TABLE notes ADD COLUMN encrypted_title BYTEA NULL;
+ALTER TABLE notes ADD COLUMN data_key_id INT NULL REFERENCES drive_data_keys(id);
+CREATE INDEX notes_data_key_id_idx ON notes (data_key_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notes DROP COLUMN IF EXISTS data_key_id;
+ALTER TABLE notes DROP COLUMN IF EXISTS encrypted_title;
+ALTER TABLE notes DROP COLUMN IF EXISTS encrypted_blocks;
+ALTER TABLE notes ALTER COLUMN note_blocks SET NOT NULL;
+ALTER TABLE files DROP COLUMN IF EXISTS data_key_id;
+ALTER TABLE files DROP COLUMN IF EXISTS plain_size;
+-- +goose StatementEnd
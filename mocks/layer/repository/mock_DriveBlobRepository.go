@@ -0,0 +1,310 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockDriveBlobRepository is an autogenerated mock type for the DriveBlobRepository type
+type MockDriveBlobRepository struct {
+	mock.Mock
+}
+
+type MockDriveBlobRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDriveBlobRepository) EXPECT() *MockDriveBlobRepository_Expecter {
+	return &MockDriveBlobRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, in
+func (_m *MockDriveBlobRepository) Create(ctx context.Context, in *entity.DriveBlob) (*entity.DriveBlob, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.DriveBlob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveBlob) (*entity.DriveBlob, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveBlob) *entity.DriveBlob); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveBlob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.DriveBlob) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveBlobRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockDriveBlobRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.DriveBlob
+func (_e *MockDriveBlobRepository_Expecter) Create(ctx interface{}, in interface{}) *MockDriveBlobRepository_Create_Call {
+	return &MockDriveBlobRepository_Create_Call{Call: _e.mock.On("Create", ctx, in)}
+}
+
+func (_c *MockDriveBlobRepository_Create_Call) Run(run func(ctx context.Context, in *entity.DriveBlob)) *MockDriveBlobRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.DriveBlob))
+	})
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_Create_Call) Return(_a0 *entity.DriveBlob, _a1 error) *MockDriveBlobRepository_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.DriveBlob) (*entity.DriveBlob, error)) *MockDriveBlobRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DecrementRef provides a mock function with given fields: ctx, blobID, count
+func (_m *MockDriveBlobRepository) DecrementRef(ctx context.Context, blobID int, count int) (*entity.DriveBlob, error) {
+	ret := _m.Called(ctx, blobID, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DecrementRef")
+	}
+
+	var r0 *entity.DriveBlob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*entity.DriveBlob, error)); ok {
+		return rf(ctx, blobID, count)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *entity.DriveBlob); ok {
+		r0 = rf(ctx, blobID, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveBlob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, blobID, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveBlobRepository_DecrementRef_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DecrementRef'
+type MockDriveBlobRepository_DecrementRef_Call struct {
+	*mock.Call
+}
+
+// DecrementRef is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blobID int
+//   - count int
+func (_e *MockDriveBlobRepository_Expecter) DecrementRef(ctx interface{}, blobID interface{}, count interface{}) *MockDriveBlobRepository_DecrementRef_Call {
+	return &MockDriveBlobRepository_DecrementRef_Call{Call: _e.mock.On("DecrementRef", ctx, blobID, count)}
+}
+
+func (_c *MockDriveBlobRepository_DecrementRef_Call) Run(run func(ctx context.Context, blobID int, count int)) *MockDriveBlobRepository_DecrementRef_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_DecrementRef_Call) Return(_a0 *entity.DriveBlob, _a1 error) *MockDriveBlobRepository_DecrementRef_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_DecrementRef_Call) RunAndReturn(run func(context.Context, int, int) (*entity.DriveBlob, error)) *MockDriveBlobRepository_DecrementRef_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, blobID
+func (_m *MockDriveBlobRepository) Delete(ctx context.Context, blobID int) error {
+	ret := _m.Called(ctx, blobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, blobID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveBlobRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockDriveBlobRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blobID int
+func (_e *MockDriveBlobRepository_Expecter) Delete(ctx interface{}, blobID interface{}) *MockDriveBlobRepository_Delete_Call {
+	return &MockDriveBlobRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, blobID)}
+}
+
+func (_c *MockDriveBlobRepository_Delete_Call) Run(run func(ctx context.Context, blobID int)) *MockDriveBlobRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_Delete_Call) Return(_a0 error) *MockDriveBlobRepository_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_Delete_Call) RunAndReturn(run func(context.Context, int) error) *MockDriveBlobRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function with given fields: ctx, userID, sha256
+func (_m *MockDriveBlobRepository) GetByHash(ctx context.Context, userID int, sha256 string) (*entity.DriveBlob, error) {
+	ret := _m.Called(ctx, userID, sha256)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *entity.DriveBlob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (*entity.DriveBlob, error)); ok {
+		return rf(ctx, userID, sha256)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) *entity.DriveBlob); ok {
+		r0 = rf(ctx, userID, sha256)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveBlob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userID, sha256)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveBlobRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockDriveBlobRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - sha256 string
+func (_e *MockDriveBlobRepository_Expecter) GetByHash(ctx interface{}, userID interface{}, sha256 interface{}) *MockDriveBlobRepository_GetByHash_Call {
+	return &MockDriveBlobRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, userID, sha256)}
+}
+
+func (_c *MockDriveBlobRepository_GetByHash_Call) Run(run func(ctx context.Context, userID int, sha256 string)) *MockDriveBlobRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_GetByHash_Call) Return(_a0 *entity.DriveBlob, _a1 error) *MockDriveBlobRepository_GetByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_GetByHash_Call) RunAndReturn(run func(context.Context, int, string) (*entity.DriveBlob, error)) *MockDriveBlobRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementRef provides a mock function with given fields: ctx, blobID
+func (_m *MockDriveBlobRepository) IncrementRef(ctx context.Context, blobID int) error {
+	ret := _m.Called(ctx, blobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementRef")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, blobID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveBlobRepository_IncrementRef_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementRef'
+type MockDriveBlobRepository_IncrementRef_Call struct {
+	*mock.Call
+}
+
+// IncrementRef is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blobID int
+func (_e *MockDriveBlobRepository_Expecter) IncrementRef(ctx interface{}, blobID interface{}) *MockDriveBlobRepository_IncrementRef_Call {
+	return &MockDriveBlobRepository_IncrementRef_Call{Call: _e.mock.On("IncrementRef", ctx, blobID)}
+}
+
+func (_c *MockDriveBlobRepository_IncrementRef_Call) Run(run func(ctx context.Context, blobID int)) *MockDriveBlobRepository_IncrementRef_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_IncrementRef_Call) Return(_a0 error) *MockDriveBlobRepository_IncrementRef_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveBlobRepository_IncrementRef_Call) RunAndReturn(run func(context.Context, int) error) *MockDriveBlobRepository_IncrementRef_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockDriveBlobRepository creates a new instance of MockDriveBlobRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDriveBlobRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDriveBlobRepository {
+	mock := &MockDriveBlobRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,555 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockDriveDataKeyRepository is an autogenerated mock type for the DriveDataKeyRepository type
+type MockDriveDataKeyRepository struct {
+	mock.Mock
+}
+
+type MockDriveDataKeyRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDriveDataKeyRepository) EXPECT() *MockDriveDataKeyRepository_Expecter {
+	return &MockDriveDataKeyRepository_Expecter{mock: &_m.Mock}
+}
+
+// CreateActive provides a mock function with given fields: ctx, in
+func (_m *MockDriveDataKeyRepository) CreateActive(ctx context.Context, in *entity.DriveDataKey) (*entity.DriveDataKey, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateActive")
+	}
+
+	var r0 *entity.DriveDataKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveDataKey) (*entity.DriveDataKey, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveDataKey) *entity.DriveDataKey); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveDataKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.DriveDataKey) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveDataKeyRepository_CreateActive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateActive'
+type MockDriveDataKeyRepository_CreateActive_Call struct {
+	*mock.Call
+}
+
+// CreateActive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.DriveDataKey
+func (_e *MockDriveDataKeyRepository_Expecter) CreateActive(ctx interface{}, in interface{}) *MockDriveDataKeyRepository_CreateActive_Call {
+	return &MockDriveDataKeyRepository_CreateActive_Call{Call: _e.mock.On("CreateActive", ctx, in)}
+}
+
+func (_c *MockDriveDataKeyRepository_CreateActive_Call) Run(run func(ctx context.Context, in *entity.DriveDataKey)) *MockDriveDataKeyRepository_CreateActive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.DriveDataKey))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_CreateActive_Call) Return(_a0 *entity.DriveDataKey, _a1 error) *MockDriveDataKeyRepository_CreateActive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_CreateActive_Call) RunAndReturn(run func(context.Context, *entity.DriveDataKey) (*entity.DriveDataKey, error)) *MockDriveDataKeyRepository_CreateActive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeactivateAll provides a mock function with given fields: ctx
+func (_m *MockDriveDataKeyRepository) DeactivateAll(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeactivateAll")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveDataKeyRepository_DeactivateAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeactivateAll'
+type MockDriveDataKeyRepository_DeactivateAll_Call struct {
+	*mock.Call
+}
+
+// DeactivateAll is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockDriveDataKeyRepository_Expecter) DeactivateAll(ctx interface{}) *MockDriveDataKeyRepository_DeactivateAll_Call {
+	return &MockDriveDataKeyRepository_DeactivateAll_Call{Call: _e.mock.On("DeactivateAll", ctx)}
+}
+
+func (_c *MockDriveDataKeyRepository_DeactivateAll_Call) Run(run func(ctx context.Context)) *MockDriveDataKeyRepository_DeactivateAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_DeactivateAll_Call) Return(_a0 int, _a1 error) *MockDriveDataKeyRepository_DeactivateAll_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_DeactivateAll_Call) RunAndReturn(run func(context.Context) (int, error)) *MockDriveDataKeyRepository_DeactivateAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUnused provides a mock function with given fields: ctx
+func (_m *MockDriveDataKeyRepository) DeleteUnused(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUnused")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveDataKeyRepository_DeleteUnused_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUnused'
+type MockDriveDataKeyRepository_DeleteUnused_Call struct {
+	*mock.Call
+}
+
+// DeleteUnused is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockDriveDataKeyRepository_Expecter) DeleteUnused(ctx interface{}) *MockDriveDataKeyRepository_DeleteUnused_Call {
+	return &MockDriveDataKeyRepository_DeleteUnused_Call{Call: _e.mock.On("DeleteUnused", ctx)}
+}
+
+func (_c *MockDriveDataKeyRepository_DeleteUnused_Call) Run(run func(ctx context.Context)) *MockDriveDataKeyRepository_DeleteUnused_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_DeleteUnused_Call) Return(_a0 int, _a1 error) *MockDriveDataKeyRepository_DeleteUnused_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_DeleteUnused_Call) RunAndReturn(run func(context.Context) (int, error)) *MockDriveDataKeyRepository_DeleteUnused_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetActive provides a mock function with given fields: ctx, userID
+func (_m *MockDriveDataKeyRepository) GetActive(ctx context.Context, userID int) (*entity.DriveDataKey, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActive")
+	}
+
+	var r0 *entity.DriveDataKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.DriveDataKey, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.DriveDataKey); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveDataKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveDataKeyRepository_GetActive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActive'
+type MockDriveDataKeyRepository_GetActive_Call struct {
+	*mock.Call
+}
+
+// GetActive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *MockDriveDataKeyRepository_Expecter) GetActive(ctx interface{}, userID interface{}) *MockDriveDataKeyRepository_GetActive_Call {
+	return &MockDriveDataKeyRepository_GetActive_Call{Call: _e.mock.On("GetActive", ctx, userID)}
+}
+
+func (_c *MockDriveDataKeyRepository_GetActive_Call) Run(run func(ctx context.Context, userID int)) *MockDriveDataKeyRepository_GetActive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_GetActive_Call) Return(_a0 *entity.DriveDataKey, _a1 error) *MockDriveDataKeyRepository_GetActive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_GetActive_Call) RunAndReturn(run func(context.Context, int) (*entity.DriveDataKey, error)) *MockDriveDataKeyRepository_GetActive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *MockDriveDataKeyRepository) GetByID(ctx context.Context, id int) (*entity.DriveDataKey, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *entity.DriveDataKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.DriveDataKey, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.DriveDataKey); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveDataKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveDataKeyRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockDriveDataKeyRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MockDriveDataKeyRepository_Expecter) GetByID(ctx interface{}, id interface{}) *MockDriveDataKeyRepository_GetByID_Call {
+	return &MockDriveDataKeyRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, id)}
+}
+
+func (_c *MockDriveDataKeyRepository_GetByID_Call) Run(run func(ctx context.Context, id int)) *MockDriveDataKeyRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_GetByID_Call) Return(_a0 *entity.DriveDataKey, _a1 error) *MockDriveDataKeyRepository_GetByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_GetByID_Call) RunAndReturn(run func(context.Context, int) (*entity.DriveDataKey, error)) *MockDriveDataKeyRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetStaleObjects provides a mock function with given fields: ctx, kind, afterID, limit
+func (_m *MockDriveDataKeyRepository) GetStaleObjects(ctx context.Context, kind string, afterID int, limit int) ([]*entity.StorageObject, error) {
+	ret := _m.Called(ctx, kind, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStaleObjects")
+	}
+
+	var r0 []*entity.StorageObject
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]*entity.StorageObject, error)); ok {
+		return rf(ctx, kind, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []*entity.StorageObject); ok {
+		r0 = rf(ctx, kind, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.StorageObject)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, kind, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveDataKeyRepository_GetStaleObjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetStaleObjects'
+type MockDriveDataKeyRepository_GetStaleObjects_Call struct {
+	*mock.Call
+}
+
+// GetStaleObjects is a helper method to define mock.On call
+//   - ctx context.Context
+//   - kind string
+//   - afterID int
+//   - limit int
+func (_e *MockDriveDataKeyRepository_Expecter) GetStaleObjects(ctx interface{}, kind interface{}, afterID interface{}, limit interface{}) *MockDriveDataKeyRepository_GetStaleObjects_Call {
+	return &MockDriveDataKeyRepository_GetStaleObjects_Call{Call: _e.mock.On("GetStaleObjects", ctx, kind, afterID, limit)}
+}
+
+func (_c *MockDriveDataKeyRepository_GetStaleObjects_Call) Run(run func(ctx context.Context, kind string, afterID int, limit int)) *MockDriveDataKeyRepository_GetStaleObjects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_GetStaleObjects_Call) Return(_a0 []*entity.StorageObject, _a1 error) *MockDriveDataKeyRepository_GetStaleObjects_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_GetStaleObjects_Call) RunAndReturn(run func(context.Context, string, int, int) ([]*entity.StorageObject, error)) *MockDriveDataKeyRepository_GetStaleObjects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWrappedByOther provides a mock function with given fields: ctx, masterKeyID, afterID, limit
+func (_m *MockDriveDataKeyRepository) GetWrappedByOther(ctx context.Context, masterKeyID string, afterID int, limit int) ([]*entity.DriveDataKey, error) {
+	ret := _m.Called(ctx, masterKeyID, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWrappedByOther")
+	}
+
+	var r0 []*entity.DriveDataKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]*entity.DriveDataKey, error)); ok {
+		return rf(ctx, masterKeyID, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []*entity.DriveDataKey); ok {
+		r0 = rf(ctx, masterKeyID, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.DriveDataKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, masterKeyID, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveDataKeyRepository_GetWrappedByOther_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWrappedByOther'
+type MockDriveDataKeyRepository_GetWrappedByOther_Call struct {
+	*mock.Call
+}
+
+// GetWrappedByOther is a helper method to define mock.On call
+//   - ctx context.Context
+//   - masterKeyID string
+//   - afterID int
+//   - limit int
+func (_e *MockDriveDataKeyRepository_Expecter) GetWrappedByOther(ctx interface{}, masterKeyID interface{}, afterID interface{}, limit interface{}) *MockDriveDataKeyRepository_GetWrappedByOther_Call {
+	return &MockDriveDataKeyRepository_GetWrappedByOther_Call{Call: _e.mock.On("GetWrappedByOther", ctx, masterKeyID, afterID, limit)}
+}
+
+func (_c *MockDriveDataKeyRepository_GetWrappedByOther_Call) Run(run func(ctx context.Context, masterKeyID string, afterID int, limit int)) *MockDriveDataKeyRepository_GetWrappedByOther_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_GetWrappedByOther_Call) Return(_a0 []*entity.DriveDataKey, _a1 error) *MockDriveDataKeyRepository_GetWrappedByOther_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_GetWrappedByOther_Call) RunAndReturn(run func(context.Context, string, int, int) ([]*entity.DriveDataKey, error)) *MockDriveDataKeyRepository_GetWrappedByOther_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReplaceObject provides a mock function with given fields: ctx, object, previous
+func (_m *MockDriveDataKeyRepository) ReplaceObject(ctx context.Context, object *entity.StorageObject, previous *entity.StorageObject) (bool, error) {
+	ret := _m.Called(ctx, object, previous)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceObject")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.StorageObject, *entity.StorageObject) (bool, error)); ok {
+		return rf(ctx, object, previous)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.StorageObject, *entity.StorageObject) bool); ok {
+		r0 = rf(ctx, object, previous)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.StorageObject, *entity.StorageObject) error); ok {
+		r1 = rf(ctx, object, previous)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveDataKeyRepository_ReplaceObject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplaceObject'
+type MockDriveDataKeyRepository_ReplaceObject_Call struct {
+	*mock.Call
+}
+
+// ReplaceObject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - object *entity.StorageObject
+//   - previous *entity.StorageObject
+func (_e *MockDriveDataKeyRepository_Expecter) ReplaceObject(ctx interface{}, object interface{}, previous interface{}) *MockDriveDataKeyRepository_ReplaceObject_Call {
+	return &MockDriveDataKeyRepository_ReplaceObject_Call{Call: _e.mock.On("ReplaceObject", ctx, object, previous)}
+}
+
+func (_c *MockDriveDataKeyRepository_ReplaceObject_Call) Run(run func(ctx context.Context, object *entity.StorageObject, previous *entity.StorageObject)) *MockDriveDataKeyRepository_ReplaceObject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.StorageObject), args[2].(*entity.StorageObject))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_ReplaceObject_Call) Return(_a0 bool, _a1 error) *MockDriveDataKeyRepository_ReplaceObject_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_ReplaceObject_Call) RunAndReturn(run func(context.Context, *entity.StorageObject, *entity.StorageObject) (bool, error)) *MockDriveDataKeyRepository_ReplaceObject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateWrapped provides a mock function with given fields: ctx, id, masterKeyID, wrappedKey
+func (_m *MockDriveDataKeyRepository) UpdateWrapped(ctx context.Context, id int, masterKeyID string, wrappedKey []byte) error {
+	ret := _m.Called(ctx, id, masterKeyID, wrappedKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWrapped")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, []byte) error); ok {
+		r0 = rf(ctx, id, masterKeyID, wrappedKey)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveDataKeyRepository_UpdateWrapped_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateWrapped'
+type MockDriveDataKeyRepository_UpdateWrapped_Call struct {
+	*mock.Call
+}
+
+// UpdateWrapped is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - masterKeyID string
+//   - wrappedKey []byte
+func (_e *MockDriveDataKeyRepository_Expecter) UpdateWrapped(ctx interface{}, id interface{}, masterKeyID interface{}, wrappedKey interface{}) *MockDriveDataKeyRepository_UpdateWrapped_Call {
+	return &MockDriveDataKeyRepository_UpdateWrapped_Call{Call: _e.mock.On("UpdateWrapped", ctx, id, masterKeyID, wrappedKey)}
+}
+
+func (_c *MockDriveDataKeyRepository_UpdateWrapped_Call) Run(run func(ctx context.Context, id int, masterKeyID string, wrappedKey []byte)) *MockDriveDataKeyRepository_UpdateWrapped_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_UpdateWrapped_Call) Return(_a0 error) *MockDriveDataKeyRepository_UpdateWrapped_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveDataKeyRepository_UpdateWrapped_Call) RunAndReturn(run func(context.Context, int, string, []byte) error) *MockDriveDataKeyRepository_UpdateWrapped_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockDriveDataKeyRepository creates a new instance of MockDriveDataKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDriveDataKeyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDriveDataKeyRepository {
+	mock := &MockDriveDataKeyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,543 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "assistant-go/internal/layer/dto"
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockDriveFileChunkRepository is an autogenerated mock type for the DriveFileChunkRepository type
+type MockDriveFileChunkRepository struct {
+	mock.Mock
+}
+
+type MockDriveFileChunkRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDriveFileChunkRepository) EXPECT() *MockDriveFileChunkRepository_Expecter {
+	return &MockDriveFileChunkRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, in
+func (_m *MockDriveFileChunkRepository) Create(ctx context.Context, in *entity.DriveFileChunk) (*entity.DriveFileChunk, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.DriveFileChunk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveFileChunk) (*entity.DriveFileChunk, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveFileChunk) *entity.DriveFileChunk); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveFileChunk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.DriveFileChunk) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveFileChunkRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockDriveFileChunkRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.DriveFileChunk
+func (_e *MockDriveFileChunkRepository_Expecter) Create(ctx interface{}, in interface{}) *MockDriveFileChunkRepository_Create_Call {
+	return &MockDriveFileChunkRepository_Create_Call{Call: _e.mock.On("Create", ctx, in)}
+}
+
+func (_c *MockDriveFileChunkRepository_Create_Call) Run(run func(ctx context.Context, in *entity.DriveFileChunk)) *MockDriveFileChunkRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.DriveFileChunk))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_Create_Call) Return(_a0 *entity.DriveFileChunk, _a1 error) *MockDriveFileChunkRepository_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.DriveFileChunk) (*entity.DriveFileChunk, error)) *MockDriveFileChunkRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByFileID provides a mock function with given fields: ctx, fileID
+func (_m *MockDriveFileChunkRepository) DeleteByFileID(ctx context.Context, fileID int) error {
+	ret := _m.Called(ctx, fileID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByFileID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveFileChunkRepository_DeleteByFileID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByFileID'
+type MockDriveFileChunkRepository_DeleteByFileID_Call struct {
+	*mock.Call
+}
+
+// DeleteByFileID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fileID int
+func (_e *MockDriveFileChunkRepository_Expecter) DeleteByFileID(ctx interface{}, fileID interface{}) *MockDriveFileChunkRepository_DeleteByFileID_Call {
+	return &MockDriveFileChunkRepository_DeleteByFileID_Call{Call: _e.mock.On("DeleteByFileID", ctx, fileID)}
+}
+
+func (_c *MockDriveFileChunkRepository_DeleteByFileID_Call) Run(run func(ctx context.Context, fileID int)) *MockDriveFileChunkRepository_DeleteByFileID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_DeleteByFileID_Call) Return(_a0 error) *MockDriveFileChunkRepository_DeleteByFileID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_DeleteByFileID_Call) RunAndReturn(run func(context.Context, int) error) *MockDriveFileChunkRepository_DeleteByFileID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function with given fields: ctx, chunkID
+func (_m *MockDriveFileChunkRepository) DeleteByID(ctx context.Context, chunkID int) error {
+	ret := _m.Called(ctx, chunkID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, chunkID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveFileChunkRepository_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockDriveFileChunkRepository_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chunkID int
+func (_e *MockDriveFileChunkRepository_Expecter) DeleteByID(ctx interface{}, chunkID interface{}) *MockDriveFileChunkRepository_DeleteByID_Call {
+	return &MockDriveFileChunkRepository_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, chunkID)}
+}
+
+func (_c *MockDriveFileChunkRepository_DeleteByID_Call) Run(run func(ctx context.Context, chunkID int)) *MockDriveFileChunkRepository_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_DeleteByID_Call) Return(_a0 error) *MockDriveFileChunkRepository_DeleteByID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_DeleteByID_Call) RunAndReturn(run func(context.Context, int) error) *MockDriveFileChunkRepository_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllRecursive provides a mock function with given fields: ctx, structID, userID
+func (_m *MockDriveFileChunkRepository) GetAllRecursive(ctx context.Context, structID int, userID int) ([]*entity.DriveFileChunk, error) {
+	ret := _m.Called(ctx, structID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllRecursive")
+	}
+
+	var r0 []*entity.DriveFileChunk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*entity.DriveFileChunk, error)); ok {
+		return rf(ctx, structID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*entity.DriveFileChunk); ok {
+		r0 = rf(ctx, structID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.DriveFileChunk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, structID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveFileChunkRepository_GetAllRecursive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllRecursive'
+type MockDriveFileChunkRepository_GetAllRecursive_Call struct {
+	*mock.Call
+}
+
+// GetAllRecursive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - structID int
+//   - userID int
+func (_e *MockDriveFileChunkRepository_Expecter) GetAllRecursive(ctx interface{}, structID interface{}, userID interface{}) *MockDriveFileChunkRepository_GetAllRecursive_Call {
+	return &MockDriveFileChunkRepository_GetAllRecursive_Call{Call: _e.mock.On("GetAllRecursive", ctx, structID, userID)}
+}
+
+func (_c *MockDriveFileChunkRepository_GetAllRecursive_Call) Run(run func(ctx context.Context, structID int, userID int)) *MockDriveFileChunkRepository_GetAllRecursive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetAllRecursive_Call) Return(_a0 []*entity.DriveFileChunk, _a1 error) *MockDriveFileChunkRepository_GetAllRecursive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetAllRecursive_Call) RunAndReturn(run func(context.Context, int, int) ([]*entity.DriveFileChunk, error)) *MockDriveFileChunkRepository_GetAllRecursive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByFileID provides a mock function with given fields: ctx, fileID
+func (_m *MockDriveFileChunkRepository) GetByFileID(ctx context.Context, fileID int) ([]*entity.DriveFileChunk, error) {
+	ret := _m.Called(ctx, fileID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByFileID")
+	}
+
+	var r0 []*entity.DriveFileChunk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*entity.DriveFileChunk, error)); ok {
+		return rf(ctx, fileID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*entity.DriveFileChunk); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.DriveFileChunk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, fileID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveFileChunkRepository_GetByFileID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByFileID'
+type MockDriveFileChunkRepository_GetByFileID_Call struct {
+	*mock.Call
+}
+
+// GetByFileID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fileID int
+func (_e *MockDriveFileChunkRepository_Expecter) GetByFileID(ctx interface{}, fileID interface{}) *MockDriveFileChunkRepository_GetByFileID_Call {
+	return &MockDriveFileChunkRepository_GetByFileID_Call{Call: _e.mock.On("GetByFileID", ctx, fileID)}
+}
+
+func (_c *MockDriveFileChunkRepository_GetByFileID_Call) Run(run func(ctx context.Context, fileID int)) *MockDriveFileChunkRepository_GetByFileID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetByFileID_Call) Return(_a0 []*entity.DriveFileChunk, _a1 error) *MockDriveFileChunkRepository_GetByFileID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetByFileID_Call) RunAndReturn(run func(context.Context, int) ([]*entity.DriveFileChunk, error)) *MockDriveFileChunkRepository_GetByFileID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByFileIDAndNumber provides a mock function with given fields: ctx, fileID, chunkNumber
+func (_m *MockDriveFileChunkRepository) GetByFileIDAndNumber(ctx context.Context, fileID int, chunkNumber int) (*entity.DriveFileChunk, error) {
+	ret := _m.Called(ctx, fileID, chunkNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByFileIDAndNumber")
+	}
+
+	var r0 *entity.DriveFileChunk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*entity.DriveFileChunk, error)); ok {
+		return rf(ctx, fileID, chunkNumber)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *entity.DriveFileChunk); ok {
+		r0 = rf(ctx, fileID, chunkNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveFileChunk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, fileID, chunkNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveFileChunkRepository_GetByFileIDAndNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByFileIDAndNumber'
+type MockDriveFileChunkRepository_GetByFileIDAndNumber_Call struct {
+	*mock.Call
+}
+
+// GetByFileIDAndNumber is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fileID int
+//   - chunkNumber int
+func (_e *MockDriveFileChunkRepository_Expecter) GetByFileIDAndNumber(ctx interface{}, fileID interface{}, chunkNumber interface{}) *MockDriveFileChunkRepository_GetByFileIDAndNumber_Call {
+	return &MockDriveFileChunkRepository_GetByFileIDAndNumber_Call{Call: _e.mock.On("GetByFileIDAndNumber", ctx, fileID, chunkNumber)}
+}
+
+func (_c *MockDriveFileChunkRepository_GetByFileIDAndNumber_Call) Run(run func(ctx context.Context, fileID int, chunkNumber int)) *MockDriveFileChunkRepository_GetByFileIDAndNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetByFileIDAndNumber_Call) Return(_a0 *entity.DriveFileChunk, _a1 error) *MockDriveFileChunkRepository_GetByFileIDAndNumber_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetByFileIDAndNumber_Call) RunAndReturn(run func(context.Context, int, int) (*entity.DriveFileChunk, error)) *MockDriveFileChunkRepository_GetByFileIDAndNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChunksInfo provides a mock function with given fields: ctx, fileID
+func (_m *MockDriveFileChunkRepository) GetChunksInfo(ctx context.Context, fileID int) (*dto.DriveChunksInfo, error) {
+	ret := _m.Called(ctx, fileID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChunksInfo")
+	}
+
+	var r0 *dto.DriveChunksInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*dto.DriveChunksInfo, error)); ok {
+		return rf(ctx, fileID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *dto.DriveChunksInfo); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DriveChunksInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, fileID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveFileChunkRepository_GetChunksInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChunksInfo'
+type MockDriveFileChunkRepository_GetChunksInfo_Call struct {
+	*mock.Call
+}
+
+// GetChunksInfo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fileID int
+func (_e *MockDriveFileChunkRepository_Expecter) GetChunksInfo(ctx interface{}, fileID interface{}) *MockDriveFileChunkRepository_GetChunksInfo_Call {
+	return &MockDriveFileChunkRepository_GetChunksInfo_Call{Call: _e.mock.On("GetChunksInfo", ctx, fileID)}
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksInfo_Call) Run(run func(ctx context.Context, fileID int)) *MockDriveFileChunkRepository_GetChunksInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksInfo_Call) Return(_a0 *dto.DriveChunksInfo, _a1 error) *MockDriveFileChunkRepository_GetChunksInfo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksInfo_Call) RunAndReturn(run func(context.Context, int) (*dto.DriveChunksInfo, error)) *MockDriveFileChunkRepository_GetChunksInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChunksPlainSize provides a mock function with given fields: ctx, fileID
+func (_m *MockDriveFileChunkRepository) GetChunksPlainSize(ctx context.Context, fileID int) (int64, error) {
+	ret := _m.Called(ctx, fileID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChunksPlainSize")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int64, error)); ok {
+		return rf(ctx, fileID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int64); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, fileID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveFileChunkRepository_GetChunksPlainSize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChunksPlainSize'
+type MockDriveFileChunkRepository_GetChunksPlainSize_Call struct {
+	*mock.Call
+}
+
+// GetChunksPlainSize is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fileID int
+func (_e *MockDriveFileChunkRepository_Expecter) GetChunksPlainSize(ctx interface{}, fileID interface{}) *MockDriveFileChunkRepository_GetChunksPlainSize_Call {
+	return &MockDriveFileChunkRepository_GetChunksPlainSize_Call{Call: _e.mock.On("GetChunksPlainSize", ctx, fileID)}
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksPlainSize_Call) Run(run func(ctx context.Context, fileID int)) *MockDriveFileChunkRepository_GetChunksPlainSize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksPlainSize_Call) Return(_a0 int64, _a1 error) *MockDriveFileChunkRepository_GetChunksPlainSize_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksPlainSize_Call) RunAndReturn(run func(context.Context, int) (int64, error)) *MockDriveFileChunkRepository_GetChunksPlainSize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChunksSize provides a mock function with given fields: ctx, fileID
+func (_m *MockDriveFileChunkRepository) GetChunksSize(ctx context.Context, fileID int) (int64, error) {
+	ret := _m.Called(ctx, fileID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChunksSize")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int64, error)); ok {
+		return rf(ctx, fileID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int64); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, fileID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveFileChunkRepository_GetChunksSize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChunksSize'
+type MockDriveFileChunkRepository_GetChunksSize_Call struct {
+	*mock.Call
+}
+
+// GetChunksSize is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fileID int
+func (_e *MockDriveFileChunkRepository_Expecter) GetChunksSize(ctx interface{}, fileID interface{}) *MockDriveFileChunkRepository_GetChunksSize_Call {
+	return &MockDriveFileChunkRepository_GetChunksSize_Call{Call: _e.mock.On("GetChunksSize", ctx, fileID)}
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksSize_Call) Run(run func(ctx context.Context, fileID int)) *MockDriveFileChunkRepository_GetChunksSize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksSize_Call) Return(_a0 int64, _a1 error) *MockDriveFileChunkRepository_GetChunksSize_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveFileChunkRepository_GetChunksSize_Call) RunAndReturn(run func(context.Context, int) (int64, error)) *MockDriveFileChunkRepository_GetChunksSize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockDriveFileChunkRepository creates a new instance of MockDriveFileChunkRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDriveFileChunkRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDriveFileChunkRepository {
+	mock := &MockDriveFileChunkRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,331 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "assistant-go/internal/layer/dto"
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockDriveGrantRepository is an autogenerated mock type for the DriveGrantRepository type
+type MockDriveGrantRepository struct {
+	mock.Mock
+}
+
+type MockDriveGrantRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDriveGrantRepository) EXPECT() *MockDriveGrantRepository_Expecter {
+	return &MockDriveGrantRepository_Expecter{mock: &_m.Mock}
+}
+
+// DeleteByID provides a mock function with given fields: ctx, ID, ownerID
+func (_m *MockDriveGrantRepository) DeleteByID(ctx context.Context, ID int, ownerID int) (bool, error) {
+	ret := _m.Called(ctx, ID, ownerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (bool, error)); ok {
+		return rf(ctx, ID, ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) bool); ok {
+		r0 = rf(ctx, ID, ownerID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, ID, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveGrantRepository_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockDriveGrantRepository_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID int
+//   - ownerID int
+func (_e *MockDriveGrantRepository_Expecter) DeleteByID(ctx interface{}, ID interface{}, ownerID interface{}) *MockDriveGrantRepository_DeleteByID_Call {
+	return &MockDriveGrantRepository_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, ID, ownerID)}
+}
+
+func (_c *MockDriveGrantRepository_DeleteByID_Call) Run(run func(ctx context.Context, ID int, ownerID int)) *MockDriveGrantRepository_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_DeleteByID_Call) Return(_a0 bool, _a1 error) *MockDriveGrantRepository_DeleteByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_DeleteByID_Call) RunAndReturn(run func(context.Context, int, int) (bool, error)) *MockDriveGrantRepository_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByStructID provides a mock function with given fields: ctx, structID
+func (_m *MockDriveGrantRepository) GetByStructID(ctx context.Context, structID int) ([]*dto.DriveGrantItem, error) {
+	ret := _m.Called(ctx, structID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByStructID")
+	}
+
+	var r0 []*dto.DriveGrantItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*dto.DriveGrantItem, error)); ok {
+		return rf(ctx, structID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*dto.DriveGrantItem); ok {
+		r0 = rf(ctx, structID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.DriveGrantItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, structID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveGrantRepository_GetByStructID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByStructID'
+type MockDriveGrantRepository_GetByStructID_Call struct {
+	*mock.Call
+}
+
+// GetByStructID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - structID int
+func (_e *MockDriveGrantRepository_Expecter) GetByStructID(ctx interface{}, structID interface{}) *MockDriveGrantRepository_GetByStructID_Call {
+	return &MockDriveGrantRepository_GetByStructID_Call{Call: _e.mock.On("GetByStructID", ctx, structID)}
+}
+
+func (_c *MockDriveGrantRepository_GetByStructID_Call) Run(run func(ctx context.Context, structID int)) *MockDriveGrantRepository_GetByStructID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_GetByStructID_Call) Return(_a0 []*dto.DriveGrantItem, _a1 error) *MockDriveGrantRepository_GetByStructID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_GetByStructID_Call) RunAndReturn(run func(context.Context, int) ([]*dto.DriveGrantItem, error)) *MockDriveGrantRepository_GetByStructID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRole provides a mock function with given fields: ctx, structID, granteeID
+func (_m *MockDriveGrantRepository) GetRole(ctx context.Context, structID int, granteeID int) (int8, error) {
+	ret := _m.Called(ctx, structID, granteeID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRole")
+	}
+
+	var r0 int8
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (int8, error)); ok {
+		return rf(ctx, structID, granteeID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) int8); ok {
+		r0 = rf(ctx, structID, granteeID)
+	} else {
+		r0 = ret.Get(0).(int8)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, structID, granteeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveGrantRepository_GetRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRole'
+type MockDriveGrantRepository_GetRole_Call struct {
+	*mock.Call
+}
+
+// GetRole is a helper method to define mock.On call
+//   - ctx context.Context
+//   - structID int
+//   - granteeID int
+func (_e *MockDriveGrantRepository_Expecter) GetRole(ctx interface{}, structID interface{}, granteeID interface{}) *MockDriveGrantRepository_GetRole_Call {
+	return &MockDriveGrantRepository_GetRole_Call{Call: _e.mock.On("GetRole", ctx, structID, granteeID)}
+}
+
+func (_c *MockDriveGrantRepository_GetRole_Call) Run(run func(ctx context.Context, structID int, granteeID int)) *MockDriveGrantRepository_GetRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_GetRole_Call) Return(_a0 int8, _a1 error) *MockDriveGrantRepository_GetRole_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_GetRole_Call) RunAndReturn(run func(context.Context, int, int) (int8, error)) *MockDriveGrantRepository_GetRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SharedWithUser provides a mock function with given fields: ctx, granteeID
+func (_m *MockDriveGrantRepository) SharedWithUser(ctx context.Context, granteeID int) ([]*dto.DriveTree, error) {
+	ret := _m.Called(ctx, granteeID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SharedWithUser")
+	}
+
+	var r0 []*dto.DriveTree
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*dto.DriveTree, error)); ok {
+		return rf(ctx, granteeID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*dto.DriveTree); ok {
+		r0 = rf(ctx, granteeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.DriveTree)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, granteeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveGrantRepository_SharedWithUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SharedWithUser'
+type MockDriveGrantRepository_SharedWithUser_Call struct {
+	*mock.Call
+}
+
+// SharedWithUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - granteeID int
+func (_e *MockDriveGrantRepository_Expecter) SharedWithUser(ctx interface{}, granteeID interface{}) *MockDriveGrantRepository_SharedWithUser_Call {
+	return &MockDriveGrantRepository_SharedWithUser_Call{Call: _e.mock.On("SharedWithUser", ctx, granteeID)}
+}
+
+func (_c *MockDriveGrantRepository_SharedWithUser_Call) Run(run func(ctx context.Context, granteeID int)) *MockDriveGrantRepository_SharedWithUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_SharedWithUser_Call) Return(_a0 []*dto.DriveTree, _a1 error) *MockDriveGrantRepository_SharedWithUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_SharedWithUser_Call) RunAndReturn(run func(context.Context, int) ([]*dto.DriveTree, error)) *MockDriveGrantRepository_SharedWithUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function with given fields: ctx, in
+func (_m *MockDriveGrantRepository) Upsert(ctx context.Context, in *entity.DriveGrant) (*entity.DriveGrant, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 *entity.DriveGrant
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveGrant) (*entity.DriveGrant, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveGrant) *entity.DriveGrant); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveGrant)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.DriveGrant) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveGrantRepository_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type MockDriveGrantRepository_Upsert_Call struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.DriveGrant
+func (_e *MockDriveGrantRepository_Expecter) Upsert(ctx interface{}, in interface{}) *MockDriveGrantRepository_Upsert_Call {
+	return &MockDriveGrantRepository_Upsert_Call{Call: _e.mock.On("Upsert", ctx, in)}
+}
+
+func (_c *MockDriveGrantRepository_Upsert_Call) Run(run func(ctx context.Context, in *entity.DriveGrant)) *MockDriveGrantRepository_Upsert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.DriveGrant))
+	})
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_Upsert_Call) Return(_a0 *entity.DriveGrant, _a1 error) *MockDriveGrantRepository_Upsert_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveGrantRepository_Upsert_Call) RunAndReturn(run func(context.Context, *entity.DriveGrant) (*entity.DriveGrant, error)) *MockDriveGrantRepository_Upsert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockDriveGrantRepository creates a new instance of MockDriveGrantRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDriveGrantRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDriveGrantRepository {
+	mock := &MockDriveGrantRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
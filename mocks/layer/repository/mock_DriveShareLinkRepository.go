@@ -0,0 +1,498 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "assistant-go/internal/layer/dto"
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockDriveShareLinkRepository is an autogenerated mock type for the DriveShareLinkRepository type
+type MockDriveShareLinkRepository struct {
+	mock.Mock
+}
+
+type MockDriveShareLinkRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDriveShareLinkRepository) EXPECT() *MockDriveShareLinkRepository_Expecter {
+	return &MockDriveShareLinkRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, in
+func (_m *MockDriveShareLinkRepository) Create(ctx context.Context, in *entity.DriveShareLink) (*entity.DriveShareLink, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.DriveShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveShareLink) (*entity.DriveShareLink, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveShareLink) *entity.DriveShareLink); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveShareLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.DriveShareLink) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveShareLinkRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockDriveShareLinkRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.DriveShareLink
+func (_e *MockDriveShareLinkRepository_Expecter) Create(ctx interface{}, in interface{}) *MockDriveShareLinkRepository_Create_Call {
+	return &MockDriveShareLinkRepository_Create_Call{Call: _e.mock.On("Create", ctx, in)}
+}
+
+func (_c *MockDriveShareLinkRepository_Create_Call) Run(run func(ctx context.Context, in *entity.DriveShareLink)) *MockDriveShareLinkRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.DriveShareLink))
+	})
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_Create_Call) Return(_a0 *entity.DriveShareLink, _a1 error) *MockDriveShareLinkRepository_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.DriveShareLink) (*entity.DriveShareLink, error)) *MockDriveShareLinkRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function with given fields: ctx, ID, userID
+func (_m *MockDriveShareLinkRepository) DeleteByID(ctx context.Context, ID int, userID int) (bool, error) {
+	ret := _m.Called(ctx, ID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (bool, error)); ok {
+		return rf(ctx, ID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) bool); ok {
+		r0 = rf(ctx, ID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, ID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveShareLinkRepository_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockDriveShareLinkRepository_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID int
+//   - userID int
+func (_e *MockDriveShareLinkRepository_Expecter) DeleteByID(ctx interface{}, ID interface{}, userID interface{}) *MockDriveShareLinkRepository_DeleteByID_Call {
+	return &MockDriveShareLinkRepository_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, ID, userID)}
+}
+
+func (_c *MockDriveShareLinkRepository_DeleteByID_Call) Run(run func(ctx context.Context, ID int, userID int)) *MockDriveShareLinkRepository_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_DeleteByID_Call) Return(_a0 bool, _a1 error) *MockDriveShareLinkRepository_DeleteByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_DeleteByID_Call) RunAndReturn(run func(context.Context, int, int) (bool, error)) *MockDriveShareLinkRepository_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExistsByHash provides a mock function with given fields: ctx, hash
+func (_m *MockDriveShareLinkRepository) ExistsByHash(ctx context.Context, hash string) (bool, error) {
+	ret := _m.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExistsByHash")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveShareLinkRepository_ExistsByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExistsByHash'
+type MockDriveShareLinkRepository_ExistsByHash_Call struct {
+	*mock.Call
+}
+
+// ExistsByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockDriveShareLinkRepository_Expecter) ExistsByHash(ctx interface{}, hash interface{}) *MockDriveShareLinkRepository_ExistsByHash_Call {
+	return &MockDriveShareLinkRepository_ExistsByHash_Call{Call: _e.mock.On("ExistsByHash", ctx, hash)}
+}
+
+func (_c *MockDriveShareLinkRepository_ExistsByHash_Call) Run(run func(ctx context.Context, hash string)) *MockDriveShareLinkRepository_ExistsByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_ExistsByHash_Call) Return(_a0 bool, _a1 error) *MockDriveShareLinkRepository_ExistsByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_ExistsByHash_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockDriveShareLinkRepository_ExistsByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetActiveByUserID provides a mock function with given fields: ctx, userID, structID, now
+func (_m *MockDriveShareLinkRepository) GetActiveByUserID(ctx context.Context, userID int, structID *int, now time.Time) ([]*entity.DriveShareLink, error) {
+	ret := _m.Called(ctx, userID, structID, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveByUserID")
+	}
+
+	var r0 []*entity.DriveShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, time.Time) ([]*entity.DriveShareLink, error)); ok {
+		return rf(ctx, userID, structID, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, time.Time) []*entity.DriveShareLink); ok {
+		r0 = rf(ctx, userID, structID, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.DriveShareLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, *int, time.Time) error); ok {
+		r1 = rf(ctx, userID, structID, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveShareLinkRepository_GetActiveByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActiveByUserID'
+type MockDriveShareLinkRepository_GetActiveByUserID_Call struct {
+	*mock.Call
+}
+
+// GetActiveByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - structID *int
+//   - now time.Time
+func (_e *MockDriveShareLinkRepository_Expecter) GetActiveByUserID(ctx interface{}, userID interface{}, structID interface{}, now interface{}) *MockDriveShareLinkRepository_GetActiveByUserID_Call {
+	return &MockDriveShareLinkRepository_GetActiveByUserID_Call{Call: _e.mock.On("GetActiveByUserID", ctx, userID, structID, now)}
+}
+
+func (_c *MockDriveShareLinkRepository_GetActiveByUserID_Call) Run(run func(ctx context.Context, userID int, structID *int, now time.Time)) *MockDriveShareLinkRepository_GetActiveByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(*int), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_GetActiveByUserID_Call) Return(_a0 []*entity.DriveShareLink, _a1 error) *MockDriveShareLinkRepository_GetActiveByUserID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_GetActiveByUserID_Call) RunAndReturn(run func(context.Context, int, *int, time.Time) ([]*entity.DriveShareLink, error)) *MockDriveShareLinkRepository_GetActiveByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function with given fields: ctx, hash
+func (_m *MockDriveShareLinkRepository) GetByHash(ctx context.Context, hash string) (*entity.DriveShareLink, error) {
+	ret := _m.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *entity.DriveShareLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.DriveShareLink, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.DriveShareLink); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveShareLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveShareLinkRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockDriveShareLinkRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockDriveShareLinkRepository_Expecter) GetByHash(ctx interface{}, hash interface{}) *MockDriveShareLinkRepository_GetByHash_Call {
+	return &MockDriveShareLinkRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, hash)}
+}
+
+func (_c *MockDriveShareLinkRepository_GetByHash_Call) Run(run func(ctx context.Context, hash string)) *MockDriveShareLinkRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_GetByHash_Call) Return(_a0 *entity.DriveShareLink, _a1 error) *MockDriveShareLinkRepository_GetByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_GetByHash_Call) RunAndReturn(run func(context.Context, string) (*entity.DriveShareLink, error)) *MockDriveShareLinkRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementDownloads provides a mock function with given fields: ctx, ID
+func (_m *MockDriveShareLinkRepository) IncrementDownloads(ctx context.Context, ID int) error {
+	ret := _m.Called(ctx, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementDownloads")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveShareLinkRepository_IncrementDownloads_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementDownloads'
+type MockDriveShareLinkRepository_IncrementDownloads_Call struct {
+	*mock.Call
+}
+
+// IncrementDownloads is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID int
+func (_e *MockDriveShareLinkRepository_Expecter) IncrementDownloads(ctx interface{}, ID interface{}) *MockDriveShareLinkRepository_IncrementDownloads_Call {
+	return &MockDriveShareLinkRepository_IncrementDownloads_Call{Call: _e.mock.On("IncrementDownloads", ctx, ID)}
+}
+
+func (_c *MockDriveShareLinkRepository_IncrementDownloads_Call) Run(run func(ctx context.Context, ID int)) *MockDriveShareLinkRepository_IncrementDownloads_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_IncrementDownloads_Call) Return(_a0 error) *MockDriveShareLinkRepository_IncrementDownloads_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_IncrementDownloads_Call) RunAndReturn(run func(context.Context, int) error) *MockDriveShareLinkRepository_IncrementDownloads_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsInSubtree provides a mock function with given fields: ctx, rootID, structID
+func (_m *MockDriveShareLinkRepository) IsInSubtree(ctx context.Context, rootID int, structID int) (bool, error) {
+	ret := _m.Called(ctx, rootID, structID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsInSubtree")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (bool, error)); ok {
+		return rf(ctx, rootID, structID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) bool); ok {
+		r0 = rf(ctx, rootID, structID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, rootID, structID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveShareLinkRepository_IsInSubtree_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsInSubtree'
+type MockDriveShareLinkRepository_IsInSubtree_Call struct {
+	*mock.Call
+}
+
+// IsInSubtree is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rootID int
+//   - structID int
+func (_e *MockDriveShareLinkRepository_Expecter) IsInSubtree(ctx interface{}, rootID interface{}, structID interface{}) *MockDriveShareLinkRepository_IsInSubtree_Call {
+	return &MockDriveShareLinkRepository_IsInSubtree_Call{Call: _e.mock.On("IsInSubtree", ctx, rootID, structID)}
+}
+
+func (_c *MockDriveShareLinkRepository_IsInSubtree_Call) Run(run func(ctx context.Context, rootID int, structID int)) *MockDriveShareLinkRepository_IsInSubtree_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_IsInSubtree_Call) Return(_a0 bool, _a1 error) *MockDriveShareLinkRepository_IsInSubtree_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_IsInSubtree_Call) RunAndReturn(run func(context.Context, int, int) (bool, error)) *MockDriveShareLinkRepository_IsInSubtree_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SubtreeByRootID provides a mock function with given fields: ctx, rootID
+func (_m *MockDriveShareLinkRepository) SubtreeByRootID(ctx context.Context, rootID int) ([]*dto.DriveSharedItem, error) {
+	ret := _m.Called(ctx, rootID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubtreeByRootID")
+	}
+
+	var r0 []*dto.DriveSharedItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*dto.DriveSharedItem, error)); ok {
+		return rf(ctx, rootID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*dto.DriveSharedItem); ok {
+		r0 = rf(ctx, rootID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.DriveSharedItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, rootID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveShareLinkRepository_SubtreeByRootID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SubtreeByRootID'
+type MockDriveShareLinkRepository_SubtreeByRootID_Call struct {
+	*mock.Call
+}
+
+// SubtreeByRootID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rootID int
+func (_e *MockDriveShareLinkRepository_Expecter) SubtreeByRootID(ctx interface{}, rootID interface{}) *MockDriveShareLinkRepository_SubtreeByRootID_Call {
+	return &MockDriveShareLinkRepository_SubtreeByRootID_Call{Call: _e.mock.On("SubtreeByRootID", ctx, rootID)}
+}
+
+func (_c *MockDriveShareLinkRepository_SubtreeByRootID_Call) Run(run func(ctx context.Context, rootID int)) *MockDriveShareLinkRepository_SubtreeByRootID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_SubtreeByRootID_Call) Return(_a0 []*dto.DriveSharedItem, _a1 error) *MockDriveShareLinkRepository_SubtreeByRootID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveShareLinkRepository_SubtreeByRootID_Call) RunAndReturn(run func(context.Context, int) ([]*dto.DriveSharedItem, error)) *MockDriveShareLinkRepository_SubtreeByRootID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockDriveShareLinkRepository creates a new instance of MockDriveShareLinkRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDriveShareLinkRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDriveShareLinkRepository {
+	mock := &MockDriveShareLinkRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,943 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "assistant-go/internal/layer/dto"
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockDriveStructRepository is an autogenerated mock type for the DriveStructRepository type
+type MockDriveStructRepository struct {
+	mock.Mock
+}
+
+type MockDriveStructRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDriveStructRepository) EXPECT() *MockDriveStructRepository_Expecter {
+	return &MockDriveStructRepository_Expecter{mock: &_m.Mock}
+}
+
+// Ancestors provides a mock function with given fields: ctx, userID, structID
+func (_m *MockDriveStructRepository) Ancestors(ctx context.Context, userID int, structID int) ([]*dto.DrivePathItem, error) {
+	ret := _m.Called(ctx, userID, structID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ancestors")
+	}
+
+	var r0 []*dto.DrivePathItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*dto.DrivePathItem, error)); ok {
+		return rf(ctx, userID, structID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*dto.DrivePathItem); ok {
+		r0 = rf(ctx, userID, structID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.DrivePathItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userID, structID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_Ancestors_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ancestors'
+type MockDriveStructRepository_Ancestors_Call struct {
+	*mock.Call
+}
+
+// Ancestors is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - structID int
+func (_e *MockDriveStructRepository_Expecter) Ancestors(ctx interface{}, userID interface{}, structID interface{}) *MockDriveStructRepository_Ancestors_Call {
+	return &MockDriveStructRepository_Ancestors_Call{Call: _e.mock.On("Ancestors", ctx, userID, structID)}
+}
+
+func (_c *MockDriveStructRepository_Ancestors_Call) Run(run func(ctx context.Context, userID int, structID int)) *MockDriveStructRepository_Ancestors_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_Ancestors_Call) Return(_a0 []*dto.DrivePathItem, _a1 error) *MockDriveStructRepository_Ancestors_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_Ancestors_Call) RunAndReturn(run func(context.Context, int, int) ([]*dto.DrivePathItem, error)) *MockDriveStructRepository_Ancestors_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: ctx, _a1
+func (_m *MockDriveStructRepository) Create(ctx context.Context, _a1 *entity.DriveStruct) (*entity.DriveStruct, error) {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.DriveStruct
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveStruct) (*entity.DriveStruct, error)); ok {
+		return rf(ctx, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveStruct) *entity.DriveStruct); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveStruct)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.DriveStruct) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockDriveStructRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - _a1 *entity.DriveStruct
+func (_e *MockDriveStructRepository_Expecter) Create(ctx interface{}, _a1 interface{}) *MockDriveStructRepository_Create_Call {
+	return &MockDriveStructRepository_Create_Call{Call: _e.mock.On("Create", ctx, _a1)}
+}
+
+func (_c *MockDriveStructRepository_Create_Call) Run(run func(ctx context.Context, _a1 *entity.DriveStruct)) *MockDriveStructRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.DriveStruct))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_Create_Call) Return(_a0 *entity.DriveStruct, _a1 error) *MockDriveStructRepository_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.DriveStruct) (*entity.DriveStruct, error)) *MockDriveStructRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteRecursive provides a mock function with given fields: ctx, userID, structID
+func (_m *MockDriveStructRepository) DeleteRecursive(ctx context.Context, userID int, structID int) error {
+	ret := _m.Called(ctx, userID, structID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRecursive")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userID, structID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveStructRepository_DeleteRecursive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRecursive'
+type MockDriveStructRepository_DeleteRecursive_Call struct {
+	*mock.Call
+}
+
+// DeleteRecursive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - structID int
+func (_e *MockDriveStructRepository_Expecter) DeleteRecursive(ctx interface{}, userID interface{}, structID interface{}) *MockDriveStructRepository_DeleteRecursive_Call {
+	return &MockDriveStructRepository_DeleteRecursive_Call{Call: _e.mock.On("DeleteRecursive", ctx, userID, structID)}
+}
+
+func (_c *MockDriveStructRepository_DeleteRecursive_Call) Run(run func(ctx context.Context, userID int, structID int)) *MockDriveStructRepository_DeleteRecursive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_DeleteRecursive_Call) Return(_a0 error) *MockDriveStructRepository_DeleteRecursive_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_DeleteRecursive_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDriveStructRepository_DeleteRecursive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindRow provides a mock function with given fields: ctx, userID, name, rowType, parentID
+func (_m *MockDriveStructRepository) FindRow(ctx context.Context, userID int, name string, rowType int8, parentID *int) (*entity.DriveStruct, error) {
+	ret := _m.Called(ctx, userID, name, rowType, parentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindRow")
+	}
+
+	var r0 *entity.DriveStruct
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int8, *int) (*entity.DriveStruct, error)); ok {
+		return rf(ctx, userID, name, rowType, parentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int8, *int) *entity.DriveStruct); ok {
+		r0 = rf(ctx, userID, name, rowType, parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveStruct)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, int8, *int) error); ok {
+		r1 = rf(ctx, userID, name, rowType, parentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_FindRow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindRow'
+type MockDriveStructRepository_FindRow_Call struct {
+	*mock.Call
+}
+
+// FindRow is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - name string
+//   - rowType int8
+//   - parentID *int
+func (_e *MockDriveStructRepository_Expecter) FindRow(ctx interface{}, userID interface{}, name interface{}, rowType interface{}, parentID interface{}) *MockDriveStructRepository_FindRow_Call {
+	return &MockDriveStructRepository_FindRow_Call{Call: _e.mock.On("FindRow", ctx, userID, name, rowType, parentID)}
+}
+
+func (_c *MockDriveStructRepository_FindRow_Call) Run(run func(ctx context.Context, userID int, name string, rowType int8, parentID *int)) *MockDriveStructRepository_FindRow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(int8), args[4].(*int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_FindRow_Call) Return(_a0 *entity.DriveStruct, _a1 error) *MockDriveStructRepository_FindRow_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_FindRow_Call) RunAndReturn(run func(context.Context, int, string, int8, *int) (*entity.DriveStruct, error)) *MockDriveStructRepository_FindRow_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllRecursive provides a mock function with given fields: ctx, userID, structID
+func (_m *MockDriveStructRepository) GetAllRecursive(ctx context.Context, userID int, structID int) ([]*entity.DriveStruct, error) {
+	ret := _m.Called(ctx, userID, structID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllRecursive")
+	}
+
+	var r0 []*entity.DriveStruct
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*entity.DriveStruct, error)); ok {
+		return rf(ctx, userID, structID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*entity.DriveStruct); ok {
+		r0 = rf(ctx, userID, structID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.DriveStruct)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userID, structID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_GetAllRecursive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllRecursive'
+type MockDriveStructRepository_GetAllRecursive_Call struct {
+	*mock.Call
+}
+
+// GetAllRecursive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - structID int
+func (_e *MockDriveStructRepository_Expecter) GetAllRecursive(ctx interface{}, userID interface{}, structID interface{}) *MockDriveStructRepository_GetAllRecursive_Call {
+	return &MockDriveStructRepository_GetAllRecursive_Call{Call: _e.mock.On("GetAllRecursive", ctx, userID, structID)}
+}
+
+func (_c *MockDriveStructRepository_GetAllRecursive_Call) Run(run func(ctx context.Context, userID int, structID int)) *MockDriveStructRepository_GetAllRecursive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_GetAllRecursive_Call) Return(_a0 []*entity.DriveStruct, _a1 error) *MockDriveStructRepository_GetAllRecursive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_GetAllRecursive_Call) RunAndReturn(run func(context.Context, int, int) ([]*entity.DriveStruct, error)) *MockDriveStructRepository_GetAllRecursive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function with given fields: ctx, ID
+func (_m *MockDriveStructRepository) GetByID(ctx context.Context, ID int) (*entity.DriveStruct, error) {
+	ret := _m.Called(ctx, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *entity.DriveStruct
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.DriveStruct, error)); ok {
+		return rf(ctx, ID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.DriveStruct); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.DriveStruct)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockDriveStructRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID int
+func (_e *MockDriveStructRepository_Expecter) GetByID(ctx interface{}, ID interface{}) *MockDriveStructRepository_GetByID_Call {
+	return &MockDriveStructRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, ID)}
+}
+
+func (_c *MockDriveStructRepository_GetByID_Call) Run(run func(ctx context.Context, ID int)) *MockDriveStructRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_GetByID_Call) Return(_a0 *entity.DriveStruct, _a1 error) *MockDriveStructRepository_GetByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_GetByID_Call) RunAndReturn(run func(context.Context, int) (*entity.DriveStruct, error)) *MockDriveStructRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTrashRootsBefore provides a mock function with given fields: ctx, before
+func (_m *MockDriveStructRepository) GetTrashRootsBefore(ctx context.Context, before time.Time) ([]*entity.DriveStruct, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrashRootsBefore")
+	}
+
+	var r0 []*entity.DriveStruct
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*entity.DriveStruct, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*entity.DriveStruct); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.DriveStruct)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_GetTrashRootsBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTrashRootsBefore'
+type MockDriveStructRepository_GetTrashRootsBefore_Call struct {
+	*mock.Call
+}
+
+// GetTrashRootsBefore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+func (_e *MockDriveStructRepository_Expecter) GetTrashRootsBefore(ctx interface{}, before interface{}) *MockDriveStructRepository_GetTrashRootsBefore_Call {
+	return &MockDriveStructRepository_GetTrashRootsBefore_Call{Call: _e.mock.On("GetTrashRootsBefore", ctx, before)}
+}
+
+func (_c *MockDriveStructRepository_GetTrashRootsBefore_Call) Run(run func(ctx context.Context, before time.Time)) *MockDriveStructRepository_GetTrashRootsBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_GetTrashRootsBefore_Call) Return(_a0 []*entity.DriveStruct, _a1 error) *MockDriveStructRepository_GetTrashRootsBefore_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_GetTrashRootsBefore_Call) RunAndReturn(run func(context.Context, time.Time) ([]*entity.DriveStruct, error)) *MockDriveStructRepository_GetTrashRootsBefore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MassUpdateParentID provides a mock function with given fields: ctx, parentID, IDs
+func (_m *MockDriveStructRepository) MassUpdateParentID(ctx context.Context, parentID *int, IDs []int) error {
+	ret := _m.Called(ctx, parentID, IDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MassUpdateParentID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *int, []int) error); ok {
+		r0 = rf(ctx, parentID, IDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveStructRepository_MassUpdateParentID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MassUpdateParentID'
+type MockDriveStructRepository_MassUpdateParentID_Call struct {
+	*mock.Call
+}
+
+// MassUpdateParentID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - parentID *int
+//   - IDs []int
+func (_e *MockDriveStructRepository_Expecter) MassUpdateParentID(ctx interface{}, parentID interface{}, IDs interface{}) *MockDriveStructRepository_MassUpdateParentID_Call {
+	return &MockDriveStructRepository_MassUpdateParentID_Call{Call: _e.mock.On("MassUpdateParentID", ctx, parentID, IDs)}
+}
+
+func (_c *MockDriveStructRepository_MassUpdateParentID_Call) Run(run func(ctx context.Context, parentID *int, IDs []int)) *MockDriveStructRepository_MassUpdateParentID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*int), args[2].([]int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_MassUpdateParentID_Call) Return(_a0 error) *MockDriveStructRepository_MassUpdateParentID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_MassUpdateParentID_Call) RunAndReturn(run func(context.Context, *int, []int) error) *MockDriveStructRepository_MassUpdateParentID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MoveToTrash provides a mock function with given fields: ctx, userID, structID, deletedAt
+func (_m *MockDriveStructRepository) MoveToTrash(ctx context.Context, userID int, structID int, deletedAt time.Time) error {
+	ret := _m.Called(ctx, userID, structID, deletedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MoveToTrash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, time.Time) error); ok {
+		r0 = rf(ctx, userID, structID, deletedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveStructRepository_MoveToTrash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MoveToTrash'
+type MockDriveStructRepository_MoveToTrash_Call struct {
+	*mock.Call
+}
+
+// MoveToTrash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - structID int
+//   - deletedAt time.Time
+func (_e *MockDriveStructRepository_Expecter) MoveToTrash(ctx interface{}, userID interface{}, structID interface{}, deletedAt interface{}) *MockDriveStructRepository_MoveToTrash_Call {
+	return &MockDriveStructRepository_MoveToTrash_Call{Call: _e.mock.On("MoveToTrash", ctx, userID, structID, deletedAt)}
+}
+
+func (_c *MockDriveStructRepository_MoveToTrash_Call) Run(run func(ctx context.Context, userID int, structID int, deletedAt time.Time)) *MockDriveStructRepository_MoveToTrash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_MoveToTrash_Call) Return(_a0 error) *MockDriveStructRepository_MoveToTrash_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_MoveToTrash_Call) RunAndReturn(run func(context.Context, int, int, time.Time) error) *MockDriveStructRepository_MoveToTrash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolvePath provides a mock function with given fields: ctx, userID, names
+func (_m *MockDriveStructRepository) ResolvePath(ctx context.Context, userID int, names []string) (*dto.DrivePathItem, error) {
+	ret := _m.Called(ctx, userID, names)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolvePath")
+	}
+
+	var r0 *dto.DrivePathItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []string) (*dto.DrivePathItem, error)); ok {
+		return rf(ctx, userID, names)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, []string) *dto.DrivePathItem); ok {
+		r0 = rf(ctx, userID, names)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.DrivePathItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, []string) error); ok {
+		r1 = rf(ctx, userID, names)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_ResolvePath_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolvePath'
+type MockDriveStructRepository_ResolvePath_Call struct {
+	*mock.Call
+}
+
+// ResolvePath is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - names []string
+func (_e *MockDriveStructRepository_Expecter) ResolvePath(ctx interface{}, userID interface{}, names interface{}) *MockDriveStructRepository_ResolvePath_Call {
+	return &MockDriveStructRepository_ResolvePath_Call{Call: _e.mock.On("ResolvePath", ctx, userID, names)}
+}
+
+func (_c *MockDriveStructRepository_ResolvePath_Call) Run(run func(ctx context.Context, userID int, names []string)) *MockDriveStructRepository_ResolvePath_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_ResolvePath_Call) Return(_a0 *dto.DrivePathItem, _a1 error) *MockDriveStructRepository_ResolvePath_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_ResolvePath_Call) RunAndReturn(run func(context.Context, int, []string) (*dto.DrivePathItem, error)) *MockDriveStructRepository_ResolvePath_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreFromTrash provides a mock function with given fields: ctx, userID, structID
+func (_m *MockDriveStructRepository) RestoreFromTrash(ctx context.Context, userID int, structID int) error {
+	ret := _m.Called(ctx, userID, structID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreFromTrash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userID, structID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveStructRepository_RestoreFromTrash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreFromTrash'
+type MockDriveStructRepository_RestoreFromTrash_Call struct {
+	*mock.Call
+}
+
+// RestoreFromTrash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - structID int
+func (_e *MockDriveStructRepository_Expecter) RestoreFromTrash(ctx interface{}, userID interface{}, structID interface{}) *MockDriveStructRepository_RestoreFromTrash_Call {
+	return &MockDriveStructRepository_RestoreFromTrash_Call{Call: _e.mock.On("RestoreFromTrash", ctx, userID, structID)}
+}
+
+func (_c *MockDriveStructRepository_RestoreFromTrash_Call) Run(run func(ctx context.Context, userID int, structID int)) *MockDriveStructRepository_RestoreFromTrash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_RestoreFromTrash_Call) Return(_a0 error) *MockDriveStructRepository_RestoreFromTrash_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_RestoreFromTrash_Call) RunAndReturn(run func(context.Context, int, int) error) *MockDriveStructRepository_RestoreFromTrash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function with given fields: ctx, userID, in
+func (_m *MockDriveStructRepository) Search(ctx context.Context, userID int, in *dto.DriveSearch) ([]*dto.DriveSearchItem, int, error) {
+	ret := _m.Called(ctx, userID, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []*dto.DriveSearchItem
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *dto.DriveSearch) ([]*dto.DriveSearchItem, int, error)); ok {
+		return rf(ctx, userID, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, *dto.DriveSearch) []*dto.DriveSearchItem); ok {
+		r0 = rf(ctx, userID, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.DriveSearchItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, *dto.DriveSearch) int); ok {
+		r1 = rf(ctx, userID, in)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, *dto.DriveSearch) error); ok {
+		r2 = rf(ctx, userID, in)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockDriveStructRepository_Search_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Search'
+type MockDriveStructRepository_Search_Call struct {
+	*mock.Call
+}
+
+// Search is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - in *dto.DriveSearch
+func (_e *MockDriveStructRepository_Expecter) Search(ctx interface{}, userID interface{}, in interface{}) *MockDriveStructRepository_Search_Call {
+	return &MockDriveStructRepository_Search_Call{Call: _e.mock.On("Search", ctx, userID, in)}
+}
+
+func (_c *MockDriveStructRepository_Search_Call) Run(run func(ctx context.Context, userID int, in *dto.DriveSearch)) *MockDriveStructRepository_Search_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(*dto.DriveSearch))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_Search_Call) Return(_a0 []*dto.DriveSearchItem, _a1 int, _a2 error) *MockDriveStructRepository_Search_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_Search_Call) RunAndReturn(run func(context.Context, int, *dto.DriveSearch) ([]*dto.DriveSearchItem, int, error)) *MockDriveStructRepository_Search_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StructCountByUserAndIDs provides a mock function with given fields: ctx, userID, IDs
+func (_m *MockDriveStructRepository) StructCountByUserAndIDs(ctx context.Context, userID int, IDs []int) (int, error) {
+	ret := _m.Called(ctx, userID, IDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StructCountByUserAndIDs")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) (int, error)); ok {
+		return rf(ctx, userID, IDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) int); ok {
+		r0 = rf(ctx, userID, IDs)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, []int) error); ok {
+		r1 = rf(ctx, userID, IDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_StructCountByUserAndIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StructCountByUserAndIDs'
+type MockDriveStructRepository_StructCountByUserAndIDs_Call struct {
+	*mock.Call
+}
+
+// StructCountByUserAndIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - IDs []int
+func (_e *MockDriveStructRepository_Expecter) StructCountByUserAndIDs(ctx interface{}, userID interface{}, IDs interface{}) *MockDriveStructRepository_StructCountByUserAndIDs_Call {
+	return &MockDriveStructRepository_StructCountByUserAndIDs_Call{Call: _e.mock.On("StructCountByUserAndIDs", ctx, userID, IDs)}
+}
+
+func (_c *MockDriveStructRepository_StructCountByUserAndIDs_Call) Run(run func(ctx context.Context, userID int, IDs []int)) *MockDriveStructRepository_StructCountByUserAndIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].([]int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_StructCountByUserAndIDs_Call) Return(_a0 int, _a1 error) *MockDriveStructRepository_StructCountByUserAndIDs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_StructCountByUserAndIDs_Call) RunAndReturn(run func(context.Context, int, []int) (int, error)) *MockDriveStructRepository_StructCountByUserAndIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TrashByUserID provides a mock function with given fields: ctx, userID
+func (_m *MockDriveStructRepository) TrashByUserID(ctx context.Context, userID int) ([]*dto.DriveTrashItem, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrashByUserID")
+	}
+
+	var r0 []*dto.DriveTrashItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*dto.DriveTrashItem, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*dto.DriveTrashItem); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.DriveTrashItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_TrashByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TrashByUserID'
+type MockDriveStructRepository_TrashByUserID_Call struct {
+	*mock.Call
+}
+
+// TrashByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *MockDriveStructRepository_Expecter) TrashByUserID(ctx interface{}, userID interface{}) *MockDriveStructRepository_TrashByUserID_Call {
+	return &MockDriveStructRepository_TrashByUserID_Call{Call: _e.mock.On("TrashByUserID", ctx, userID)}
+}
+
+func (_c *MockDriveStructRepository_TrashByUserID_Call) Run(run func(ctx context.Context, userID int)) *MockDriveStructRepository_TrashByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_TrashByUserID_Call) Return(_a0 []*dto.DriveTrashItem, _a1 error) *MockDriveStructRepository_TrashByUserID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_TrashByUserID_Call) RunAndReturn(run func(context.Context, int) ([]*dto.DriveTrashItem, error)) *MockDriveStructRepository_TrashByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TreeByUserID provides a mock function with given fields: ctx, userID, parentID
+func (_m *MockDriveStructRepository) TreeByUserID(ctx context.Context, userID int, parentID *int) ([]*dto.DriveTree, error) {
+	ret := _m.Called(ctx, userID, parentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TreeByUserID")
+	}
+
+	var r0 []*dto.DriveTree
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int) ([]*dto.DriveTree, error)); ok {
+		return rf(ctx, userID, parentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int) []*dto.DriveTree); ok {
+		r0 = rf(ctx, userID, parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.DriveTree)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, *int) error); ok {
+		r1 = rf(ctx, userID, parentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDriveStructRepository_TreeByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TreeByUserID'
+type MockDriveStructRepository_TreeByUserID_Call struct {
+	*mock.Call
+}
+
+// TreeByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - parentID *int
+func (_e *MockDriveStructRepository_Expecter) TreeByUserID(ctx interface{}, userID interface{}, parentID interface{}) *MockDriveStructRepository_TreeByUserID_Call {
+	return &MockDriveStructRepository_TreeByUserID_Call{Call: _e.mock.On("TreeByUserID", ctx, userID, parentID)}
+}
+
+func (_c *MockDriveStructRepository_TreeByUserID_Call) Run(run func(ctx context.Context, userID int, parentID *int)) *MockDriveStructRepository_TreeByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(*int))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_TreeByUserID_Call) Return(_a0 []*dto.DriveTree, _a1 error) *MockDriveStructRepository_TreeByUserID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_TreeByUserID_Call) RunAndReturn(run func(context.Context, int, *int) ([]*dto.DriveTree, error)) *MockDriveStructRepository_TreeByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, in
+func (_m *MockDriveStructRepository) Update(ctx context.Context, in *entity.DriveStruct) error {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DriveStruct) error); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDriveStructRepository_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockDriveStructRepository_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.DriveStruct
+func (_e *MockDriveStructRepository_Expecter) Update(ctx interface{}, in interface{}) *MockDriveStructRepository_Update_Call {
+	return &MockDriveStructRepository_Update_Call{Call: _e.mock.On("Update", ctx, in)}
+}
+
+func (_c *MockDriveStructRepository_Update_Call) Run(run func(ctx context.Context, in *entity.DriveStruct)) *MockDriveStructRepository_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.DriveStruct))
+	})
+	return _c
+}
+
+func (_c *MockDriveStructRepository_Update_Call) Return(_a0 error) *MockDriveStructRepository_Update_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDriveStructRepository_Update_Call) RunAndReturn(run func(context.Context, *entity.DriveStruct) error) *MockDriveStructRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockDriveStructRepository creates a new instance of MockDriveStructRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDriveStructRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDriveStructRepository {
+	mock := &MockDriveStructRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
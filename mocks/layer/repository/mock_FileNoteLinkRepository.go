@@ -0,0 +1,179 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockFileNoteLinkRepository is an autogenerated mock type for the FileNoteLinkRepository type
+type MockFileNoteLinkRepository struct {
+	mock.Mock
+}
+
+type MockFileNoteLinkRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockFileNoteLinkRepository) EXPECT() *MockFileNoteLinkRepository_Expecter {
+	return &MockFileNoteLinkRepository_Expecter{mock: &_m.Mock}
+}
+
+// DeleteByNoteID provides a mock function with given fields: ctx, noteID
+func (_m *MockFileNoteLinkRepository) DeleteByNoteID(ctx context.Context, noteID int) error {
+	ret := _m.Called(ctx, noteID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByNoteID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, noteID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileNoteLinkRepository_DeleteByNoteID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByNoteID'
+type MockFileNoteLinkRepository_DeleteByNoteID_Call struct {
+	*mock.Call
+}
+
+// DeleteByNoteID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - noteID int
+func (_e *MockFileNoteLinkRepository_Expecter) DeleteByNoteID(ctx interface{}, noteID interface{}) *MockFileNoteLinkRepository_DeleteByNoteID_Call {
+	return &MockFileNoteLinkRepository_DeleteByNoteID_Call{Call: _e.mock.On("DeleteByNoteID", ctx, noteID)}
+}
+
+func (_c *MockFileNoteLinkRepository_DeleteByNoteID_Call) Run(run func(ctx context.Context, noteID int)) *MockFileNoteLinkRepository_DeleteByNoteID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockFileNoteLinkRepository_DeleteByNoteID_Call) Return(_a0 error) *MockFileNoteLinkRepository_DeleteByNoteID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileNoteLinkRepository_DeleteByNoteID_Call) RunAndReturn(run func(context.Context, int) error) *MockFileNoteLinkRepository_DeleteByNoteID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Set provides a mock function with given fields: ctx, noteID, fileIDs
+func (_m *MockFileNoteLinkRepository) Set(ctx context.Context, noteID int, fileIDs []int) error {
+	ret := _m.Called(ctx, noteID, fileIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Set")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) error); ok {
+		r0 = rf(ctx, noteID, fileIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileNoteLinkRepository_Set_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Set'
+type MockFileNoteLinkRepository_Set_Call struct {
+	*mock.Call
+}
+
+// Set is a helper method to define mock.On call
+//   - ctx context.Context
+//   - noteID int
+//   - fileIDs []int
+func (_e *MockFileNoteLinkRepository_Expecter) Set(ctx interface{}, noteID interface{}, fileIDs interface{}) *MockFileNoteLinkRepository_Set_Call {
+	return &MockFileNoteLinkRepository_Set_Call{Call: _e.mock.On("Set", ctx, noteID, fileIDs)}
+}
+
+func (_c *MockFileNoteLinkRepository_Set_Call) Run(run func(ctx context.Context, noteID int, fileIDs []int)) *MockFileNoteLinkRepository_Set_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].([]int))
+	})
+	return _c
+}
+
+func (_c *MockFileNoteLinkRepository_Set_Call) Return(_a0 error) *MockFileNoteLinkRepository_Set_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileNoteLinkRepository_Set_Call) RunAndReturn(run func(context.Context, int, []int) error) *MockFileNoteLinkRepository_Set_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function with given fields: ctx, noteID, fileIDs
+func (_m *MockFileNoteLinkRepository) Upsert(ctx context.Context, noteID int, fileIDs []int) error {
+	ret := _m.Called(ctx, noteID, fileIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) error); ok {
+		r0 = rf(ctx, noteID, fileIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileNoteLinkRepository_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type MockFileNoteLinkRepository_Upsert_Call struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - noteID int
+//   - fileIDs []int
+func (_e *MockFileNoteLinkRepository_Expecter) Upsert(ctx interface{}, noteID interface{}, fileIDs interface{}) *MockFileNoteLinkRepository_Upsert_Call {
+	return &MockFileNoteLinkRepository_Upsert_Call{Call: _e.mock.On("Upsert", ctx, noteID, fileIDs)}
+}
+
+func (_c *MockFileNoteLinkRepository_Upsert_Call) Run(run func(ctx context.Context, noteID int, fileIDs []int)) *MockFileNoteLinkRepository_Upsert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].([]int))
+	})
+	return _c
+}
+
+func (_c *MockFileNoteLinkRepository_Upsert_Call) Return(_a0 error) *MockFileNoteLinkRepository_Upsert_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileNoteLinkRepository_Upsert_Call) RunAndReturn(run func(context.Context, int, []int) error) *MockFileNoteLinkRepository_Upsert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockFileNoteLinkRepository creates a new instance of MockFileNoteLinkRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockFileNoteLinkRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockFileNoteLinkRepository {
+	mock := &MockFileNoteLinkRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
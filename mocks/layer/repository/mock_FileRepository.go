@@ -0,0 +1,375 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockFileRepository is an autogenerated mock type for the FileRepository type
+type MockFileRepository struct {
+	mock.Mock
+}
+
+type MockFileRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockFileRepository) EXPECT() *MockFileRepository_Expecter {
+	return &MockFileRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, in
+func (_m *MockFileRepository) Create(ctx context.Context, in *entity.File) (*entity.File, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.File
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.File) (*entity.File, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.File) *entity.File); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.File)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.File) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockFileRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockFileRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.File
+func (_e *MockFileRepository_Expecter) Create(ctx interface{}, in interface{}) *MockFileRepository_Create_Call {
+	return &MockFileRepository_Create_Call{Call: _e.mock.On("Create", ctx, in)}
+}
+
+func (_c *MockFileRepository_Create_Call) Run(run func(ctx context.Context, in *entity.File)) *MockFileRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.File))
+	})
+	return _c
+}
+
+func (_c *MockFileRepository_Create_Call) Return(_a0 *entity.File, _a1 error) *MockFileRepository_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockFileRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.File) (*entity.File, error)) *MockFileRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function with given fields: ctx, fileID
+func (_m *MockFileRepository) DeleteByID(ctx context.Context, fileID int) error {
+	ret := _m.Called(ctx, fileID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileRepository_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockFileRepository_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fileID int
+func (_e *MockFileRepository_Expecter) DeleteByID(ctx interface{}, fileID interface{}) *MockFileRepository_DeleteByID_Call {
+	return &MockFileRepository_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, fileID)}
+}
+
+func (_c *MockFileRepository_DeleteByID_Call) Run(run func(ctx context.Context, fileID int)) *MockFileRepository_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockFileRepository_DeleteByID_Call) Return(_a0 error) *MockFileRepository_DeleteByID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileRepository_DeleteByID_Call) RunAndReturn(run func(context.Context, int) error) *MockFileRepository_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllFilesSize provides a mock function with given fields: ctx
+func (_m *MockFileRepository) GetAllFilesSize(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllFilesSize")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockFileRepository_GetAllFilesSize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllFilesSize'
+type MockFileRepository_GetAllFilesSize_Call struct {
+	*mock.Call
+}
+
+// GetAllFilesSize is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockFileRepository_Expecter) GetAllFilesSize(ctx interface{}) *MockFileRepository_GetAllFilesSize_Call {
+	return &MockFileRepository_GetAllFilesSize_Call{Call: _e.mock.On("GetAllFilesSize", ctx)}
+}
+
+func (_c *MockFileRepository_GetAllFilesSize_Call) Run(run func(ctx context.Context)) *MockFileRepository_GetAllFilesSize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockFileRepository_GetAllFilesSize_Call) Return(_a0 int64, _a1 error) *MockFileRepository_GetAllFilesSize_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockFileRepository_GetAllFilesSize_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockFileRepository_GetAllFilesSize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function with given fields: ctx, hash
+func (_m *MockFileRepository) GetByHash(ctx context.Context, hash string) (*entity.File, error) {
+	ret := _m.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *entity.File
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.File, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.File); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.File)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockFileRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockFileRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockFileRepository_Expecter) GetByHash(ctx interface{}, hash interface{}) *MockFileRepository_GetByHash_Call {
+	return &MockFileRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, hash)}
+}
+
+func (_c *MockFileRepository_GetByHash_Call) Run(run func(ctx context.Context, hash string)) *MockFileRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockFileRepository_GetByHash_Call) Return(_a0 *entity.File, _a1 error) *MockFileRepository_GetByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockFileRepository_GetByHash_Call) RunAndReturn(run func(context.Context, string) (*entity.File, error)) *MockFileRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByID provides a mock function with given fields: ctx, fileID
+func (_m *MockFileRepository) GetByID(ctx context.Context, fileID int) (*entity.File, error) {
+	ret := _m.Called(ctx, fileID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *entity.File
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.File, error)); ok {
+		return rf(ctx, fileID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.File); ok {
+		r0 = rf(ctx, fileID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.File)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, fileID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockFileRepository_GetByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByID'
+type MockFileRepository_GetByID_Call struct {
+	*mock.Call
+}
+
+// GetByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fileID int
+func (_e *MockFileRepository_Expecter) GetByID(ctx interface{}, fileID interface{}) *MockFileRepository_GetByID_Call {
+	return &MockFileRepository_GetByID_Call{Call: _e.mock.On("GetByID", ctx, fileID)}
+}
+
+func (_c *MockFileRepository_GetByID_Call) Run(run func(ctx context.Context, fileID int)) *MockFileRepository_GetByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockFileRepository_GetByID_Call) Return(_a0 *entity.File, _a1 error) *MockFileRepository_GetByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockFileRepository_GetByID_Call) RunAndReturn(run func(context.Context, int) (*entity.File, error)) *MockFileRepository_GetByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUnusedFileIDs provides a mock function with given fields: ctx
+func (_m *MockFileRepository) GetUnusedFileIDs(ctx context.Context) (<-chan int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnusedFileIDs")
+	}
+
+	var r0 <-chan int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (<-chan int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) <-chan int); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockFileRepository_GetUnusedFileIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUnusedFileIDs'
+type MockFileRepository_GetUnusedFileIDs_Call struct {
+	*mock.Call
+}
+
+// GetUnusedFileIDs is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockFileRepository_Expecter) GetUnusedFileIDs(ctx interface{}) *MockFileRepository_GetUnusedFileIDs_Call {
+	return &MockFileRepository_GetUnusedFileIDs_Call{Call: _e.mock.On("GetUnusedFileIDs", ctx)}
+}
+
+func (_c *MockFileRepository_GetUnusedFileIDs_Call) Run(run func(ctx context.Context)) *MockFileRepository_GetUnusedFileIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockFileRepository_GetUnusedFileIDs_Call) Return(_a0 <-chan int, _a1 error) *MockFileRepository_GetUnusedFileIDs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockFileRepository_GetUnusedFileIDs_Call) RunAndReturn(run func(context.Context) (<-chan int, error)) *MockFileRepository_GetUnusedFileIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockFileRepository creates a new instance of MockFileRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockFileRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockFileRepository {
+	mock := &MockFileRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,395 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	dto "assistant-go/internal/layer/dto"
+	context "context"
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockFileStorageRepository is an autogenerated mock type for the FileStorageRepository type
+type MockFileStorageRepository struct {
+	mock.Mock
+}
+
+type MockFileStorageRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockFileStorageRepository) EXPECT() *MockFileStorageRepository_Expecter {
+	return &MockFileStorageRepository_Expecter{mock: &_m.Mock}
+}
+
+// Copy provides a mock function with given fields: ctx, srcPath, dstPath
+func (_m *MockFileStorageRepository) Copy(ctx context.Context, srcPath string, dstPath string) error {
+	ret := _m.Called(ctx, srcPath, dstPath)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Copy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, srcPath, dstPath)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileStorageRepository_Copy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Copy'
+type MockFileStorageRepository_Copy_Call struct {
+	*mock.Call
+}
+
+// Copy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - srcPath string
+//   - dstPath string
+func (_e *MockFileStorageRepository_Expecter) Copy(ctx interface{}, srcPath interface{}, dstPath interface{}) *MockFileStorageRepository_Copy_Call {
+	return &MockFileStorageRepository_Copy_Call{Call: _e.mock.On("Copy", ctx, srcPath, dstPath)}
+}
+
+func (_c *MockFileStorageRepository_Copy_Call) Run(run func(ctx context.Context, srcPath string, dstPath string)) *MockFileStorageRepository_Copy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockFileStorageRepository_Copy_Call) Return(_a0 error) *MockFileStorageRepository_Copy_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileStorageRepository_Copy_Call) RunAndReturn(run func(context.Context, string, string) error) *MockFileStorageRepository_Copy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, filePath
+func (_m *MockFileStorageRepository) Delete(ctx context.Context, filePath string) error {
+	ret := _m.Called(ctx, filePath)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, filePath)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileStorageRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockFileStorageRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filePath string
+func (_e *MockFileStorageRepository_Expecter) Delete(ctx interface{}, filePath interface{}) *MockFileStorageRepository_Delete_Call {
+	return &MockFileStorageRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, filePath)}
+}
+
+func (_c *MockFileStorageRepository_Delete_Call) Run(run func(ctx context.Context, filePath string)) *MockFileStorageRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockFileStorageRepository_Delete_Call) Return(_a0 error) *MockFileStorageRepository_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileStorageRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *MockFileStorageRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAll provides a mock function with given fields: ctx, filePaths
+func (_m *MockFileStorageRepository) DeleteAll(ctx context.Context, filePaths []string) error {
+	ret := _m.Called(ctx, filePaths)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAll")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, filePaths)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileStorageRepository_DeleteAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAll'
+type MockFileStorageRepository_DeleteAll_Call struct {
+	*mock.Call
+}
+
+// DeleteAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filePaths []string
+func (_e *MockFileStorageRepository_Expecter) DeleteAll(ctx interface{}, filePaths interface{}) *MockFileStorageRepository_DeleteAll_Call {
+	return &MockFileStorageRepository_DeleteAll_Call{Call: _e.mock.On("DeleteAll", ctx, filePaths)}
+}
+
+func (_c *MockFileStorageRepository_DeleteAll_Call) Run(run func(ctx context.Context, filePaths []string)) *MockFileStorageRepository_DeleteAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockFileStorageRepository_DeleteAll_Call) Return(_a0 error) *MockFileStorageRepository_DeleteAll_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileStorageRepository_DeleteAll_Call) RunAndReturn(run func(context.Context, []string) error) *MockFileStorageRepository_DeleteAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFile provides a mock function with given fields: ctx, filePath
+func (_m *MockFileStorageRepository) GetFile(ctx context.Context, filePath string) (io.Reader, error) {
+	ret := _m.Called(ctx, filePath)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFile")
+	}
+
+	var r0 io.Reader
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (io.Reader, error)); ok {
+		return rf(ctx, filePath)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) io.Reader); ok {
+		r0 = rf(ctx, filePath)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.Reader)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, filePath)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockFileStorageRepository_GetFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFile'
+type MockFileStorageRepository_GetFile_Call struct {
+	*mock.Call
+}
+
+// GetFile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filePath string
+func (_e *MockFileStorageRepository_Expecter) GetFile(ctx interface{}, filePath interface{}) *MockFileStorageRepository_GetFile_Call {
+	return &MockFileStorageRepository_GetFile_Call{Call: _e.mock.On("GetFile", ctx, filePath)}
+}
+
+func (_c *MockFileStorageRepository_GetFile_Call) Run(run func(ctx context.Context, filePath string)) *MockFileStorageRepository_GetFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockFileStorageRepository_GetFile_Call) Return(_a0 io.Reader, _a1 error) *MockFileStorageRepository_GetFile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockFileStorageRepository_GetFile_Call) RunAndReturn(run func(context.Context, string) (io.Reader, error)) *MockFileStorageRepository_GetFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFileRange provides a mock function with given fields: ctx, filePath, offset, length
+func (_m *MockFileStorageRepository) GetFileRange(ctx context.Context, filePath string, offset int64, length int64) (io.Reader, error) {
+	ret := _m.Called(ctx, filePath, offset, length)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFileRange")
+	}
+
+	var r0 io.Reader
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64) (io.Reader, error)); ok {
+		return rf(ctx, filePath, offset, length)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64) io.Reader); ok {
+		r0 = rf(ctx, filePath, offset, length)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.Reader)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int64) error); ok {
+		r1 = rf(ctx, filePath, offset, length)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockFileStorageRepository_GetFileRange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFileRange'
+type MockFileStorageRepository_GetFileRange_Call struct {
+	*mock.Call
+}
+
+// GetFileRange is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filePath string
+//   - offset int64
+//   - length int64
+func (_e *MockFileStorageRepository_Expecter) GetFileRange(ctx interface{}, filePath interface{}, offset interface{}, length interface{}) *MockFileStorageRepository_GetFileRange_Call {
+	return &MockFileStorageRepository_GetFileRange_Call{Call: _e.mock.On("GetFileRange", ctx, filePath, offset, length)}
+}
+
+func (_c *MockFileStorageRepository_GetFileRange_Call) Run(run func(ctx context.Context, filePath string, offset int64, length int64)) *MockFileStorageRepository_GetFileRange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *MockFileStorageRepository_GetFileRange_Call) Return(_a0 io.Reader, _a1 error) *MockFileStorageRepository_GetFileRange_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockFileStorageRepository_GetFileRange_Call) RunAndReturn(run func(context.Context, string, int64, int64) (io.Reader, error)) *MockFileStorageRepository_GetFileRange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, prefix, fn
+func (_m *MockFileStorageRepository) List(ctx context.Context, prefix string, fn func(*dto.StorageObjectInfo) error) error {
+	ret := _m.Called(ctx, prefix, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, func(*dto.StorageObjectInfo) error) error); ok {
+		r0 = rf(ctx, prefix, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileStorageRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockFileStorageRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+//   - fn func(*dto.StorageObjectInfo) error
+func (_e *MockFileStorageRepository_Expecter) List(ctx interface{}, prefix interface{}, fn interface{}) *MockFileStorageRepository_List_Call {
+	return &MockFileStorageRepository_List_Call{Call: _e.mock.On("List", ctx, prefix, fn)}
+}
+
+func (_c *MockFileStorageRepository_List_Call) Run(run func(ctx context.Context, prefix string, fn func(*dto.StorageObjectInfo) error)) *MockFileStorageRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(func(*dto.StorageObjectInfo) error))
+	})
+	return _c
+}
+
+func (_c *MockFileStorageRepository_List_Call) Return(_a0 error) *MockFileStorageRepository_List_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileStorageRepository_List_Call) RunAndReturn(run func(context.Context, string, func(*dto.StorageObjectInfo) error) error) *MockFileStorageRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: ctx, in
+func (_m *MockFileStorageRepository) Save(ctx context.Context, in *dto.SaveFile) error {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.SaveFile) error); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockFileStorageRepository_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type MockFileStorageRepository_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *dto.SaveFile
+func (_e *MockFileStorageRepository_Expecter) Save(ctx interface{}, in interface{}) *MockFileStorageRepository_Save_Call {
+	return &MockFileStorageRepository_Save_Call{Call: _e.mock.On("Save", ctx, in)}
+}
+
+func (_c *MockFileStorageRepository_Save_Call) Run(run func(ctx context.Context, in *dto.SaveFile)) *MockFileStorageRepository_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*dto.SaveFile))
+	})
+	return _c
+}
+
+func (_c *MockFileStorageRepository_Save_Call) Return(_a0 error) *MockFileStorageRepository_Save_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockFileStorageRepository_Save_Call) RunAndReturn(run func(context.Context, *dto.SaveFile) error) *MockFileStorageRepository_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockFileStorageRepository creates a new instance of MockFileStorageRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockFileStorageRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockFileStorageRepository {
+	mock := &MockFileStorageRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -22,6 +22,64 @@ func (_m *MockNoteRepository) EXPECT() *MockNoteRepository_Expecter {
 	return &MockNoteRepository_Expecter{mock: &_m.Mock}
 }
 
+// BelongsToUser provides a mock function with given fields: ctx, noteID, userID
+func (_m *MockNoteRepository) BelongsToUser(ctx context.Context, noteID int, userID int) (bool, error) {
+	ret := _m.Called(ctx, noteID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BelongsToUser")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (bool, error)); ok {
+		return rf(ctx, noteID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) bool); ok {
+		r0 = rf(ctx, noteID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, noteID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockNoteRepository_BelongsToUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BelongsToUser'
+type MockNoteRepository_BelongsToUser_Call struct {
+	*mock.Call
+}
+
+// BelongsToUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - noteID int
+//   - userID int
+func (_e *MockNoteRepository_Expecter) BelongsToUser(ctx interface{}, noteID interface{}, userID interface{}) *MockNoteRepository_BelongsToUser_Call {
+	return &MockNoteRepository_BelongsToUser_Call{Call: _e.mock.On("BelongsToUser", ctx, noteID, userID)}
+}
+
+func (_c *MockNoteRepository_BelongsToUser_Call) Run(run func(ctx context.Context, noteID int, userID int)) *MockNoteRepository_BelongsToUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockNoteRepository_BelongsToUser_Call) Return(_a0 bool, _a1 error) *MockNoteRepository_BelongsToUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockNoteRepository_BelongsToUser_Call) RunAndReturn(run func(context.Context, int, int) (bool, error)) *MockNoteRepository_BelongsToUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CheckExistsByCategoryIDs provides a mock function with given fields: ctx, catIDs
 func (_m *MockNoteRepository) CheckExistsByCategoryIDs(ctx context.Context, catIDs []int) (bool, error) {
 	ret := _m.Called(ctx, catIDs)
@@ -244,24 +302,83 @@ func (_c *MockNoteRepository_GetById_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// GetByShareHash provides a mock function with given fields: ctx, hash
+func (_m *MockNoteRepository) GetByShareHash(ctx context.Context, hash string) (*entity.Note, error) {
+	ret := _m.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByShareHash")
+	}
+
+	var r0 *entity.Note
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Note, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Note); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Note)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockNoteRepository_GetByShareHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByShareHash'
+type MockNoteRepository_GetByShareHash_Call struct {
+	*mock.Call
+}
+
+// GetByShareHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockNoteRepository_Expecter) GetByShareHash(ctx interface{}, hash interface{}) *MockNoteRepository_GetByShareHash_Call {
+	return &MockNoteRepository_GetByShareHash_Call{Call: _e.mock.On("GetByShareHash", ctx, hash)}
+}
+
+func (_c *MockNoteRepository_GetByShareHash_Call) Run(run func(ctx context.Context, hash string)) *MockNoteRepository_GetByShareHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockNoteRepository_GetByShareHash_Call) Return(_a0 *entity.Note, _a1 error) *MockNoteRepository_GetByShareHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockNoteRepository_GetByShareHash_Call) RunAndReturn(run func(context.Context, string) (*entity.Note, error)) *MockNoteRepository_GetByShareHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetMinimalByCategoryIds provides a mock function with given fields: ctx, catIds
-func (_m *MockNoteRepository) GetMinimalByCategoryIds(ctx context.Context, catIds []int) ([]*entity.Note, error) {
+func (_m *MockNoteRepository) GetMinimalByCategoryIds(ctx context.Context, catIds []int) ([]*entity.NoteMinimal, error) {
 	ret := _m.Called(ctx, catIds)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetMinimalByCategoryIds")
 	}
 
-	var r0 []*entity.Note
+	var r0 []*entity.NoteMinimal
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, []int) ([]*entity.Note, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, []int) ([]*entity.NoteMinimal, error)); ok {
 		return rf(ctx, catIds)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []int) []*entity.Note); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, []int) []*entity.NoteMinimal); ok {
 		r0 = rf(ctx, catIds)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*entity.Note)
+			r0 = ret.Get(0).([]*entity.NoteMinimal)
 		}
 	}
 
@@ -293,12 +410,72 @@ func (_c *MockNoteRepository_GetMinimalByCategoryIds_Call) Run(run func(ctx cont
 	return _c
 }
 
-func (_c *MockNoteRepository_GetMinimalByCategoryIds_Call) Return(_a0 []*entity.Note, _a1 error) *MockNoteRepository_GetMinimalByCategoryIds_Call {
+func (_c *MockNoteRepository_GetMinimalByCategoryIds_Call) Return(_a0 []*entity.NoteMinimal, _a1 error) *MockNoteRepository_GetMinimalByCategoryIds_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockNoteRepository_GetMinimalByCategoryIds_Call) RunAndReturn(run func(context.Context, []int) ([]*entity.NoteMinimal, error)) *MockNoteRepository_GetMinimalByCategoryIds_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetStale provides a mock function with given fields: ctx, afterID, limit
+func (_m *MockNoteRepository) GetStale(ctx context.Context, afterID int, limit int) ([]*entity.NoteWithOwner, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStale")
+	}
+
+	var r0 []*entity.NoteWithOwner
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*entity.NoteWithOwner, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*entity.NoteWithOwner); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.NoteWithOwner)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockNoteRepository_GetStale_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetStale'
+type MockNoteRepository_GetStale_Call struct {
+	*mock.Call
+}
+
+// GetStale is a helper method to define mock.On call
+//   - ctx context.Context
+//   - afterID int
+//   - limit int
+func (_e *MockNoteRepository_Expecter) GetStale(ctx interface{}, afterID interface{}, limit interface{}) *MockNoteRepository_GetStale_Call {
+	return &MockNoteRepository_GetStale_Call{Call: _e.mock.On("GetStale", ctx, afterID, limit)}
+}
+
+func (_c *MockNoteRepository_GetStale_Call) Run(run func(ctx context.Context, afterID int, limit int)) *MockNoteRepository_GetStale_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockNoteRepository_GetStale_Call) Return(_a0 []*entity.NoteWithOwner, _a1 error) *MockNoteRepository_GetStale_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockNoteRepository_GetMinimalByCategoryIds_Call) RunAndReturn(run func(context.Context, []int) ([]*entity.Note, error)) *MockNoteRepository_GetMinimalByCategoryIds_Call {
+func (_c *MockNoteRepository_GetStale_Call) RunAndReturn(run func(context.Context, int, int) ([]*entity.NoteWithOwner, error)) *MockNoteRepository_GetStale_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -350,6 +527,64 @@ func (_c *MockNoteRepository_Pin_Call) RunAndReturn(run func(context.Context, in
 	return _c
 }
 
+// ReplaceEncrypted provides a mock function with given fields: ctx, in, previousDataKeyID
+func (_m *MockNoteRepository) ReplaceEncrypted(ctx context.Context, in *entity.Note, previousDataKeyID *int) (bool, error) {
+	ret := _m.Called(ctx, in, previousDataKeyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceEncrypted")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Note, *int) (bool, error)); ok {
+		return rf(ctx, in, previousDataKeyID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Note, *int) bool); ok {
+		r0 = rf(ctx, in, previousDataKeyID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.Note, *int) error); ok {
+		r1 = rf(ctx, in, previousDataKeyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockNoteRepository_ReplaceEncrypted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplaceEncrypted'
+type MockNoteRepository_ReplaceEncrypted_Call struct {
+	*mock.Call
+}
+
+// ReplaceEncrypted is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.Note
+//   - previousDataKeyID *int
+func (_e *MockNoteRepository_Expecter) ReplaceEncrypted(ctx interface{}, in interface{}, previousDataKeyID interface{}) *MockNoteRepository_ReplaceEncrypted_Call {
+	return &MockNoteRepository_ReplaceEncrypted_Call{Call: _e.mock.On("ReplaceEncrypted", ctx, in, previousDataKeyID)}
+}
+
+func (_c *MockNoteRepository_ReplaceEncrypted_Call) Run(run func(ctx context.Context, in *entity.Note, previousDataKeyID *int)) *MockNoteRepository_ReplaceEncrypted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.Note), args[2].(*int))
+	})
+	return _c
+}
+
+func (_c *MockNoteRepository_ReplaceEncrypted_Call) Return(_a0 bool, _a1 error) *MockNoteRepository_ReplaceEncrypted_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockNoteRepository_ReplaceEncrypted_Call) RunAndReturn(run func(context.Context, *entity.Note, *int) (bool, error)) *MockNoteRepository_ReplaceEncrypted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UnPin provides a mock function with given fields: ctx, noteID
 func (_m *MockNoteRepository) UnPin(ctx context.Context, noteID int) error {
 	ret := _m.Called(ctx, noteID)
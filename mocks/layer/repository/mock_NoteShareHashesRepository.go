@@ -0,0 +1,316 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockNoteShareHashesRepository is an autogenerated mock type for the NoteShareHashesRepository type
+type MockNoteShareHashesRepository struct {
+	mock.Mock
+}
+
+type MockNoteShareHashesRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockNoteShareHashesRepository) EXPECT() *MockNoteShareHashesRepository_Expecter {
+	return &MockNoteShareHashesRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, in
+func (_m *MockNoteShareHashesRepository) Create(ctx context.Context, in entity.NoteShare) (*entity.NoteShare, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.NoteShare
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.NoteShare) (*entity.NoteShare, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.NoteShare) *entity.NoteShare); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.NoteShare)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.NoteShare) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockNoteShareHashesRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockNoteShareHashesRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in entity.NoteShare
+func (_e *MockNoteShareHashesRepository_Expecter) Create(ctx interface{}, in interface{}) *MockNoteShareHashesRepository_Create_Call {
+	return &MockNoteShareHashesRepository_Create_Call{Call: _e.mock.On("Create", ctx, in)}
+}
+
+func (_c *MockNoteShareHashesRepository_Create_Call) Run(run func(ctx context.Context, in entity.NoteShare)) *MockNoteShareHashesRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(entity.NoteShare))
+	})
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_Create_Call) Return(_a0 *entity.NoteShare, _a1 error) *MockNoteShareHashesRepository_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_Create_Call) RunAndReturn(run func(context.Context, entity.NoteShare) (*entity.NoteShare, error)) *MockNoteShareHashesRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByNoteID provides a mock function with given fields: ctx, noteID
+func (_m *MockNoteShareHashesRepository) DeleteByNoteID(ctx context.Context, noteID int) error {
+	ret := _m.Called(ctx, noteID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByNoteID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, noteID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockNoteShareHashesRepository_DeleteByNoteID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByNoteID'
+type MockNoteShareHashesRepository_DeleteByNoteID_Call struct {
+	*mock.Call
+}
+
+// DeleteByNoteID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - noteID int
+func (_e *MockNoteShareHashesRepository_Expecter) DeleteByNoteID(ctx interface{}, noteID interface{}) *MockNoteShareHashesRepository_DeleteByNoteID_Call {
+	return &MockNoteShareHashesRepository_DeleteByNoteID_Call{Call: _e.mock.On("DeleteByNoteID", ctx, noteID)}
+}
+
+func (_c *MockNoteShareHashesRepository_DeleteByNoteID_Call) Run(run func(ctx context.Context, noteID int)) *MockNoteShareHashesRepository_DeleteByNoteID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_DeleteByNoteID_Call) Return(_a0 error) *MockNoteShareHashesRepository_DeleteByNoteID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_DeleteByNoteID_Call) RunAndReturn(run func(context.Context, int) error) *MockNoteShareHashesRepository_DeleteByNoteID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExistsByHash provides a mock function with given fields: ctx, hash
+func (_m *MockNoteShareHashesRepository) ExistsByHash(ctx context.Context, hash string) (bool, error) {
+	ret := _m.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExistsByHash")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockNoteShareHashesRepository_ExistsByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExistsByHash'
+type MockNoteShareHashesRepository_ExistsByHash_Call struct {
+	*mock.Call
+}
+
+// ExistsByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockNoteShareHashesRepository_Expecter) ExistsByHash(ctx interface{}, hash interface{}) *MockNoteShareHashesRepository_ExistsByHash_Call {
+	return &MockNoteShareHashesRepository_ExistsByHash_Call{Call: _e.mock.On("ExistsByHash", ctx, hash)}
+}
+
+func (_c *MockNoteShareHashesRepository_ExistsByHash_Call) Run(run func(ctx context.Context, hash string)) *MockNoteShareHashesRepository_ExistsByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_ExistsByHash_Call) Return(_a0 bool, _a1 error) *MockNoteShareHashesRepository_ExistsByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_ExistsByHash_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockNoteShareHashesRepository_ExistsByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExistsByNoteID provides a mock function with given fields: ctx, noteID
+func (_m *MockNoteShareHashesRepository) ExistsByNoteID(ctx context.Context, noteID int) (bool, error) {
+	ret := _m.Called(ctx, noteID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExistsByNoteID")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (bool, error)); ok {
+		return rf(ctx, noteID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) bool); ok {
+		r0 = rf(ctx, noteID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, noteID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockNoteShareHashesRepository_ExistsByNoteID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExistsByNoteID'
+type MockNoteShareHashesRepository_ExistsByNoteID_Call struct {
+	*mock.Call
+}
+
+// ExistsByNoteID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - noteID int
+func (_e *MockNoteShareHashesRepository_Expecter) ExistsByNoteID(ctx interface{}, noteID interface{}) *MockNoteShareHashesRepository_ExistsByNoteID_Call {
+	return &MockNoteShareHashesRepository_ExistsByNoteID_Call{Call: _e.mock.On("ExistsByNoteID", ctx, noteID)}
+}
+
+func (_c *MockNoteShareHashesRepository_ExistsByNoteID_Call) Run(run func(ctx context.Context, noteID int)) *MockNoteShareHashesRepository_ExistsByNoteID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_ExistsByNoteID_Call) Return(_a0 bool, _a1 error) *MockNoteShareHashesRepository_ExistsByNoteID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_ExistsByNoteID_Call) RunAndReturn(run func(context.Context, int) (bool, error)) *MockNoteShareHashesRepository_ExistsByNoteID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByNoteID provides a mock function with given fields: ctx, noteID
+func (_m *MockNoteShareHashesRepository) GetByNoteID(ctx context.Context, noteID int) (*entity.NoteShare, error) {
+	ret := _m.Called(ctx, noteID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByNoteID")
+	}
+
+	var r0 *entity.NoteShare
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.NoteShare, error)); ok {
+		return rf(ctx, noteID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.NoteShare); ok {
+		r0 = rf(ctx, noteID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.NoteShare)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, noteID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockNoteShareHashesRepository_GetByNoteID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByNoteID'
+type MockNoteShareHashesRepository_GetByNoteID_Call struct {
+	*mock.Call
+}
+
+// GetByNoteID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - noteID int
+func (_e *MockNoteShareHashesRepository_Expecter) GetByNoteID(ctx interface{}, noteID interface{}) *MockNoteShareHashesRepository_GetByNoteID_Call {
+	return &MockNoteShareHashesRepository_GetByNoteID_Call{Call: _e.mock.On("GetByNoteID", ctx, noteID)}
+}
+
+func (_c *MockNoteShareHashesRepository_GetByNoteID_Call) Run(run func(ctx context.Context, noteID int)) *MockNoteShareHashesRepository_GetByNoteID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_GetByNoteID_Call) Return(_a0 *entity.NoteShare, _a1 error) *MockNoteShareHashesRepository_GetByNoteID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockNoteShareHashesRepository_GetByNoteID_Call) RunAndReturn(run func(context.Context, int) (*entity.NoteShare, error)) *MockNoteShareHashesRepository_GetByNoteID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockNoteShareHashesRepository creates a new instance of MockNoteShareHashesRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockNoteShareHashesRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockNoteShareHashesRepository {
+	mock := &MockNoteShareHashesRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,208 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockPendingObjectRepository is an autogenerated mock type for the PendingObjectRepository type
+type MockPendingObjectRepository struct {
+	mock.Mock
+}
+
+type MockPendingObjectRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPendingObjectRepository) EXPECT() *MockPendingObjectRepository_Expecter {
+	return &MockPendingObjectRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, storageKey, createdAt
+func (_m *MockPendingObjectRepository) Create(ctx context.Context, storageKey string, createdAt time.Time) error {
+	ret := _m.Called(ctx, storageKey, createdAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, storageKey, createdAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPendingObjectRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockPendingObjectRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - storageKey string
+//   - createdAt time.Time
+func (_e *MockPendingObjectRepository_Expecter) Create(ctx interface{}, storageKey interface{}, createdAt interface{}) *MockPendingObjectRepository_Create_Call {
+	return &MockPendingObjectRepository_Create_Call{Call: _e.mock.On("Create", ctx, storageKey, createdAt)}
+}
+
+func (_c *MockPendingObjectRepository_Create_Call) Run(run func(ctx context.Context, storageKey string, createdAt time.Time)) *MockPendingObjectRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockPendingObjectRepository_Create_Call) Return(_a0 error) *MockPendingObjectRepository_Create_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPendingObjectRepository_Create_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *MockPendingObjectRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, storageKeys
+func (_m *MockPendingObjectRepository) Delete(ctx context.Context, storageKeys ...string) error {
+	_va := make([]interface{}, len(storageKeys))
+	for _i := range storageKeys {
+		_va[_i] = storageKeys[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) error); ok {
+		r0 = rf(ctx, storageKeys...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPendingObjectRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockPendingObjectRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - storageKeys ...string
+func (_e *MockPendingObjectRepository_Expecter) Delete(ctx interface{}, storageKeys ...interface{}) *MockPendingObjectRepository_Delete_Call {
+	return &MockPendingObjectRepository_Delete_Call{Call: _e.mock.On("Delete",
+		append([]interface{}{ctx}, storageKeys...)...)}
+}
+
+func (_c *MockPendingObjectRepository_Delete_Call) Run(run func(ctx context.Context, storageKeys ...string)) *MockPendingObjectRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockPendingObjectRepository_Delete_Call) Return(_a0 error) *MockPendingObjectRepository_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPendingObjectRepository_Delete_Call) RunAndReturn(run func(context.Context, ...string) error) *MockPendingObjectRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOlder provides a mock function with given fields: ctx, before, limit
+func (_m *MockPendingObjectRepository) GetOlder(ctx context.Context, before time.Time, limit int) ([]*entity.PendingObject, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOlder")
+	}
+
+	var r0 []*entity.PendingObject
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]*entity.PendingObject, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []*entity.PendingObject); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.PendingObject)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPendingObjectRepository_GetOlder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOlder'
+type MockPendingObjectRepository_GetOlder_Call struct {
+	*mock.Call
+}
+
+// GetOlder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+//   - limit int
+func (_e *MockPendingObjectRepository_Expecter) GetOlder(ctx interface{}, before interface{}, limit interface{}) *MockPendingObjectRepository_GetOlder_Call {
+	return &MockPendingObjectRepository_GetOlder_Call{Call: _e.mock.On("GetOlder", ctx, before, limit)}
+}
+
+func (_c *MockPendingObjectRepository_GetOlder_Call) Run(run func(ctx context.Context, before time.Time, limit int)) *MockPendingObjectRepository_GetOlder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockPendingObjectRepository_GetOlder_Call) Return(_a0 []*entity.PendingObject, _a1 error) *MockPendingObjectRepository_GetOlder_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPendingObjectRepository_GetOlder_Call) RunAndReturn(run func(context.Context, time.Time, int) ([]*entity.PendingObject, error)) *MockPendingObjectRepository_GetOlder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPendingObjectRepository creates a new instance of MockPendingObjectRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPendingObjectRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPendingObjectRepository {
+	mock := &MockPendingObjectRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
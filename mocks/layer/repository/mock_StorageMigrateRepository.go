@@ -0,0 +1,317 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockStorageMigrateRepository is an autogenerated mock type for the StorageMigrateRepository type
+type MockStorageMigrateRepository struct {
+	mock.Mock
+}
+
+type MockStorageMigrateRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStorageMigrateRepository) EXPECT() *MockStorageMigrateRepository_Expecter {
+	return &MockStorageMigrateRepository_Expecter{mock: &_m.Mock}
+}
+
+// CountObjects provides a mock function with given fields: ctx, kind, afterID
+func (_m *MockStorageMigrateRepository) CountObjects(ctx context.Context, kind string, afterID int) (int, error) {
+	ret := _m.Called(ctx, kind, afterID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountObjects")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (int, error)); ok {
+		return rf(ctx, kind, afterID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) int); ok {
+		r0 = rf(ctx, kind, afterID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, kind, afterID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageMigrateRepository_CountObjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountObjects'
+type MockStorageMigrateRepository_CountObjects_Call struct {
+	*mock.Call
+}
+
+// CountObjects is a helper method to define mock.On call
+//   - ctx context.Context
+//   - kind string
+//   - afterID int
+func (_e *MockStorageMigrateRepository_Expecter) CountObjects(ctx interface{}, kind interface{}, afterID interface{}) *MockStorageMigrateRepository_CountObjects_Call {
+	return &MockStorageMigrateRepository_CountObjects_Call{Call: _e.mock.On("CountObjects", ctx, kind, afterID)}
+}
+
+func (_c *MockStorageMigrateRepository_CountObjects_Call) Run(run func(ctx context.Context, kind string, afterID int)) *MockStorageMigrateRepository_CountObjects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_CountObjects_Call) Return(_a0 int, _a1 error) *MockStorageMigrateRepository_CountObjects_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_CountObjects_Call) RunAndReturn(run func(context.Context, string, int) (int, error)) *MockStorageMigrateRepository_CountObjects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetObjects provides a mock function with given fields: ctx, kind, afterID, limit
+func (_m *MockStorageMigrateRepository) GetObjects(ctx context.Context, kind string, afterID int, limit int) ([]*entity.StorageObject, error) {
+	ret := _m.Called(ctx, kind, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetObjects")
+	}
+
+	var r0 []*entity.StorageObject
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]*entity.StorageObject, error)); ok {
+		return rf(ctx, kind, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []*entity.StorageObject); ok {
+		r0 = rf(ctx, kind, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.StorageObject)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, kind, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageMigrateRepository_GetObjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetObjects'
+type MockStorageMigrateRepository_GetObjects_Call struct {
+	*mock.Call
+}
+
+// GetObjects is a helper method to define mock.On call
+//   - ctx context.Context
+//   - kind string
+//   - afterID int
+//   - limit int
+func (_e *MockStorageMigrateRepository_Expecter) GetObjects(ctx interface{}, kind interface{}, afterID interface{}, limit interface{}) *MockStorageMigrateRepository_GetObjects_Call {
+	return &MockStorageMigrateRepository_GetObjects_Call{Call: _e.mock.On("GetObjects", ctx, kind, afterID, limit)}
+}
+
+func (_c *MockStorageMigrateRepository_GetObjects_Call) Run(run func(ctx context.Context, kind string, afterID int, limit int)) *MockStorageMigrateRepository_GetObjects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_GetObjects_Call) Return(_a0 []*entity.StorageObject, _a1 error) *MockStorageMigrateRepository_GetObjects_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_GetObjects_Call) RunAndReturn(run func(context.Context, string, int, int) ([]*entity.StorageObject, error)) *MockStorageMigrateRepository_GetObjects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProgress provides a mock function with given fields: ctx, source, target
+func (_m *MockStorageMigrateRepository) GetProgress(ctx context.Context, source string, target string) (map[string]int, error) {
+	ret := _m.Called(ctx, source, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProgress")
+	}
+
+	var r0 map[string]int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (map[string]int, error)); ok {
+		return rf(ctx, source, target)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) map[string]int); ok {
+		r0 = rf(ctx, source, target)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, source, target)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageMigrateRepository_GetProgress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProgress'
+type MockStorageMigrateRepository_GetProgress_Call struct {
+	*mock.Call
+}
+
+// GetProgress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - source string
+//   - target string
+func (_e *MockStorageMigrateRepository_Expecter) GetProgress(ctx interface{}, source interface{}, target interface{}) *MockStorageMigrateRepository_GetProgress_Call {
+	return &MockStorageMigrateRepository_GetProgress_Call{Call: _e.mock.On("GetProgress", ctx, source, target)}
+}
+
+func (_c *MockStorageMigrateRepository_GetProgress_Call) Run(run func(ctx context.Context, source string, target string)) *MockStorageMigrateRepository_GetProgress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_GetProgress_Call) Return(_a0 map[string]int, _a1 error) *MockStorageMigrateRepository_GetProgress_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_GetProgress_Call) RunAndReturn(run func(context.Context, string, string) (map[string]int, error)) *MockStorageMigrateRepository_GetProgress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetProgress provides a mock function with given fields: ctx, source, target
+func (_m *MockStorageMigrateRepository) ResetProgress(ctx context.Context, source string, target string) error {
+	ret := _m.Called(ctx, source, target)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetProgress")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, source, target)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorageMigrateRepository_ResetProgress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetProgress'
+type MockStorageMigrateRepository_ResetProgress_Call struct {
+	*mock.Call
+}
+
+// ResetProgress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - source string
+//   - target string
+func (_e *MockStorageMigrateRepository_Expecter) ResetProgress(ctx interface{}, source interface{}, target interface{}) *MockStorageMigrateRepository_ResetProgress_Call {
+	return &MockStorageMigrateRepository_ResetProgress_Call{Call: _e.mock.On("ResetProgress", ctx, source, target)}
+}
+
+func (_c *MockStorageMigrateRepository_ResetProgress_Call) Run(run func(ctx context.Context, source string, target string)) *MockStorageMigrateRepository_ResetProgress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_ResetProgress_Call) Return(_a0 error) *MockStorageMigrateRepository_ResetProgress_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_ResetProgress_Call) RunAndReturn(run func(context.Context, string, string) error) *MockStorageMigrateRepository_ResetProgress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveProgress provides a mock function with given fields: ctx, source, target, kind, lastID, at
+func (_m *MockStorageMigrateRepository) SaveProgress(ctx context.Context, source string, target string, kind string, lastID int, at time.Time) error {
+	ret := _m.Called(ctx, source, target, kind, lastID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveProgress")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int, time.Time) error); ok {
+		r0 = rf(ctx, source, target, kind, lastID, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorageMigrateRepository_SaveProgress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveProgress'
+type MockStorageMigrateRepository_SaveProgress_Call struct {
+	*mock.Call
+}
+
+// SaveProgress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - source string
+//   - target string
+//   - kind string
+//   - lastID int
+//   - at time.Time
+func (_e *MockStorageMigrateRepository_Expecter) SaveProgress(ctx interface{}, source interface{}, target interface{}, kind interface{}, lastID interface{}, at interface{}) *MockStorageMigrateRepository_SaveProgress_Call {
+	return &MockStorageMigrateRepository_SaveProgress_Call{Call: _e.mock.On("SaveProgress", ctx, source, target, kind, lastID, at)}
+}
+
+func (_c *MockStorageMigrateRepository_SaveProgress_Call) Run(run func(ctx context.Context, source string, target string, kind string, lastID int, at time.Time)) *MockStorageMigrateRepository_SaveProgress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(int), args[5].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_SaveProgress_Call) Return(_a0 error) *MockStorageMigrateRepository_SaveProgress_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorageMigrateRepository_SaveProgress_Call) RunAndReturn(run func(context.Context, string, string, string, int, time.Time) error) *MockStorageMigrateRepository_SaveProgress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStorageMigrateRepository creates a new instance of MockStorageMigrateRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStorageMigrateRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStorageMigrateRepository {
+	mock := &MockStorageMigrateRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
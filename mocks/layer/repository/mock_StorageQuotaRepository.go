@@ -0,0 +1,378 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockStorageQuotaRepository is an autogenerated mock type for the StorageQuotaRepository type
+type MockStorageQuotaRepository struct {
+	mock.Mock
+}
+
+type MockStorageQuotaRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStorageQuotaRepository) EXPECT() *MockStorageQuotaRepository_Expecter {
+	return &MockStorageQuotaRepository_Expecter{mock: &_m.Mock}
+}
+
+// GetLimits provides a mock function with given fields: ctx, userID
+func (_m *MockStorageQuotaRepository) GetLimits(ctx context.Context, userID int) (*entity.StorageLimits, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLimits")
+	}
+
+	var r0 *entity.StorageLimits
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.StorageLimits, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.StorageLimits); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.StorageLimits)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageQuotaRepository_GetLimits_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLimits'
+type MockStorageQuotaRepository_GetLimits_Call struct {
+	*mock.Call
+}
+
+// GetLimits is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *MockStorageQuotaRepository_Expecter) GetLimits(ctx interface{}, userID interface{}) *MockStorageQuotaRepository_GetLimits_Call {
+	return &MockStorageQuotaRepository_GetLimits_Call{Call: _e.mock.On("GetLimits", ctx, userID)}
+}
+
+func (_c *MockStorageQuotaRepository_GetLimits_Call) Run(run func(ctx context.Context, userID int)) *MockStorageQuotaRepository_GetLimits_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_GetLimits_Call) Return(_a0 *entity.StorageLimits, _a1 error) *MockStorageQuotaRepository_GetLimits_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_GetLimits_Call) RunAndReturn(run func(context.Context, int) (*entity.StorageLimits, error)) *MockStorageQuotaRepository_GetLimits_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPlanByName provides a mock function with given fields: ctx, name
+func (_m *MockStorageQuotaRepository) GetPlanByName(ctx context.Context, name string) (*entity.StoragePlan, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPlanByName")
+	}
+
+	var r0 *entity.StoragePlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.StoragePlan, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.StoragePlan); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.StoragePlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageQuotaRepository_GetPlanByName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPlanByName'
+type MockStorageQuotaRepository_GetPlanByName_Call struct {
+	*mock.Call
+}
+
+// GetPlanByName is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+func (_e *MockStorageQuotaRepository_Expecter) GetPlanByName(ctx interface{}, name interface{}) *MockStorageQuotaRepository_GetPlanByName_Call {
+	return &MockStorageQuotaRepository_GetPlanByName_Call{Call: _e.mock.On("GetPlanByName", ctx, name)}
+}
+
+func (_c *MockStorageQuotaRepository_GetPlanByName_Call) Run(run func(ctx context.Context, name string)) *MockStorageQuotaRepository_GetPlanByName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_GetPlanByName_Call) Return(_a0 *entity.StoragePlan, _a1 error) *MockStorageQuotaRepository_GetPlanByName_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_GetPlanByName_Call) RunAndReturn(run func(context.Context, string) (*entity.StoragePlan, error)) *MockStorageQuotaRepository_GetPlanByName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPlans provides a mock function with given fields: ctx
+func (_m *MockStorageQuotaRepository) GetPlans(ctx context.Context) ([]*entity.StoragePlan, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPlans")
+	}
+
+	var r0 []*entity.StoragePlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*entity.StoragePlan, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*entity.StoragePlan); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.StoragePlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageQuotaRepository_GetPlans_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPlans'
+type MockStorageQuotaRepository_GetPlans_Call struct {
+	*mock.Call
+}
+
+// GetPlans is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockStorageQuotaRepository_Expecter) GetPlans(ctx interface{}) *MockStorageQuotaRepository_GetPlans_Call {
+	return &MockStorageQuotaRepository_GetPlans_Call{Call: _e.mock.On("GetPlans", ctx)}
+}
+
+func (_c *MockStorageQuotaRepository_GetPlans_Call) Run(run func(ctx context.Context)) *MockStorageQuotaRepository_GetPlans_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_GetPlans_Call) Return(_a0 []*entity.StoragePlan, _a1 error) *MockStorageQuotaRepository_GetPlans_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_GetPlans_Call) RunAndReturn(run func(context.Context) ([]*entity.StoragePlan, error)) *MockStorageQuotaRepository_GetPlans_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserQuota provides a mock function with given fields: ctx, userID
+func (_m *MockStorageQuotaRepository) GetUserQuota(ctx context.Context, userID int) (*entity.UserStorageQuota, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserQuota")
+	}
+
+	var r0 *entity.UserStorageQuota
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.UserStorageQuota, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.UserStorageQuota); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.UserStorageQuota)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageQuotaRepository_GetUserQuota_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserQuota'
+type MockStorageQuotaRepository_GetUserQuota_Call struct {
+	*mock.Call
+}
+
+// GetUserQuota is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *MockStorageQuotaRepository_Expecter) GetUserQuota(ctx interface{}, userID interface{}) *MockStorageQuotaRepository_GetUserQuota_Call {
+	return &MockStorageQuotaRepository_GetUserQuota_Call{Call: _e.mock.On("GetUserQuota", ctx, userID)}
+}
+
+func (_c *MockStorageQuotaRepository_GetUserQuota_Call) Run(run func(ctx context.Context, userID int)) *MockStorageQuotaRepository_GetUserQuota_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_GetUserQuota_Call) Return(_a0 *entity.UserStorageQuota, _a1 error) *MockStorageQuotaRepository_GetUserQuota_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_GetUserQuota_Call) RunAndReturn(run func(context.Context, int) (*entity.UserStorageQuota, error)) *MockStorageQuotaRepository_GetUserQuota_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SavePlan provides a mock function with given fields: ctx, in
+func (_m *MockStorageQuotaRepository) SavePlan(ctx context.Context, in *entity.StoragePlan) (*entity.StoragePlan, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePlan")
+	}
+
+	var r0 *entity.StoragePlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.StoragePlan) (*entity.StoragePlan, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.StoragePlan) *entity.StoragePlan); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.StoragePlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.StoragePlan) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageQuotaRepository_SavePlan_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SavePlan'
+type MockStorageQuotaRepository_SavePlan_Call struct {
+	*mock.Call
+}
+
+// SavePlan is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.StoragePlan
+func (_e *MockStorageQuotaRepository_Expecter) SavePlan(ctx interface{}, in interface{}) *MockStorageQuotaRepository_SavePlan_Call {
+	return &MockStorageQuotaRepository_SavePlan_Call{Call: _e.mock.On("SavePlan", ctx, in)}
+}
+
+func (_c *MockStorageQuotaRepository_SavePlan_Call) Run(run func(ctx context.Context, in *entity.StoragePlan)) *MockStorageQuotaRepository_SavePlan_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.StoragePlan))
+	})
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_SavePlan_Call) Return(_a0 *entity.StoragePlan, _a1 error) *MockStorageQuotaRepository_SavePlan_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_SavePlan_Call) RunAndReturn(run func(context.Context, *entity.StoragePlan) (*entity.StoragePlan, error)) *MockStorageQuotaRepository_SavePlan_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUserQuota provides a mock function with given fields: ctx, in
+func (_m *MockStorageQuotaRepository) SaveUserQuota(ctx context.Context, in *entity.UserStorageQuota) error {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUserQuota")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.UserStorageQuota) error); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorageQuotaRepository_SaveUserQuota_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUserQuota'
+type MockStorageQuotaRepository_SaveUserQuota_Call struct {
+	*mock.Call
+}
+
+// SaveUserQuota is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.UserStorageQuota
+func (_e *MockStorageQuotaRepository_Expecter) SaveUserQuota(ctx interface{}, in interface{}) *MockStorageQuotaRepository_SaveUserQuota_Call {
+	return &MockStorageQuotaRepository_SaveUserQuota_Call{Call: _e.mock.On("SaveUserQuota", ctx, in)}
+}
+
+func (_c *MockStorageQuotaRepository_SaveUserQuota_Call) Run(run func(ctx context.Context, in *entity.UserStorageQuota)) *MockStorageQuotaRepository_SaveUserQuota_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.UserStorageQuota))
+	})
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_SaveUserQuota_Call) Return(_a0 error) *MockStorageQuotaRepository_SaveUserQuota_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorageQuotaRepository_SaveUserQuota_Call) RunAndReturn(run func(context.Context, *entity.UserStorageQuota) error) *MockStorageQuotaRepository_SaveUserQuota_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStorageQuotaRepository creates a new instance of MockStorageQuotaRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStorageQuotaRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStorageQuotaRepository {
+	mock := &MockStorageQuotaRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,261 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockStorageUsageRepository is an autogenerated mock type for the StorageUsageRepository type
+type MockStorageUsageRepository struct {
+	mock.Mock
+}
+
+type MockStorageUsageRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStorageUsageRepository) EXPECT() *MockStorageUsageRepository_Expecter {
+	return &MockStorageUsageRepository_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function with given fields: ctx, userID, driveDelta, filesDelta
+func (_m *MockStorageUsageRepository) Add(ctx context.Context, userID int, driveDelta int64, filesDelta int64) error {
+	ret := _m.Called(ctx, userID, driveDelta, filesDelta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int64, int64) error); ok {
+		r0 = rf(ctx, userID, driveDelta, filesDelta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStorageUsageRepository_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockStorageUsageRepository_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - driveDelta int64
+//   - filesDelta int64
+func (_e *MockStorageUsageRepository_Expecter) Add(ctx interface{}, userID interface{}, driveDelta interface{}, filesDelta interface{}) *MockStorageUsageRepository_Add_Call {
+	return &MockStorageUsageRepository_Add_Call{Call: _e.mock.On("Add", ctx, userID, driveDelta, filesDelta)}
+}
+
+func (_c *MockStorageUsageRepository_Add_Call) Run(run func(ctx context.Context, userID int, driveDelta int64, filesDelta int64)) *MockStorageUsageRepository_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int64), args[3].(int64))
+	})
+	return _c
+}
+
+func (_c *MockStorageUsageRepository_Add_Call) Return(_a0 error) *MockStorageUsageRepository_Add_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStorageUsageRepository_Add_Call) RunAndReturn(run func(context.Context, int, int64, int64) error) *MockStorageUsageRepository_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: ctx, userID
+func (_m *MockStorageUsageRepository) Get(ctx context.Context, userID int) (*entity.StorageUsage, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *entity.StorageUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.StorageUsage, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.StorageUsage); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.StorageUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageUsageRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockStorageUsageRepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *MockStorageUsageRepository_Expecter) Get(ctx interface{}, userID interface{}) *MockStorageUsageRepository_Get_Call {
+	return &MockStorageUsageRepository_Get_Call{Call: _e.mock.On("Get", ctx, userID)}
+}
+
+func (_c *MockStorageUsageRepository_Get_Call) Run(run func(ctx context.Context, userID int)) *MockStorageUsageRepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockStorageUsageRepository_Get_Call) Return(_a0 *entity.StorageUsage, _a1 error) *MockStorageUsageRepository_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageUsageRepository_Get_Call) RunAndReturn(run func(context.Context, int) (*entity.StorageUsage, error)) *MockStorageUsageRepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Lock provides a mock function with given fields: ctx, userID
+func (_m *MockStorageUsageRepository) Lock(ctx context.Context, userID int) (*entity.StorageUsage, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Lock")
+	}
+
+	var r0 *entity.StorageUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.StorageUsage, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.StorageUsage); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.StorageUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageUsageRepository_Lock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Lock'
+type MockStorageUsageRepository_Lock_Call struct {
+	*mock.Call
+}
+
+// Lock is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *MockStorageUsageRepository_Expecter) Lock(ctx interface{}, userID interface{}) *MockStorageUsageRepository_Lock_Call {
+	return &MockStorageUsageRepository_Lock_Call{Call: _e.mock.On("Lock", ctx, userID)}
+}
+
+func (_c *MockStorageUsageRepository_Lock_Call) Run(run func(ctx context.Context, userID int)) *MockStorageUsageRepository_Lock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockStorageUsageRepository_Lock_Call) Return(_a0 *entity.StorageUsage, _a1 error) *MockStorageUsageRepository_Lock_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageUsageRepository_Lock_Call) RunAndReturn(run func(context.Context, int) (*entity.StorageUsage, error)) *MockStorageUsageRepository_Lock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Recalculate provides a mock function with given fields: ctx, userID
+func (_m *MockStorageUsageRepository) Recalculate(ctx context.Context, userID *int) (int, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Recalculate")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *int) (int, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *int) int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStorageUsageRepository_Recalculate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Recalculate'
+type MockStorageUsageRepository_Recalculate_Call struct {
+	*mock.Call
+}
+
+// Recalculate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID *int
+func (_e *MockStorageUsageRepository_Expecter) Recalculate(ctx interface{}, userID interface{}) *MockStorageUsageRepository_Recalculate_Call {
+	return &MockStorageUsageRepository_Recalculate_Call{Call: _e.mock.On("Recalculate", ctx, userID)}
+}
+
+func (_c *MockStorageUsageRepository_Recalculate_Call) Run(run func(ctx context.Context, userID *int)) *MockStorageUsageRepository_Recalculate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*int))
+	})
+	return _c
+}
+
+func (_c *MockStorageUsageRepository_Recalculate_Call) Return(_a0 int, _a1 error) *MockStorageUsageRepository_Recalculate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStorageUsageRepository_Recalculate_Call) RunAndReturn(run func(context.Context, *int) (int, error)) *MockStorageUsageRepository_Recalculate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStorageUsageRepository creates a new instance of MockStorageUsageRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStorageUsageRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStorageUsageRepository {
+	mock := &MockStorageUsageRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	pgx "github.com/jackc/pgx/v5"
+	mock "github.com/stretchr/testify/mock"
+
+	repository "assistant-go/internal/layer/repository"
+)
+
+// MockTransactionRepository is an autogenerated mock type for the TransactionRepository type
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+type MockTransactionRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTransactionRepository) EXPECT() *MockTransactionRepository_Expecter {
+	return &MockTransactionRepository_Expecter{mock: &_m.Mock}
+}
+
+// GetTransaction provides a mock function with given fields: ctx
+func (_m *MockTransactionRepository) GetTransaction(ctx context.Context) (pgx.Tx, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransaction")
+	}
+
+	var r0 pgx.Tx
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (pgx.Tx, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) pgx.Tx); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(pgx.Tx)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTransactionRepository_GetTransaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTransaction'
+type MockTransactionRepository_GetTransaction_Call struct {
+	*mock.Call
+}
+
+// GetTransaction is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTransactionRepository_Expecter) GetTransaction(ctx interface{}) *MockTransactionRepository_GetTransaction_Call {
+	return &MockTransactionRepository_GetTransaction_Call{Call: _e.mock.On("GetTransaction", ctx)}
+}
+
+func (_c *MockTransactionRepository_GetTransaction_Call) Run(run func(ctx context.Context)) *MockTransactionRepository_GetTransaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockTransactionRepository_GetTransaction_Call) Return(_a0 pgx.Tx, _a1 error) *MockTransactionRepository_GetTransaction_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTransactionRepository_GetTransaction_Call) RunAndReturn(run func(context.Context) (pgx.Tx, error)) *MockTransactionRepository_GetTransaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Repositories provides a mock function with given fields: tx
+func (_m *MockTransactionRepository) Repositories(tx pgx.Tx) *repository.Repositories {
+	ret := _m.Called(tx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Repositories")
+	}
+
+	var r0 *repository.Repositories
+	if rf, ok := ret.Get(0).(func(pgx.Tx) *repository.Repositories); ok {
+		r0 = rf(tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*repository.Repositories)
+		}
+	}
+
+	return r0
+}
+
+// MockTransactionRepository_Repositories_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Repositories'
+type MockTransactionRepository_Repositories_Call struct {
+	*mock.Call
+}
+
+// Repositories is a helper method to define mock.On call
+//   - tx pgx.Tx
+func (_e *MockTransactionRepository_Expecter) Repositories(tx interface{}) *MockTransactionRepository_Repositories_Call {
+	return &MockTransactionRepository_Repositories_Call{Call: _e.mock.On("Repositories", tx)}
+}
+
+func (_c *MockTransactionRepository_Repositories_Call) Run(run func(tx pgx.Tx)) *MockTransactionRepository_Repositories_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(pgx.Tx))
+	})
+	return _c
+}
+
+func (_c *MockTransactionRepository_Repositories_Call) Return(_a0 *repository.Repositories) *MockTransactionRepository_Repositories_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTransactionRepository_Repositories_Call) RunAndReturn(run func(pgx.Tx) *repository.Repositories) *MockTransactionRepository_Repositories_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTransactionRepository creates a new instance of MockTransactionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTransactionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTransactionRepository {
+	mock := &MockTransactionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,322 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "assistant-go/internal/layer/entity"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockUserAppTokenRepository is an autogenerated mock type for the UserAppTokenRepository type
+type MockUserAppTokenRepository struct {
+	mock.Mock
+}
+
+type MockUserAppTokenRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockUserAppTokenRepository) EXPECT() *MockUserAppTokenRepository_Expecter {
+	return &MockUserAppTokenRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, in
+func (_m *MockUserAppTokenRepository) Create(ctx context.Context, in *entity.UserAppToken) (*entity.UserAppToken, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.UserAppToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.UserAppToken) (*entity.UserAppToken, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.UserAppToken) *entity.UserAppToken); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.UserAppToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.UserAppToken) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserAppTokenRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockUserAppTokenRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in *entity.UserAppToken
+func (_e *MockUserAppTokenRepository_Expecter) Create(ctx interface{}, in interface{}) *MockUserAppTokenRepository_Create_Call {
+	return &MockUserAppTokenRepository_Create_Call{Call: _e.mock.On("Create", ctx, in)}
+}
+
+func (_c *MockUserAppTokenRepository_Create_Call) Run(run func(ctx context.Context, in *entity.UserAppToken)) *MockUserAppTokenRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*entity.UserAppToken))
+	})
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_Create_Call) Return(_a0 *entity.UserAppToken, _a1 error) *MockUserAppTokenRepository_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_Create_Call) RunAndReturn(run func(context.Context, *entity.UserAppToken) (*entity.UserAppToken, error)) *MockUserAppTokenRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteByID provides a mock function with given fields: ctx, ID, userID
+func (_m *MockUserAppTokenRepository) DeleteByID(ctx context.Context, ID int, userID int) (bool, error) {
+	ret := _m.Called(ctx, ID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (bool, error)); ok {
+		return rf(ctx, ID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) bool); ok {
+		r0 = rf(ctx, ID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, ID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserAppTokenRepository_DeleteByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteByID'
+type MockUserAppTokenRepository_DeleteByID_Call struct {
+	*mock.Call
+}
+
+// DeleteByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID int
+//   - userID int
+func (_e *MockUserAppTokenRepository_Expecter) DeleteByID(ctx interface{}, ID interface{}, userID interface{}) *MockUserAppTokenRepository_DeleteByID_Call {
+	return &MockUserAppTokenRepository_DeleteByID_Call{Call: _e.mock.On("DeleteByID", ctx, ID, userID)}
+}
+
+func (_c *MockUserAppTokenRepository_DeleteByID_Call) Run(run func(ctx context.Context, ID int, userID int)) *MockUserAppTokenRepository_DeleteByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_DeleteByID_Call) Return(_a0 bool, _a1 error) *MockUserAppTokenRepository_DeleteByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_DeleteByID_Call) RunAndReturn(run func(context.Context, int, int) (bool, error)) *MockUserAppTokenRepository_DeleteByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function with given fields: ctx, tokenHash
+func (_m *MockUserAppTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*entity.UserAppToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *entity.UserAppToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.UserAppToken, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.UserAppToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.UserAppToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserAppTokenRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockUserAppTokenRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash string
+func (_e *MockUserAppTokenRepository_Expecter) GetByHash(ctx interface{}, tokenHash interface{}) *MockUserAppTokenRepository_GetByHash_Call {
+	return &MockUserAppTokenRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, tokenHash)}
+}
+
+func (_c *MockUserAppTokenRepository_GetByHash_Call) Run(run func(ctx context.Context, tokenHash string)) *MockUserAppTokenRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_GetByHash_Call) Return(_a0 *entity.UserAppToken, _a1 error) *MockUserAppTokenRepository_GetByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_GetByHash_Call) RunAndReturn(run func(context.Context, string) (*entity.UserAppToken, error)) *MockUserAppTokenRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByUserID provides a mock function with given fields: ctx, userID
+func (_m *MockUserAppTokenRepository) GetByUserID(ctx context.Context, userID int) ([]*entity.UserAppToken, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUserID")
+	}
+
+	var r0 []*entity.UserAppToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*entity.UserAppToken, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*entity.UserAppToken); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.UserAppToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserAppTokenRepository_GetByUserID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByUserID'
+type MockUserAppTokenRepository_GetByUserID_Call struct {
+	*mock.Call
+}
+
+// GetByUserID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *MockUserAppTokenRepository_Expecter) GetByUserID(ctx interface{}, userID interface{}) *MockUserAppTokenRepository_GetByUserID_Call {
+	return &MockUserAppTokenRepository_GetByUserID_Call{Call: _e.mock.On("GetByUserID", ctx, userID)}
+}
+
+func (_c *MockUserAppTokenRepository_GetByUserID_Call) Run(run func(ctx context.Context, userID int)) *MockUserAppTokenRepository_GetByUserID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_GetByUserID_Call) Return(_a0 []*entity.UserAppToken, _a1 error) *MockUserAppTokenRepository_GetByUserID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_GetByUserID_Call) RunAndReturn(run func(context.Context, int) ([]*entity.UserAppToken, error)) *MockUserAppTokenRepository_GetByUserID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Touch provides a mock function with given fields: ctx, ID, usedAt
+func (_m *MockUserAppTokenRepository) Touch(ctx context.Context, ID int, usedAt time.Time) error {
+	ret := _m.Called(ctx, ID, usedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Touch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) error); ok {
+		r0 = rf(ctx, ID, usedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserAppTokenRepository_Touch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Touch'
+type MockUserAppTokenRepository_Touch_Call struct {
+	*mock.Call
+}
+
+// Touch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID int
+//   - usedAt time.Time
+func (_e *MockUserAppTokenRepository_Expecter) Touch(ctx interface{}, ID interface{}, usedAt interface{}) *MockUserAppTokenRepository_Touch_Call {
+	return &MockUserAppTokenRepository_Touch_Call{Call: _e.mock.On("Touch", ctx, ID, usedAt)}
+}
+
+func (_c *MockUserAppTokenRepository_Touch_Call) Run(run func(ctx context.Context, ID int, usedAt time.Time)) *MockUserAppTokenRepository_Touch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_Touch_Call) Return(_a0 error) *MockUserAppTokenRepository_Touch_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserAppTokenRepository_Touch_Call) RunAndReturn(run func(context.Context, int, time.Time) error) *MockUserAppTokenRepository_Touch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockUserAppTokenRepository creates a new instance of MockUserAppTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockUserAppTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockUserAppTokenRepository {
+	mock := &MockUserAppTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
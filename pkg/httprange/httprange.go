@@ -0,0 +1,94 @@
+package httprange
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidRange   = errors.New("invalid range")
+	ErrNoOverlap      = errors.New("invalid range: failed to overlap")
+	ErrMultipleRanges = errors.New("multiple ranges are not supported")
+)
+
+type Range struct {
+	Start  int64
+	Length int64
+}
+
+// ContentRange возвращает значение заголовка Content-Range для ответа 206
+func (r Range) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.Start+r.Length-1, size)
+}
+
+// Parse разбирает заголовок Range (RFC 7233) для ресурса размером size.
+// Пустой заголовок возвращает nil без ошибки. Поддерживается только один диапазон.
+func Parse(header string, size int64) (*Range, error) {
+	if header == "" {
+		return nil, nil
+	}
+
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, ErrInvalidRange
+	}
+
+	spec := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if strings.Contains(spec, ",") {
+		return nil, ErrMultipleRanges
+	}
+
+	startStr, endStr, found := strings.Cut(spec, "-")
+	if !found {
+		return nil, ErrInvalidRange
+	}
+	startStr = strings.TrimSpace(startStr)
+	endStr = strings.TrimSpace(endStr)
+
+	var r Range
+	if startStr == "" {
+		// suffix-range: bytes=-N — последние N байт
+		if endStr == "" {
+			return nil, ErrInvalidRange
+		}
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, ErrInvalidRange
+		}
+		if suffix == 0 || size == 0 {
+			return nil, ErrNoOverlap
+		}
+		if suffix > size {
+			suffix = size
+		}
+		r.Start = size - suffix
+		r.Length = suffix
+		return &r, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return nil, ErrInvalidRange
+	}
+	if start >= size {
+		return nil, ErrNoOverlap
+	}
+	r.Start = start
+
+	if endStr == "" {
+		r.Length = size - start
+		return &r, nil
+	}
+
+	end, err := strconv.ParseInt(endStr, 10, 64)
+	if err != nil || end < start {
+		return nil, ErrInvalidRange
+	}
+	if end >= size {
+		end = size - 1
+	}
+	r.Length = end - start + 1
+	return &r, nil
+}
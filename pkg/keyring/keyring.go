@@ -0,0 +1,233 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrNoMasterKey      = errors.New("master key is not configured")
+	ErrUnknownMasterKey = errors.New("master key with this id is not configured")
+	ErrInvalidKeyList   = errors.New("invalid master key list, expected id:secret pairs separated by commas")
+	ErrInvalidWrapped   = errors.New("wrapped key is corrupted or was wrapped by another master key")
+	ErrInvalidSealed    = errors.New("sealed value is corrupted or was sealed by another key")
+)
+
+const (
+	// DataKeySize - размер ключа данных (AES-256)
+	DataKeySize = 32
+
+	// PurposeThumbnails - ключ превью: превью, зашифрованные прежним мастер-ключом, строятся заново
+	PurposeThumbnails = "thumbnails"
+
+	wrapVersion1 = 1
+	nonceSize    = 12
+)
+
+// Keyring хранит мастер-ключи по ID. Текущим ключом заворачиваются новые ключи данных,
+// выведенные из оборота ключи нужны, пока ими завернуты ключи данных в БД.
+// Ключ шифрования выводится из секрета через HKDF-SHA256, секрет в памяти не хранится
+type Keyring struct {
+	currentID string
+	masters   map[string][]byte
+	derived   map[string][]byte
+	legacy    []byte
+}
+
+// New создает набор ключей из текущего мастер-ключа и списка выведенных из оборота ключей
+// в формате "id:secret,id:secret". legacyID - ключ, секрет которого до появления ключей данных
+// использовался для шифрования файлов напрямую (SHA-256 от секрета), пустой - текущий ключ.
+// Без текущего секрета набор можно использовать только для чтения
+func New(currentID string, currentSecret string, retired string, legacyID string) (*Keyring, error) {
+	secrets := make(map[string]string)
+	if currentSecret != "" {
+		if currentID == "" {
+			return nil, ErrInvalidKeyList
+		}
+		secrets[currentID] = currentSecret
+	}
+
+	for _, pair := range strings.Split(retired, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, secret, found := strings.Cut(pair, ":")
+		if !found || id == "" || secret == "" {
+			return nil, ErrInvalidKeyList
+		}
+		if _, exists := secrets[id]; exists {
+			return nil, ErrInvalidKeyList
+		}
+		secrets[id] = secret
+	}
+
+	keyring := &Keyring{
+		masters: make(map[string][]byte, len(secrets)),
+		derived: make(map[string][]byte),
+	}
+	for id, secret := range secrets {
+		master, err := hkdf.Key(sha256.New, []byte(secret), nil, "master:"+id, DataKeySize)
+		if err != nil {
+			return nil, err
+		}
+		keyring.masters[id] = master
+	}
+
+	if currentSecret != "" {
+		keyring.currentID = currentID
+
+		// производные ключи текущего секрета (например, для превью) меняются вместе с мастер-ключом
+		for _, purpose := range []string{PurposeThumbnails} {
+			key, err := hkdf.Key(sha256.New, []byte(currentSecret), nil, "purpose:"+purpose, DataKeySize)
+			if err != nil {
+				return nil, err
+			}
+			keyring.derived[purpose] = key
+		}
+	}
+
+	if legacyID == "" {
+		legacyID = currentID
+	}
+	if secret, ok := secrets[legacyID]; ok {
+		legacy := sha256.Sum256([]byte(secret))
+		keyring.legacy = legacy[:]
+	}
+	return keyring, nil
+}
+
+// CurrentID возвращает ID мастер-ключа, которым заворачиваются новые ключи данных
+func (k *Keyring) CurrentID() string {
+	if k == nil {
+		return ""
+	}
+	return k.currentID
+}
+
+// Has сообщает, настроен ли мастер-ключ с таким ID
+func (k *Keyring) Has(id string) bool {
+	if k == nil {
+		return false
+	}
+	_, ok := k.masters[id]
+	return ok
+}
+
+// NewDataKey создает случайный ключ данных
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Wrap заворачивает ключ данных текущим мастер-ключом и возвращает ID этого мастер-ключа
+func (k *Keyring) Wrap(dataKey []byte) (string, []byte, error) {
+	if k == nil || k.currentID == "" {
+		return "", nil, ErrNoMasterKey
+	}
+
+	aead, err := newAEAD(k.masters[k.currentID])
+	if err != nil {
+		return "", nil, err
+	}
+
+	wrapped := make([]byte, 1+nonceSize, 1+nonceSize+len(dataKey)+aead.Overhead())
+	wrapped[0] = wrapVersion1
+	if _, err = rand.Read(wrapped[1:]); err != nil {
+		return "", nil, err
+	}
+	// ID мастер-ключа входит в additional data: завернутый ключ нельзя выдать за завернутый другим ключом
+	wrapped = aead.Seal(wrapped, wrapped[1:1+nonceSize], dataKey, []byte(k.currentID))
+	return k.currentID, wrapped, nil
+}
+
+// Unwrap разворачивает ключ данных мастер-ключом masterKeyID
+func (k *Keyring) Unwrap(masterKeyID string, wrapped []byte) ([]byte, error) {
+	if !k.Has(masterKeyID) {
+		return nil, ErrUnknownMasterKey
+	}
+	if len(wrapped) < 1+nonceSize || wrapped[0] != wrapVersion1 {
+		return nil, ErrInvalidWrapped
+	}
+
+	aead, err := newAEAD(k.masters[masterKeyID])
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := aead.Open(nil, wrapped[1:1+nonceSize], wrapped[1+nonceSize:], []byte(masterKeyID))
+	if err != nil {
+		return nil, ErrInvalidWrapped
+	}
+	return dataKey, nil
+}
+
+// Derived возвращает ключ назначения purpose, производный от текущего мастер-ключа
+func (k *Keyring) Derived(purpose string) ([]byte, error) {
+	if k == nil {
+		return nil, ErrNoMasterKey
+	}
+	key, ok := k.derived[purpose]
+	if !ok {
+		return nil, ErrNoMasterKey
+	}
+	return key, nil
+}
+
+// LegacyKey возвращает ключ, которым файлы шифровались до появления ключей данных
+func (k *Keyring) LegacyKey() ([]byte, error) {
+	if k == nil || k.legacy == nil {
+		return nil, ErrNoMasterKey
+	}
+	return k.legacy, nil
+}
+
+// Seal шифрует небольшое значение (например, поле записи в БД) ключом данных.
+// additionalData связывает шифртекст с местом хранения: значение одного поля нельзя подставить в другое
+func Seal(dataKey []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 1+nonceSize, 1+nonceSize+len(plaintext)+aead.Overhead())
+	sealed[0] = wrapVersion1
+	if _, err = rand.Read(sealed[1:]); err != nil {
+		return nil, err
+	}
+	return aead.Seal(sealed, sealed[1:1+nonceSize], plaintext, additionalData), nil
+}
+
+// Open расшифровывает значение, зашифрованное Seal
+func Open(dataKey []byte, sealed []byte, additionalData []byte) ([]byte, error) {
+	if len(sealed) < 1+nonceSize || sealed[0] != wrapVersion1 {
+		return nil, ErrInvalidSealed
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, sealed[1:1+nonceSize], sealed[1+nonceSize:], additionalData)
+	if err != nil {
+		return nil, ErrInvalidSealed
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
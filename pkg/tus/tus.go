@@ -0,0 +1,99 @@
+package tus
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"strings"
+)
+
+// Заголовки и значения протокола tus 1.0 (https://tus.io/protocols/resumable-upload)
+const (
+	Version            = "1.0.0"
+	Extensions         = "creation,termination,checksum"
+	ChecksumAlgorithms = "sha1,sha256"
+	OffsetContentType  = "application/offset+octet-stream"
+
+	HeaderResumable         = "Tus-Resumable"
+	HeaderVersion           = "Tus-Version"
+	HeaderExtension         = "Tus-Extension"
+	HeaderMaxSize           = "Tus-Max-Size"
+	HeaderChecksumAlgorithm = "Tus-Checksum-Algorithm"
+	HeaderUploadOffset      = "Upload-Offset"
+	HeaderUploadLength      = "Upload-Length"
+	HeaderUploadDeferLength = "Upload-Defer-Length"
+	HeaderUploadMetadata    = "Upload-Metadata"
+	HeaderUploadChecksum    = "Upload-Checksum"
+	HeaderMethodOverride    = "X-HTTP-Method-Override"
+	StatusChecksumMismatch  = 460
+)
+
+var (
+	ErrInvalidMetadata      = errors.New("invalid upload metadata")
+	ErrInvalidChecksum      = errors.New("invalid upload checksum")
+	ErrUnsupportedAlgorithm = errors.New("unsupported checksum algorithm")
+)
+
+// ParseMetadata разбирает заголовок Upload-Metadata: пары "ключ base64(значение)" через запятую.
+// Значение может отсутствовать, тогда ключу соответствует пустая строка
+func ParseMetadata(header string) (map[string]string, error) {
+	result := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return result, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, ErrInvalidMetadata
+		}
+		if _, exists := result[parts[0]]; exists {
+			return nil, ErrInvalidMetadata
+		}
+
+		value := ""
+		if len(parts) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, ErrInvalidMetadata
+			}
+			value = string(decoded)
+		}
+		result[parts[0]] = value
+	}
+	return result, nil
+}
+
+// ParseChecksum разбирает заголовок Upload-Checksum вида "алгоритм base64(хэш)"
+func ParseChecksum(header string) (string, []byte, error) {
+	parts := strings.Fields(header)
+	if len(parts) != 2 {
+		return "", nil, ErrInvalidChecksum
+	}
+
+	algorithm := strings.ToLower(parts[0])
+	newHash, err := NewChecksumHash(algorithm)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(sum) != newHash.Size() {
+		return "", nil, ErrInvalidChecksum
+	}
+	return algorithm, sum, nil
+}
+
+// NewChecksumHash возвращает хэш для алгоритма из Tus-Checksum-Algorithm
+func NewChecksumHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
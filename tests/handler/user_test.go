@@ -39,7 +39,7 @@ func setupTest() (*mocks.MockUserRepository, *handler.UserHandler, context.Conte
 	}
 
 	cfg := &config.Config{BlockingParanoia: 0}
-	handler.InitHandler(repos, cfg)
+	handler.InitHandler(repos, cfg, nil)
 
 	userUseCase := ucase.NewUserUseCase(repos)
 	userHandler := handler.NewUserHandler(userUseCase)
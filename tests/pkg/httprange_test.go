@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"assistant-go/pkg/httprange"
+	"errors"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		size        int64
+		expected    *httprange.Range
+		expectedErr error
+	}{
+		{name: "empty header", header: "", size: 100, expected: nil},
+		{name: "full range", header: "bytes=0-99", size: 100, expected: &httprange.Range{Start: 0, Length: 100}},
+		{name: "first bytes", header: "bytes=0-9", size: 100, expected: &httprange.Range{Start: 0, Length: 10}},
+		{name: "open end", header: "bytes=90-", size: 100, expected: &httprange.Range{Start: 90, Length: 10}},
+		{name: "suffix", header: "bytes=-20", size: 100, expected: &httprange.Range{Start: 80, Length: 20}},
+		{name: "suffix larger than size", header: "bytes=-500", size: 100, expected: &httprange.Range{Start: 0, Length: 100}},
+		{name: "end beyond size", header: "bytes=50-1000", size: 100, expected: &httprange.Range{Start: 50, Length: 50}},
+		{name: "start beyond size", header: "bytes=100-", size: 100, expectedErr: httprange.ErrNoOverlap},
+		{name: "zero suffix", header: "bytes=-0", size: 100, expectedErr: httprange.ErrNoOverlap},
+		{name: "empty file", header: "bytes=0-", size: 0, expectedErr: httprange.ErrNoOverlap},
+		{name: "end before start", header: "bytes=10-5", size: 100, expectedErr: httprange.ErrInvalidRange},
+		{name: "wrong unit", header: "items=0-5", size: 100, expectedErr: httprange.ErrInvalidRange},
+		{name: "no dash", header: "bytes=5", size: 100, expectedErr: httprange.ErrInvalidRange},
+		{name: "not a number", header: "bytes=a-b", size: 100, expectedErr: httprange.ErrInvalidRange},
+		{name: "multiple ranges", header: "bytes=0-1,5-6", size: 100, expectedErr: httprange.ErrMultipleRanges},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := httprange.Parse(tt.header, tt.size)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tt.expected == nil {
+				if result != nil {
+					t.Fatalf("Expected nil range, got %+v", result)
+				}
+				return
+			}
+			if result == nil || *result != *tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	r := httprange.Range{Start: 10, Length: 5}
+	if got := r.ContentRange(100); got != "bytes 10-14/100" {
+		t.Errorf("Expected bytes 10-14/100, got %s", got)
+	}
+}
@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"assistant-go/pkg/keyring"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+func TestKeyringWrapUnwrap(t *testing.T) {
+	old, err := keyring.New("1", "old-secret", "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	dataKey, err := keyring.NewDataKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	masterKeyID, wrapped, err := old.Wrap(dataKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if masterKeyID != "1" {
+		t.Fatalf("Expected master key id 1, got %s", masterKeyID)
+	}
+
+	// после ротации прежний ключ остается в списке выведенных из оборота
+	rotated, err := keyring.New("2", "new-secret", "1:old-secret", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	unwrapped, err := rotated.Unwrap(masterKeyID, wrapped)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(unwrapped, dataKey) {
+		t.Fatal("Unwrapped key differs from the original")
+	}
+
+	if _, err = rotated.Unwrap("2", wrapped); !errors.Is(err, keyring.ErrInvalidWrapped) {
+		t.Fatalf("Expected error %v, got %v", keyring.ErrInvalidWrapped, err)
+	}
+	if _, err = rotated.Unwrap("3", wrapped); !errors.Is(err, keyring.ErrUnknownMasterKey) {
+		t.Fatalf("Expected error %v, got %v", keyring.ErrUnknownMasterKey, err)
+	}
+
+	legacy, err := rotated.LegacyKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := sha256.Sum256([]byte("old-secret"))
+	if !bytes.Equal(legacy, expected[:]) {
+		t.Fatal("Legacy key must be SHA-256 of the legacy secret")
+	}
+}
+
+func TestKeyringInvalidKeyList(t *testing.T) {
+	tests := []struct {
+		name    string
+		retired string
+	}{
+		{name: "no separator", retired: "old-secret"},
+		{name: "empty id", retired: ":old-secret"},
+		{name: "empty secret", retired: "1:"},
+		{name: "duplicate id", retired: "2:old-secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := keyring.New("2", "new-secret", tt.retired, "")
+			if !errors.Is(err, keyring.ErrInvalidKeyList) {
+				t.Fatalf("Expected error %v, got %v", keyring.ErrInvalidKeyList, err)
+			}
+		})
+	}
+}
+
+func TestKeyringWithoutMasterKey(t *testing.T) {
+	keys, err := keyring.New("1", "", "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err = keys.Wrap(make([]byte, keyring.DataKeySize)); !errors.Is(err, keyring.ErrNoMasterKey) {
+		t.Fatalf("Expected error %v, got %v", keyring.ErrNoMasterKey, err)
+	}
+	if _, err = keys.Derived(keyring.PurposeThumbnails); !errors.Is(err, keyring.ErrNoMasterKey) {
+		t.Fatalf("Expected error %v, got %v", keyring.ErrNoMasterKey, err)
+	}
+}
+
+func TestKeyringSealOpen(t *testing.T) {
+	dataKey, err := keyring.NewDataKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	plaintext := []byte(`[{"type":"paragraph","data":{"text":"note"}}]`)
+
+	sealed, err := keyring.Seal(dataKey, plaintext, []byte("notes.note_blocks"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Fatal("Sealed value contains the plaintext")
+	}
+
+	opened, err := keyring.Open(dataKey, sealed, []byte("notes.note_blocks"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatal("Opened value differs from the original")
+	}
+
+	// значение одной колонки нельзя подставить в другую
+	if _, err = keyring.Open(dataKey, sealed, []byte("notes.title")); !errors.Is(err, keyring.ErrInvalidSealed) {
+		t.Fatalf("Expected error %v, got %v", keyring.ErrInvalidSealed, err)
+	}
+
+	otherKey, err := keyring.NewDataKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err = keyring.Open(otherKey, sealed, []byte("notes.note_blocks")); !errors.Is(err, keyring.ErrInvalidSealed) {
+		t.Fatalf("Expected error %v, got %v", keyring.ErrInvalidSealed, err)
+	}
+	if _, err = keyring.Open(dataKey, sealed[:5], []byte("notes.note_blocks")); !errors.Is(err, keyring.ErrInvalidSealed) {
+		t.Fatalf("Expected error %v, got %v", keyring.ErrInvalidSealed, err)
+	}
+}
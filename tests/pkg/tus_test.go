@@ -0,0 +1,54 @@
+package pkg
+
+import (
+	"assistant-go/pkg/tus"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestParseMetadata(t *testing.T) {
+	result, err := tus.ParseMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,parent_id MTI=,is_confidential")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result["filename"] != "world_domination_plan.pdf" {
+		t.Errorf("Expected filename world_domination_plan.pdf, got %q", result["filename"])
+	}
+	if result["parent_id"] != "12" {
+		t.Errorf("Expected parent_id 12, got %q", result["parent_id"])
+	}
+	if value, ok := result["is_confidential"]; !ok || value != "" {
+		t.Errorf("Expected empty is_confidential, got %q", value)
+	}
+
+	empty, err := tus.ParseMetadata("")
+	if err != nil || len(empty) != 0 {
+		t.Errorf("Expected empty metadata, got %v, %v", empty, err)
+	}
+
+	for _, header := range []string{"filename !!!", "a YQ==,a Yg==", "a YQ== b"} {
+		if _, err = tus.ParseMetadata(header); !errors.Is(err, tus.ErrInvalidMetadata) {
+			t.Errorf("Expected ErrInvalidMetadata for %q, got %v", header, err)
+		}
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	sum := sha1.Sum([]byte("hello"))
+	algorithm, result, err := tus.ParseChecksum("sha1 " + base64.StdEncoding.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if algorithm != "sha1" || string(result) != string(sum[:]) {
+		t.Errorf("Unexpected checksum %s %x", algorithm, result)
+	}
+
+	if _, _, err = tus.ParseChecksum("md5 XUFAKrxLKna5cZ2REBfFkg=="); !errors.Is(err, tus.ErrUnsupportedAlgorithm) {
+		t.Errorf("Expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+	if _, _, err = tus.ParseChecksum("sha1 YQ=="); !errors.Is(err, tus.ErrInvalidChecksum) {
+		t.Errorf("Expected ErrInvalidChecksum, got %v", err)
+	}
+}
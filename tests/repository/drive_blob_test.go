@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testBlobHash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+// createBlob создает блоб пользователя с одной ссылкой
+func createBlob(t *testing.T, ctx context.Context, userID int, hash string) *entity.DriveBlob {
+	t.Helper()
+	blob, err := repository.NewDriveBlobRepository(testDB).Create(ctx, &entity.DriveBlob{
+		UserID:    userID,
+		SHA256:    hash,
+		Path:      "blobs/" + hash[:8],
+		Size:      5,
+		PlainSize: 5,
+		RefCount:  1,
+		CreatedAt: testTime(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return blob
+}
+
+func TestDriveBlobGetByHash(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveBlobRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+	blob := createBlob(t, ctx, userID, testBlobHash)
+
+	found, err := repo.GetByHash(ctx, userID, testBlobHash)
+	if assert.NoError(t, err) {
+		assert.Equal(t, blob.ID, found.ID)
+	}
+
+	// содержимое дедуплицируется только в пределах одного пользователя
+	_, err = repo.GetByHash(ctx, otherID, testBlobHash)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	createBlob(t, ctx, otherID, testBlobHash)
+
+	_, err = repo.Create(ctx, &entity.DriveBlob{UserID: userID, SHA256: testBlobHash, Path: "blobs/copy", CreatedAt: testTime()})
+	var pgErr *pgconn.PgError
+	if assert.ErrorAs(t, err, &pgErr) {
+		assert.Equal(t, "23505", pgErr.Code)
+	}
+}
+
+func TestDriveBlobRefCount(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveBlobRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	blob := createBlob(t, ctx, userID, testBlobHash)
+
+	for range 2 {
+		if err := repo.IncrementRef(ctx, blob.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	released, err := repo.DecrementRef(ctx, blob.ID, 2)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, released.RefCount)
+		assert.Equal(t, blob.Path, released.Path)
+	}
+	released, err = repo.DecrementRef(ctx, blob.ID, 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, released.RefCount)
+	}
+
+	if err = repo.Delete(ctx, blob.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.GetByHash(ctx, userID, testBlobHash)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
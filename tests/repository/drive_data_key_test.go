@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// createDataKey создает активный ключ данных пользователя, завернутый мастер-ключом masterKeyID
+func createDataKey(t *testing.T, ctx context.Context, userID int, masterKeyID string) *entity.DriveDataKey {
+	t.Helper()
+	dataKey, err := repository.NewDriveDataKeyRepository(testDB).CreateActive(ctx, &entity.DriveDataKey{
+		UserID:      &userID,
+		MasterKeyID: masterKeyID,
+		WrappedKey:  []byte("wrapped"),
+		CreatedAt:   testTime(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dataKey
+}
+
+// setDataKey отмечает объект таблицы kind зашифрованным ключом dataKeyID
+func setDataKey(t *testing.T, ctx context.Context, kind string, id int, dataKeyID int) {
+	t.Helper()
+	if _, err := testDB.Exec(ctx, `UPDATE `+kind+` SET data_key_id = $2 WHERE id = $1`, id, dataKeyID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDriveDataKeyCreateActive(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveDataKeyRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	first := createDataKey(t, ctx, userID, "m1")
+	createDataKey(t, ctx, otherID, "m1")
+
+	// у пользователя один активный ключ: ключ параллельного запроса не сохраняется
+	_, err := repo.CreateActive(ctx, &entity.DriveDataKey{
+		UserID:      &userID,
+		MasterKeyID: "m1",
+		WrappedKey:  []byte("concurrent"),
+		CreatedAt:   testTime(),
+	})
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	active, err := repo.GetActive(ctx, userID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, first.ID, active.ID)
+	}
+
+	// после ротации создаются новые активные ключи, старые остаются для расшифровки
+	count, err := repo.DeactivateAll(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, count)
+	}
+	_, err = repo.GetActive(ctx, userID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	second := createDataKey(t, ctx, userID, "m2")
+	assert.NotEqual(t, first.ID, second.ID)
+	previous, err := repo.GetByID(ctx, first.ID)
+	if assert.NoError(t, err) {
+		assert.False(t, previous.IsActive)
+	}
+}
+
+func TestDriveDataKeyRewrap(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveDataKeyRepository(testDB)
+
+	keys := []*entity.DriveDataKey{
+		createDataKey(t, ctx, createUser(t, ctx, "first"), "old"),
+		createDataKey(t, ctx, createUser(t, ctx, "second"), "new"),
+		createDataKey(t, ctx, createUser(t, ctx, "third"), "old"),
+	}
+
+	list, err := repo.GetWrappedByOther(ctx, "new", 0, 1)
+	if assert.NoError(t, err) && assert.Len(t, list, 1) {
+		assert.Equal(t, keys[0].ID, list[0].ID)
+	}
+	if err = repo.UpdateWrapped(ctx, keys[0].ID, "new", []byte("rewrapped")); err != nil {
+		t.Fatal(err)
+	}
+
+	// перезавернутый ключ больше не выбирается
+	list, err = repo.GetWrappedByOther(ctx, "new", 0, 10)
+	if assert.NoError(t, err) && assert.Len(t, list, 1) {
+		assert.Equal(t, keys[2].ID, list[0].ID)
+	}
+	list, err = repo.GetWrappedByOther(ctx, "new", keys[2].ID, 10)
+	if assert.NoError(t, err) {
+		assert.Empty(t, list)
+	}
+
+	dataKey, err := repo.GetByID(ctx, keys[0].ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "new", dataKey.MasterKeyID)
+		assert.Equal(t, []byte("rewrapped"), dataKey.WrappedKey)
+	}
+}
+
+func TestDriveDataKeyDeleteUnused(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveDataKeyRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	usedKey := createDataKey(t, ctx, userID, "m1")
+	unusedKey := createDataKey(t, ctx, otherID, "m1")
+	setDataKey(t, ctx, "drive_blobs", createBlob(t, ctx, userID, testBlobHash).ID, usedKey.ID)
+	if _, err := repo.DeactivateAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	activeKey := createDataKey(t, ctx, otherID, "m1")
+
+	// удаляются только неактивные ключи без зашифрованных ими объектов
+	count, err := repo.DeleteUnused(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, count)
+	}
+	_, err = repo.GetByID(ctx, unusedKey.ID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	for _, id := range []int{usedKey.ID, activeKey.ID} {
+		_, err = repo.GetByID(ctx, id)
+		assert.NoError(t, err)
+	}
+}
+
+func TestDriveDataKeyGetStaleObjects(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveDataKeyRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	// пользователь без ключа: все его объекты зашифрованы старым способом или не зашифрованы
+	legacyFileID := createFile(t, ctx, createStruct(t, ctx, otherID, "legacy.txt", 1, nil), "2/legacy.txt", 3)
+
+	oldKey := createDataKey(t, ctx, userID, "m1")
+	if _, err := repo.DeactivateAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	activeKey := createDataKey(t, ctx, userID, "m1")
+	otherKey := createDataKey(t, ctx, otherID, "m1")
+	if _, err := testDB.Exec(ctx, `UPDATE drive_data_keys SET is_active = FALSE WHERE id = $1`, otherKey.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	blob := createBlob(t, ctx, userID, testBlobHash)
+	setDataKey(t, ctx, "drive_blobs", blob.ID, oldKey.ID)
+	_, err := repository.NewDriveFileRepository(testDB).Create(ctx, &entity.DriveFile{
+		DriveStructID: createStruct(t, ctx, userID, "blob.txt", 1, nil),
+		Path:          &blob.Path,
+		Ext:           "txt",
+		Size:          blob.Size,
+		BlobID:        &blob.ID,
+		CreatedAt:     testTime(),
+		UploadState:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentFileID := createFile(t, ctx, createStruct(t, ctx, userID, "current.txt", 1, nil), "1/current.txt", 4)
+	setDataKey(t, ctx, "drive_files", currentFileID, activeKey.ID)
+	// копия файла другого пользователя зашифрована его ключом
+	copiedFileID := createFile(t, ctx, createStruct(t, ctx, userID, "copy.txt", 1, nil), "1/copy.txt", 4)
+	setDataKey(t, ctx, "drive_files", copiedFileID, otherKey.ID)
+	chunkedFileID := createChunkedFile(t, ctx, createStruct(t, ctx, userID, "e.bin", 1, nil), 3, 1, 2)
+	chunks, err := repository.NewDriveFileChunkRepository(testDB).GetByFileID(ctx, chunkedFileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setDataKey(t, ctx, "drive_file_chunks", chunks[0].ID, activeKey.ID)
+
+	tests := []struct {
+		name        string
+		kind        string
+		afterID     int
+		expectedIDs []int
+	}{
+		{name: "blobs", kind: repository.StorageObjectDriveBlobs, expectedIDs: []int{blob.ID}},
+		{name: "files without blob", kind: repository.StorageObjectDriveFiles, expectedIDs: []int{legacyFileID, copiedFileID}},
+		{name: "after id", kind: repository.StorageObjectDriveFiles, afterID: legacyFileID, expectedIDs: []int{copiedFileID}},
+		{name: "chunks", kind: repository.StorageObjectDriveChunks, expectedIDs: []int{chunks[1].ID}},
+		{name: "note files", kind: repository.StorageObjectFiles, expectedIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := repo.GetStaleObjects(ctx, tt.kind, tt.afterID, 10)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ids := make([]int, 0, len(objects))
+			for _, object := range objects {
+				assert.Equal(t, tt.kind, object.Kind)
+				ids = append(ids, object.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestDriveDataKeyReplaceObject(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveDataKeyRepository(testDB)
+	driveFileRepo := repository.NewDriveFileRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	dataKey := createDataKey(t, ctx, userID, "m1")
+
+	blob := createBlob(t, ctx, userID, testBlobHash)
+	blobFile, err := driveFileRepo.Create(ctx, &entity.DriveFile{
+		DriveStructID: createStruct(t, ctx, userID, "blob.txt", 1, nil),
+		Path:          &blob.Path,
+		Ext:           "txt",
+		Size:          blob.Size,
+		BlobID:        &blob.ID,
+		CreatedAt:     testTime(),
+		UploadState:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunkedFileID := createChunkedFile(t, ctx, createStruct(t, ctx, userID, "e.bin", 1, nil), 3, 1, 2)
+	if err = driveFileRepo.Complete(ctx, chunkedFileID, 6, 6, testBlobHash, "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := repository.NewDriveFileChunkRepository(testDB).GetByFileID(ctx, chunkedFileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plainSize := int64(5)
+	replaced := &entity.StorageObject{
+		Kind:      repository.StorageObjectDriveBlobs,
+		ID:        blob.ID,
+		Path:      "blobs/encrypted",
+		Size:      33,
+		PlainSize: &plainSize,
+		DataKeyID: &dataKey.ID,
+	}
+	previous := &entity.StorageObject{Kind: repository.StorageObjectDriveBlobs, ID: blob.ID, Path: blob.Path, Size: blob.Size}
+
+	// файлы с блобом переводятся на перешифрованный объект вместе с блобом
+	updated, err := repo.ReplaceObject(ctx, replaced, previous)
+	if assert.NoError(t, err) {
+		assert.True(t, updated)
+	}
+	driveFile, err := driveFileRepo.GetByID(ctx, blobFile.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "blobs/encrypted", *driveFile.Path)
+		assert.Equal(t, int64(33), driveFile.Size)
+		assert.Equal(t, &dataKey.ID, driveFile.DataKeyID)
+	}
+
+	// запись уже ссылается на другой объект: повторная замена не выполняется
+	updated, err = repo.ReplaceObject(ctx, replaced, previous)
+	if assert.NoError(t, err) {
+		assert.False(t, updated)
+	}
+
+	// размер завершенного чанкового файла меняется на разницу размеров чанка
+	chunkSize := int64(3)
+	updated, err = repo.ReplaceObject(ctx, &entity.StorageObject{
+		Kind:      repository.StorageObjectDriveChunks,
+		ID:        chunks[0].ID,
+		Path:      "chunks/encrypted",
+		Size:      31,
+		PlainSize: &chunkSize,
+		DataKeyID: &dataKey.ID,
+	}, &entity.StorageObject{
+		Kind: repository.StorageObjectDriveChunks,
+		ID:   chunks[0].ID,
+		Path: chunks[0].Path,
+		Size: chunks[0].Size,
+	})
+	if assert.NoError(t, err) {
+		assert.True(t, updated)
+	}
+	driveFile, err = driveFileRepo.GetByID(ctx, chunkedFileID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(6+31-3), driveFile.Size)
+	}
+
+	objects, err := repo.GetStaleObjects(ctx, repository.StorageObjectDriveChunks, 0, 10)
+	if assert.NoError(t, err) && assert.Len(t, objects, 1) {
+		assert.Equal(t, chunks[1].ID, objects[0].ID)
+	}
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// createChunkedFile создает незавершенную чанковую загрузку с чанками numbers по size байт
+func createChunkedFile(t *testing.T, ctx context.Context, structID int, size int64, numbers ...int) int {
+	t.Helper()
+	driveFile, err := repository.NewDriveFileRepository(testDB).Create(ctx, &entity.DriveFile{
+		DriveStructID: structID,
+		Ext:           "bin",
+		CreatedAt:     testTime(),
+		IsChunk:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunkRepo := repository.NewDriveFileChunkRepository(testDB)
+	for _, number := range numbers {
+		_, err = chunkRepo.Create(ctx, &entity.DriveFileChunk{
+			DriveFileID: driveFile.ID,
+			Path:        fmt.Sprintf("%d/chunk.%d", structID, number),
+			Size:        size,
+			PlainSize:   &size,
+			ChunkNumber: number,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return driveFile.ID
+}
+
+func TestDriveFileChunks(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveFileChunkRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	structID := createStruct(t, ctx, userID, "a.bin", 1, nil)
+	otherStructID := createStruct(t, ctx, userID, "b.bin", 1, nil)
+
+	// чанки приходят в произвольном порядке
+	fileID := createChunkedFile(t, ctx, structID, 3, 3, 1, 2)
+	createChunkedFile(t, ctx, otherStructID, 5, 1)
+
+	chunks, err := repo.GetByFileID(ctx, fileID)
+	if assert.NoError(t, err) && assert.Len(t, chunks, 3) {
+		assert.Equal(t, []int{1, 2, 3}, []int{chunks[0].ChunkNumber, chunks[1].ChunkNumber, chunks[2].ChunkNumber})
+	}
+
+	info, err := repo.GetChunksInfo(ctx, fileID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, info.StartNumber)
+		assert.Equal(t, 3, info.EndNumber)
+	}
+
+	size, err := repo.GetChunksSize(ctx, fileID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(9), size)
+	}
+
+	chunk, err := repo.GetByFileIDAndNumber(ctx, fileID, 2)
+	if assert.NoError(t, err) {
+		assert.Equal(t, fmt.Sprintf("%d/chunk.2", structID), chunk.Path)
+	}
+}
+
+func TestDriveFileChunksComplete(t *testing.T) {
+	ctx := setupDB(t)
+	fileRepo := repository.NewDriveFileRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	structID := createStruct(t, ctx, userID, "a.bin", 1, nil)
+	fileID := createChunkedFile(t, ctx, structID, 3, 1, 2)
+
+	hash := "bef57ec7f53a6d40beb640a780a639c83bc29ac8a9816f1fc6c5c6dcd93c4721"
+	if err := fileRepo.Complete(ctx, fileID, 6, 6, hash, "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+	driveFile, err := fileRepo.GetByID(ctx, fileID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int8(1), driveFile.UploadState)
+	assert.True(t, driveFile.IsChunk)
+	assert.Equal(t, int64(6), driveFile.Size)
+	assert.Equal(t, &hash, driveFile.SHA256)
+}
+
+func TestDriveFileChunksAttachBlob(t *testing.T) {
+	ctx := setupDB(t)
+	fileRepo := repository.NewDriveFileRepository(testDB)
+	chunkRepo := repository.NewDriveFileChunkRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	structID := createStruct(t, ctx, userID, "a.bin", 1, nil)
+	fileID := createChunkedFile(t, ctx, structID, 3, 1, 2)
+
+	blob, err := repository.NewDriveBlobRepository(testDB).Create(ctx, &entity.DriveBlob{
+		UserID:    userID,
+		SHA256:    "bef57ec7f53a6d40beb640a780a639c83bc29ac8a9816f1fc6c5c6dcd93c4721",
+		Path:      "1/blob.bin",
+		Size:      6,
+		PlainSize: 6,
+		RefCount:  1,
+		CreatedAt: testTime(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// одинаковое содержимое: файл переходит на блоб, чанки удаляются
+	if err = fileRepo.AttachBlob(ctx, fileID, blob, "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+	if err = chunkRepo.DeleteByFileID(ctx, fileID); err != nil {
+		t.Fatal(err)
+	}
+
+	driveFile, err := fileRepo.GetByID(ctx, fileID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, driveFile.IsChunk)
+	assert.Equal(t, int8(1), driveFile.UploadState)
+	assert.Equal(t, &blob.ID, driveFile.BlobID)
+	assert.Equal(t, &blob.Path, driveFile.Path)
+
+	chunks, err := chunkRepo.GetByFileID(ctx, fileID)
+	if assert.NoError(t, err) {
+		assert.Empty(t, chunks)
+	}
+}
+
+func TestDriveFileChunksGetAllRecursive(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveFileChunkRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	dirID := createStruct(t, ctx, userID, "docs", 0, nil)
+	subDirID := createStruct(t, ctx, userID, "sub", 0, &dirID)
+	nestedID := createStruct(t, ctx, userID, "a.bin", 1, &subDirID)
+	keptID := createStruct(t, ctx, userID, "b.bin", 1, nil)
+	createChunkedFile(t, ctx, nestedID, 3, 1, 2)
+	createChunkedFile(t, ctx, keptID, 3, 1)
+
+	chunks, err := repo.GetAllRecursive(ctx, dirID, userID)
+	if assert.NoError(t, err) {
+		assert.Len(t, chunks, 2)
+	}
+
+	// дерево другого пользователя не выбирается
+	chunks, err = repo.GetAllRecursive(ctx, dirID, otherID)
+	if assert.NoError(t, err) {
+		assert.Empty(t, chunks)
+	}
+}
+
+func TestDriveFileGetAbandonedUploads(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveFileRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	now := testTime()
+	cutoff := now.Add(-time.Hour)
+
+	// давно начатая загрузка с недавним чанком еще активна
+	activeID := createChunkedFile(t, ctx, createStruct(t, ctx, userID, "a.bin", 1, nil), 3, 1)
+	// загрузка, где последний чанк пришел до отсечки
+	staleID := createChunkedFile(t, ctx, createStruct(t, ctx, userID, "b.bin", 1, nil), 3, 1)
+	// загрузка без чанков оценивается по времени создания
+	untouched, err := repo.Create(ctx, &entity.DriveFile{
+		DriveStructID: createStruct(t, ctx, userID, "c.bin", 1, nil),
+		Ext:           "bin",
+		CreatedAt:     now.Add(-2 * time.Hour),
+		IsChunk:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// завершенные файлы не выбираются независимо от возраста
+	completedID := createChunkedFile(t, ctx, createStruct(t, ctx, userID, "d.bin", 1, nil), 3, 1)
+	if err = repo.Complete(ctx, completedID, 3, 3, "hash", "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, at := range map[int]time.Time{
+		activeID:    now,
+		staleID:     now.Add(-2 * time.Hour),
+		completedID: now.Add(-2 * time.Hour),
+	} {
+		if err = repo.TouchActivity(ctx, id, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := repo.GetAbandonedUploads(ctx, cutoff)
+	if assert.NoError(t, err) && assert.Len(t, files, 2) {
+		assert.Equal(t, []int{staleID, untouched.ID}, []int{files[0].ID, files[1].ID})
+	}
+}
+
+func TestDriveFileGetCompleteChunkedFiles(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveFileRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+
+	// незавершенные загрузки и файлы без чанков не проверяются
+	completeIDs := make([]int, 0, 3)
+	for _, name := range []string{"a.bin", "b.bin", "c.bin"} {
+		fileID := createChunkedFile(t, ctx, createStruct(t, ctx, userID, name, 1, nil), 3, 1, 2)
+		if err := repo.Complete(ctx, fileID, 6, 6, testBlobHash, "application/octet-stream"); err != nil {
+			t.Fatal(err)
+		}
+		completeIDs = append(completeIDs, fileID)
+	}
+	createChunkedFile(t, ctx, createStruct(t, ctx, userID, "d.bin", 1, nil), 3, 1)
+	createFile(t, ctx, createStruct(t, ctx, userID, "e.txt", 1, nil), "1/e.txt", 4)
+
+	tests := []struct {
+		name        string
+		afterID     int
+		limit       int
+		expectedIDs []int
+	}{
+		{name: "first page", limit: 2, expectedIDs: completeIDs[:2]},
+		{name: "next page", afterID: completeIDs[1], limit: 2, expectedIDs: completeIDs[2:]},
+		{name: "end", afterID: completeIDs[2], limit: 2, expectedIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := repo.GetCompleteChunkedFiles(ctx, tt.afterID, tt.limit)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ids := make([]int, 0, len(files))
+			for _, driveFile := range files {
+				ids = append(ids, driveFile.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/repository"
+	"context"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// replaceFile делает текущую версию файла предыдущей с временем замены replacedAt и создает новую текущую
+func replaceFile(t *testing.T, ctx context.Context, structID int, path string, replacedAt time.Time) int {
+	t.Helper()
+	if err := repository.NewDriveFileRepository(testDB).UnsetCurrent(ctx, structID, replacedAt); err != nil {
+		t.Fatal(err)
+	}
+	return createFile(t, ctx, structID, path, 1)
+}
+
+func TestDriveFileVersions(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveFileRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	structID := createStruct(t, ctx, userID, "a.txt", 1, nil)
+
+	now := testTime()
+	v1 := createFile(t, ctx, structID, "1/v1", 1)
+	v2 := replaceFile(t, ctx, structID, "1/v2", now.Add(-2*time.Hour))
+	v3 := replaceFile(t, ctx, structID, "1/v3", now.Add(-time.Hour))
+
+	versions, err := repo.GetVersions(ctx, structID)
+	if !assert.NoError(t, err) || !assert.Len(t, versions, 3) {
+		return
+	}
+	assert.Equal(t, []int{v3, v2, v1}, []int{versions[0].ID, versions[1].ID, versions[2].ID})
+	assert.True(t, versions[0].IsCurrent)
+	assert.Nil(t, versions[0].ReplacedAt)
+
+	current, err := repo.GetByStructID(ctx, structID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, v3, current.ID)
+	}
+
+	outdated, err := repo.GetOutdatedVersionIDs(ctx, structID, 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []int{v1}, outdated)
+	}
+
+	// восстановление версии: текущая становится предыдущей в той же последовательности, что и в ucase
+	if err = repo.UnsetCurrent(ctx, structID, now); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.MarkCurrent(ctx, v1); err != nil {
+		t.Fatal(err)
+	}
+	versions, err = repo.GetVersions(ctx, structID)
+	if assert.NoError(t, err) && assert.Len(t, versions, 3) {
+		assert.Equal(t, []int{v1, v3, v2}, []int{versions[0].ID, versions[1].ID, versions[2].ID})
+		assert.Nil(t, versions[0].ReplacedAt)
+		assert.Equal(t, &now, versions[1].ReplacedAt)
+	}
+}
+
+func TestDriveFileSingleCurrentVersion(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveFileRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	structID := createStruct(t, ctx, userID, "a.txt", 1, nil)
+	otherStructID := createStruct(t, ctx, userID, "b.txt", 1, nil)
+
+	v1 := createFile(t, ctx, structID, "1/v1", 1)
+	replaceFile(t, ctx, structID, "1/v2", testTime())
+	// текущие версии разных структур не конфликтуют
+	createFile(t, ctx, otherStructID, "1/b", 1)
+
+	// частичный уникальный индекс допускает одну текущую версию на структуру
+	err := repo.MarkCurrent(ctx, v1)
+	var pgErr *pgconn.PgError
+	if assert.ErrorAs(t, err, &pgErr) {
+		assert.Equal(t, "23505", pgErr.Code)
+	}
+}
+
+func TestDriveFileGetExpiredVersionIDs(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveFileRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	firstID := createStruct(t, ctx, userID, "a.txt", 1, nil)
+	secondID := createStruct(t, ctx, userID, "b.txt", 1, nil)
+
+	now := testTime()
+	a1 := createFile(t, ctx, firstID, "1/a1", 1)
+	a2 := replaceFile(t, ctx, firstID, "1/a2", now.Add(-72*time.Hour))
+	replaceFile(t, ctx, firstID, "1/a3", now.Add(-time.Hour))
+	replaceFile(t, ctx, firstID, "1/a4", now)
+	b1 := createFile(t, ctx, secondID, "1/b1", 1)
+	replaceFile(t, ctx, secondID, "1/b2", now.Add(-48*time.Hour))
+	dayAgo := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		maxCount       int
+		replacedBefore *time.Time
+		expectedIDs    []int
+	}{
+		{name: "by count", maxCount: 1, expectedIDs: []int{a1, a2}},
+		{name: "by age", replacedBefore: &dayAgo, expectedIDs: []int{a1, b1}},
+		{name: "by count or age", maxCount: 2, replacedBefore: &dayAgo, expectedIDs: []int{a1, b1}},
+		{name: "disabled", expectedIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := repo.GetExpiredVersionIDs(ctx, tt.maxCount, tt.replacedBefore)
+			if assert.NoError(t, err) {
+				assert.ElementsMatch(t, tt.expectedIDs, ids)
+			}
+		})
+	}
+}
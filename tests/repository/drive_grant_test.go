@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// createGrant выдает granteeID роль role на структуру владельца ownerID
+func createGrant(t *testing.T, ctx context.Context, structID int, ownerID int, granteeID int, role int8) *entity.DriveGrant {
+	t.Helper()
+	grant, err := repository.NewDriveGrantRepository(testDB).Upsert(ctx, &entity.DriveGrant{
+		DriveStructID: structID,
+		OwnerID:       ownerID,
+		GranteeID:     granteeID,
+		Role:          role,
+		CreatedAt:     testTime(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grant
+}
+
+func TestDriveGrantGetRole(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveGrantRepository(testDB)
+	structRepo := repository.NewDriveStructRepository(testDB)
+	ownerID := createUser(t, ctx, "owner")
+	guestID := createUser(t, ctx, "guest")
+	strangerID := createUser(t, ctx, "stranger")
+
+	sharedID := createStruct(t, ctx, ownerID, "shared", 0, nil)
+	editableID := createStruct(t, ctx, ownerID, "editable", 0, &sharedID)
+	fileID := createStruct(t, ctx, ownerID, "a.txt", 1, &editableID)
+	privateID := createStruct(t, ctx, ownerID, "private", 0, nil)
+	trashedRootID := createStruct(t, ctx, ownerID, "trashed", 0, nil)
+	trashedFileID := createStruct(t, ctx, ownerID, "b.txt", 1, &trashedRootID)
+	createGrant(t, ctx, sharedID, ownerID, guestID, 1)
+	createGrant(t, ctx, editableID, ownerID, guestID, 2)
+	createGrant(t, ctx, trashedRootID, ownerID, guestID, 2)
+	if err := structRepo.MoveToTrash(ctx, ownerID, trashedRootID, testTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		structID    int
+		granteeID   int
+		expected    int8
+		expectedErr error
+	}{
+		{name: "granted directory", structID: sharedID, granteeID: guestID, expected: 1},
+		{name: "highest role in chain", structID: fileID, granteeID: guestID, expected: 2},
+		{name: "no grant", structID: privateID, granteeID: guestID, expectedErr: pgx.ErrNoRows},
+		{name: "other grantee", structID: fileID, granteeID: strangerID, expectedErr: pgx.ErrNoRows},
+		// грант на директорию в корзине не действует
+		{name: "trashed ancestor", structID: trashedFileID, granteeID: guestID, expectedErr: pgx.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, err := repo.GetRole(ctx, tt.structID, tt.granteeID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, role)
+			}
+		})
+	}
+}
+
+func TestDriveGrantUpsert(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveGrantRepository(testDB)
+	ownerID := createUser(t, ctx, "owner")
+	guestID := createUser(t, ctx, "guest")
+	otherID := createUser(t, ctx, "another")
+	sharedID := createStruct(t, ctx, ownerID, "shared", 0, nil)
+
+	first := createGrant(t, ctx, sharedID, ownerID, guestID, 1)
+	// повторная выдача тому же пользователю меняет роль существующего гранта
+	second := createGrant(t, ctx, sharedID, ownerID, guestID, 2)
+	assert.Equal(t, first.ID, second.ID)
+	createGrant(t, ctx, sharedID, ownerID, otherID, 1)
+
+	grants, err := repo.GetByStructID(ctx, sharedID)
+	if assert.NoError(t, err) && assert.Len(t, grants, 2) {
+		assert.Equal(t, "another", grants[0].GranteeLogin)
+		assert.Equal(t, "guest", grants[1].GranteeLogin)
+		assert.Equal(t, int8(2), grants[1].Role)
+	}
+
+	// удалить грант может только владелец
+	deleted, err := repo.DeleteByID(ctx, first.ID, guestID)
+	if assert.NoError(t, err) {
+		assert.False(t, deleted)
+	}
+	deleted, err = repo.DeleteByID(ctx, first.ID, ownerID)
+	if assert.NoError(t, err) {
+		assert.True(t, deleted)
+	}
+}
+
+func TestDriveGrantSharedWithUser(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveGrantRepository(testDB)
+	structRepo := repository.NewDriveStructRepository(testDB)
+	ownerID := createUser(t, ctx, "owner")
+	guestID := createUser(t, ctx, "guest")
+
+	sharedID := createStruct(t, ctx, ownerID, "shared", 0, nil)
+	trashedID := createStruct(t, ctx, ownerID, "trashed", 0, nil)
+	createGrant(t, ctx, sharedID, ownerID, guestID, 2)
+	createGrant(t, ctx, trashedID, ownerID, guestID, 1)
+	if err := structRepo.MoveToTrash(ctx, ownerID, trashedID, testTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := repo.SharedWithUser(ctx, guestID)
+	if assert.NoError(t, err) && assert.Len(t, list, 1) {
+		assert.Equal(t, sharedID, list[0].ID)
+		assert.Equal(t, ownerID, list[0].UserID)
+		assert.Equal(t, int8(2), *list[0].Role)
+	}
+
+	list, err = repo.SharedWithUser(ctx, ownerID)
+	if assert.NoError(t, err) {
+		assert.Empty(t, list)
+	}
+}
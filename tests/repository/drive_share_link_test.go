@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// createShareLink сохраняет ссылку in с текущим временем создания
+func createShareLink(t *testing.T, ctx context.Context, in *entity.DriveShareLink) *entity.DriveShareLink {
+	t.Helper()
+	in.CreatedAt = testTime()
+	link, err := repository.NewDriveShareLinkRepository(testDB).Create(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return link
+}
+
+func TestDriveShareLinkIncrementDownloads(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveShareLinkRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	structID := createStruct(t, ctx, userID, "a.txt", 1, nil)
+
+	maxDownloads := 2
+	limited := createShareLink(t, ctx, &entity.DriveShareLink{UserID: userID, DriveStructID: structID, Hash: "limited", MaxDownloads: &maxDownloads})
+	unlimited := createShareLink(t, ctx, &entity.DriveShareLink{UserID: userID, DriveStructID: structID, Hash: "unlimited"})
+
+	for range maxDownloads {
+		if err := repo.IncrementDownloads(ctx, limited.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// исчерпанный лимит не превышается
+	assert.ErrorIs(t, repo.IncrementDownloads(ctx, limited.ID), pgx.ErrNoRows)
+	assert.NoError(t, repo.IncrementDownloads(ctx, unlimited.ID))
+
+	link, err := repo.GetByHash(ctx, "limited")
+	if assert.NoError(t, err) {
+		assert.Equal(t, maxDownloads, link.DownloadCount)
+	}
+}
+
+func TestDriveShareLinkGetActiveByUserID(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveShareLinkRepository(testDB)
+	structRepo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+	firstID := createStruct(t, ctx, userID, "a.txt", 1, nil)
+	secondID := createStruct(t, ctx, userID, "b.txt", 1, nil)
+	trashedID := createStruct(t, ctx, userID, "c.txt", 1, nil)
+
+	now := testTime()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+	maxDownloads := 1
+	first := createShareLink(t, ctx, &entity.DriveShareLink{UserID: userID, DriveStructID: firstID, Hash: "first", ExpiresAt: &future})
+	second := createShareLink(t, ctx, &entity.DriveShareLink{UserID: userID, DriveStructID: secondID, Hash: "second"})
+	createShareLink(t, ctx, &entity.DriveShareLink{UserID: userID, DriveStructID: firstID, Hash: "expired", ExpiresAt: &past})
+	createShareLink(t, ctx, &entity.DriveShareLink{
+		UserID:        userID,
+		DriveStructID: firstID,
+		Hash:          "exhausted",
+		MaxDownloads:  &maxDownloads,
+		DownloadCount: 1,
+	})
+	createShareLink(t, ctx, &entity.DriveShareLink{UserID: userID, DriveStructID: trashedID, Hash: "trashed"})
+	if err := structRepo.MoveToTrash(ctx, userID, trashedID, now); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		userID      int
+		structID    *int
+		expectedIDs []int
+	}{
+		{name: "all", userID: userID, expectedIDs: []int{second.ID, first.ID}},
+		{name: "by struct", userID: userID, structID: &firstID, expectedIDs: []int{first.ID}},
+		{name: "other user", userID: otherID, expectedIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := repo.GetActiveByUserID(ctx, tt.userID, tt.structID, now)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ids := make([]int, 0, len(links))
+			for _, link := range links {
+				ids = append(ids, link.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestDriveShareLinkSubtree(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveShareLinkRepository(testDB)
+	structRepo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+
+	sharedID := createStruct(t, ctx, userID, "shared", 0, nil)
+	innerID := createStruct(t, ctx, userID, "inner", 0, &sharedID)
+	insideID := createStruct(t, ctx, userID, "a.txt", 1, &innerID)
+	createFile(t, ctx, insideID, "1/a.txt", 4)
+	uploadingID := createStruct(t, ctx, userID, "b.bin", 1, &sharedID)
+	createChunkedFile(t, ctx, uploadingID, 3, 1)
+	trashedDirID := createStruct(t, ctx, userID, "old", 0, &sharedID)
+	trashedInsideID := createStruct(t, ctx, userID, "c.txt", 1, &trashedDirID)
+	outsideID := createStruct(t, ctx, userID, "d.txt", 1, nil)
+	if err := structRepo.MoveToTrash(ctx, userID, trashedDirID, testTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		structID int
+		expected bool
+	}{
+		{name: "root", structID: sharedID, expected: true},
+		{name: "nested", structID: insideID, expected: true},
+		{name: "outside", structID: outsideID},
+		{name: "trashed", structID: trashedDirID},
+		{name: "inside trashed directory", structID: trashedInsideID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inSubtree, err := repo.IsInSubtree(ctx, sharedID, tt.structID)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, inSubtree)
+			}
+		})
+	}
+
+	// недозагруженные файлы и содержимое корзины не раскрываются
+	items, err := repo.SubtreeByRootID(ctx, sharedID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	assert.ElementsMatch(t, []int{sharedID, innerID, insideID}, ids)
+	for _, item := range items {
+		if item.ID == insideID {
+			assert.Equal(t, int64(4), item.Size)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// createStruct создает директорию (rowType 0) или файл (rowType 1) и возвращает его ID
+func createStruct(t *testing.T, ctx context.Context, userID int, name string, rowType int8, parentID *int) int {
+	t.Helper()
+	now := testTime()
+	driveStruct, err := repository.NewDriveStructRepository(testDB).Create(ctx, &entity.DriveStruct{
+		UserID:    userID,
+		Name:      name,
+		Type:      rowType,
+		ParentID:  parentID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return driveStruct.ID
+}
+
+// createFile создает загруженную текущую версию файла структуры
+func createFile(t *testing.T, ctx context.Context, structID int, path string, size int64) int {
+	t.Helper()
+	driveFile, err := repository.NewDriveFileRepository(testDB).Create(ctx, &entity.DriveFile{
+		DriveStructID: structID,
+		Path:          &path,
+		Ext:           "txt",
+		Size:          size,
+		PlainSize:     &size,
+		CreatedAt:     testTime(),
+		UploadState:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return driveFile.ID
+}
+
+func TestDriveStructMoveToTrash(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	dirID := createStruct(t, ctx, userID, "docs", 0, nil)
+	subDirID := createStruct(t, ctx, userID, "sub", 0, &dirID)
+	fileID := createStruct(t, ctx, userID, "a.txt", 1, &subDirID)
+	keptID := createStruct(t, ctx, userID, "b.txt", 1, nil)
+	foreignID := createStruct(t, ctx, otherID, "docs", 0, nil)
+
+	// элемент, удаленный раньше, остается отдельным корнем корзины
+	earlier := testTime().Add(-time.Hour)
+	if err := repo.MoveToTrash(ctx, userID, fileID, earlier); err != nil {
+		t.Fatal(err)
+	}
+	deletedAt := testTime()
+	if err := repo.MoveToTrash(ctx, userID, dirID, deletedAt); err != nil {
+		t.Fatal(err)
+	}
+	// чужая структура не удаляется
+	if err := repo.MoveToTrash(ctx, userID, foreignID, deletedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                string
+		structID            int
+		expectedDeletedAt   *time.Time
+		expectedTrashRootID *int
+	}{
+		{name: "root", structID: dirID, expectedDeletedAt: &deletedAt, expectedTrashRootID: &dirID},
+		{name: "nested directory", structID: subDirID, expectedDeletedAt: &deletedAt, expectedTrashRootID: &dirID},
+		{name: "deleted earlier", structID: fileID, expectedDeletedAt: &earlier, expectedTrashRootID: &fileID},
+		{name: "outside of tree", structID: keptID},
+		{name: "other user", structID: foreignID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driveStruct, err := repo.GetByID(ctx, tt.structID)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expectedDeletedAt, driveStruct.DeletedAt)
+			assert.Equal(t, tt.expectedTrashRootID, driveStruct.TrashRootID)
+		})
+	}
+}
+
+func TestDriveStructRestoreFromTrash(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+
+	dirID := createStruct(t, ctx, userID, "docs", 0, nil)
+	nestedID := createStruct(t, ctx, userID, "a.txt", 1, &dirID)
+	fileID := createStruct(t, ctx, userID, "b.txt", 1, &dirID)
+
+	if err := repo.MoveToTrash(ctx, userID, fileID, testTime().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MoveToTrash(ctx, userID, dirID, testTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RestoreFromTrash(ctx, userID, dirID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, structID := range []int{dirID, nestedID} {
+		driveStruct, err := repo.GetByID(ctx, structID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Nil(t, driveStruct.DeletedAt)
+		assert.Nil(t, driveStruct.TrashRootID)
+	}
+
+	// отдельно удаленный файл остается в корзине
+	driveStruct, err := repo.GetByID(ctx, fileID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, driveStruct.DeletedAt)
+	assert.Equal(t, &fileID, driveStruct.TrashRootID)
+}
+
+func TestDriveStructTrashByUserID(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+
+	dirID := createStruct(t, ctx, userID, "docs", 0, nil)
+	firstID := createStruct(t, ctx, userID, "a.txt", 1, &dirID)
+	createFile(t, ctx, firstID, "1/a.txt", 5)
+	secondID := createStruct(t, ctx, userID, "b.txt", 1, &dirID)
+	createFile(t, ctx, secondID, "1/b.txt", 7)
+	fileID := createStruct(t, ctx, userID, "c.txt", 1, nil)
+	createFile(t, ctx, fileID, "1/c.txt", 11)
+	createStruct(t, ctx, userID, "kept", 0, nil)
+
+	if err := repo.MoveToTrash(ctx, userID, fileID, testTime().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MoveToTrash(ctx, userID, dirID, testTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := repo.TrashByUserID(ctx, userID)
+	if !assert.NoError(t, err) || !assert.Len(t, items, 2) {
+		return
+	}
+	// только корни корзины, последние удаленные первыми, с размером всего содержимого
+	assert.Equal(t, dirID, items[0].ID)
+	assert.Equal(t, int64(12), items[0].Size)
+	assert.Equal(t, fileID, items[1].ID)
+	assert.Equal(t, int64(11), items[1].Size)
+}
+
+func TestDriveStructGetTrashRootsBefore(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+
+	oldID := createStruct(t, ctx, userID, "old", 0, nil)
+	createStruct(t, ctx, userID, "nested", 0, &oldID)
+	recentID := createStruct(t, ctx, userID, "recent", 0, nil)
+
+	now := testTime()
+	if err := repo.MoveToTrash(ctx, userID, oldID, now.Add(-48*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MoveToTrash(ctx, userID, recentID, now); err != nil {
+		t.Fatal(err)
+	}
+
+	roots, err := repo.GetTrashRootsBefore(ctx, now.Add(-24*time.Hour))
+	if !assert.NoError(t, err) || !assert.Len(t, roots, 1) {
+		return
+	}
+	assert.Equal(t, oldID, roots[0].ID)
+}
+
+func TestDriveStructDeleteRecursive(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	dirID := createStruct(t, ctx, userID, "docs", 0, nil)
+	subDirID := createStruct(t, ctx, userID, "sub", 0, &dirID)
+	fileID := createStruct(t, ctx, userID, "a.txt", 1, &subDirID)
+	driveFileID := createFile(t, ctx, fileID, "1/a.txt", 5)
+	keptID := createStruct(t, ctx, userID, "b.txt", 1, nil)
+
+	// чужой пользователь не может удалить дерево
+	if err := repo.DeleteRecursive(ctx, otherID, dirID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID(ctx, dirID); err != nil {
+		t.Fatalf("Expected directory to stay, got %v", err)
+	}
+
+	if err := repo.DeleteRecursive(ctx, userID, dirID); err != nil {
+		t.Fatal(err)
+	}
+	for _, structID := range []int{dirID, subDirID, fileID} {
+		_, err := repo.GetByID(ctx, structID)
+		assert.Error(t, err)
+	}
+	// файлы удаляются каскадом
+	_, err := repository.NewDriveFileRepository(testDB).GetByID(ctx, driveFileID)
+	assert.Error(t, err)
+
+	_, err = repo.GetByID(ctx, keptID)
+	assert.NoError(t, err)
+}
+
+func TestDriveStructSearch(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	docsID := createStruct(t, ctx, userID, "docs", 0, nil)
+	reportsID := createStruct(t, ctx, userID, "reports", 0, &docsID)
+	reportID := createStruct(t, ctx, userID, "report.txt", 1, &reportsID)
+	createFile(t, ctx, reportID, "1/report", 10)
+	percentID := createStruct(t, ctx, userID, "100%.txt", 1, nil)
+	createFile(t, ctx, percentID, "1/percent", 200)
+	underscoreID := createStruct(t, ctx, userID, "a_b.txt", 1, nil)
+	createFile(t, ctx, underscoreID, "1/underscore", 30)
+	likeID := createStruct(t, ctx, userID, "axb.txt", 1, nil)
+	createFile(t, ctx, likeID, "1/like", 40)
+	backslashID := createStruct(t, ctx, userID, `c:\tmp`, 1, nil)
+	createFile(t, ctx, backslashID, "1/backslash", 50)
+	uploadingID := createStruct(t, ctx, userID, "report-upload.txt", 1, nil)
+	createChunkedFile(t, ctx, uploadingID, 3, 1)
+	trashedID := createStruct(t, ctx, userID, "report-old.txt", 1, nil)
+	createFile(t, ctx, trashedID, "1/old", 1)
+	if err := repo.MoveToTrash(ctx, userID, trashedID, testTime()); err != nil {
+		t.Fatal(err)
+	}
+	foreignID := createStruct(t, ctx, otherID, "report.txt", 1, nil)
+	createFile(t, ctx, foreignID, "2/report", 10)
+
+	directory := int8(0)
+	minSize := int64(40)
+	tests := []struct {
+		name          string
+		in            dto.DriveSearch
+		expectedIDs   []int
+		expectedTotal int
+	}{
+		{name: "by name", in: dto.DriveSearch{Query: "REPORT"}, expectedIDs: []int{reportsID, reportID}, expectedTotal: 2},
+		// спецсимволы LIKE ищутся буквально
+		{name: "percent", in: dto.DriveSearch{Query: "100%"}, expectedIDs: []int{percentID}, expectedTotal: 1},
+		{name: "underscore", in: dto.DriveSearch{Query: "a_b"}, expectedIDs: []int{underscoreID}, expectedTotal: 1},
+		{name: "backslash", in: dto.DriveSearch{Query: `c:\tmp`}, expectedIDs: []int{backslashID}, expectedTotal: 1},
+		{name: "by type", in: dto.DriveSearch{Type: &directory}, expectedIDs: []int{docsID, reportsID}, expectedTotal: 2},
+		{name: "by size", in: dto.DriveSearch{Ext: ".TXT", MinSize: &minSize}, expectedIDs: []int{percentID, likeID, backslashID}, expectedTotal: 3},
+		// total считается без учета страницы
+		{name: "page", in: dto.DriveSearch{Query: ".txt", Page: 2, Limit: 2}, expectedIDs: []int{likeID, reportID}, expectedTotal: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.in.Page == 0 {
+				tt.in.Page, tt.in.Limit = 1, 50
+			}
+			items, total, err := repo.Search(ctx, userID, &tt.in)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ids := make([]int, 0, len(items))
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedTotal, total)
+		})
+	}
+
+	items, _, err := repo.Search(ctx, userID, &dto.DriveSearch{Query: "report.txt", Page: 1, Limit: 10})
+	if assert.NoError(t, err) && assert.Len(t, items, 1) {
+		assert.Equal(t, "/docs/reports/report.txt", items[0].Path)
+		assert.Equal(t, int64(10), items[0].Size)
+	}
+}
+
+func TestDriveStructResolvePath(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	docsID := createStruct(t, ctx, userID, "docs", 0, nil)
+	fileID := createStruct(t, ctx, userID, "a.txt", 1, &docsID)
+	archiveID := createStruct(t, ctx, userID, "archive", 0, &docsID)
+	// файл и директория с одинаковым именем: в середине пути подходит только директория
+	createStruct(t, ctx, userID, "same", 1, nil)
+	sameDirID := createStruct(t, ctx, userID, "same", 0, nil)
+	nestedID := createStruct(t, ctx, userID, "b.txt", 1, &sameDirID)
+	createStruct(t, ctx, otherID, "docs", 0, nil)
+	if err := repo.MoveToTrash(ctx, userID, archiveID, testTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		names      []string
+		expectedID int
+	}{
+		{name: "file", names: []string{"docs", "a.txt"}, expectedID: fileID},
+		{name: "directory", names: []string{"docs"}, expectedID: docsID},
+		{name: "directory before file", names: []string{"same"}, expectedID: sameDirID},
+		{name: "through directory", names: []string{"same", "b.txt"}, expectedID: nestedID},
+		{name: "missing", names: []string{"docs", "missing.txt"}},
+		{name: "through file", names: []string{"docs", "a.txt", "b"}},
+		{name: "trashed", names: []string{"docs", "archive"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := repo.ResolvePath(ctx, userID, tt.names)
+			if tt.expectedID == 0 {
+				assert.ErrorIs(t, err, pgx.ErrNoRows)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedID, item.ID)
+			}
+		})
+	}
+}
+
+func TestDriveStructAncestors(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewDriveStructRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	projectsID := createStruct(t, ctx, userID, "Projects", 0, nil)
+	yearID := createStruct(t, ctx, userID, "2026", 0, &projectsID)
+	reportID := createStruct(t, ctx, userID, "report.txt", 1, &yearID)
+	trashedID := createStruct(t, ctx, userID, "old", 0, &projectsID)
+	if err := repo.MoveToTrash(ctx, userID, trashedID, testTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		userID      int
+		structID    int
+		expectedIDs []int
+	}{
+		{name: "breadcrumbs", userID: userID, structID: reportID, expectedIDs: []int{projectsID, yearID, reportID}},
+		{name: "root", userID: userID, structID: projectsID, expectedIDs: []int{projectsID}},
+		{name: "other user", userID: otherID, structID: reportID, expectedIDs: []int{}},
+		{name: "trashed", userID: userID, structID: trashedID, expectedIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := repo.Ancestors(ctx, tt.userID, tt.structID)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ids := make([]int, 0, len(items))
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDB - пул соединений отдельной схемы, в которую применены миграции. Тесты репозиториев
+// выполняются на настоящей БД из TEST_DATABASE_URL, без нее они пропускаются
+var testDB *pgxpool.Pool
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		return m.Run()
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		fmt.Printf("Error connecting to the test database: %v\n", err)
+		return 1
+	}
+	defer conn.Close(ctx)
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err = conn.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		fmt.Printf("Error creating schema %s: %v\n", schema, err)
+		return 1
+	}
+	defer func() {
+		if _, err := conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			fmt.Printf("Error dropping schema %s: %v\n", schema, err)
+		}
+	}()
+
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		fmt.Printf("Error parsing TEST_DATABASE_URL: %v\n", err)
+		return 1
+	}
+	// public остается в пути поиска для расширений, установленных в БД до запуска тестов
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+	testDB, err = pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		fmt.Printf("Error connecting to the test database: %v\n", err)
+		return 1
+	}
+	defer testDB.Close()
+
+	if err = migrate(ctx, testDB); err != nil {
+		fmt.Printf("Error applying migrations: %v\n", err)
+		return 1
+	}
+	return m.Run()
+}
+
+// migrate применяет секции Up миграций goose в порядке их имен
+func migrate(ctx context.Context, db *pgxpool.Pool) error {
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		up, _, _ := strings.Cut(string(content), "-- +goose Down")
+		// без аргументов запрос выполняется простым протоколом, несколько команд за раз допустимы
+		if _, err = db.Exec(ctx, up); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
+
+// setupDB пропускает тест без тестовой БД, иначе очищает все таблицы схемы
+func setupDB(t *testing.T) context.Context {
+	t.Helper()
+	if testDB == nil {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	_, err := testDB.Exec(ctx, `
+		DO $$
+		DECLARE
+			tables TEXT;
+		BEGIN
+			SELECT string_agg(quote_ident(tablename), ', ') INTO tables
+			FROM pg_tables WHERE schemaname = current_schema();
+			EXECUTE 'TRUNCATE ' || tables || ' RESTART IDENTITY CASCADE';
+		END $$
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+// createUser создает пользователя и возвращает его ID
+func createUser(t *testing.T, ctx context.Context, login string) int {
+	t.Helper()
+	var id int
+	err := testDB.QueryRow(ctx, `
+		INSERT INTO users (login, password, created_at, updated_at) VALUES ($1, 'password', now(), now()) RETURNING id
+	`, login).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// testTime - время с точностью колонок TIMESTAMP(0)
+func testTime() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
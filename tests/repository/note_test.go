@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// createNote создает незашифрованную заметку в новой категории пользователя
+func createNote(t *testing.T, ctx context.Context, userID int, title string) *entity.Note {
+	t.Helper()
+	category, err := repository.NewNoteCategoryRepository(testDB).Create(ctx, entity.NoteCategory{UserId: userID, Name: title})
+	if err != nil {
+		t.Fatal(err)
+	}
+	note, err := repository.NewNoteRepository(testDB).Create(ctx, entity.Note{
+		CategoryID: category.ID,
+		NoteBlocks: json.RawMessage(`[]`),
+		CreatedAt:  testTime(),
+		UpdatedAt:  testTime(),
+		Title:      &title,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return note
+}
+
+func TestNoteGetStale(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewNoteRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	oldKey := createDataKey(t, ctx, userID, "m1")
+	if _, err := repository.NewDriveDataKeyRepository(testDB).DeactivateAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	activeKey := createDataKey(t, ctx, userID, "m1")
+
+	// незашифрованная заметка, заметка со старым ключом, с активным ключом и заметка пользователя без ключа
+	plain := createNote(t, ctx, userID, "plain")
+	old := createNote(t, ctx, userID, "old")
+	setDataKey(t, ctx, "notes", old.ID, oldKey.ID)
+	current := createNote(t, ctx, userID, "current")
+	setDataKey(t, ctx, "notes", current.ID, activeKey.ID)
+	other := createNote(t, ctx, otherID, "other")
+
+	tests := []struct {
+		name           string
+		afterID        int
+		limit          int
+		expectedIDs    []int
+		expectedOwners []int
+	}{
+		{name: "all", limit: 10, expectedIDs: []int{plain.ID, old.ID, other.ID}, expectedOwners: []int{userID, userID, otherID}},
+		{name: "limit", limit: 1, expectedIDs: []int{plain.ID}, expectedOwners: []int{userID}},
+		{name: "after id", afterID: old.ID, limit: 10, expectedIDs: []int{other.ID}, expectedOwners: []int{otherID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notes, err := repo.GetStale(ctx, tt.afterID, tt.limit)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ids := make([]int, 0, len(notes))
+			owners := make([]int, 0, len(notes))
+			for _, note := range notes {
+				ids = append(ids, note.ID)
+				owners = append(owners, note.UserID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedOwners, owners)
+		})
+	}
+}
+
+func TestNoteReplaceEncrypted(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewNoteRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	dataKey := createDataKey(t, ctx, userID, "m1")
+	note := createNote(t, ctx, userID, "title")
+
+	sealed := *note
+	sealed.NoteBlocks, sealed.Title = nil, nil
+	sealed.EncryptedBlocks, sealed.EncryptedTitle = []byte("blocks"), []byte("title")
+	sealed.DataKeyID = &dataKey.ID
+
+	tests := []struct {
+		name              string
+		in                *entity.Note
+		previousDataKeyID *int
+		expected          bool
+	}{
+		{
+			// заметка обновлена после чтения: перешифрованное содержимое устарело
+			name:     "updated",
+			in:       &entity.Note{ID: note.ID, UpdatedAt: note.UpdatedAt.Add(-time.Second), DataKeyID: &dataKey.ID},
+			expected: false,
+		},
+		{
+			name:              "encrypted by other key",
+			in:                &sealed,
+			previousDataKeyID: &dataKey.ID,
+			expected:          false,
+		},
+		{name: "replaced", in: &sealed, expected: true},
+		// заметка уже зашифрована: повторная замена с прежним ключом не выполняется
+		{name: "already replaced", in: &sealed, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replaced, err := repo.ReplaceEncrypted(ctx, tt.in, tt.previousDataKeyID)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, replaced)
+			}
+		})
+	}
+
+	stored, err := repo.GetById(ctx, note.ID)
+	if assert.NoError(t, err) {
+		assert.Nil(t, stored.NoteBlocks)
+		assert.Nil(t, stored.Title)
+		assert.Equal(t, []byte("blocks"), stored.EncryptedBlocks)
+		assert.Equal(t, []byte("title"), stored.EncryptedTitle)
+		assert.Equal(t, &dataKey.ID, stored.DataKeyID)
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPendingObjectGetOlder(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewPendingObjectRepository(testDB)
+
+	now := testTime()
+	created := map[string]time.Time{
+		"drive/1/active":  now,
+		"drive/1/newer":   now.Add(-2 * time.Hour),
+		"drive/1/oldest":  now.Add(-4 * time.Hour),
+		"drive/1/older":   now.Add(-3 * time.Hour),
+		"files/1/old.pdf": now.Add(-5 * time.Hour),
+	}
+	for key, createdAt := range created {
+		if err := repo.Create(ctx, key, createdAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// ключ отмечается один раз
+	assert.Error(t, repo.Create(ctx, "drive/1/active", now))
+
+	tests := []struct {
+		name         string
+		before       time.Time
+		limit        int
+		expectedKeys []string
+	}{
+		{
+			name:         "oldest first",
+			before:       now.Add(-time.Hour),
+			limit:        10,
+			expectedKeys: []string{"files/1/old.pdf", "drive/1/oldest", "drive/1/older", "drive/1/newer"},
+		},
+		{name: "limit", before: now.Add(-time.Hour), limit: 2, expectedKeys: []string{"files/1/old.pdf", "drive/1/oldest"}},
+		{name: "nothing older", before: now.Add(-6 * time.Hour), limit: 10, expectedKeys: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := repo.GetOlder(ctx, tt.before, tt.limit)
+			if !assert.NoError(t, err) {
+				return
+			}
+			keys := make([]string, 0, len(list))
+			for _, object := range list {
+				assert.Equal(t, created[object.StorageKey], object.CreatedAt.UTC())
+				keys = append(keys, object.StorageKey)
+			}
+			assert.Equal(t, tt.expectedKeys, keys)
+		})
+	}
+}
+
+func TestPendingObjectDelete(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewPendingObjectRepository(testDB)
+
+	before := testTime().Add(time.Hour)
+	for _, key := range []string{"drive/1/a", "drive/1/b", "drive/1/c"} {
+		if err := repo.Create(ctx, key, testTime()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// без ключей ничего не удаляется, отсутствующие ключи пропускаются
+	if err := repo.Delete(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, "drive/1/a", "drive/1/c", "drive/1/missing"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := repo.GetOlder(ctx, before, 10)
+	if assert.NoError(t, err) && assert.Len(t, list, 1) {
+		assert.Equal(t, "drive/1/b", list[0].StorageKey)
+	}
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStorageMigrateGetObjects(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewStorageMigrateRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+
+	// файл с блобом и чанковый файл ссылаются на объекты блоба и чанков и сами не выбираются
+	blob := createBlob(t, ctx, userID, testBlobHash)
+	_, err := repository.NewDriveFileRepository(testDB).Create(ctx, &entity.DriveFile{
+		DriveStructID: createStruct(t, ctx, userID, "a.txt", 1, nil),
+		Path:          &blob.Path,
+		Ext:           "txt",
+		Size:          blob.Size,
+		BlobID:        &blob.ID,
+		CreatedAt:     testTime(),
+		UploadState:   1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	createFile(t, ctx, createStruct(t, ctx, userID, "b.txt", 1, nil), "1/b.txt", 2)
+	secondID := createFile(t, ctx, createStruct(t, ctx, userID, "c.txt", 1, nil), "1/c.txt", 3)
+	createFile(t, ctx, createStruct(t, ctx, userID, "d.txt", 1, nil), "1/d.txt", 4)
+	createChunkedFile(t, ctx, createStruct(t, ctx, userID, "e.bin", 1, nil), 3, 1, 2)
+
+	tests := []struct {
+		name          string
+		kind          string
+		afterID       int
+		limit         int
+		expectedPaths []string
+		expectedCount int
+	}{
+		{name: "blobs", kind: repository.StorageObjectDriveBlobs, limit: 10, expectedPaths: []string{blob.Path}, expectedCount: 1},
+		{
+			name:          "files without blob",
+			kind:          repository.StorageObjectDriveFiles,
+			limit:         2,
+			expectedPaths: []string{"1/b.txt", "1/c.txt"},
+			expectedCount: 3,
+		},
+		{
+			name:          "files after id",
+			kind:          repository.StorageObjectDriveFiles,
+			afterID:       secondID,
+			limit:         10,
+			expectedPaths: []string{"1/d.txt"},
+			expectedCount: 1,
+		},
+		{name: "chunks", kind: repository.StorageObjectDriveChunks, limit: 10, expectedCount: 2},
+		{name: "note files", kind: repository.StorageObjectFiles, limit: 10, expectedPaths: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := repo.GetObjects(ctx, tt.kind, tt.afterID, tt.limit)
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tt.expectedPaths != nil {
+				paths := make([]string, 0, len(objects))
+				for _, object := range objects {
+					assert.Equal(t, tt.kind, object.Kind)
+					paths = append(paths, object.Path)
+				}
+				assert.Equal(t, tt.expectedPaths, paths)
+			}
+
+			count, err := repo.CountObjects(ctx, tt.kind, tt.afterID)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedCount, count)
+			}
+		})
+	}
+}
+
+func TestStorageMigrateProgress(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewStorageMigrateRepository(testDB)
+
+	for _, lastID := range []int{5, 7} {
+		err := repo.SaveProgress(ctx, "local", "s3", repository.StorageObjectDriveBlobs, lastID, testTime())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.SaveProgress(ctx, "local", "s3", repository.StorageObjectFiles, 3, testTime()); err != nil {
+		t.Fatal(err)
+	}
+	// прогресс ведется отдельно для каждой пары хранилищ
+	if err := repo.SaveProgress(ctx, "s3", "local", repository.StorageObjectFiles, 9, testTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err := repo.GetProgress(ctx, "local", "s3")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]int{repository.StorageObjectDriveBlobs: 7, repository.StorageObjectFiles: 3}, progress)
+	}
+
+	if err = repo.ResetProgress(ctx, "local", "s3"); err != nil {
+		t.Fatal(err)
+	}
+	progress, err = repo.GetProgress(ctx, "local", "s3")
+	if assert.NoError(t, err) {
+		assert.Empty(t, progress)
+	}
+	progress, err = repo.GetProgress(ctx, "s3", "local")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]int{repository.StorageObjectFiles: 9}, progress)
+	}
+}
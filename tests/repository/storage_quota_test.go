@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStorageQuotaGetLimits(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewStorageQuotaRepository(testDB)
+	personalID := createUser(t, ctx, "personal")
+	planOnlyID := createUser(t, ctx, "plan")
+	withoutPlanID := createUser(t, ctx, "without-plan")
+	defaultID := createUser(t, ctx, "default")
+
+	plan, err := repo.SavePlan(ctx, &entity.StoragePlan{Name: "basic", DriveLimit: 100, FilesLimit: 10, UpdatedAt: testTime()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// повторное сохранение тарифа с тем же названием меняет лимиты
+	updated, err := repo.SavePlan(ctx, &entity.StoragePlan{Name: "basic", DriveLimit: 200, FilesLimit: 20, UpdatedAt: testTime()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, plan.ID, updated.ID)
+
+	driveLimit := int64(50)
+	quotas := []*entity.UserStorageQuota{
+		{UserID: personalID, PlanID: &plan.ID, DriveLimit: &driveLimit},
+		{UserID: planOnlyID, PlanID: &plan.ID},
+		{UserID: withoutPlanID, DriveLimit: &driveLimit},
+	}
+	for _, quota := range quotas {
+		quota.UpdatedAt = testTime()
+		if err = repo.SaveUserQuota(ctx, quota); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	planName := "basic"
+	planDrive := int64(200)
+	planFiles := int64(20)
+	tests := []struct {
+		name        string
+		userID      int
+		expected    *entity.StorageLimits
+		expectedErr error
+	}{
+		{
+			// персональное значение важнее лимита тарифа
+			name:     "personal",
+			userID:   personalID,
+			expected: &entity.StorageLimits{PlanName: &planName, DriveLimit: &driveLimit, FilesLimit: &planFiles},
+		},
+		{
+			name:     "plan",
+			userID:   planOnlyID,
+			expected: &entity.StorageLimits{PlanName: &planName, DriveLimit: &planDrive, FilesLimit: &planFiles},
+		},
+		{
+			name:     "without plan",
+			userID:   withoutPlanID,
+			expected: &entity.StorageLimits{DriveLimit: &driveLimit},
+		},
+		{name: "without quota", userID: defaultID, expectedErr: pgx.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := repo.GetLimits(ctx, tt.userID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, limits)
+			}
+		})
+	}
+
+	// при удалении тарифа пользователи остаются только с персональными лимитами
+	if _, err = testDB.Exec(ctx, `DELETE FROM storage_plans WHERE id = $1`, plan.ID); err != nil {
+		t.Fatal(err)
+	}
+	limits, err := repo.GetLimits(ctx, personalID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &entity.StorageLimits{DriveLimit: &driveLimit}, limits)
+	}
+}
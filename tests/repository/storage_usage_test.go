@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStorageUsageAdd(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewStorageUsageRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+
+	_, err := repo.Get(ctx, userID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// строка создается при первом изменении, отрицательный результат обрезается до нуля
+	if err = repo.Add(ctx, userID, 5, -3); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.Add(ctx, userID, -10, 4); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := repo.Get(ctx, userID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &entity.StorageUsage{UserID: userID, DriveUsed: 0, FilesUsed: 4}, usage)
+	}
+}
+
+func TestStorageUsageLock(t *testing.T) {
+	ctx := setupDB(t)
+	userID := createUser(t, ctx, "owner")
+
+	first, err := testDB.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Rollback(ctx)
+
+	// строки счетчиков еще нет: Lock создает ее с нулями
+	usage, err := repository.NewStorageUsageRepository(first).Lock(ctx, userID)
+	if !assert.NoError(t, err) || !assert.Equal(t, int64(0), usage.DriveUsed) {
+		return
+	}
+
+	// вторая транзакция ждет, пока первая не зафиксирует свое резервирование, и видит его
+	locked := make(chan *entity.StorageUsage, 1)
+	go func() {
+		second, err := testDB.Begin(ctx)
+		if err != nil {
+			locked <- nil
+			return
+		}
+		defer second.Rollback(ctx)
+		usage, err := repository.NewStorageUsageRepository(second).Lock(ctx, userID)
+		if err != nil {
+			locked <- nil
+			return
+		}
+		locked <- usage
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("Expected the second lock to wait for the first transaction")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err = repository.NewStorageUsageRepository(first).Add(ctx, userID, 8, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err = first.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case usage = <-locked:
+		if assert.NotNil(t, usage) {
+			assert.Equal(t, int64(8), usage.DriveUsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the second lock after commit")
+	}
+}
+
+func TestStorageUsageRecalculate(t *testing.T) {
+	ctx := setupDB(t)
+	repo := repository.NewStorageUsageRepository(testDB)
+	driveFileRepo := repository.NewDriveFileRepository(testDB)
+	userID := createUser(t, ctx, "owner")
+	otherID := createUser(t, ctx, "other")
+
+	// файл без блоба, два файла с одним блобом (учитывается один раз), чанки и вложение заметки
+	createFile(t, ctx, createStruct(t, ctx, userID, "a.txt", 1, nil), "1/a.txt", 4)
+	blob := createBlob(t, ctx, userID, testBlobHash)
+	for _, name := range []string{"b.txt", "c.txt"} {
+		_, err := driveFileRepo.Create(ctx, &entity.DriveFile{
+			DriveStructID: createStruct(t, ctx, userID, name, 1, nil),
+			Path:          &blob.Path,
+			Ext:           "txt",
+			Size:          blob.Size,
+			PlainSize:     &blob.PlainSize,
+			BlobID:        &blob.ID,
+			CreatedAt:     testTime(),
+			UploadState:   1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	createChunkedFile(t, ctx, createStruct(t, ctx, userID, "d.bin", 1, nil), 3, 1, 2)
+	_, err := repository.NewFileRepository(testDB).Create(ctx, &entity.File{
+		UserID:           userID,
+		OriginalFilename: "note.pdf",
+		FilePath:         "1/note.pdf",
+		Ext:              "pdf",
+		Size:             7,
+		Hash:             "note",
+		CreatedAt:        testTime(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.Add(ctx, userID, 100, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := repo.Recalculate(ctx, &userID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, count)
+	}
+	usage, err := repo.Get(ctx, userID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &entity.StorageUsage{UserID: userID, DriveUsed: 4 + 5 + 6, FilesUsed: 7}, usage)
+	}
+	_, err = repo.Get(ctx, otherID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// без пользователя пересчитываются все, в том числе пользователи без данных
+	count, err = repo.Recalculate(ctx, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, count)
+	}
+	usage, err = repo.Get(ctx, otherID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, &entity.StorageUsage{UserID: otherID}, usage)
+	}
+}
@@ -0,0 +1,186 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// testChunk - чанк 20-го файла и его содержимое в хранилище
+func testChunk(number int, data []byte) (*entity.DriveFileChunk, []byte) {
+	size := int64(len(data))
+	return &entity.DriveFileChunk{
+		ID:          30 + number,
+		DriveFileID: 20,
+		Path:        "1/a.txt." + string(rune('0'+number)),
+		Size:        size,
+		PlainSize:   &size,
+		ChunkNumber: number,
+	}, data
+}
+
+func TestDriveChunkEnd(t *testing.T) {
+	user := &entity.User{ID: 1}
+	chunk1, data1 := testChunk(1, []byte("abc"))
+	chunk2, data2 := testChunk(2, []byte("def"))
+	chunk3, data3 := testChunk(3, []byte("ghi"))
+	chunk0, data0 := testChunk(0, []byte("abc"))
+	size := func(size int64) *int64 {
+		return &size
+	}
+	hash := func(data string) *string {
+		hash := sha256Hex([]byte(data))
+		return &hash
+	}
+
+	tests := []struct {
+		name         string
+		expectedSize *int64
+		clientHash   *string
+		chunks       []*entity.DriveFileChunk
+		contents     [][]byte
+		mockSetup    func(repos *mockRepositories)
+		expectedErr  error
+	}{
+		{
+			name:         "complete",
+			expectedSize: size(9),
+			chunks:       []*entity.DriveFileChunk{chunk1, chunk2, chunk3},
+			contents:     [][]byte{data1, data2, data3},
+			mockSetup: func(repos *mockRepositories) {
+				repos.blobs.EXPECT().GetByHash(mock.Anything, user.ID, sha256Hex([]byte("abcdefghi"))).Return(nil, pgx.ErrNoRows)
+				repos.driveFiles.EXPECT().Complete(mock.Anything, 20, int64(9), int64(9), sha256Hex([]byte("abcdefghi")), mock.Anything).
+					Return(nil)
+			},
+		},
+		{
+			name:         "numbering from zero",
+			expectedSize: size(6),
+			chunks:       []*entity.DriveFileChunk{chunk0, chunk1},
+			contents:     [][]byte{data0, data1},
+			mockSetup: func(repos *mockRepositories) {
+				repos.blobs.EXPECT().GetByHash(mock.Anything, user.ID, sha256Hex([]byte("abcabc"))).Return(nil, pgx.ErrNoRows)
+				repos.driveFiles.EXPECT().Complete(mock.Anything, 20, int64(6), int64(6), sha256Hex([]byte("abcabc")), mock.Anything).
+					Return(nil)
+			},
+		},
+		{
+			name:         "empty file",
+			expectedSize: size(0),
+			mockSetup: func(repos *mockRepositories) {
+				repos.blobs.EXPECT().GetByHash(mock.Anything, user.ID, sha256Hex(nil)).Return(nil, pgx.ErrNoRows)
+				repos.driveFiles.EXPECT().Complete(mock.Anything, 20, int64(0), int64(0), sha256Hex(nil), mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:         "sequence gap",
+			expectedSize: size(6),
+			chunks:       []*entity.DriveFileChunk{chunk1, chunk3},
+			expectedErr:  ucase.ErrDriveChunksSequenceBroken,
+		},
+		{
+			name:         "sequence starts from third chunk",
+			expectedSize: size(3),
+			chunks:       []*entity.DriveFileChunk{chunk3},
+			expectedErr:  ucase.ErrDriveChunksSequenceBroken,
+		},
+		{
+			name:        "no chunks",
+			expectedErr: ucase.ErrDriveChunksSequenceBroken,
+		},
+		{
+			name:         "size mismatch",
+			expectedSize: size(10),
+			chunks:       []*entity.DriveFileChunk{chunk1, chunk2},
+			expectedErr:  ucase.ErrDriveFileSizeMismatch,
+		},
+		{
+			// отклоненная загрузка единственной версии удаляется вместе со структурой
+			name:         "hash mismatch",
+			expectedSize: size(3),
+			clientHash:   hash("expected"),
+			chunks:       []*entity.DriveFileChunk{chunk1},
+			contents:     [][]byte{data1},
+			mockSetup: func(repos *mockRepositories) {
+				repos.driveFiles.EXPECT().GetVersions(mock.Anything, 10).Return([]*entity.DriveFile{{ID: 20, DriveStructID: 10}}, nil)
+				repos.chunks.EXPECT().GetAllRecursive(mock.Anything, 10, user.ID).Return([]*entity.DriveFileChunk{chunk1}, nil)
+				repos.driveFiles.EXPECT().GetAllRecursive(mock.Anything, 10, user.ID).
+					Return([]*entity.DriveFile{{ID: 20, DriveStructID: 10, Ext: "txt", IsChunk: true}}, nil)
+				repos.structs.EXPECT().DeleteRecursive(mock.Anything, user.ID, 10).Return(nil)
+				repos.usage.EXPECT().Add(mock.Anything, user.ID, int64(-3), int64(0)).Return(nil)
+				repos.storage.EXPECT().DeleteAll(mock.Anything, []string{"drive/1/a.txt.1"}).Return(nil)
+			},
+			expectedErr: ucase.ErrDriveFileHashMismatch,
+		},
+		{
+			// такое содержимое у пользователя уже есть: чанки удаляются, файл ссылается на блоб
+			name:         "deduplicated",
+			expectedSize: size(6),
+			clientHash:   hash("abcdef"),
+			chunks:       []*entity.DriveFileChunk{chunk1, chunk2},
+			contents:     [][]byte{data1, data2},
+			mockSetup: func(repos *mockRepositories) {
+				blob := &entity.DriveBlob{ID: 40, UserID: user.ID, SHA256: sha256Hex([]byte("abcdef")), Path: "1/blob", Size: 6, PlainSize: 6, RefCount: 1}
+				repos.blobs.EXPECT().GetByHash(mock.Anything, user.ID, blob.SHA256).Return(blob, nil)
+				repos.blobs.EXPECT().IncrementRef(mock.Anything, 40).Return(nil)
+				repos.driveFiles.EXPECT().AttachBlob(mock.Anything, 20, blob, mock.Anything).Return(nil)
+				repos.chunks.EXPECT().DeleteByFileID(mock.Anything, 20).Return(nil)
+				repos.usage.EXPECT().Add(mock.Anything, user.ID, int64(-6), int64(0)).Return(nil)
+				repos.storage.EXPECT().DeleteAll(mock.Anything, []string{"drive/1/a.txt.1", "drive/1/a.txt.2"}).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(&entity.DriveStruct{ID: 10, UserID: user.ID, Name: "a.txt", Type: 1}, nil)
+			repos.driveFiles.EXPECT().GetByStructID(mock.Anything, 10).Return(&entity.DriveFile{
+				ID:            20,
+				DriveStructID: 10,
+				Ext:           "txt",
+				IsChunk:       true,
+				IsCurrent:     true,
+				SHA256:        tt.clientHash,
+				ExpectedSize:  tt.expectedSize,
+			}, nil)
+			repos.chunks.EXPECT().GetByFileID(mock.Anything, 20).Return(tt.chunks, nil)
+			for i, content := range tt.contents {
+				repos.storage.EXPECT().GetFile(mock.Anything, "drive/"+tt.chunks[i].Path).Return(bytes.NewReader(content), nil)
+			}
+			if tt.mockSetup != nil {
+				tt.mockSetup(repos)
+			}
+
+			err := ucase.NewDriveUseCase(repos.repos).ChunkEnd(testContext(), user, dto.DriveChunkEndIn{StructID: 10, SavePath: "drive"})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDriveChunkEndCompleted(t *testing.T) {
+	user := &entity.User{ID: 1}
+	repos := newMockRepositories(t)
+	repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(&entity.DriveStruct{ID: 10, UserID: user.ID, Name: "a.txt", Type: 1}, nil)
+	repos.driveFiles.EXPECT().GetByStructID(mock.Anything, 10).
+		Return(&entity.DriveFile{ID: 20, DriveStructID: 10, IsChunk: true, IsCurrent: true, UploadState: 1}, nil)
+
+	err := ucase.NewDriveUseCase(repos.repos).ChunkEnd(testContext(), user, dto.DriveChunkEndIn{StructID: 10, SavePath: "drive"})
+	assert.ErrorIs(t, err, ucase.ErrDriveUploadCompleted)
+}
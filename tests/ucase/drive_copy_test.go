@@ -0,0 +1,209 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func copyIn(structIDs []int, parentID *int, limit int64) dto.DriveCopyIn {
+	return dto.DriveCopyIn{
+		DriveCopy:             dto.DriveCopy{StructIDs: structIDs, ParentID: parentID},
+		SavePath:              testSavePath,
+		StorageMaxSizePerUser: limit,
+	}
+}
+
+// expectCopySource настраивает моки на файл пользователя userID в корне
+func expectCopySource(repos *mockRepositories, userID int, driveStruct *entity.DriveStruct, driveFile *entity.DriveFile) {
+	driveStruct.UserID = userID
+	repos.structs.EXPECT().GetByID(mock.Anything, driveStruct.ID).Return(driveStruct, nil)
+	repos.driveFiles.EXPECT().GetByStructID(mock.Anything, driveStruct.ID).Return(driveFile, nil)
+}
+
+// expectCopyQuota настраивает моки на проверку квоты без персональных лимитов при занятых used байтах
+func expectCopyQuota(repos *mockRepositories, userID int, used int64) {
+	repos.quota.EXPECT().GetLimits(mock.Anything, userID).Return(nil, pgx.ErrNoRows)
+	repos.usage.EXPECT().Get(mock.Anything, userID).Return(&entity.StorageUsage{UserID: userID, DriveUsed: used}, nil)
+}
+
+func TestDriveCopy(t *testing.T) {
+	user := &entity.User{ID: 1}
+	guest := &entity.User{ID: grantGuestID}
+	sharedID := grantSharedID
+	dirID := 3
+	hash := sha256Hex([]byte("guest data"))
+	plainPath := "1/a.txt"
+	plainSize := int64(3)
+
+	ownerBlob := &entity.DriveBlob{ID: 40, UserID: user.ID, SHA256: hash, Path: "1/blob", Size: 10, PlainSize: 10}
+	guestBlob := &entity.DriveBlob{ID: 41, UserID: grantGuestID, SHA256: hash, Path: "2/blob", Size: 10, PlainSize: 10}
+	blobFile := &entity.DriveFile{ID: 20, Ext: "txt", Size: 10, SHA256: &hash, BlobID: &guestBlob.ID, UploadState: 1}
+	chunkedFile := &entity.DriveFile{ID: 21, Ext: "bin", Size: 6, IsChunk: true, UploadState: 1}
+	chunks := []*entity.DriveFileChunk{
+		{ID: 30, DriveFileID: 21, Path: "1/part_1", Size: 3, PlainSize: &plainSize, ChunkNumber: 1},
+		{ID: 31, DriveFileID: 21, Path: "1/part_2", Size: 3, PlainSize: &plainSize, ChunkNumber: 2},
+	}
+
+	tests := []struct {
+		name        string
+		user        *entity.User
+		in          dto.DriveCopyIn
+		mockSetup   func(t *testing.T, repos *mockRepositories)
+		expectedErr error
+	}{
+		{
+			name: "into oneself",
+			user: user,
+			in:   copyIn([]int{2}, &dirID, 100),
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				repos.structs.EXPECT().GetByID(mock.Anything, dirID).Return(&entity.DriveStruct{ID: dirID, UserID: user.ID}, nil)
+				repos.structs.EXPECT().Ancestors(mock.Anything, user.ID, dirID).
+					Return([]*dto.DrivePathItem{{ID: 2, Name: "docs"}, {ID: dirID, Name: "inner"}}, nil)
+			},
+			expectedErr: ucase.ErrDriveCopyIntoOneself,
+		},
+		{
+			name: "incomplete upload",
+			user: user,
+			in:   copyIn([]int{10}, nil, 100),
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				expectCopySource(repos, user.ID, &entity.DriveStruct{ID: 10, Name: "big.bin", Type: 1}, &entity.DriveFile{ID: 21, IsChunk: true})
+			},
+			expectedErr: ucase.ErrFileNotFound,
+		},
+		{
+			// квота проверяется по всему поддереву до записи: ни объекты, ни записи не создаются
+			name: "quota exceeded",
+			user: user,
+			in:   copyIn([]int{10}, nil, 100),
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				expectCopySource(repos, user.ID, &entity.DriveStruct{ID: 10, Name: "big.bin", Type: 1}, chunkedFile)
+				repos.chunks.EXPECT().GetByFileID(mock.Anything, chunkedFile.ID).Return(chunks, nil)
+				expectCopyQuota(repos, user.ID, 95)
+			},
+			expectedErr: ucase.ErrDriveFileSystemIsFull,
+		},
+		{
+			// у владельца уже есть такое содержимое: копия ссылается на тот же блоб и места не требует
+			name: "shares owner blob",
+			user: user,
+			in:   copyIn([]int{10}, nil, 100),
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				expectCopySource(repos, user.ID, &entity.DriveStruct{ID: 10, Name: "g.txt", Type: 1}, blobFile)
+				repos.blobs.EXPECT().GetByHash(mock.Anything, user.ID, hash).Return(ownerBlob, nil)
+				expectCopyQuota(repos, user.ID, 100)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "g.txt", int8(1), (*int)(nil)).Return(&entity.DriveStruct{ID: 10}, nil)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "g (1).txt", int8(1), (*int)(nil)).Return(nil, pgx.ErrNoRows)
+				repos.blobs.EXPECT().IncrementRef(mock.Anything, ownerBlob.ID).Return(nil)
+				repos.structs.EXPECT().Create(mock.Anything, mock.MatchedBy(func(in *entity.DriveStruct) bool {
+					return in.Name == "g (1).txt" && in.UserID == user.ID
+				})).Return(nil, nil)
+				repos.driveFiles.EXPECT().Create(mock.Anything, mock.MatchedBy(func(in *entity.DriveFile) bool {
+					return *in.BlobID == ownerBlob.ID && *in.Path == ownerBlob.Path
+				})).Return(nil, nil)
+				repos.pending.EXPECT().Delete(mock.Anything).Return(nil)
+				repos.structs.EXPECT().TreeByUserID(mock.Anything, user.ID, (*int)(nil)).Return(nil, nil)
+			},
+		},
+		{
+			// копия в директорию гранта создается и учитывается на диске владельца
+			name: "charged to owner",
+			user: guest,
+			in:   copyIn([]int{grantGuestFileID}, &sharedID, 100),
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				expectGrantTree(repos, ucase.DriveRoleEditor)
+				repos.structs.EXPECT().Ancestors(mock.Anything, grantOwnerID, sharedID).
+					Return([]*dto.DrivePathItem{{ID: sharedID, Name: "shared"}}, nil)
+				repos.driveFiles.EXPECT().GetByStructID(mock.Anything, grantGuestFileID).Return(blobFile, nil)
+				repos.blobs.EXPECT().GetByHash(mock.Anything, grantOwnerID, hash).Return(nil, pgx.ErrNoRows)
+				repos.blobs.EXPECT().GetByHash(mock.Anything, grantGuestID, hash).Return(guestBlob, nil)
+				expectCopyQuota(repos, grantOwnerID, 90)
+				repos.structs.EXPECT().FindRow(mock.Anything, grantOwnerID, "g.txt", int8(1), &sharedID).Return(nil, pgx.ErrNoRows)
+				repos.pending.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				repos.storage.EXPECT().Copy(mock.Anything, "drive/2/blob", mock.Anything).Return(nil)
+				repos.usage.EXPECT().Lock(mock.Anything, grantOwnerID).Return(&entity.StorageUsage{UserID: grantOwnerID, DriveUsed: 90}, nil)
+				repos.usage.EXPECT().Add(mock.Anything, grantOwnerID, int64(10), int64(0)).Return(nil)
+				repos.blobs.EXPECT().Create(mock.Anything, mock.MatchedBy(func(in *entity.DriveBlob) bool {
+					return in.UserID == grantOwnerID && in.SHA256 == hash && in.Path != guestBlob.Path && in.RefCount == 1
+				})).Return(nil, nil)
+				repos.structs.EXPECT().Create(mock.Anything, mock.MatchedBy(func(in *entity.DriveStruct) bool {
+					return in.UserID == grantOwnerID && *in.ParentID == sharedID
+				})).Return(nil, nil)
+				repos.driveFiles.EXPECT().Create(mock.Anything, mock.Anything).Return(nil, nil)
+				repos.pending.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil)
+				repos.structs.EXPECT().TreeByUserID(mock.Anything, grantOwnerID, &sharedID).Return(nil, nil)
+			},
+		},
+		{
+			name: "chunked file",
+			user: user,
+			in:   copyIn([]int{10}, nil, 100),
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				expectCopySource(repos, user.ID, &entity.DriveStruct{ID: 10, Name: "big.bin", Type: 1}, chunkedFile)
+				repos.chunks.EXPECT().GetByFileID(mock.Anything, chunkedFile.ID).Return(chunks, nil)
+				expectCopyQuota(repos, user.ID, 94)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "big.bin", int8(1), (*int)(nil)).Return(nil, pgx.ErrNoRows)
+				repos.pending.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(2)
+				repos.storage.EXPECT().Copy(mock.Anything, "drive/1/part_1", mock.Anything).Return(nil)
+				repos.storage.EXPECT().Copy(mock.Anything, "drive/1/part_2", mock.Anything).Return(nil)
+				repos.usage.EXPECT().Lock(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID, DriveUsed: 94}, nil)
+				repos.usage.EXPECT().Add(mock.Anything, user.ID, int64(6), int64(0)).Return(nil)
+				repos.structs.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.DriveStruct) (*entity.DriveStruct, error) {
+					in.ID = 100
+					return in, nil
+				})
+				repos.driveFiles.EXPECT().Create(mock.Anything, mock.MatchedBy(func(in *entity.DriveFile) bool {
+					return in.DriveStructID == 100 && in.IsChunk && in.Path == nil
+				})).RunAndReturn(func(_ context.Context, in *entity.DriveFile) (*entity.DriveFile, error) {
+					in.ID = 101
+					return in, nil
+				})
+				repos.chunks.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.DriveFileChunk) (*entity.DriveFileChunk, error) {
+					assert.Equal(t, 101, in.DriveFileID)
+					assert.NotEqual(t, "1/part_1", in.Path)
+					return in, nil
+				}).Times(2)
+				repos.pending.EXPECT().Delete(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				repos.structs.EXPECT().TreeByUserID(mock.Anything, user.ID, (*int)(nil)).Return(nil, nil)
+			},
+		},
+		{
+			// место заняли параллельно после проверки квоты: скопированный объект удаляется
+			name: "quota exceeded on write",
+			user: user,
+			in:   copyIn([]int{10}, nil, 100),
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				expectCopySource(repos, user.ID, &entity.DriveStruct{ID: 10, Name: "a.txt", Type: 1},
+					&entity.DriveFile{ID: 22, Ext: "txt", Size: 3, Path: &plainPath, UploadState: 1})
+				expectCopyQuota(repos, user.ID, 90)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "a.txt", int8(1), (*int)(nil)).Return(nil, pgx.ErrNoRows)
+				repos.pending.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				repos.storage.EXPECT().Copy(mock.Anything, "drive/1/a.txt", mock.Anything).Return(nil)
+				repos.usage.EXPECT().Lock(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID, DriveUsed: 99}, nil)
+				repos.storage.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil)
+				repos.pending.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedErr: ucase.ErrDriveFileSystemIsFull,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			tt.mockSetup(t, repos)
+
+			_, err := ucase.NewDriveUseCase(repos.repos).Copy(testContext(), tt.user, tt.in)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"assistant-go/pkg/keyring"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"testing"
+)
+
+func legacyEncryptForTest(t *testing.T, plain []byte) []byte {
+	t.Helper()
+	key := sha256.Sum256([]byte(testEncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := randomBytes(t, aesgcm.NonceSize())
+	return append(nonce, aesgcm.Seal(nil, nonce, plain, nil)...)
+}
+
+func flipByte(data []byte, offset int) []byte {
+	flipped := bytes.Clone(data)
+	flipped[offset] ^= 0x01
+	return flipped
+}
+
+// expectDriveFile настраивает моки на файл 10 пользователя, сохраненный в хранилище как stored
+func expectDriveFile(repos *mockRepositories, user *entity.User, stored []byte, plainSize *int64) {
+	path := "1/file.bin"
+	repos.structs.EXPECT().GetByID(mock.Anything, 10).
+		Return(&entity.DriveStruct{ID: 10, UserID: user.ID, Name: "file.bin", Type: 1}, nil)
+	repos.driveFiles.EXPECT().GetByStructID(mock.Anything, 10).Return(&entity.DriveFile{
+		ID:            20,
+		DriveStructID: 10,
+		Path:          &path,
+		Size:          int64(len(stored)),
+		PlainSize:     plainSize,
+		UploadState:   1,
+	}, nil)
+}
+
+func TestDriveGetFileEncryptionFromRow(t *testing.T) {
+	kr, err := keyring.New("1", testEncryptionKey, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &entity.User{ID: 1}
+	plain := []byte("file content stored in the drive")
+	legacy := legacyEncryptForTest(t, plain)
+	stream := encryptForTest(t, plain)
+	size := func(data []byte) *int64 {
+		size := int64(len(data))
+		return &size
+	}
+
+	tests := []struct {
+		name            string
+		stored          []byte
+		plainSize       *int64
+		useEncryption   bool
+		expected        []byte
+		expectedErr     error
+		expectedReadErr bool
+	}{
+		{name: "plaintext", stored: plain, plainSize: size(plain), useEncryption: true, expected: plain},
+		{name: "legacy", stored: legacy, plainSize: size(plain), useEncryption: true, expected: plain},
+		{name: "legacy without plain size", stored: legacy, useEncryption: true, expected: plain},
+		{name: "stream", stored: stream, plainSize: size(plain), useEncryption: false, expected: plain},
+		{name: "plaintext without plain size", stored: plain, useEncryption: false, expected: plain},
+		{
+			name:          "tampered legacy",
+			stored:        flipByte(legacy, len(legacy)-1),
+			plainSize:     size(plain),
+			useEncryption: true,
+			expectedErr:   ucase.ErrDriveDecrypting,
+		},
+		{
+			name:            "tampered stream",
+			stored:          flipByte(stream, len(stream)-1),
+			plainSize:       size(plain),
+			useEncryption:   true,
+			expectedReadErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			expectDriveFile(repos, user, tt.stored, tt.plainSize)
+			repos.storage.EXPECT().GetFile(mock.Anything, "drive/1/file.bin").Return(bytes.NewReader(tt.stored), nil)
+
+			in := &dto.GetFile{StructID: 10, SavePath: "drive", UseEncryption: tt.useEncryption, Keyring: kr}
+			response, err := ucase.NewDriveUseCase(repos.repos).GetFile(testContext(), in, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			data, err := io.ReadAll(response.File)
+			if tt.expectedReadErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+			assert.Equal(t, int64(len(tt.expected)), response.SizeBytes)
+		})
+	}
+}
+
+func TestDriveFileSizeMatchesInfo(t *testing.T) {
+	kr, err := keyring.New("1", testEncryptionKey, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &entity.User{ID: 1}
+	plain := []byte("file content stored in the drive")
+	legacy := legacyEncryptForTest(t, plain)
+
+	tests := []struct {
+		name          string
+		stored        []byte
+		useEncryption bool
+		fileRange     *dto.FileRange
+		expectedSize  int64
+	}{
+		{name: "plaintext without plain size", stored: plain, expectedSize: int64(len(plain))},
+		{name: "legacy without plain size", stored: legacy, useEncryption: true, expectedSize: int64(len(plain))},
+		{
+			name:          "legacy range",
+			stored:        legacy,
+			useEncryption: true,
+			fileRange:     &dto.FileRange{Offset: 3, Length: 10},
+			expectedSize:  10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			expectDriveFile(repos, user, tt.stored, nil)
+			if tt.fileRange == nil {
+				repos.storage.EXPECT().GetFile(mock.Anything, "drive/1/file.bin").Return(bytes.NewReader(tt.stored), nil)
+			} else {
+				repos.storage.EXPECT().GetFileRange(mock.Anything, "drive/1/file.bin", mock.Anything, mock.Anything).
+					RunAndReturn(func(ctx context.Context, path string, offset int64, length int64) (io.Reader, error) {
+						end := int64(len(tt.stored))
+						if length >= 0 && offset+length < end {
+							end = offset + length
+						}
+						return bytes.NewReader(tt.stored[offset:end]), nil
+					})
+			}
+			drive := ucase.NewDriveUseCase(repos.repos)
+
+			in := &dto.GetFile{StructID: 10, SavePath: "drive", UseEncryption: tt.useEncryption, Keyring: kr}
+			info, err := drive.GetFileInfo(testContext(), in, user)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, int64(len(plain)), info.SizeBytes)
+
+			in.Range = tt.fileRange
+			response, err := drive.GetFile(testContext(), in, user)
+			if !assert.NoError(t, err) {
+				return
+			}
+			data, err := io.ReadAll(response.File)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSize, response.SizeBytes)
+			assert.Equal(t, tt.expectedSize, int64(len(data)))
+		})
+	}
+}
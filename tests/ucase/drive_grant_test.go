@@ -0,0 +1,391 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+// диск владельца: директория shared (на нее выдан грант) с файлом и поддиректорией и директория private с файлом.
+// У гостя есть свой файл в корне
+const (
+	grantOwnerID       = 1
+	grantGuestID       = 2
+	grantSharedID      = 5
+	grantSharedFileID  = 6
+	grantPrivateID     = 7
+	grantPrivateFileID = 8
+	grantGuestFileID   = 9
+	grantSubID         = 11
+)
+
+// expectGrantTree настраивает моки на дерево владельца и грант гостя с ролью role на shared
+func expectGrantTree(repos *mockRepositories, role int8) {
+	sharedID := grantSharedID
+	privateID := grantPrivateID
+	structs := []*entity.DriveStruct{
+		{ID: grantSharedID, UserID: grantOwnerID, Name: "shared", Type: 0},
+		{ID: grantSharedFileID, UserID: grantOwnerID, Name: "a.txt", Type: 1, ParentID: &sharedID},
+		{ID: grantSubID, UserID: grantOwnerID, Name: "sub", Type: 0, ParentID: &sharedID},
+		{ID: grantPrivateID, UserID: grantOwnerID, Name: "private", Type: 0},
+		{ID: grantPrivateFileID, UserID: grantOwnerID, Name: "p.txt", Type: 1, ParentID: &privateID},
+		{ID: grantGuestFileID, UserID: grantGuestID, Name: "g.txt", Type: 1},
+	}
+	for _, driveStruct := range structs {
+		repos.structs.EXPECT().GetByID(mock.Anything, driveStruct.ID).Return(driveStruct, nil).Maybe()
+	}
+
+	// роль наследуется вложенными элементами, на private гранта нет
+	for _, structID := range []int{grantSharedID, grantSharedFileID, grantSubID} {
+		repos.grants.EXPECT().GetRole(mock.Anything, structID, grantGuestID).Return(role, nil).Maybe()
+	}
+	for _, structID := range []int{grantPrivateID, grantPrivateFileID} {
+		repos.grants.EXPECT().GetRole(mock.Anything, structID, grantGuestID).Return(0, pgx.ErrNoRows).Maybe()
+	}
+}
+
+func TestDriveGrantAccess(t *testing.T) {
+	guest := &entity.User{ID: grantGuestID}
+	sharedID := grantSharedID
+	privateID := grantPrivateID
+	subID := grantSubID
+
+	tests := []struct {
+		name        string
+		role        int8
+		call        func(uc ucase.DriveUseCase) error
+		mockSetup   func(repos *mockRepositories)
+		expectedErr error
+	}{
+		{
+			name: "viewer lists shared directory",
+			role: ucase.DriveRoleViewer,
+			call: func(uc ucase.DriveUseCase) error {
+				_, err := uc.GetTree(testContext(), &sharedID, guest)
+				return err
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().TreeByUserID(mock.Anything, grantOwnerID, &sharedID).
+					Return([]*dto.DriveTree{{ID: grantSharedFileID, UserID: grantOwnerID, Name: "a.txt", Type: 1}}, nil)
+			},
+		},
+		{
+			name: "viewer creates directory",
+			role: ucase.DriveRoleViewer,
+			call: func(uc ucase.DriveUseCase) error {
+				_, err := uc.CreateDirectory(testContext(), &dto.DriveCreateDirectory{Name: "dir", ParentID: &sharedID}, guest)
+				return err
+			},
+			expectedErr: ucase.ErrDriveAccessDenied,
+		},
+		{
+			name: "viewer renames",
+			role: ucase.DriveRoleViewer,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.Rename(testContext(), grantSharedFileID, "b.txt", guest)
+			},
+			expectedErr: ucase.ErrDriveAccessDenied,
+		},
+		{
+			name: "viewer deletes",
+			role: ucase.DriveRoleViewer,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.Delete(testContext(), grantSharedFileID, guest)
+			},
+			expectedErr: ucase.ErrDriveAccessDenied,
+		},
+		{
+			name: "viewer moves inside shared directory",
+			role: ucase.DriveRoleViewer,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.RenMov(testContext(), guest, dto.DriveRenMov{StructIDs: []int{grantSharedFileID}, ParentID: &sharedID})
+			},
+			expectedErr: ucase.ErrDriveAccessDenied,
+		},
+		{
+			name: "editor creates directory for owner",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				_, err := uc.CreateDirectory(testContext(), &dto.DriveCreateDirectory{Name: "dir", ParentID: &sharedID}, guest)
+				return err
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().FindRow(mock.Anything, grantOwnerID, "dir", int8(0), &sharedID).Return(nil, pgx.ErrNoRows)
+				repos.structs.EXPECT().Create(mock.Anything, mock.MatchedBy(func(in *entity.DriveStruct) bool {
+					return in.UserID == grantOwnerID && *in.ParentID == grantSharedID
+				})).Return(nil, nil)
+				repos.structs.EXPECT().TreeByUserID(mock.Anything, grantOwnerID, &sharedID).Return([]*dto.DriveTree{}, nil)
+			},
+		},
+		{
+			name: "editor renames shared file",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.Rename(testContext(), grantSharedFileID, "b.txt", guest)
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().Update(mock.Anything, mock.MatchedBy(func(in *entity.DriveStruct) bool {
+					return in.ID == grantSharedFileID && in.Name == "b.txt" && in.UserID == grantOwnerID
+				})).Return(nil)
+			},
+		},
+		{
+			name: "editor moves inside shared directory",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.RenMov(testContext(), guest, dto.DriveRenMov{StructIDs: []int{grantSharedFileID}, ParentID: &subID})
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().StructCountByUserAndIDs(mock.Anything, grantOwnerID, []int{grantSharedFileID}).Return(1, nil)
+				repos.structs.EXPECT().MassUpdateParentID(mock.Anything, &subID, []int{grantSharedFileID}).Return(nil)
+			},
+		},
+		{
+			name: "editor creates directory outside shared directory",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				_, err := uc.CreateDirectory(testContext(), &dto.DriveCreateDirectory{Name: "dir", ParentID: &privateID}, guest)
+				return err
+			},
+			expectedErr: ucase.ErrDriveDirectoryExists,
+		},
+		{
+			name: "editor renames outside shared directory",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.Rename(testContext(), grantPrivateFileID, "x.txt", guest)
+			},
+			expectedErr: ucase.ErrFileNotFound,
+		},
+		{
+			name: "editor deletes outside shared directory",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.Delete(testContext(), grantPrivateFileID, guest)
+			},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+		{
+			// сама директория гранта остается под управлением владельца
+			name: "editor deletes shared directory",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.Delete(testContext(), grantSharedID, guest)
+			},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+		{
+			name: "editor moves own file into shared directory",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.RenMov(testContext(), guest, dto.DriveRenMov{StructIDs: []int{grantGuestFileID}, ParentID: &sharedID})
+			},
+			expectedErr: ucase.ErrDriveRelocatableStructureNotFound,
+		},
+		{
+			name: "editor moves private file into shared directory",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.RenMov(testContext(), guest, dto.DriveRenMov{StructIDs: []int{grantPrivateFileID}, ParentID: &sharedID})
+			},
+			expectedErr: ucase.ErrDriveRelocatableStructureNotFound,
+		},
+		{
+			name: "editor moves shared file into own root",
+			role: ucase.DriveRoleEditor,
+			call: func(uc ucase.DriveUseCase) error {
+				return uc.RenMov(testContext(), guest, dto.DriveRenMov{StructIDs: []int{grantSharedFileID}})
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().StructCountByUserAndIDs(mock.Anything, grantGuestID, []int{grantSharedFileID}).Return(0, nil)
+			},
+			expectedErr: ucase.ErrDriveRelocatableStructureNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			expectGrantTree(repos, tt.role)
+			if tt.mockSetup != nil {
+				tt.mockSetup(repos)
+			}
+
+			err := tt.call(ucase.NewDriveUseCase(repos.repos))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDriveGrantUploadByHash(t *testing.T) {
+	guest := &entity.User{ID: grantGuestID}
+	sharedID := grantSharedID
+	privateID := grantPrivateID
+	hash := sha256Hex([]byte("private"))
+	ownerBlob := &entity.DriveBlob{ID: 41, UserID: grantOwnerID, SHA256: hash, Path: "1/blob", Size: 7, PlainSize: 7, RefCount: 1}
+
+	tests := []struct {
+		name        string
+		parentID    *int
+		mockSetup   func(repos *mockRepositories)
+		expectedErr error
+	}{
+		{
+			// хэш содержимого владельца без самого содержимого не дает доступа к нему
+			name:     "guest without content",
+			parentID: &sharedID,
+			mockSetup: func(repos *mockRepositories) {
+				repos.blobs.EXPECT().GetByHash(mock.Anything, grantGuestID, hash).Return(nil, pgx.ErrNoRows)
+			},
+			expectedErr: ucase.ErrDriveBlobNotFound,
+		},
+		{
+			// файл ссылается на блоб владельца и учитывается в его дереве
+			name:     "guest with content",
+			parentID: &sharedID,
+			mockSetup: func(repos *mockRepositories) {
+				repos.blobs.EXPECT().GetByHash(mock.Anything, grantGuestID, hash).Return(&entity.DriveBlob{ID: 40, UserID: grantGuestID}, nil)
+				repos.blobs.EXPECT().GetByHash(mock.Anything, grantOwnerID, hash).Return(ownerBlob, nil)
+				repos.structs.EXPECT().FindRow(mock.Anything, grantOwnerID, "copy.txt", int8(1), &sharedID).Return(nil, pgx.ErrNoRows)
+				repos.driveFiles.EXPECT().GetMimeTypeByBlobID(mock.Anything, 41).Return(nil, pgx.ErrNoRows)
+				repos.blobs.EXPECT().IncrementRef(mock.Anything, 41).Return(nil)
+				repos.structs.EXPECT().Create(mock.Anything, mock.MatchedBy(func(in *entity.DriveStruct) bool {
+					return in.UserID == grantOwnerID && in.Name == "copy.txt" && *in.ParentID == grantSharedID
+				})).RunAndReturn(func(_ context.Context, in *entity.DriveStruct) (*entity.DriveStruct, error) {
+					in.ID = 12
+					return in, nil
+				})
+				repos.driveFiles.EXPECT().Create(mock.Anything, mock.MatchedBy(func(in *entity.DriveFile) bool {
+					return in.DriveStructID == 12 && *in.BlobID == ownerBlob.ID && *in.Path == ownerBlob.Path
+				})).Return(nil, nil)
+				repos.pending.EXPECT().Delete(mock.Anything).Return(nil)
+				repos.structs.EXPECT().TreeByUserID(mock.Anything, grantOwnerID, &sharedID).Return([]*dto.DriveTree{}, nil)
+			},
+		},
+		{
+			name:        "outside shared directory",
+			parentID:    &privateID,
+			expectedErr: ucase.ErrDriveDirectoryExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			expectGrantTree(repos, ucase.DriveRoleEditor)
+			if tt.mockSetup != nil {
+				tt.mockSetup(repos)
+			}
+
+			in := dto.DriveUploadByHash{SHA256: hash, Filename: "copy.txt", ParentID: tt.parentID}
+			_, err := ucase.NewDriveUseCase(repos.repos).UploadByHash(testContext(), in, guest)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDriveUploadIntoTrashedDirectory(t *testing.T) {
+	user := &entity.User{ID: 1}
+	dirID := 5
+	deletedAt := time.Now().UTC()
+	repos := newMockRepositories(t)
+	repos.structs.EXPECT().GetByID(mock.Anything, dirID).Return(&entity.DriveStruct{ID: dirID, UserID: user.ID, DeletedAt: &deletedAt}, nil)
+
+	_, err := ucase.NewDriveUseCase(repos.repos).UploadFile(testContext(), dto.DriveUploadFile{
+		File:                  newMemoryFile([]byte("data")),
+		OriginalFilename:      "a.txt",
+		MaxSizeBytes:          1 << 20,
+		StorageMaxSizePerUser: 1 << 30,
+		SavePath:              testSavePath,
+		ParentID:              &dirID,
+	}, user)
+	assert.ErrorIs(t, err, ucase.ErrDriveParentIdNotFound)
+}
+
+func TestDriveGrantCreate(t *testing.T) {
+	owner := &entity.User{ID: grantOwnerID}
+	deletedAt := time.Now().UTC()
+
+	tests := []struct {
+		name        string
+		driveStruct *entity.DriveStruct
+		grantee     *entity.User
+		expectedErr error
+	}{
+		{
+			name:        "directory",
+			driveStruct: &entity.DriveStruct{ID: 5, UserID: owner.ID, Type: 0},
+			grantee:     &entity.User{ID: grantGuestID, Login: "guest"},
+		},
+		{
+			name:        "file",
+			driveStruct: &entity.DriveStruct{ID: 5, UserID: owner.ID, Type: 1},
+			expectedErr: ucase.ErrDriveGrantOnlyDirectories,
+		},
+		{
+			name:        "foreign directory",
+			driveStruct: &entity.DriveStruct{ID: 5, UserID: grantGuestID, Type: 0},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+		{
+			name:        "trashed directory",
+			driveStruct: &entity.DriveStruct{ID: 5, UserID: owner.ID, Type: 0, DeletedAt: &deletedAt},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+		{
+			name:        "unknown grantee",
+			driveStruct: &entity.DriveStruct{ID: 5, UserID: owner.ID, Type: 0},
+			expectedErr: ucase.ErrUserNotFound,
+		},
+		{
+			name:        "grant to oneself",
+			driveStruct: &entity.DriveStruct{ID: 5, UserID: owner.ID, Type: 0},
+			grantee:     owner,
+			expectedErr: ucase.ErrDriveGrantSelf,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.structs.EXPECT().GetByID(mock.Anything, 5).Return(tt.driveStruct, nil)
+			if tt.grantee != nil {
+				repos.users.EXPECT().Find(mock.Anything, "guest").Return(tt.grantee, nil)
+			} else {
+				repos.users.EXPECT().Find(mock.Anything, "guest").Return(nil, pgx.ErrNoRows).Maybe()
+			}
+			if tt.expectedErr == nil {
+				repos.grants.EXPECT().Upsert(mock.Anything, mock.MatchedBy(func(in *entity.DriveGrant) bool {
+					return in.DriveStructID == 5 && in.OwnerID == owner.ID && in.GranteeID == grantGuestID && in.Role == ucase.DriveRoleEditor
+				})).RunAndReturn(func(_ context.Context, in *entity.DriveGrant) (*entity.DriveGrant, error) {
+					in.ID = 3
+					return in, nil
+				})
+			}
+
+			in := dto.DriveGrantCreate{StructID: 5, Login: "guest", Role: ucase.DriveRoleEditor}
+			grant, err := ucase.NewDriveGrantUseCase(repos.repos).Create(testContext(), in, owner)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, 3, grant.ID)
+			}
+		})
+	}
+}
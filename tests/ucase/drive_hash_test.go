@@ -0,0 +1,43 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestDriveUpdateFileHash(t *testing.T) {
+	user := &entity.User{ID: 1}
+	stored := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	tests := []struct {
+		name        string
+		stored      *string
+		hash        string
+		expectedErr error
+	}{
+		{name: "same hash", stored: &stored, hash: stored},
+		{name: "same hash in upper case", stored: &stored, hash: "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"},
+		{name: "other hash", stored: &stored, hash: "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752", expectedErr: ucase.ErrDriveFileHashMismatch},
+		{name: "no stored hash", hash: stored, expectedErr: ucase.ErrDriveFileHashMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).
+				Return(&entity.DriveStruct{ID: 10, UserID: user.ID, Name: "file.txt", Type: 1}, nil)
+			repos.driveFiles.EXPECT().GetByStructID(mock.Anything, 10).
+				Return(&entity.DriveFile{ID: 20, DriveStructID: 10, SHA256: tt.stored, UploadState: 1}, nil)
+
+			err := ucase.NewDriveUseCase(repos.repos).UpdateFileHash(testContext(), 10, tt.hash, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package ucase
+
+import (
+	"archive/zip"
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"bytes"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type archiveFile struct {
+	name string
+	data string
+}
+
+func buildZip(t *testing.T, files ...archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, file := range files {
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = writer.Write([]byte(file.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func importIn(archive []byte) dto.DriveImportIn {
+	return dto.DriveImportIn{
+		File:                  newMemoryFile(archive),
+		Size:                  int64(len(archive)),
+		Filename:              "archive.zip",
+		Conflict:              ucase.ImportConflictSkip,
+		MaxSizeBytes:          1 << 20,
+		StorageMaxSizePerUser: 1 << 30,
+		SavePath:              testSavePath,
+	}
+}
+
+func TestDriveImportArchive(t *testing.T) {
+	user := &entity.User{ID: 1}
+	docsID := 5
+
+	tests := []struct {
+		name            string
+		filename        string
+		files           []archiveFile
+		maxEntries      int
+		maxUnpackedSize int64
+		mockSetup       func(repos *mockRepositories)
+		expectedResult  *dto.DriveImportResult
+		expectedErr     error
+	}{
+		{
+			// небезопасные пути не распаковываются, остальные элементы архива обрабатываются
+			name:     "unsafe paths",
+			filename: "archive.zip",
+			files: []archiveFile{
+				{name: "docs/"},
+				{name: "../evil.txt", data: "evil"},
+				{name: "docs/../../evil.txt", data: "evil"},
+				{name: "/abs.txt", data: "abs"},
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "docs", int8(0), (*int)(nil)).Return(nil, pgx.ErrNoRows)
+				repos.structs.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.DriveStruct) (*entity.DriveStruct, error) {
+					in.ID = docsID
+					return in, nil
+				})
+			},
+			expectedResult: &dto.DriveImportResult{
+				Created: []string{"docs/"},
+				Skipped: []string{},
+				Failed: []*dto.DriveImportFailure{
+					{Path: "../evil.txt", Err: ucase.ErrDriveImportUnsafePath},
+					{Path: "docs/../../evil.txt", Err: ucase.ErrDriveImportUnsafePath},
+					{Path: "/abs.txt", Err: ucase.ErrDriveImportUnsafePath},
+				},
+			},
+		},
+		{
+			// существующие директории переиспользуются, существующие файлы пропускаются
+			name:     "conflict skip",
+			filename: "archive.zip",
+			files: []archiveFile{
+				{name: "docs/a.txt", data: "a"},
+				{name: "__MACOSX/docs/._a.txt", data: "meta"},
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "docs", int8(0), (*int)(nil)).Return(&entity.DriveStruct{ID: docsID}, nil)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "a.txt", int8(1), &docsID).Return(&entity.DriveStruct{ID: 6}, nil)
+			},
+			expectedResult: &dto.DriveImportResult{
+				Created: []string{},
+				Skipped: []string{"docs/a.txt", "__MACOSX/docs/._a.txt"},
+				Failed:  []*dto.DriveImportFailure{},
+			},
+		},
+		{
+			// архив проверяется целиком до распаковки: при превышении лимита ничего не создается
+			name:        "too many entries",
+			filename:    "archive.zip",
+			files:       []archiveFile{{name: "a.txt", data: "1234"}, {name: "b.txt", data: "5678"}, {name: "c.txt", data: "90"}},
+			maxEntries:  2,
+			expectedErr: ucase.ErrDriveImportTooManyEntries,
+		},
+		{
+			name:            "unpacked size",
+			filename:        "archive.zip",
+			files:           []archiveFile{{name: "a.txt", data: "1234"}, {name: "b.txt", data: "5678"}, {name: "c.txt", data: "90"}},
+			maxUnpackedSize: 9,
+			expectedErr:     ucase.ErrDriveImportTooLarge,
+		},
+		{
+			name:        "unsupported format",
+			filename:    "archive.rar",
+			files:       []archiveFile{{name: "a.txt", data: "1"}},
+			expectedErr: ucase.ErrDriveImportUnsupportedArchive,
+		},
+		{
+			name:        "damaged archive",
+			filename:    "archive.tar.gz",
+			files:       []archiveFile{{name: "a.txt", data: "1"}},
+			expectedErr: ucase.ErrDriveImportInvalidArchive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			if tt.mockSetup != nil {
+				tt.mockSetup(repos)
+			}
+
+			in := importIn(buildZip(t, tt.files...))
+			in.Filename = tt.filename
+			in.MaxEntries = tt.maxEntries
+			in.MaxUnpackedSize = tt.maxUnpackedSize
+			result, err := ucase.NewDriveUseCase(repos.repos).ImportArchive(testContext(), in, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+}
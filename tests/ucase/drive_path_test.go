@@ -0,0 +1,193 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strconv"
+	"testing"
+)
+
+func uploadPathIn(parentID *int, path string, data []byte) dto.DriveUploadFile {
+	hash := sha256Hex(data)
+	return dto.DriveUploadFile{
+		File:                  newMemoryFile(data),
+		MaxSizeBytes:          1 << 20,
+		StorageMaxSizePerUser: 1 << 30,
+		SavePath:              testSavePath,
+		ParentID:              parentID,
+		Path:                  path,
+		SHA256:                &hash,
+	}
+}
+
+func TestDriveUploadPath(t *testing.T) {
+	user := &entity.User{ID: 1}
+	data := []byte("report")
+	projectsID := 3
+	yearID := 4
+	firstCreatedID := 100
+
+	// created - созданные структуры в виде "имя@id родителя", новые структуры получают id начиная со 100.
+	// dirID - директория, в которую загружен файл
+	tests := []struct {
+		name      string
+		parentID  *int
+		path      string
+		mockSetup func(repos *mockRepositories)
+		created   []string
+		dirID     int
+	}{
+		{
+			name: "creates directories",
+			path: "/Projects/2026/report.txt",
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "Projects", int8(0), (*int)(nil)).Return(nil, pgx.ErrNoRows)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "2026", int8(0), &firstCreatedID).Return(nil, pgx.ErrNoRows)
+			},
+			created: []string{"Projects@0", "2026@100", "report.txt@101"},
+			dirID:   101,
+		},
+		{
+			// существующие директории переиспользуются, пустые и "." части пути пропускаются
+			name: "reuses directories",
+			path: "Projects//2026/./report.txt",
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "Projects", int8(0), (*int)(nil)).
+					Return(&entity.DriveStruct{ID: projectsID}, nil)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "2026", int8(0), &projectsID).
+					Return(&entity.DriveStruct{ID: yearID}, nil)
+			},
+			created: []string{"report.txt@4"},
+			dirID:   yearID,
+		},
+		{
+			name:     "relative to parent",
+			parentID: &projectsID,
+			path:     "2027/report.txt",
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().GetByID(mock.Anything, projectsID).Return(&entity.DriveStruct{ID: projectsID, UserID: user.ID}, nil)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "2027", int8(0), &projectsID).Return(nil, pgx.ErrNoRows)
+			},
+			created: []string{"2027@3", "report.txt@100"},
+			dirID:   100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			tt.mockSetup(repos)
+			// содержимое уже есть у пользователя, поэтому хранилище и квота не используются
+			repos.blobs.EXPECT().GetByHash(mock.Anything, user.ID, sha256Hex(data)).
+				Return(&entity.DriveBlob{ID: 40, UserID: user.ID, SHA256: sha256Hex(data), Path: "blobs/report", Size: 6, PlainSize: 6}, nil)
+			repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "report.txt", int8(1), &tt.dirID).Return(nil, pgx.ErrNoRows)
+			repos.blobs.EXPECT().IncrementRef(mock.Anything, 40).Return(nil)
+			repos.driveFiles.EXPECT().Create(mock.Anything, mock.Anything).Return(nil, nil)
+			repos.pending.EXPECT().Delete(mock.Anything).Return(nil)
+			repos.structs.EXPECT().GetByID(mock.Anything, tt.dirID).Return(&entity.DriveStruct{ID: tt.dirID, UserID: user.ID}, nil)
+			repos.structs.EXPECT().TreeByUserID(mock.Anything, user.ID, &tt.dirID).Return(nil, nil)
+
+			created := make([]string, 0)
+			repos.structs.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.DriveStruct) (*entity.DriveStruct, error) {
+				in.ID = firstCreatedID + len(created)
+				parentID := 0
+				if in.ParentID != nil {
+					parentID = *in.ParentID
+				}
+				created = append(created, in.Name+"@"+strconv.Itoa(parentID))
+				return in, nil
+			})
+
+			_, err := ucase.NewDriveUseCase(repos.repos).UploadFile(testContext(), uploadPathIn(tt.parentID, tt.path, data), user)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.created, created)
+			}
+		})
+	}
+}
+
+func TestDriveUploadPathInvalid(t *testing.T) {
+	for _, path := range []string{"../evil.txt", "docs/../../evil.txt", "/", "docs/a\nb.txt"} {
+		t.Run(path, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			_, err := ucase.NewDriveUseCase(repos.repos).UploadFile(testContext(), uploadPathIn(nil, path, []byte("data")), &entity.User{ID: 1})
+			assert.ErrorIs(t, err, ucase.ErrDrivePathInvalid)
+		})
+	}
+}
+
+func TestDriveResolvePath(t *testing.T) {
+	user := &entity.User{ID: 1}
+	item := &dto.DrivePathItem{ID: 10, Name: "a.txt", Type: 1}
+
+	tests := []struct {
+		path          string
+		expectedNames []string
+		found         bool
+		expectedErr   error
+	}{
+		{path: "/docs/a.txt", expectedNames: []string{"docs", "a.txt"}, found: true},
+		{path: "docs//./a.txt/", expectedNames: []string{"docs", "a.txt"}, found: true},
+		{path: "/docs/missing.txt", expectedNames: []string{"docs", "missing.txt"}, expectedErr: ucase.ErrDrivePathNotFound},
+		{path: "/docs/../a.txt", expectedErr: ucase.ErrDrivePathInvalid},
+		{path: "/", expectedErr: ucase.ErrDrivePathInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			if tt.expectedNames != nil {
+				if tt.found {
+					repos.structs.EXPECT().ResolvePath(mock.Anything, user.ID, tt.expectedNames).Return(item, nil)
+				} else {
+					repos.structs.EXPECT().ResolvePath(mock.Anything, user.ID, tt.expectedNames).Return(nil, pgx.ErrNoRows)
+				}
+			}
+
+			result, err := ucase.NewDriveUseCase(repos.repos).ResolvePath(testContext(), tt.path, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, item, result)
+			}
+		})
+	}
+}
+
+func TestDriveGetAncestors(t *testing.T) {
+	user := &entity.User{ID: 1}
+	items := []*dto.DrivePathItem{{ID: 3, Name: "docs"}, {ID: 10, Name: "a.txt", Type: 1}}
+
+	tests := []struct {
+		name        string
+		items       []*dto.DrivePathItem
+		expectedErr error
+	}{
+		{name: "found", items: items},
+		// чужая структура или структура в корзине
+		{name: "not found", items: []*dto.DrivePathItem{}, expectedErr: ucase.ErrDriveStructNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.structs.EXPECT().Ancestors(mock.Anything, user.ID, 10).Return(tt.items, nil)
+
+			result, err := ucase.NewDriveUseCase(repos.repos).GetAncestors(testContext(), 10, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, items, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestDrivePersonalQuota(t *testing.T) {
+	user := &entity.User{ID: 1}
+	driveLimit := int64(10)
+	limits := &entity.StorageLimits{DriveLimit: &driveLimit}
+
+	tests := []struct {
+		name string
+		call func(uc ucase.DriveUseCase) error
+	}{
+		{
+			name: "upload",
+			call: func(uc ucase.DriveUseCase) error {
+				_, err := uc.UploadFile(testContext(), dto.DriveUploadFile{
+					File:                  newMemoryFile([]byte("123")),
+					OriginalFilename:      "b.txt",
+					MaxSizeBytes:          1 << 20,
+					StorageMaxSizePerUser: 1 << 30,
+					SavePath:              testSavePath,
+				}, user)
+				return err
+			},
+		},
+		{
+			name: "chunk prepare",
+			call: func(uc ucase.DriveUseCase) error {
+				_, err := uc.ChunkPrepare(testContext(), user, dto.DriveChunkPrepareIn{
+					DriveChunkPrepare:     dto.DriveChunkPrepare{Filename: "c.bin", FullSize: 3},
+					MaxSizeBytes:          1 << 20,
+					StorageMaxSizePerUser: 1 << 30,
+				})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// персональный лимит важнее лимита из конфигурации
+			repos := newMockRepositories(t)
+			repos.quota.EXPECT().GetLimits(mock.Anything, user.ID).Return(limits, nil)
+			repos.usage.EXPECT().Get(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID, DriveUsed: 8}, nil)
+
+			err := tt.call(ucase.NewDriveUseCase(repos.repos))
+			assert.ErrorIs(t, err, ucase.ErrDriveFileSystemIsFull)
+		})
+	}
+}
+
+func TestDriveSpace(t *testing.T) {
+	user := &entity.User{ID: 1}
+	plan := "basic"
+	driveLimit := int64(10)
+	defaults := dto.StorageLimits{DriveLimit: 1 << 30, FilesLimit: 1 << 20}
+
+	tests := []struct {
+		name     string
+		limits   *entity.StorageLimits
+		usage    *entity.StorageUsage
+		expected *dto.DriveSpace
+	}{
+		{
+			// лимит заметок не задан ни персонально, ни в тарифе - берется из конфигурации
+			name:   "personal limits",
+			limits: &entity.StorageLimits{PlanName: &plan, DriveLimit: &driveLimit},
+			usage:  &entity.StorageUsage{UserID: user.ID, DriveUsed: 8, FilesUsed: 2},
+			expected: &dto.DriveSpace{
+				Total: driveLimit + defaults.FilesLimit,
+				Used:  10,
+				Plan:  &plan,
+				Drive: dto.DriveSpaceUsage{Total: driveLimit, Used: 8},
+				Notes: dto.DriveSpaceUsage{Total: defaults.FilesLimit, Used: 2},
+			},
+		},
+		{
+			name: "defaults",
+			expected: &dto.DriveSpace{
+				Total: defaults.DriveLimit + defaults.FilesLimit,
+				Drive: dto.DriveSpaceUsage{Total: defaults.DriveLimit},
+				Notes: dto.DriveSpaceUsage{Total: defaults.FilesLimit},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			if tt.limits != nil {
+				repos.quota.EXPECT().GetLimits(mock.Anything, user.ID).Return(tt.limits, nil)
+			} else {
+				repos.quota.EXPECT().GetLimits(mock.Anything, user.ID).Return(nil, pgx.ErrNoRows)
+			}
+			if tt.usage != nil {
+				repos.usage.EXPECT().Get(mock.Anything, user.ID).Return(tt.usage, nil)
+			} else {
+				repos.usage.EXPECT().Get(mock.Anything, user.ID).Return(nil, pgx.ErrNoRows)
+			}
+
+			space, err := ucase.NewDriveUseCase(repos.repos).Space(testContext(), user, defaults)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, space)
+			}
+		})
+	}
+}
+
+func TestStorageQuotaSetUserQuota(t *testing.T) {
+	user := &entity.User{ID: 1, Login: "user"}
+	planID := 3
+	driveLimit := int64(10)
+	filesLimit := int64(20)
+	negative := int64(-1)
+
+	tests := []struct {
+		name        string
+		driveLimit  *int64
+		filesLimit  *int64
+		mockSetup   func(repos *mockRepositories)
+		expectedErr error
+	}{
+		{
+			// незаданный лимит и тариф сохраняются
+			name:       "keeps other limit",
+			driveLimit: &driveLimit,
+			mockSetup: func(repos *mockRepositories) {
+				repos.users.EXPECT().Find(mock.Anything, user.Login).Return(user, nil)
+				repos.quota.EXPECT().GetUserQuota(mock.Anything, user.ID).
+					Return(&entity.UserStorageQuota{UserID: user.ID, PlanID: &planID, FilesLimit: &filesLimit}, nil)
+				repos.quota.EXPECT().SaveUserQuota(mock.Anything, mock.MatchedBy(func(in *entity.UserStorageQuota) bool {
+					return *in.PlanID == planID && *in.DriveLimit == driveLimit && *in.FilesLimit == filesLimit
+				})).Return(nil)
+			},
+		},
+		{
+			name:       "without quota",
+			filesLimit: &filesLimit,
+			mockSetup: func(repos *mockRepositories) {
+				repos.users.EXPECT().Find(mock.Anything, user.Login).Return(user, nil)
+				repos.quota.EXPECT().GetUserQuota(mock.Anything, user.ID).Return(nil, pgx.ErrNoRows)
+				repos.quota.EXPECT().SaveUserQuota(mock.Anything, mock.MatchedBy(func(in *entity.UserStorageQuota) bool {
+					return in.UserID == user.ID && in.PlanID == nil && in.DriveLimit == nil && *in.FilesLimit == filesLimit
+				})).Return(nil)
+			},
+		},
+		{
+			name:        "negative",
+			driveLimit:  &negative,
+			mockSetup:   func(repos *mockRepositories) {},
+			expectedErr: ucase.ErrStorageQuotaInvalid,
+		},
+		{
+			name:       "user not found",
+			driveLimit: &driveLimit,
+			mockSetup: func(repos *mockRepositories) {
+				repos.users.EXPECT().Find(mock.Anything, user.Login).Return(nil, pgx.ErrNoRows)
+			},
+			expectedErr: ucase.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			tt.mockSetup(repos)
+
+			err := ucase.NewStorageQuotaUseCase(repos.repos).SetUserQuota(testContext(), user.Login, tt.driveLimit, tt.filesLimit)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStorageQuotaSetUserPlan(t *testing.T) {
+	user := &entity.User{ID: 1, Login: "user"}
+	planID := 3
+	plan := "basic"
+	missing := "missing"
+	driveLimit := int64(10)
+
+	tests := []struct {
+		name           string
+		planName       *string
+		mockSetup      func(repos *mockRepositories)
+		expectedPlanID *int
+		expectedErr    error
+	}{
+		{
+			name:     "set",
+			planName: &plan,
+			mockSetup: func(repos *mockRepositories) {
+				repos.quota.EXPECT().GetPlanByName(mock.Anything, plan).Return(&entity.StoragePlan{ID: planID, Name: plan}, nil)
+			},
+			expectedPlanID: &planID,
+		},
+		{
+			name:        "not found",
+			planName:    &missing,
+			expectedErr: ucase.ErrStoragePlanNotFound,
+			mockSetup: func(repos *mockRepositories) {
+				repos.quota.EXPECT().GetPlanByName(mock.Anything, missing).Return(nil, pgx.ErrNoRows)
+			},
+		},
+		{
+			name:      "unset",
+			mockSetup: func(repos *mockRepositories) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			tt.mockSetup(repos)
+			repos.users.EXPECT().Find(mock.Anything, user.Login).Return(user, nil)
+			repos.quota.EXPECT().GetUserQuota(mock.Anything, user.ID).
+				Return(&entity.UserStorageQuota{UserID: user.ID, PlanID: &planID, DriveLimit: &driveLimit}, nil)
+			if tt.expectedErr == nil {
+				// персональные лимиты при смене тарифа сохраняются
+				repos.quota.EXPECT().SaveUserQuota(mock.Anything, mock.MatchedBy(func(in *entity.UserStorageQuota) bool {
+					return assert.ObjectsAreEqual(tt.expectedPlanID, in.PlanID) && *in.DriveLimit == driveLimit
+				})).Return(nil)
+			}
+
+			err := ucase.NewStorageQuotaUseCase(repos.repos).SetUserPlan(testContext(), user.Login, tt.planName)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"assistant-go/internal/storage/postgres"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestDriveSearch(t *testing.T) {
+	user := &entity.User{ID: 1}
+	items := []*dto.DriveSearchItem{{ID: 10, Name: "report.pdf", Type: 1, Path: "/docs/report.pdf"}}
+
+	tests := []struct {
+		name           string
+		items          []*dto.DriveSearchItem
+		total          int
+		err            error
+		expectedResult *dto.DriveSearchResult
+		expectedErr    error
+	}{
+		{
+			name:           "found",
+			items:          items,
+			total:          11,
+			expectedResult: &dto.DriveSearchResult{Items: items, Total: 11, Page: 2, Limit: 10},
+		},
+		{
+			name:        "database error",
+			err:         errors.New("connection reset"),
+			expectedErr: postgres.ErrUnexpectedDBError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			in := &dto.DriveSearch{Query: "report", Page: 2, Limit: 10}
+			repos.structs.EXPECT().Search(mock.Anything, user.ID, in).Return(tt.items, tt.total, tt.err)
+
+			result, err := ucase.NewDriveUseCase(repos.repos).Search(testContext(), in, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"bytes"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"testing"
+	"time"
+)
+
+func sharedFileIn(link *entity.DriveShareLink, structID int, password string) *dto.DriveSharedFileIn {
+	return &dto.DriveSharedFileIn{
+		DriveShareAccess: dto.DriveShareAccess{Hash: link.Hash, Password: password},
+		GetFile:          dto.GetFile{StructID: structID, SavePath: testSavePath},
+	}
+}
+
+func TestDriveShareCreate(t *testing.T) {
+	user := &entity.User{ID: 1}
+	password := "secret"
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	deletedAt := time.Now().UTC()
+
+	tests := []struct {
+		name        string
+		in          dto.DriveShareCreate
+		driveStruct *entity.DriveStruct
+		hashTaken   bool
+		expectedErr error
+	}{
+		{
+			name:        "with password and expiry",
+			in:          dto.DriveShareCreate{StructID: 10, Password: &password, ExpiresAt: &future},
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID},
+		},
+		{
+			name:        "past expiry",
+			in:          dto.DriveShareCreate{StructID: 10, ExpiresAt: &past},
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID},
+			expectedErr: ucase.ErrDriveShareInvalidExpiry,
+		},
+		{
+			// ссылка без уникального хеша не создается
+			name:        "hash collision",
+			in:          dto.DriveShareCreate{StructID: 10},
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID},
+			hashTaken:   true,
+			expectedErr: ucase.ErrDriveShareHashGeneration,
+		},
+		{
+			name:        "foreign struct",
+			in:          dto.DriveShareCreate{StructID: 10},
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: 2},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+		{
+			name:        "trashed struct",
+			in:          dto.DriveShareCreate{StructID: 10},
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID, DeletedAt: &deletedAt},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+		{
+			name:        "missing struct",
+			in:          dto.DriveShareCreate{StructID: 10},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			if tt.driveStruct != nil {
+				repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(tt.driveStruct, nil)
+			} else {
+				repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(nil, pgx.ErrNoRows)
+			}
+			repos.shareLinks.EXPECT().ExistsByHash(mock.Anything, mock.Anything).Return(tt.hashTaken, nil).Maybe()
+			if tt.expectedErr == nil {
+				repos.shareLinks.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.DriveShareLink) (*entity.DriveShareLink, error) {
+					in.ID = 3
+					return in, nil
+				})
+			}
+
+			link, err := ucase.NewDriveShareUseCase(repos.repos).Create(testContext(), tt.in, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, user.ID, link.UserID)
+			assert.Len(t, link.Hash, 80)
+			assert.Equal(t, future.UTC(), *link.ExpiresAt)
+			// пароль хранится только в виде bcrypt-хеша
+			if assert.NotNil(t, link.Password) {
+				assert.NotEqual(t, password, *link.Password)
+				assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(*link.Password), []byte(password)))
+			}
+		})
+	}
+}
+
+func TestDriveShareAccess(t *testing.T) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	password := string(hashedPassword)
+	past := time.Now().UTC().Add(-time.Minute)
+	future := time.Now().UTC().Add(time.Hour)
+	maxDownloads := 1
+	rootID := 5
+	rootParentID := 4
+
+	tests := []struct {
+		name        string
+		link        *entity.DriveShareLink
+		password    string
+		expectedErr error
+	}{
+		{name: "active", link: &entity.DriveShareLink{ExpiresAt: &future}},
+		{name: "not found", expectedErr: ucase.ErrDriveShareNotFound},
+		{name: "expired", link: &entity.DriveShareLink{ExpiresAt: &past}, expectedErr: ucase.ErrDriveShareExpired},
+		{
+			name:        "download limit",
+			link:        &entity.DriveShareLink{MaxDownloads: &maxDownloads, DownloadCount: 1},
+			expectedErr: ucase.ErrDriveShareDownloadLimit,
+		},
+		{name: "password required", link: &entity.DriveShareLink{Password: &password}, expectedErr: ucase.ErrDriveSharePasswordRequired},
+		{name: "wrong password", link: &entity.DriveShareLink{Password: &password}, password: "wrong", expectedErr: ucase.ErrDriveShareWrongPassword},
+		{name: "password", link: &entity.DriveShareLink{Password: &password}, password: "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			if tt.link != nil {
+				tt.link.Hash = "hash"
+				tt.link.DriveStructID = rootID
+				repos.shareLinks.EXPECT().GetByHash(mock.Anything, "hash").Return(tt.link, nil)
+			} else {
+				repos.shareLinks.EXPECT().GetByHash(mock.Anything, "hash").Return(nil, pgx.ErrNoRows)
+			}
+			if tt.expectedErr == nil {
+				repos.shareLinks.EXPECT().SubtreeByRootID(mock.Anything, rootID).Return([]*dto.DriveSharedItem{
+					{ID: rootID, ParentID: &rootParentID, Name: "shared"},
+					{ID: 6, ParentID: &rootID, Name: "a.txt", Type: 1},
+				}, nil)
+			}
+
+			tree, err := ucase.NewDriveShareUseCase(repos.repos).GetSharedTree(testContext(), dto.DriveShareAccess{Hash: "hash", Password: tt.password})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) && assert.Len(t, tree.Items, 2) {
+				assert.Equal(t, rootID, tree.RootID)
+				// родитель корня не раскрывается
+				assert.Nil(t, tree.Items[0].ParentID)
+				assert.Equal(t, rootID, *tree.Items[1].ParentID)
+			}
+		})
+	}
+}
+
+func TestDriveShareGetSharedFile(t *testing.T) {
+	user := &entity.User{ID: 1}
+	link := &entity.DriveShareLink{ID: 3, UserID: user.ID, DriveStructID: 5, Hash: "hash"}
+
+	tests := []struct {
+		name         string
+		fileRange    *dto.FileRange
+		inSubtree    bool
+		mockSetup    func(repos *mockRepositories)
+		expectedData string
+		expectedErr  error
+	}{
+		{
+			name:      "full download",
+			inSubtree: true,
+			mockSetup: func(repos *mockRepositories) {
+				repos.shareLinks.EXPECT().IncrementDownloads(mock.Anything, 3).Return(nil)
+				repos.storage.EXPECT().GetFile(mock.Anything, "drive/1/a.txt").Return(bytes.NewReader([]byte("data")), nil)
+			},
+			expectedData: "data",
+		},
+		{
+			// докачка с ненулевого смещения лимит не расходует
+			name:      "resumed download",
+			fileRange: &dto.FileRange{Offset: 1, Length: 3},
+			inSubtree: true,
+			mockSetup: func(repos *mockRepositories) {
+				repos.storage.EXPECT().GetFileRange(mock.Anything, "drive/1/a.txt", int64(1), int64(3)).Return(bytes.NewReader([]byte("ata")), nil)
+			},
+			expectedData: "ata",
+		},
+		{
+			// лимит исчерпан конкурентным скачиванием после проверки ссылки
+			name:      "download limit",
+			inSubtree: true,
+			mockSetup: func(repos *mockRepositories) {
+				repos.shareLinks.EXPECT().IncrementDownloads(mock.Anything, 3).Return(pgx.ErrNoRows)
+			},
+			expectedErr: ucase.ErrDriveShareDownloadLimit,
+		},
+		{
+			name:        "outside shared directory",
+			expectedErr: ucase.ErrFileNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.shareLinks.EXPECT().GetByHash(mock.Anything, "hash").Return(link, nil)
+			repos.shareLinks.EXPECT().IsInSubtree(mock.Anything, 5, 10).Return(tt.inSubtree, nil)
+			if tt.inSubtree {
+				expectFileStruct(repos, user)
+				path := "1/a.txt"
+				repos.driveFiles.EXPECT().GetByStructID(mock.Anything, 10).
+					Return(&entity.DriveFile{ID: 20, DriveStructID: 10, Path: &path, Ext: "txt", Size: 4, UploadState: 1}, nil)
+			}
+			if tt.mockSetup != nil {
+				tt.mockSetup(repos)
+			}
+
+			in := sharedFileIn(link, 10, "")
+			in.Range = tt.fileRange
+			file, err := ucase.NewDriveShareUseCase(repos.repos).GetSharedFile(testContext(), in)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			data, err := io.ReadAll(file.File)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedData, string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestDriveTrashDelete(t *testing.T) {
+	user := &entity.User{ID: 1}
+	deletedAt := time.Now().UTC()
+
+	tests := []struct {
+		name        string
+		driveStruct *entity.DriveStruct
+		structErr   error
+		expectedErr error
+	}{
+		{name: "own struct", driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID, Name: "docs"}},
+		{
+			name:        "already in trash",
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID, Name: "docs", DeletedAt: &deletedAt},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+		{
+			name:        "other user root",
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: 2, Name: "docs"},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+		{name: "not found", structErr: pgx.ErrNoRows, expectedErr: ucase.ErrDriveStructNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(tt.driveStruct, tt.structErr)
+			if tt.expectedErr == nil {
+				repos.structs.EXPECT().MoveToTrash(mock.Anything, user.ID, 10, mock.Anything).Return(nil)
+			}
+
+			err := ucase.NewDriveUseCase(repos.repos).Delete(testContext(), 10, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDriveTrashRestore(t *testing.T) {
+	user := &entity.User{ID: 1}
+	deletedAt := time.Now().UTC()
+	rootID := 10
+	otherRootID := 5
+	parentID := 5
+
+	tests := []struct {
+		name             string
+		driveStruct      *entity.DriveStruct
+		mockSetup        func(repos *mockRepositories)
+		expectedParentID *int
+		expectedName     string
+		expectedErr      error
+	}{
+		{
+			name: "parent exists",
+			driveStruct: &entity.DriveStruct{
+				ID: 10, UserID: user.ID, Name: "a.txt", Type: 1, ParentID: &parentID, DeletedAt: &deletedAt, TrashRootID: &rootID,
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().GetByID(mock.Anything, parentID).
+					Return(&entity.DriveStruct{ID: parentID, UserID: user.ID, Name: "docs"}, nil)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "a.txt", int8(1), &parentID).Return(nil, pgx.ErrNoRows)
+			},
+			expectedParentID: &parentID,
+			expectedName:     "a.txt",
+		},
+		{
+			// родитель в корзине: файл возвращается в корень и получает свободное имя
+			name: "parent in trash",
+			driveStruct: &entity.DriveStruct{
+				ID: 10, UserID: user.ID, Name: "a.txt", Type: 1, ParentID: &parentID, DeletedAt: &deletedAt, TrashRootID: &rootID,
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().GetByID(mock.Anything, parentID).
+					Return(&entity.DriveStruct{ID: parentID, UserID: user.ID, Name: "docs", DeletedAt: &deletedAt}, nil)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "a.txt", int8(1), (*int)(nil)).
+					Return(&entity.DriveStruct{ID: 11, UserID: user.ID, Name: "a.txt", Type: 1}, nil)
+				repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "a (1).txt", int8(1), (*int)(nil)).Return(nil, pgx.ErrNoRows)
+			},
+			expectedName: "a (1).txt",
+		},
+		{
+			// вложенный элемент не является корнем корзины и отдельно не восстанавливается
+			name: "nested item",
+			driveStruct: &entity.DriveStruct{
+				ID: 10, UserID: user.ID, Name: "a.txt", Type: 1, ParentID: &parentID, DeletedAt: &deletedAt, TrashRootID: &otherRootID,
+			},
+			expectedErr: ucase.ErrDriveTrashItemNotFound,
+		},
+		{
+			name:        "not in trash",
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID, Name: "a.txt", Type: 1},
+			expectedErr: ucase.ErrDriveTrashItemNotFound,
+		},
+		{
+			name:        "other user",
+			driveStruct: &entity.DriveStruct{ID: 10, UserID: 2, Name: "a.txt", Type: 1, DeletedAt: &deletedAt, TrashRootID: &rootID},
+			expectedErr: ucase.ErrDriveTrashItemNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(tt.driveStruct, nil)
+			if tt.mockSetup != nil {
+				tt.mockSetup(repos)
+			}
+
+			var updated *entity.DriveStruct
+			if tt.expectedErr == nil {
+				repos.structs.EXPECT().RestoreFromTrash(mock.Anything, user.ID, 10).Return(nil)
+				repos.structs.EXPECT().Update(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.DriveStruct) error {
+					updated = in
+					return nil
+				})
+			}
+
+			err := ucase.NewDriveUseCase(repos.repos).RestoreFromTrash(testContext(), 10, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expectedParentID, updated.ParentID)
+			assert.Equal(t, tt.expectedName, updated.Name)
+			assert.Equal(t, 1, repos.tx.commits)
+		})
+	}
+}
+
+func TestDriveTrashDeleteFromTrash(t *testing.T) {
+	user := &entity.User{ID: 1}
+	deletedAt := time.Now().UTC()
+	rootID := 10
+	plainPath := "1/a.txt"
+	chunkedPath := "1/b.bin"
+	blobID := 40
+
+	tests := []struct {
+		name         string
+		blobRefCount int
+		expectedKeys []string
+		expectedFree int64
+	}{
+		{
+			name:         "last blob reference",
+			blobRefCount: 0,
+			expectedKeys: []string{"drive/1/b.bin.1", "drive/1/a.txt", "drive/blobs/c.txt"},
+			expectedFree: 5 + 7 + 11,
+		},
+		{
+			// блоб используется другими файлами и остается в хранилище
+			name:         "shared blob",
+			blobRefCount: 1,
+			expectedKeys: []string{"drive/1/b.bin.1", "drive/1/a.txt"},
+			expectedFree: 5 + 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).
+				Return(&entity.DriveStruct{ID: 10, UserID: user.ID, Name: "docs", DeletedAt: &deletedAt, TrashRootID: &rootID}, nil)
+			repos.chunks.EXPECT().GetAllRecursive(mock.Anything, 10, user.ID).Return([]*entity.DriveFileChunk{
+				{ID: 30, DriveFileID: 21, Path: "1/b.bin.1", Size: 5, ChunkNumber: 1},
+			}, nil)
+			repos.driveFiles.EXPECT().GetAllRecursive(mock.Anything, 10, user.ID).Return([]*entity.DriveFile{
+				{ID: 20, DriveStructID: 11, Path: &plainPath, Ext: "txt", Size: 7, IsCurrent: true, UploadState: 1},
+				{ID: 21, DriveStructID: 12, Path: &chunkedPath, Ext: "bin", IsChunk: true, IsCurrent: true, UploadState: 1},
+				{ID: 22, DriveStructID: 13, Ext: "txt", Size: 11, BlobID: &blobID, IsCurrent: true, UploadState: 1},
+			}, nil)
+			repos.structs.EXPECT().DeleteRecursive(mock.Anything, user.ID, 10).Return(nil)
+			repos.blobs.EXPECT().DecrementRef(mock.Anything, blobID, 1).
+				Return(&entity.DriveBlob{ID: blobID, UserID: user.ID, Path: "blobs/c.txt", Size: 11, RefCount: tt.blobRefCount}, nil)
+			if tt.blobRefCount == 0 {
+				repos.blobs.EXPECT().Delete(mock.Anything, blobID).Return(nil)
+			}
+			repos.usage.EXPECT().Add(mock.Anything, user.ID, -tt.expectedFree, int64(0)).Return(nil)
+			repos.storage.EXPECT().DeleteAll(mock.Anything, tt.expectedKeys).Return(nil)
+
+			err := ucase.NewDriveUseCase(repos.repos).DeleteFromTrash(testContext(), 10, "drive", user)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, 1, repos.tx.commits)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"testing"
+)
+
+// expectTusSave настраивает моки на сохранение фрагмента загрузки 20 одним чанком и возвращает
+// сохраненный чанк и его содержимое в хранилище
+func expectTusSave(t *testing.T, repos *mockRepositories, user *entity.User, size int64) (*entity.DriveFileChunk, *[]byte) {
+	saved := &entity.DriveFileChunk{}
+	var stored []byte
+	repos.quota.EXPECT().GetLimits(mock.Anything, user.ID).Return(nil, pgx.ErrNoRows)
+	repos.usage.EXPECT().Get(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID}, nil)
+	repos.pending.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	repos.storage.EXPECT().Save(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *dto.SaveFile) error {
+		var err error
+		stored, err = io.ReadAll(in.File)
+		return err
+	})
+	repos.usage.EXPECT().Lock(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID}, nil)
+	repos.usage.EXPECT().Add(mock.Anything, user.ID, size, int64(0)).Return(nil)
+	repos.chunks.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.DriveFileChunk) (*entity.DriveFileChunk, error) {
+		assert.Equal(t, 20, in.DriveFileID)
+		assert.Equal(t, 1, in.ChunkNumber)
+		in.ID = 31
+		*saved = *in
+		return in, nil
+	})
+	repos.pending.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil)
+	repos.driveFiles.EXPECT().TouchActivity(mock.Anything, 20, mock.Anything).Return(nil)
+	return saved, &stored
+}
+
+func TestDriveTusAppend(t *testing.T) {
+	user := &entity.User{ID: 1}
+	data := []byte("payload")
+	sum := sha256.Sum256(data)
+	stored := int64(3)
+	existing := &entity.DriveFileChunk{ID: 30, DriveFileID: 20, Path: "1/part_1", Size: 3, PlainSize: &stored, ChunkNumber: 1}
+
+	tests := []struct {
+		name              string
+		expectedSize      int64
+		chunks            []*entity.DriveFileChunk
+		checksum          []byte
+		mockSetup         func(t *testing.T, repos *mockRepositories)
+		expectedOffset    int64
+		expectedCompleted bool
+		expectedErr       error
+	}{
+		{
+			name:           "partial",
+			expectedSize:   14,
+			checksum:       sum[:],
+			mockSetup:      func(t *testing.T, repos *mockRepositories) { expectTusSave(t, repos, user, 7) },
+			expectedOffset: 7,
+		},
+		{
+			// последний фрагмент завершает загрузку так же, как ChunkEnd
+			name:         "complete",
+			expectedSize: 7,
+			checksum:     sum[:],
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				saved, content := expectTusSave(t, repos, user, 7)
+				repos.chunks.EXPECT().GetByFileID(mock.Anything, 20).RunAndReturn(func(context.Context, int) ([]*entity.DriveFileChunk, error) {
+					return []*entity.DriveFileChunk{saved}, nil
+				})
+				repos.storage.EXPECT().GetFile(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, path string) (io.Reader, error) {
+					assert.Equal(t, "drive/"+saved.Path, path)
+					return bytes.NewReader(*content), nil
+				})
+				repos.blobs.EXPECT().GetByHash(mock.Anything, user.ID, sha256Hex(data)).Return(nil, pgx.ErrNoRows)
+				repos.driveFiles.EXPECT().Complete(mock.Anything, 20, int64(7), int64(7), sha256Hex(data), mock.Anything).Return(nil)
+			},
+			expectedOffset:    7,
+			expectedCompleted: true,
+		},
+		{
+			// отклоненный фрагмент удаляется, место освобождается
+			name:         "checksum mismatch",
+			expectedSize: 7,
+			checksum:     make([]byte, sha256.Size),
+			mockSetup: func(t *testing.T, repos *mockRepositories) {
+				saved, _ := expectTusSave(t, repos, user, 7)
+				repos.chunks.EXPECT().DeleteByID(mock.Anything, 31).Return(nil)
+				repos.usage.EXPECT().Add(mock.Anything, user.ID, int64(-7), int64(0)).Return(nil)
+				repos.storage.EXPECT().DeleteAll(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, keys []string) error {
+					assert.Equal(t, []string{"drive/" + saved.Path}, keys)
+					return nil
+				})
+			},
+			expectedErr: ucase.ErrDriveChecksumMismatch,
+		},
+		{
+			name:         "offset mismatch",
+			expectedSize: 10,
+			chunks:       []*entity.DriveFileChunk{existing},
+			expectedErr:  ucase.ErrDriveUploadOffsetMismatch,
+		},
+		{
+			name:         "longer than expected",
+			expectedSize: 5,
+			expectedErr:  ucase.ErrDriveFileSizeMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			expectedSize := tt.expectedSize
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).
+				Return(&entity.DriveStruct{ID: 10, UserID: user.ID, Name: "a.txt", Type: 1}, nil)
+			repos.driveFiles.EXPECT().GetByStructID(mock.Anything, 10).
+				Return(&entity.DriveFile{ID: 20, DriveStructID: 10, Ext: "txt", IsChunk: true, ExpectedSize: &expectedSize}, nil)
+			repos.chunks.EXPECT().GetByFileID(mock.Anything, 20).Return(tt.chunks, nil).Once()
+			if tt.mockSetup != nil {
+				tt.mockSetup(t, repos)
+			}
+
+			in := dto.DriveTusAppend{
+				StructID:              10,
+				Body:                  bytes.NewReader(data),
+				ContentLength:         int64(len(data)),
+				ChecksumAlgorithm:     "sha256",
+				Checksum:              tt.checksum,
+				MaxSizeBytes:          1 << 20,
+				StorageMaxSizePerUser: 1 << 30,
+				SavePath:              "drive",
+			}
+			upload, err := ucase.NewDriveUseCase(repos.repos).TusAppend(testContext(), user, in)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expectedOffset, upload.Offset)
+			assert.Equal(t, tt.expectedSize, upload.Length)
+			assert.Equal(t, tt.expectedCompleted, upload.Completed)
+		})
+	}
+}
+
+func TestDriveTusAppendQuotaUsesStoredSize(t *testing.T) {
+	user := &entity.User{ID: 1}
+	const contentLength = 1000
+
+	tests := []struct {
+		name          string
+		useEncryption bool
+		limit         int64
+	}{
+		// открытый текст укладывается в лимит, зашифрованный - нет
+		{name: "encrypted", useEncryption: true, limit: contentLength},
+		{name: "plaintext", limit: contentLength - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			expectedSize := int64(contentLength)
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).
+				Return(&entity.DriveStruct{ID: 10, UserID: user.ID, Name: "a.txt", Type: 1}, nil)
+			repos.driveFiles.EXPECT().GetByStructID(mock.Anything, 10).
+				Return(&entity.DriveFile{ID: 20, DriveStructID: 10, IsChunk: true, ExpectedSize: &expectedSize}, nil)
+			repos.chunks.EXPECT().GetByFileID(mock.Anything, 20).Return(nil, nil)
+			repos.quota.EXPECT().GetLimits(mock.Anything, user.ID).Return(nil, pgx.ErrNoRows)
+			repos.usage.EXPECT().Get(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID}, nil)
+
+			in := dto.DriveTusAppend{
+				StructID:              10,
+				Body:                  bytes.NewReader(make([]byte, contentLength)),
+				ContentLength:         contentLength,
+				MaxSizeBytes:          1 << 20,
+				StorageMaxSizePerUser: tt.limit,
+				SavePath:              "drive",
+				UseEncryption:         tt.useEncryption,
+			}
+			_, err := ucase.NewDriveUseCase(repos.repos).TusAppend(testContext(), user, in)
+			assert.ErrorIs(t, err, ucase.ErrDriveFileSystemIsFull)
+		})
+	}
+}
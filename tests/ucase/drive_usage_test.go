@@ -0,0 +1,196 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestStorageUsageCounters(t *testing.T) {
+	user := &entity.User{ID: 1}
+	note := []byte("%PDF-1.4 note attachment")
+	filePath := "1/a.bin"
+	freedBlobID := 40
+	sharedBlobID := 41
+
+	tests := []struct {
+		name      string
+		call      func(repos *mockRepositories) error
+		mockSetup func(repos *mockRepositories)
+	}{
+		{
+			// освобождаются чанки, файлы без блоба и блобы, на которые больше нет ссылок
+			name: "purge",
+			call: func(repos *mockRepositories) error {
+				_, err := ucase.NewDriveUseCase(repos.repos).PurgeTrash(testContext(), testSavePath, time.Hour)
+				return err
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.structs.EXPECT().GetTrashRootsBefore(mock.Anything, mock.Anything).
+					Return([]*entity.DriveStruct{{ID: 5, UserID: user.ID}}, nil)
+				repos.chunks.EXPECT().GetAllRecursive(mock.Anything, 5, user.ID).
+					Return([]*entity.DriveFileChunk{{ID: 30, Path: "1/part_1", Size: 3}, {ID: 31, Path: "1/part_2", Size: 3}}, nil)
+				repos.driveFiles.EXPECT().GetAllRecursive(mock.Anything, 5, user.ID).Return([]*entity.DriveFile{
+					{ID: 20, Ext: "bin", IsChunk: true},
+					{ID: 21, Ext: "bin", Path: &filePath, Size: 4},
+					{ID: 22, Ext: "bin", BlobID: &freedBlobID, Size: 5},
+					{ID: 23, Ext: "bin", BlobID: &freedBlobID, Size: 5},
+					{ID: 24, Ext: "bin", BlobID: &sharedBlobID, Size: 7},
+				}, nil)
+				repos.structs.EXPECT().DeleteRecursive(mock.Anything, user.ID, 5).Return(nil)
+				repos.blobs.EXPECT().DecrementRef(mock.Anything, freedBlobID, 2).
+					Return(&entity.DriveBlob{ID: freedBlobID, Path: "1/blob", Size: 5}, nil)
+				repos.blobs.EXPECT().Delete(mock.Anything, freedBlobID).Return(nil)
+				repos.blobs.EXPECT().DecrementRef(mock.Anything, sharedBlobID, 1).
+					Return(&entity.DriveBlob{ID: sharedBlobID, Size: 7, RefCount: 1}, nil)
+				repos.usage.EXPECT().Add(mock.Anything, user.ID, int64(-15), int64(0)).Return(nil)
+				repos.storage.EXPECT().DeleteAll(mock.Anything, mock.MatchedBy(func(keys []string) bool {
+					return assert.ObjectsAreEqual([]string{"drive/1/part_1", "drive/1/part_2", "drive/1/a.bin", "drive/1/blob"}, keys)
+				})).Return(nil)
+			},
+		},
+		{
+			// вложения заметок учитываются отдельно от диска
+			name: "note upload",
+			call: func(repos *mockRepositories) error {
+				_, err := ucase.NewFileUseCase(repos.repos).Upload(testContext(), dto.UploadFile{
+					File:             newMemoryFile(note),
+					OriginalFilename: "note.pdf",
+					MaxSizeBytes:     1 << 20,
+					StorageMaxSize:   1 << 20,
+					SavePath:         testSavePath,
+				}, user)
+				return err
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.quota.EXPECT().GetLimits(mock.Anything, user.ID).Return(nil, pgx.ErrNoRows)
+				repos.usage.EXPECT().Get(mock.Anything, user.ID).Return(nil, pgx.ErrNoRows)
+				repos.pending.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				repos.storage.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+				repos.usage.EXPECT().Lock(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID}, nil)
+				repos.usage.EXPECT().Add(mock.Anything, user.ID, int64(0), int64(len(note))).Return(nil)
+				repos.files.EXPECT().Create(mock.Anything, mock.Anything).Return(nil, nil)
+				repos.pending.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "note delete",
+			call: func(repos *mockRepositories) error {
+				return ucase.NewFileUseCase(repos.repos).DeleteByID(testContext(), 50, testSavePath)
+			},
+			mockSetup: func(repos *mockRepositories) {
+				repos.files.EXPECT().GetByID(mock.Anything, 50).
+					Return(&entity.File{ID: 50, UserID: user.ID, FilePath: "1/note.pdf", Ext: "pdf", Size: len(note)}, nil)
+				repos.storage.EXPECT().Delete(mock.Anything, "drive/1/note.pdf").Return(nil)
+				repos.files.EXPECT().DeleteByID(mock.Anything, 50).Return(nil)
+				repos.usage.EXPECT().Add(mock.Anything, user.ID, int64(0), -int64(len(note))).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			tt.mockSetup(repos)
+
+			assert.NoError(t, tt.call(repos))
+			assert.Equal(t, 1, repos.tx.commits)
+		})
+	}
+}
+
+func TestDriveChunkUploadQuotaAfterPrepare(t *testing.T) {
+	user := &entity.User{ID: 1}
+	driveLimit := int64(10)
+	repos := newMockRepositories(t)
+
+	// подготовка только предварительно проверяет квоту: загрузка первого чанка соседнего файла
+	// заняла место уже после проверки, и чанк не укладывается в лимит под блокировкой счетчика
+	repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(&entity.DriveStruct{ID: 10, UserID: user.ID}, nil)
+	repos.driveFiles.EXPECT().GetByStructID(mock.Anything, 10).
+		Return(&entity.DriveFile{ID: 20, IsChunk: true, UploadState: 0}, nil)
+	repos.driveFiles.EXPECT().CheckFileOwner(mock.Anything, 20, user.ID).Return(true, nil)
+	repos.chunks.EXPECT().GetChunksSize(mock.Anything, 20).Return(0, nil)
+	repos.quota.EXPECT().GetLimits(mock.Anything, user.ID).Return(&entity.StorageLimits{DriveLimit: &driveLimit}, nil)
+	repos.usage.EXPECT().Get(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID}, nil)
+	repos.pending.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	repos.storage.EXPECT().Save(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *dto.SaveFile) error {
+		_, err := io.Copy(io.Discard, in.File)
+		return err
+	})
+	repos.usage.EXPECT().Lock(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID, DriveUsed: 8}, nil)
+	repos.storage.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil)
+	repos.pending.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil)
+
+	err := ucase.NewDriveUseCase(repos.repos).ChunkUpload(testContext(), user, dto.DriveUploadChunk{
+		File:                  newMemoryFile([]byte("12345678")),
+		StructID:              10,
+		ChunkNumber:           1,
+		MaxSizeBytes:          1 << 20,
+		StorageMaxSizePerUser: 1 << 30,
+		SavePath:              testSavePath,
+	})
+	assert.ErrorIs(t, err, ucase.ErrDriveFileSystemIsFull)
+	assert.Equal(t, 1, repos.tx.rollbacks)
+}
+
+func TestStorageQuotaRecalculateUsage(t *testing.T) {
+	user := &entity.User{ID: 1, Login: "user"}
+	missing := "missing"
+
+	tests := []struct {
+		name          string
+		login         *string
+		mockSetup     func(repos *mockRepositories)
+		expectedCount int
+		expectedErr   error
+	}{
+		{
+			name: "all users",
+			mockSetup: func(repos *mockRepositories) {
+				repos.usage.EXPECT().Recalculate(mock.Anything, (*int)(nil)).Return(3, nil)
+			},
+			expectedCount: 3,
+		},
+		{
+			name:  "one user",
+			login: &user.Login,
+			mockSetup: func(repos *mockRepositories) {
+				repos.users.EXPECT().Find(mock.Anything, user.Login).Return(user, nil)
+				repos.usage.EXPECT().Recalculate(mock.Anything, &user.ID).Return(1, nil)
+			},
+			expectedCount: 1,
+		},
+		{
+			name:  "user not found",
+			login: &missing,
+			mockSetup: func(repos *mockRepositories) {
+				repos.users.EXPECT().Find(mock.Anything, missing).Return(nil, pgx.ErrNoRows)
+			},
+			expectedErr: ucase.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			tt.mockSetup(repos)
+
+			count, err := ucase.NewStorageQuotaUseCase(repos.repos).RecalculateUsage(testContext(), tt.login)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedCount, count)
+			}
+		})
+	}
+}
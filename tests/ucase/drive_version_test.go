@@ -0,0 +1,287 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+// expectFileStruct настраивает моки на файл 10 пользователя
+func expectFileStruct(repos *mockRepositories, user *entity.User) *entity.DriveStruct {
+	driveStruct := &entity.DriveStruct{ID: 10, UserID: user.ID, Name: "a.txt", Type: 1}
+	repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(driveStruct, nil)
+	return driveStruct
+}
+
+func TestDriveGetFileVersions(t *testing.T) {
+	user := &entity.User{ID: 1}
+	replacedAt := time.Now().UTC()
+	size := int64(2)
+
+	tests := []struct {
+		name        string
+		driveStruct *entity.DriveStruct
+		expectedErr error
+	}{
+		{name: "file", driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID, Name: "a.txt", Type: 1}},
+		{name: "directory", driveStruct: &entity.DriveStruct{ID: 10, UserID: user.ID, Name: "docs"}, expectedErr: ucase.ErrFileNotFound},
+		{name: "other user", driveStruct: &entity.DriveStruct{ID: 10, UserID: 2, Name: "a.txt", Type: 1}, expectedErr: ucase.ErrFileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(tt.driveStruct, nil)
+			if tt.expectedErr == nil {
+				repos.driveFiles.EXPECT().GetVersions(mock.Anything, 10).Return([]*entity.DriveFile{
+					{ID: 22, DriveStructID: 10, Size: 2, PlainSize: &size, IsCurrent: true, UploadState: 1},
+					{ID: 21, DriveStructID: 10, Size: 5, ReplacedAt: &replacedAt, UploadState: 1},
+				}, nil)
+			}
+
+			versions, err := ucase.NewDriveUseCase(repos.repos).GetFileVersions(testContext(), 10, false, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) || !assert.Len(t, versions, 2) {
+				return
+			}
+			assert.Equal(t, 22, versions[0].ID)
+			assert.True(t, versions[0].IsCurrent)
+			assert.Equal(t, int64(2), versions[0].Size)
+			assert.Equal(t, 21, versions[1].ID)
+			assert.Equal(t, int64(5), versions[1].Size)
+			assert.Equal(t, &replacedAt, versions[1].ReplacedAt)
+		})
+	}
+}
+
+func TestDriveRestoreFileVersion(t *testing.T) {
+	user := &entity.User{ID: 1}
+
+	tests := []struct {
+		name            string
+		version         *entity.DriveFile
+		expectedRestore bool
+		expectedErr     error
+	}{
+		{name: "previous version", version: &entity.DriveFile{ID: 21, DriveStructID: 10}, expectedRestore: true},
+		{name: "current version", version: &entity.DriveFile{ID: 21, DriveStructID: 10, IsCurrent: true}},
+		{name: "version of other file", version: &entity.DriveFile{ID: 21, DriveStructID: 11}, expectedErr: ucase.ErrFileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			driveStruct := expectFileStruct(repos, user)
+			repos.driveFiles.EXPECT().GetByID(mock.Anything, 21).Return(tt.version, nil)
+			if tt.expectedRestore {
+				repos.driveFiles.EXPECT().UnsetCurrent(mock.Anything, 10, mock.Anything).Return(nil)
+				repos.driveFiles.EXPECT().MarkCurrent(mock.Anything, 21).Return(nil)
+				repos.structs.EXPECT().Update(mock.Anything, driveStruct).Return(nil)
+			}
+
+			err := ucase.NewDriveUseCase(repos.repos).RestoreFileVersion(testContext(), 10, 21, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tt.expectedRestore {
+				assert.Equal(t, 1, repos.tx.commits)
+			}
+		})
+	}
+}
+
+func TestDrivePruneFileVersions(t *testing.T) {
+	user := &entity.User{ID: 1}
+	path := "1/v1"
+	blobID := 40
+
+	tests := []struct {
+		name         string
+		version      *entity.DriveFile
+		chunks       []*entity.DriveFileChunk
+		mockSetup    func(repos *mockRepositories)
+		expectedKeys []string
+		expectedFree int64
+	}{
+		{
+			name:         "stored version",
+			version:      &entity.DriveFile{ID: 21, DriveStructID: 10, Path: &path, Ext: "txt", Size: 3},
+			expectedKeys: []string{"drive/1/v1"},
+			expectedFree: 3,
+		},
+		{
+			name:    "chunked version",
+			version: &entity.DriveFile{ID: 21, DriveStructID: 10, Path: &path, Ext: "txt", IsChunk: true},
+			chunks: []*entity.DriveFileChunk{
+				{ID: 30, DriveFileID: 21, Path: "1/v1.1", Size: 4, ChunkNumber: 1},
+				{ID: 31, DriveFileID: 21, Path: "1/v1.2", Size: 2, ChunkNumber: 2},
+			},
+			expectedKeys: []string{"drive/1/v1.1", "drive/1/v1.2"},
+			expectedFree: 6,
+		},
+		{
+			name:    "blob version",
+			version: &entity.DriveFile{ID: 21, DriveStructID: 10, Ext: "txt", Size: 8, BlobID: &blobID},
+			mockSetup: func(repos *mockRepositories) {
+				repos.blobs.EXPECT().DecrementRef(mock.Anything, blobID, 1).
+					Return(&entity.DriveBlob{ID: blobID, UserID: user.ID, Path: "blobs/v1", Size: 8}, nil)
+				repos.blobs.EXPECT().Delete(mock.Anything, blobID).Return(nil)
+			},
+			expectedKeys: []string{"drive/blobs/v1"},
+			expectedFree: 8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			expectFileStruct(repos, user)
+			repos.driveFiles.EXPECT().GetOutdatedVersionIDs(mock.Anything, 10, 1).Return([]int{21}, nil)
+			repos.driveFiles.EXPECT().GetByID(mock.Anything, 21).Return(tt.version, nil)
+			repos.chunks.EXPECT().GetByFileID(mock.Anything, 21).Return(tt.chunks, nil)
+			if tt.mockSetup != nil {
+				tt.mockSetup(repos)
+			}
+			repos.driveFiles.EXPECT().DeleteByID(mock.Anything, 21).Return(nil)
+			repos.usage.EXPECT().Add(mock.Anything, user.ID, -tt.expectedFree, int64(0)).Return(nil)
+			repos.storage.EXPECT().DeleteAll(mock.Anything, tt.expectedKeys).Return(nil)
+
+			err := ucase.NewDriveUseCase(repos.repos).PruneFileVersions(testContext(), 10, 1, "drive", user)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, 1, repos.tx.commits)
+		})
+	}
+}
+
+func TestDrivePruneVersions(t *testing.T) {
+	path := "1/v1"
+
+	tests := []struct {
+		name          string
+		maxCount      int
+		maxAge        time.Duration
+		version       *entity.DriveFile
+		expectedCount int
+	}{
+		{name: "disabled"},
+		{
+			name:          "by count",
+			maxCount:      2,
+			version:       &entity.DriveFile{ID: 21, DriveStructID: 10, Path: &path, Ext: "txt", Size: 3},
+			expectedCount: 1,
+		},
+		{
+			name:          "by age",
+			maxAge:        24 * time.Hour,
+			version:       &entity.DriveFile{ID: 21, DriveStructID: 10, Path: &path, Ext: "txt", Size: 3},
+			expectedCount: 1,
+		},
+		{
+			// версия стала текущей после выборки и не удаляется
+			name:          "restored meanwhile",
+			maxCount:      2,
+			version:       &entity.DriveFile{ID: 21, DriveStructID: 10, Path: &path, Ext: "txt", Size: 3, IsCurrent: true},
+			expectedCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			if tt.version != nil {
+				repos.driveFiles.EXPECT().GetExpiredVersionIDs(mock.Anything, tt.maxCount, mock.Anything).
+					RunAndReturn(func(_ context.Context, _ int, replacedBefore *time.Time) ([]int, error) {
+						assert.Equal(t, tt.maxAge > 0, replacedBefore != nil)
+						return []int{21}, nil
+					})
+				repos.driveFiles.EXPECT().GetByID(mock.Anything, 21).Return(tt.version, nil)
+			}
+			if tt.version != nil && !tt.version.IsCurrent {
+				repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(&entity.DriveStruct{ID: 10, UserID: 1, Name: "a.txt", Type: 1}, nil)
+				repos.chunks.EXPECT().GetByFileID(mock.Anything, 21).Return(nil, nil)
+				repos.driveFiles.EXPECT().DeleteByID(mock.Anything, 21).Return(nil)
+				repos.usage.EXPECT().Add(mock.Anything, 1, int64(-3), int64(0)).Return(nil)
+				repos.storage.EXPECT().DeleteAll(mock.Anything, []string{"drive/1/v1"}).Return(nil)
+			}
+
+			count, err := ucase.NewDriveUseCase(repos.repos).PruneVersions(testContext(), "drive", tt.maxCount, tt.maxAge)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expectedCount, count)
+			}
+		})
+	}
+}
+
+func TestDriveUploadReplace(t *testing.T) {
+	user := &entity.User{ID: 1}
+	data := []byte("v2")
+	hash := "fb04dcb6970e4c3d1873de51fd5a50d7bb46b3383113602665c350ec40b5f990"
+	existing := &entity.DriveStruct{ID: 10, UserID: user.ID, Name: "a.txt", Type: 1}
+
+	tests := []struct {
+		name        string
+		replace     bool
+		expectedErr error
+	}{
+		// замена сохраняет структуру, прежний файл становится предыдущей версией
+		{name: "replace", replace: true},
+		{name: "name exists", replace: false, expectedErr: ucase.ErrDriveFilenameExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			// содержимое уже есть у пользователя, поэтому хранилище и квота не используются
+			repos.blobs.EXPECT().GetByHash(mock.Anything, user.ID, hash).
+				Return(&entity.DriveBlob{ID: 40, UserID: user.ID, SHA256: hash, Path: "blobs/v2", Size: 2, PlainSize: 2, RefCount: 1}, nil)
+			repos.structs.EXPECT().FindRow(mock.Anything, user.ID, "a.txt", int8(1), (*int)(nil)).Return(existing, nil)
+
+			var created *entity.DriveFile
+			if tt.expectedErr == nil {
+				repos.blobs.EXPECT().IncrementRef(mock.Anything, 40).Return(nil)
+				repos.driveFiles.EXPECT().UnsetCurrent(mock.Anything, 10, mock.Anything).Return(nil)
+				repos.structs.EXPECT().Update(mock.Anything, existing).Return(nil)
+				repos.driveFiles.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.DriveFile) (*entity.DriveFile, error) {
+					created = in
+					return in, nil
+				})
+				repos.pending.EXPECT().Delete(mock.Anything).Return(nil)
+				repos.structs.EXPECT().TreeByUserID(mock.Anything, user.ID, (*int)(nil)).Return(nil, nil)
+			}
+
+			_, err := ucase.NewDriveUseCase(repos.repos).UploadFile(testContext(), dto.DriveUploadFile{
+				File:                  newMemoryFile(data),
+				OriginalFilename:      "a.txt",
+				MaxSizeBytes:          1 << 20,
+				StorageMaxSizePerUser: 1 << 30,
+				SavePath:              "drive",
+				SHA256:                &hash,
+				Replace:               tt.replace,
+			}, user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, 10, created.DriveStructID)
+			assert.True(t, created.IsCurrent)
+			assert.Equal(t, 1, repos.tx.commits)
+		})
+	}
+}
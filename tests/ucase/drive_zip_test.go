@@ -0,0 +1,97 @@
+package ucase
+
+import (
+	"archive/zip"
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"bytes"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"testing"
+)
+
+func TestDriveGetZipAccess(t *testing.T) {
+	owner := &entity.User{ID: 1}
+	content := []byte("shared")
+	size := int64(len(content))
+	path := "1/a.txt"
+	parentID := 10
+
+	tests := []struct {
+		name        string
+		user        *entity.User
+		mockSetup   func(repos *mockRepositories)
+		expectedErr error
+	}{
+		{name: "owner", user: owner, mockSetup: func(repos *mockRepositories) {}},
+		{
+			name: "grantee",
+			user: &entity.User{ID: 2},
+			mockSetup: func(repos *mockRepositories) {
+				repos.grants.EXPECT().GetRole(mock.Anything, 10, 2).Return(ucase.DriveRoleViewer, nil)
+			},
+		},
+		{
+			name: "stranger",
+			user: &entity.User{ID: 3},
+			mockSetup: func(repos *mockRepositories) {
+				repos.grants.EXPECT().GetRole(mock.Anything, 10, 3).Return(0, pgx.ErrNoRows)
+			},
+			expectedErr: ucase.ErrDriveStructNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			root := &entity.DriveStruct{ID: 10, UserID: owner.ID, Name: "shared", Type: 0}
+			repos.structs.EXPECT().GetByID(mock.Anything, 10).Return(root, nil)
+			tt.mockSetup(repos)
+			if tt.expectedErr == nil {
+				repos.structs.EXPECT().GetAllRecursive(mock.Anything, owner.ID, 10).Return([]*entity.DriveStruct{
+					root,
+					{ID: 11, UserID: owner.ID, Name: "a.txt", Type: 1, ParentID: &parentID},
+				}, nil)
+				repos.driveFiles.EXPECT().GetAllRecursive(mock.Anything, 10, owner.ID).Return([]*entity.DriveFile{
+					{ID: 20, DriveStructID: 11, Path: &path, Size: size, PlainSize: &size, IsCurrent: true, UploadState: 1},
+				}, nil)
+				repos.chunks.EXPECT().GetAllRecursive(mock.Anything, 10, owner.ID).Return(nil, nil)
+				repos.storage.EXPECT().GetFile(mock.Anything, "drive/1/a.txt").Return(bytes.NewReader(content), nil)
+			}
+
+			in := dto.DriveZipIn{StructIDs: []int{10}, SavePath: "drive"}
+			response, err := ucase.NewDriveUseCase(repos.repos).GetZip(testContext(), in, tt.user)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "shared.zip", response.OriginalFilename)
+
+			archive, err := io.ReadAll(response.File)
+			if !assert.NoError(t, err) {
+				return
+			}
+			zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+			if !assert.NoError(t, err) || !assert.Len(t, zipReader.File, 2) {
+				return
+			}
+			assert.Equal(t, "shared/", zipReader.File[0].Name)
+			assert.Equal(t, "shared/a.txt", zipReader.File[1].Name)
+
+			file, err := zipReader.File[1].Open()
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer file.Close()
+			data, err := io.ReadAll(file)
+			assert.NoError(t, err)
+			assert.Equal(t, content, data)
+		})
+	}
+}
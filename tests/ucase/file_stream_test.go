@@ -0,0 +1,198 @@
+package ucase
+
+import (
+	service "assistant-go/internal/layer/service/file"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+const testEncryptionKey = "test-encryption-key"
+
+func encryptForTest(t *testing.T, plain []byte) []byte {
+	t.Helper()
+	fileService := service.NewFile().FileService()
+
+	reader, err := fileService.EncryptStream(bytes.NewReader(plain), testEncryptionKey)
+	if err != nil {
+		t.Fatalf("Unexpected encrypt error: %v", err)
+	}
+	encrypted, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Unexpected encrypt error: %v", err)
+	}
+	return encrypted
+}
+
+func randomBytes(t *testing.T, size int) []byte {
+	t.Helper()
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestEncryptStreamRoundTrip(t *testing.T) {
+	fileService := service.NewFile().FileService()
+	sizes := []int{0, 1, 100, 64<<10 - 1, 64 << 10, 64<<10 + 1, 3*(64<<10) + 5}
+
+	for _, size := range sizes {
+		t.Run(fmt.Sprintf("size=%d", size), func(t *testing.T) {
+			plain := randomBytes(t, size)
+			encrypted := encryptForTest(t, plain)
+
+			if int64(len(encrypted)) != fileService.EncryptedSize(int64(size)) {
+				t.Fatalf("Expected encrypted size %d, got %d", fileService.EncryptedSize(int64(size)), len(encrypted))
+			}
+
+			reader, err := fileService.DecryptStream(bytes.NewReader(encrypted), testEncryptionKey)
+			if err != nil {
+				t.Fatalf("Unexpected decrypt error: %v", err)
+			}
+			decrypted, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Unexpected decrypt error: %v", err)
+			}
+			if !bytes.Equal(plain, decrypted) {
+				t.Errorf("Decrypted data does not match source")
+			}
+		})
+	}
+}
+
+func TestDecryptStreamRange(t *testing.T) {
+	fileService := service.NewFile().FileService()
+	plain := randomBytes(t, 3*(64<<10)+5)
+	encrypted := encryptForTest(t, plain)
+
+	open := func(offset int64, length int64) (io.Reader, error) {
+		end := int64(len(encrypted))
+		if length >= 0 && offset+length < end {
+			end = offset + length
+		}
+		return bytes.NewReader(encrypted[offset:end]), nil
+	}
+
+	tests := []struct {
+		offset int64
+		length int64
+	}{
+		{offset: 0, length: 10},
+		{offset: 0, length: int64(len(plain))},
+		{offset: 64 << 10, length: 64 << 10},
+		{offset: 64<<10 - 3, length: 10},
+		{offset: int64(len(plain)) - 5, length: 5},
+		{offset: 100, length: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("offset=%d,length=%d", tt.offset, tt.length), func(t *testing.T) {
+			reader, err := fileService.DecryptStreamRange(open, testEncryptionKey, tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !bytes.Equal(plain[tt.offset:tt.offset+tt.length], result) {
+				t.Errorf("Range data does not match source")
+			}
+		})
+	}
+}
+
+func TestDecryptStreamDetectsTampering(t *testing.T) {
+	fileService := service.NewFile().FileService()
+	plain := randomBytes(t, 2*(64<<10)+10)
+	encrypted := encryptForTest(t, plain)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "modified byte", data: func() []byte {
+			data := bytes.Clone(encrypted)
+			data[len(data)/2] ^= 1
+			return data
+		}()},
+		{name: "truncated at segment boundary", data: encrypted[:len(encrypted)-10-16]},
+		{name: "truncated tail", data: encrypted[:len(encrypted)-1]},
+		{name: "wrong key", data: encrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := testEncryptionKey
+			if tt.name == "wrong key" {
+				key = "other-key"
+			}
+			reader, err := fileService.DecryptStream(bytes.NewReader(tt.data), key)
+			if err == nil {
+				_, err = io.ReadAll(reader)
+			}
+			if !errors.Is(err, service.ErrStreamCorrupted) {
+				t.Errorf("Expected %v, got %v", service.ErrStreamCorrupted, err)
+			}
+		})
+	}
+}
+
+func TestDecryptStreamLegacyFormat(t *testing.T) {
+	fileService := service.NewFile().FileService()
+	plain := randomBytes(t, 1000)
+
+	key := sha256.Sum256([]byte(testEncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := randomBytes(t, aesgcm.NonceSize())
+	legacy := append(nonce, aesgcm.Seal(nil, nonce, plain, nil)...)
+
+	reader, err := fileService.DecryptStream(bytes.NewReader(legacy), testEncryptionKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decrypted, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(plain, decrypted) {
+		t.Errorf("Decrypted data does not match source")
+	}
+
+	if fileService.DecryptedSize(int64(len(legacy))) != int64(len(plain)) {
+		t.Errorf("Expected legacy size %d, got %d", len(plain), fileService.DecryptedSize(int64(len(legacy))))
+	}
+
+	open := func(offset int64, length int64) (io.Reader, error) {
+		end := int64(len(legacy))
+		if length >= 0 && offset+length < end {
+			end = offset + length
+		}
+		return bytes.NewReader(legacy[offset:end]), nil
+	}
+	reader, err = fileService.DecryptStreamRange(open, testEncryptionKey, 10, 20)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	decrypted, err = io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(plain[10:30], decrypted) {
+		t.Errorf("Range data does not match source")
+	}
+}
@@ -0,0 +1,184 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/logging"
+	mocks "assistant-go/mocks/layer/repository"
+	"bytes"
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"maps"
+	"mime/multipart"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testSavePath = "drive"
+
+func testContext() context.Context {
+	return logging.ContextWithLogger(context.Background(), logging.NewLogger(logging.TestsEnv))
+}
+
+// memoryFile - multipart.File поверх байт
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (f *memoryFile) Close() error {
+	return nil
+}
+
+func newMemoryFile(data []byte) multipart.File {
+	return &memoryFile{Reader: bytes.NewReader(data)}
+}
+
+// mockRepositories - моки репозиториев. Репозитории транзакции - те же моки
+type mockRepositories struct {
+	users           *mocks.MockUserRepository
+	notes           *mocks.MockNoteRepository
+	noteCategories  *mocks.MockNoteCategoryRepository
+	noteShareHashes *mocks.MockNoteShareHashesRepository
+	files           *mocks.MockFileRepository
+	fileNoteLinks   *mocks.MockFileNoteLinkRepository
+	storage         *mocks.MockFileStorageRepository
+	transactions    *mocks.MockTransactionRepository
+	structs         *mocks.MockDriveStructRepository
+	driveFiles      *mocks.MockDriveFileRepository
+	chunks          *mocks.MockDriveFileChunkRepository
+	blobs           *mocks.MockDriveBlobRepository
+	shareLinks      *mocks.MockDriveShareLinkRepository
+	grants          *mocks.MockDriveGrantRepository
+	appTokens       *mocks.MockUserAppTokenRepository
+	quota           *mocks.MockStorageQuotaRepository
+	usage           *mocks.MockStorageUsageRepository
+	migrate         *mocks.MockStorageMigrateRepository
+	pending         *mocks.MockPendingObjectRepository
+	dataKeys        *mocks.MockDriveDataKeyRepository
+	tx              *testTx
+	repos           *repository.Repositories
+}
+
+// testTx - транзакция, которая только считает завершения
+type testTx struct {
+	pgx.Tx
+	commits   int
+	rollbacks int
+}
+
+func (tx *testTx) Commit(ctx context.Context) error {
+	tx.commits++
+	return nil
+}
+
+func (tx *testTx) Rollback(ctx context.Context) error {
+	tx.rollbacks++
+	return nil
+}
+
+func newMockRepositories(t *testing.T) *mockRepositories {
+	t.Helper()
+	m := &mockRepositories{
+		users:           mocks.NewMockUserRepository(t),
+		notes:           mocks.NewMockNoteRepository(t),
+		noteCategories:  mocks.NewMockNoteCategoryRepository(t),
+		noteShareHashes: mocks.NewMockNoteShareHashesRepository(t),
+		files:           mocks.NewMockFileRepository(t),
+		fileNoteLinks:   mocks.NewMockFileNoteLinkRepository(t),
+		storage:         mocks.NewMockFileStorageRepository(t),
+		transactions:    mocks.NewMockTransactionRepository(t),
+		structs:         mocks.NewMockDriveStructRepository(t),
+		driveFiles:      mocks.NewMockDriveFileRepository(t),
+		chunks:          mocks.NewMockDriveFileChunkRepository(t),
+		blobs:           mocks.NewMockDriveBlobRepository(t),
+		shareLinks:      mocks.NewMockDriveShareLinkRepository(t),
+		grants:          mocks.NewMockDriveGrantRepository(t),
+		appTokens:       mocks.NewMockUserAppTokenRepository(t),
+		quota:           mocks.NewMockStorageQuotaRepository(t),
+		usage:           mocks.NewMockStorageUsageRepository(t),
+		migrate:         mocks.NewMockStorageMigrateRepository(t),
+		pending:         mocks.NewMockPendingObjectRepository(t),
+		dataKeys:        mocks.NewMockDriveDataKeyRepository(t),
+		tx:              &testTx{},
+	}
+	m.repos = &repository.Repositories{
+		UserRepository:            m.users,
+		NoteRepository:            m.notes,
+		NoteCategoryRepository:    m.noteCategories,
+		FileRepository:            m.files,
+		StorageRepository:         m.storage,
+		FileNoteLinkRepository:    m.fileNoteLinks,
+		TransactionRepository:     m.transactions,
+		DriveStructRepository:     m.structs,
+		DriveFileRepository:       m.driveFiles,
+		DriveFileChunkRepository:  m.chunks,
+		DriveBlobRepository:       m.blobs,
+		DriveShareLinkRepository:  m.shareLinks,
+		DriveGrantRepository:      m.grants,
+		NoteShareHashesRepository: m.noteShareHashes,
+		UserAppTokenRepository:    m.appTokens,
+		StorageQuotaRepository:    m.quota,
+		StorageUsageRepository:    m.usage,
+		StorageMigrateRepository:  m.migrate,
+		PendingObjectRepository:   m.pending,
+		DriveDataKeyRepository:    m.dataKeys,
+	}
+
+	m.transactions.EXPECT().GetTransaction(mock.Anything).Return(m.tx, nil).Maybe()
+	m.transactions.EXPECT().Repositories(mock.Anything).RunAndReturn(func(pgx.Tx) *repository.Repositories {
+		repos := *m.repos
+		return &repos
+	}).Maybe()
+	return m
+}
+
+// expectMemoryStorage настраивает мок хранилища на работу с объектами objects: чтение, запись, копирование,
+// удаление и обход. Время изменения объектов не задается, поэтому все они считаются давними
+func expectMemoryStorage(storage *mocks.MockFileStorageRepository, objects map[string][]byte) {
+	storage.EXPECT().GetFile(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, key string) (io.Reader, error) {
+		data, ok := objects[key]
+		if !ok {
+			return nil, repository.ErrFileNotFoundInFilesystem
+		}
+		return bytes.NewReader(data), nil
+	}).Maybe()
+	storage.EXPECT().Save(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *dto.SaveFile) error {
+		data, err := io.ReadAll(in.File)
+		if err != nil {
+			return err
+		}
+		objects[in.SavePath] = data
+		return nil
+	}).Maybe()
+	storage.EXPECT().Delete(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, key string) error {
+		if _, ok := objects[key]; !ok {
+			return repository.ErrFileNotFoundInFilesystem
+		}
+		delete(objects, key)
+		return nil
+	}).Maybe()
+	storage.EXPECT().Copy(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, src string, dst string) error {
+		data, ok := objects[src]
+		if !ok {
+			return repository.ErrFileNotFoundInFilesystem
+		}
+		objects[dst] = bytes.Clone(data)
+		return nil
+	}).Maybe()
+	storage.EXPECT().List(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, prefix string, fn func(object *dto.StorageObjectInfo) error) error {
+			for _, key := range slices.Sorted(maps.Keys(objects)) {
+				if !strings.HasPrefix(key, prefix) {
+					continue
+				}
+				if err := fn(&dto.StorageObjectInfo{Key: key, Size: int64(len(objects[key]))}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	).Maybe()
+}
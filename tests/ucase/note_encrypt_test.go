@@ -0,0 +1,277 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/ucase"
+	"assistant-go/pkg/keyring"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"testing"
+)
+
+const testNoteBlocks = `[` +
+	`{"type":"paragraph","data":{"text":"Shopping <b>list</b>"}},` +
+	`{"type":"attaches","data":{"file":{"id":5}}},` +
+	`{"type":"image","data":{"file":{"id":6}}}` +
+	`]`
+
+func newTestKeyring(t *testing.T) *keyring.Keyring {
+	t.Helper()
+	kr, err := keyring.New("1", testEncryptionKey, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+// expectDataKey настраивает моки на активный ключ данных пользователя
+func expectDataKey(t *testing.T, repos *mockRepositories, kr *keyring.Keyring, userID int) *entity.DriveDataKey {
+	t.Helper()
+	key, err := keyring.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	masterKeyID, wrapped, err := kr.Wrap(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey := &entity.DriveDataKey{ID: 30, UserID: &userID, MasterKeyID: masterKeyID, WrappedKey: wrapped, IsActive: true}
+	repos.dataKeys.EXPECT().GetActive(mock.Anything, userID).Return(dataKey, nil).Maybe()
+	repos.dataKeys.EXPECT().GetByID(mock.Anything, dataKey.ID).Return(dataKey, nil).Maybe()
+	return dataKey
+}
+
+func TestNoteEncryptionRoundTrip(t *testing.T) {
+	user := &entity.User{ID: 1}
+	kr := newTestKeyring(t)
+
+	tests := []struct {
+		name          string
+		useEncryption bool
+	}{
+		{name: "encryption on", useEncryption: true},
+		{name: "encryption off", useEncryption: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			dataKey := expectDataKey(t, repos, kr, user.ID)
+			repos.noteCategories.EXPECT().FindByIDAndUser(mock.Anything, user.ID, 3).
+				Return(&entity.NoteCategory{ID: 3, UserId: user.ID}, nil)
+
+			var stored entity.Note
+			repos.notes.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in entity.Note) (*entity.Note, error) {
+				stored = in
+				stored.ID = 7
+				return &stored, nil
+			})
+			// вложения определяются по содержимому до шифрования
+			repos.fileNoteLinks.EXPECT().Upsert(mock.Anything, 7, []int{5, 6}).Return(nil)
+
+			noteUseCase := ucase.NewNoteUseCase(repos.repos, tt.useEncryption, kr)
+			created, err := noteUseCase.Create(testContext(), dto.NoteCreate{
+				CategoryID: 3,
+				NoteBlocks: json.RawMessage(testNoteBlocks),
+			}, user)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "Shopping list", *created.Title)
+
+			if tt.useEncryption {
+				assert.Equal(t, &dataKey.ID, stored.DataKeyID)
+				assert.Nil(t, stored.NoteBlocks)
+				assert.Nil(t, stored.Title)
+				assert.NotContains(t, string(stored.EncryptedBlocks), "Shopping")
+				assert.NotEmpty(t, stored.EncryptedTitle)
+			} else {
+				assert.Nil(t, stored.DataKeyID)
+				assert.JSONEq(t, testNoteBlocks, string(stored.NoteBlocks))
+				assert.Nil(t, stored.EncryptedBlocks)
+			}
+
+			repos.notes.EXPECT().GetById(mock.Anything, 7).RunAndReturn(func(context.Context, int) (*entity.Note, error) {
+				note := stored
+				return &note, nil
+			})
+			note, err := noteUseCase.GetOne(testContext(), dto.RequiredID{ID: 7}, user)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.JSONEq(t, testNoteBlocks, string(note.NoteBlocks))
+			assert.Equal(t, "Shopping list", *note.Title)
+			assert.Nil(t, note.EncryptedBlocks)
+		})
+	}
+}
+
+func TestNoteGetOneTampered(t *testing.T) {
+	user := &entity.User{ID: 1}
+	kr := newTestKeyring(t)
+	repos := newMockRepositories(t)
+	dataKey := expectDataKey(t, repos, kr, user.ID)
+
+	key, err := kr.Unwrap(dataKey.MasterKeyID, dataKey.WrappedKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := keyring.Seal(key, []byte(testNoteBlocks), []byte("notes.note_blocks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos.notes.EXPECT().GetById(mock.Anything, 7).Return(&entity.Note{
+		ID:              7,
+		CategoryID:      3,
+		EncryptedBlocks: flipByte(blocks, len(blocks)-1),
+		DataKeyID:       &dataKey.ID,
+	}, nil)
+	repos.noteCategories.EXPECT().FindByIDAndUser(mock.Anything, user.ID, 3).
+		Return(&entity.NoteCategory{ID: 3, UserId: user.ID}, nil)
+
+	_, err = ucase.NewNoteUseCase(repos.repos, true, kr).GetOne(testContext(), dto.RequiredID{ID: 7}, user)
+	assert.ErrorIs(t, err, ucase.ErrNoteDecrypting)
+}
+
+func TestNoteEncryptMigration(t *testing.T) {
+	kr := newTestKeyring(t)
+	title := "Shopping list"
+
+	tests := []struct {
+		name            string
+		replaced        bool
+		expectedNotes   int
+		expectedSkipped []string
+	}{
+		{name: "encrypted", replaced: true, expectedNotes: 1},
+		// заметка изменена во время шифрования, при сохранении она уже зашифрована
+		{name: "updated during migration", replaced: false, expectedSkipped: []string{"note 7"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			dataKey := expectDataKey(t, repos, kr, 1)
+			repos.notes.EXPECT().GetStale(mock.Anything, 0, mock.Anything).Return([]*entity.NoteWithOwner{{
+				Note:   entity.Note{ID: 7, CategoryID: 3, NoteBlocks: json.RawMessage(testNoteBlocks), Title: &title},
+				UserID: 1,
+			}}, nil)
+			repos.notes.EXPECT().GetStale(mock.Anything, 7, mock.Anything).Return(nil, nil)
+			repos.notes.EXPECT().ReplaceEncrypted(mock.Anything, mock.Anything, (*int)(nil)).
+				RunAndReturn(func(_ context.Context, in *entity.Note, _ *int) (bool, error) {
+					assert.Equal(t, &dataKey.ID, in.DataKeyID)
+					assert.Nil(t, in.NoteBlocks)
+					assert.NotEmpty(t, in.EncryptedBlocks)
+					return tt.replaced, nil
+				})
+			repos.dataKeys.EXPECT().DeleteUnused(mock.Anything).Return(0, nil)
+
+			var reports int
+			progress, err := ucase.NewNoteEncryptUseCase(repos.repos).Encrypt(testContext(), dto.NoteEncryptIn{
+				EncryptNotes: true,
+				Keyring:      kr,
+				OnProgress:   func(dto.NoteEncryptProgress) { reports++ },
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.expectedNotes, progress.Notes)
+			assert.Equal(t, tt.expectedSkipped, progress.Skipped)
+			assert.Equal(t, 1, reports)
+		})
+	}
+}
+
+func TestNoteEncryptDisabled(t *testing.T) {
+	repos := newMockRepositories(t)
+	_, err := ucase.NewNoteEncryptUseCase(repos.repos).Encrypt(testContext(), dto.NoteEncryptIn{Keyring: newTestKeyring(t)})
+	assert.ErrorIs(t, err, ucase.ErrNoteEncryptDisabled)
+}
+
+func TestFileUploadEncryption(t *testing.T) {
+	user := &entity.User{ID: 1}
+	kr := newTestKeyring(t)
+	data := append([]byte("\x89PNG\r\n\x1a\n"), randomBytes(t, 600)...)
+
+	tests := []struct {
+		name          string
+		useEncryption bool
+	}{
+		{name: "encryption on", useEncryption: true},
+		{name: "encryption off", useEncryption: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			dataKey := expectDataKey(t, repos, kr, user.ID)
+			repos.quota.EXPECT().GetLimits(mock.Anything, user.ID).Return(nil, pgx.ErrNoRows)
+			repos.usage.EXPECT().Get(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID}, nil)
+			repos.usage.EXPECT().Lock(mock.Anything, user.ID).Return(&entity.StorageUsage{UserID: user.ID}, nil)
+			repos.pending.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			repos.pending.EXPECT().Delete(mock.Anything, mock.Anything).Return(nil)
+
+			var storedData []byte
+			repos.storage.EXPECT().Save(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *dto.SaveFile) error {
+				var err error
+				storedData, err = io.ReadAll(in.File)
+				return err
+			})
+			var storedFile *entity.File
+			repos.files.EXPECT().Create(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *entity.File) (*entity.File, error) {
+				storedFile = in
+				return in, nil
+			})
+			repos.usage.EXPECT().Add(mock.Anything, user.ID, int64(0), mock.Anything).
+				RunAndReturn(func(_ context.Context, _ int, _ int64, filesSize int64) error {
+					assert.Equal(t, int64(len(storedData)), filesSize)
+					return nil
+				})
+
+			fileUseCase := ucase.NewFileUseCase(repos.repos)
+			_, err := fileUseCase.Upload(testContext(), dto.UploadFile{
+				File:             newMemoryFile(data),
+				OriginalFilename: "image.png",
+				MaxSizeBytes:     1 << 20,
+				StorageMaxSize:   1 << 30,
+				SavePath:         "files",
+				UseEncryption:    tt.useEncryption,
+				Keyring:          kr,
+			}, user)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, int64(len(data)), *storedFile.PlainSize)
+			assert.Equal(t, len(storedData), storedFile.Size)
+			if tt.useEncryption {
+				assert.Equal(t, &dataKey.ID, storedFile.DataKeyID)
+				assert.NotEqual(t, data, storedData)
+			} else {
+				assert.Nil(t, storedFile.DataKeyID)
+				assert.Equal(t, data, storedData)
+			}
+
+			// вложение читается независимо от текущей настройки шифрования
+			repos.files.EXPECT().GetByHash(mock.Anything, storedFile.Hash).Return(storedFile, nil)
+			repos.storage.EXPECT().GetFile(mock.Anything, "files/"+storedFile.FilePath).Return(bytes.NewReader(storedData), nil)
+			response, err := fileUseCase.GetFileByHash(testContext(), dto.GetFileByHash{
+				Hash:     storedFile.Hash,
+				SavePath: "files",
+				Keyring:  kr,
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			read, err := io.ReadAll(response.File)
+			assert.NoError(t, err)
+			assert.Equal(t, data, read)
+		})
+	}
+}
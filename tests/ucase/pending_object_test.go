@@ -0,0 +1,103 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/layer/ucase"
+	"assistant-go/internal/storage/postgres"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestPendingObjectReapAbandoned(t *testing.T) {
+	abandoned := []*entity.PendingObject{{StorageKey: "drive/1/1/a.txt"}, {StorageKey: "drive/1/1/b.txt"}}
+	errStorage := errors.New("storage is unavailable")
+
+	tests := []struct {
+		name           string
+		mockSetup      func(repos *mockRepositories)
+		expectedReaped int
+		expectedErr    error
+	}{
+		{
+			name: "nothing pending",
+			mockSetup: func(repos *mockRepositories) {
+				repos.pending.EXPECT().GetOlder(mock.Anything, mock.Anything, 500).Return(nil, nil)
+			},
+		},
+		{
+			// объект, удаленный до сбоя, тоже снимается с отметки
+			name: "removes abandoned",
+			mockSetup: func(repos *mockRepositories) {
+				repos.pending.EXPECT().GetOlder(mock.Anything, mock.Anything, 500).Return(abandoned, nil).Once()
+				repos.pending.EXPECT().GetOlder(mock.Anything, mock.Anything, 500).Return(nil, nil).Once()
+				repos.storage.EXPECT().Delete(mock.Anything, "drive/1/1/a.txt").Return(nil)
+				repos.storage.EXPECT().Delete(mock.Anything, "drive/1/1/b.txt").Return(repository.ErrFileNotFoundInFilesystem)
+				repos.pending.EXPECT().Delete(mock.Anything, "drive/1/1/a.txt", "drive/1/1/b.txt").Return(nil)
+			},
+			expectedReaped: 2,
+		},
+		{
+			// отметка объекта, который не удалось удалить, остается до следующего запуска
+			name: "partly removed",
+			mockSetup: func(repos *mockRepositories) {
+				repos.pending.EXPECT().GetOlder(mock.Anything, mock.Anything, 500).Return(abandoned, nil).Once()
+				repos.pending.EXPECT().GetOlder(mock.Anything, mock.Anything, 500).
+					Return(abandoned[1:], nil).Once()
+				repos.storage.EXPECT().Delete(mock.Anything, "drive/1/1/a.txt").Return(nil)
+				repos.storage.EXPECT().Delete(mock.Anything, "drive/1/1/b.txt").Return(errStorage)
+				repos.pending.EXPECT().Delete(mock.Anything, "drive/1/1/a.txt").Return(nil)
+				repos.pending.EXPECT().Delete(mock.Anything).Return(nil)
+			},
+			expectedReaped: 1,
+			expectedErr:    ucase.ErrStorageWriteAbortFailed,
+		},
+		{
+			name: "storage unavailable",
+			mockSetup: func(repos *mockRepositories) {
+				repos.pending.EXPECT().GetOlder(mock.Anything, mock.Anything, 500).Return(abandoned[:1], nil)
+				repos.storage.EXPECT().Delete(mock.Anything, "drive/1/1/a.txt").Return(errStorage)
+				repos.pending.EXPECT().Delete(mock.Anything).Return(nil)
+			},
+			expectedErr: ucase.ErrStorageWriteAbortFailed,
+		},
+		{
+			name: "db error",
+			mockSetup: func(repos *mockRepositories) {
+				repos.pending.EXPECT().GetOlder(mock.Anything, mock.Anything, 500).Return(nil, errors.New("db error"))
+			},
+			expectedErr: postgres.ErrUnexpectedDBError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			tt.mockSetup(repos)
+
+			reaped, err := ucase.NewPendingObjectUseCase(repos.repos).ReapAbandoned(testContext(), time.Hour)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedReaped, reaped)
+		})
+	}
+}
+
+func TestPendingObjectReapAbandonedTTL(t *testing.T) {
+	repos := newMockRepositories(t)
+
+	// удаляются только записи, начатые раньше срока
+	now := time.Now().UTC()
+	repos.pending.EXPECT().GetOlder(mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return !before.Before(now.Add(-time.Hour)) && before.Before(now.Add(-time.Hour+time.Minute))
+	}), 500).Return(nil, nil)
+
+	_, err := ucase.NewPendingObjectUseCase(repos.repos).ReapAbandoned(testContext(), time.Hour)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,168 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/layer/ucase"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+// expectFsckRecords настраивает моки на объекты objects, на которые ссылаются записи в БД, по таблицам
+func expectFsckRecords(repos *mockRepositories, objects map[string][]*entity.StorageObject) {
+	for _, kind := range repository.StorageObjectKinds {
+		lastID := 0
+		if list := objects[kind]; len(list) > 0 {
+			for _, object := range list {
+				object.Kind = kind
+			}
+			repos.migrate.EXPECT().GetObjects(mock.Anything, kind, 0, 500).Return(list, nil)
+			lastID = list[len(list)-1].ID
+		}
+		repos.migrate.EXPECT().GetObjects(mock.Anything, kind, lastID, 500).Return(nil, nil)
+	}
+}
+
+func fsckIssues(report *dto.StorageFsckReport) []string {
+	issues := make([]string, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		if issue.Key != "" {
+			issues = append(issues, issue.Problem+" "+issue.Key)
+		} else {
+			issues = append(issues, fmt.Sprintf("%s %s/%d", issue.Problem, issue.Kind, issue.ID))
+		}
+	}
+	return issues
+}
+
+func TestStorageFsck(t *testing.T) {
+	blobHash := sha256Hex([]byte("kept"))
+	missingHash := sha256Hex([]byte("missing"))
+	chunkedHash := sha256Hex([]byte("abcdef"))
+
+	tests := []struct {
+		name                string
+		in                  dto.StorageFsckIn
+		chunkedSize         int64
+		expectedIssues      []string
+		expectedQuarantined int
+		expectedKeys        []string
+	}{
+		{
+			// превью существующего файла и содержимое карантина не считаются лишними объектами
+			name:        "check",
+			in:          dto.StorageFsckIn{VerifyHash: true},
+			chunkedSize: 6,
+			expectedIssues: []string{
+				"size_mismatch files/1/note.pdf",
+				"orphan drive/1/stray.bin",
+				"orphan drive/thumbnails/drive/1/1/99_128.jpg",
+				"missing drive/1/a.txt",
+				// содержимое чанка подменено без изменения размера
+				"hash_mismatch drive_files/21",
+			},
+		},
+		{
+			name:        "without hash",
+			chunkedSize: 6,
+			expectedIssues: []string{
+				"size_mismatch files/1/note.pdf",
+				"orphan drive/1/stray.bin",
+				"orphan drive/thumbnails/drive/1/1/99_128.jpg",
+				"missing drive/1/a.txt",
+			},
+		},
+		{
+			name:        "chunks mismatch",
+			in:          dto.StorageFsckIn{VerifyHash: true},
+			chunkedSize: 7,
+			expectedIssues: []string{
+				"size_mismatch files/1/note.pdf",
+				"orphan drive/1/stray.bin",
+				"orphan drive/thumbnails/drive/1/1/99_128.jpg",
+				"missing drive/1/a.txt",
+				"chunks_mismatch drive_files/21",
+			},
+		},
+		{
+			// лишние объекты переносятся в карантин внутри каталога сохранения
+			name:        "repair",
+			in:          dto.StorageFsckIn{Repair: true},
+			chunkedSize: 6,
+			expectedIssues: []string{
+				"size_mismatch files/1/note.pdf",
+				"orphan drive/1/stray.bin",
+				"orphan drive/thumbnails/drive/1/1/99_128.jpg",
+				"missing drive/1/a.txt",
+			},
+			expectedQuarantined: 2,
+			expectedKeys: []string{
+				"drive/1/blob",
+				"drive/1/part_1",
+				"drive/1/part_2",
+				"drive/quarantine/1/old.bin",
+				"drive/quarantine/1/stray.bin",
+				"drive/quarantine/thumbnails/drive/1/1/99_128.jpg",
+				"drive/thumbnails/drive/1/1/20_128.jpg",
+				"files/1/note.pdf",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			objects := map[string][]byte{
+				"drive/1/blob":                          []byte("kept"),
+				"drive/1/part_1":                        []byte("abc"),
+				"drive/1/part_2":                        []byte("xyz"),
+				"drive/1/stray.bin":                     []byte("stray"),
+				"drive/quarantine/1/old.bin":            []byte("old"),
+				"drive/thumbnails/drive/1/1/20_128.jpg": []byte("jpeg"),
+				"drive/thumbnails/drive/1/1/99_128.jpg": []byte("jpeg"),
+				"files/1/note.pdf":                      []byte("note!"),
+			}
+			expectMemoryStorage(repos.storage, objects)
+			expectFsckRecords(repos, map[string][]*entity.StorageObject{
+				repository.StorageObjectDriveBlobs: {{ID: 1, Path: "1/blob", Size: 4, SHA256: &blobHash}},
+				repository.StorageObjectDriveFiles: {{ID: 20, Path: "1/a.txt", Size: 7, SHA256: &missingHash}},
+				repository.StorageObjectDriveChunks: {
+					{ID: 30, Path: "1/part_1", Size: 3},
+					{ID: 31, Path: "1/part_2", Size: 3},
+				},
+				repository.StorageObjectFiles: {{ID: 40, Path: "1/note.pdf", Size: 4}},
+			})
+			repos.driveFiles.EXPECT().GetByID(mock.Anything, 20).Return(&entity.DriveFile{ID: 20}, nil)
+			repos.driveFiles.EXPECT().GetByID(mock.Anything, 99).Return(nil, pgx.ErrNoRows)
+			repos.driveFiles.EXPECT().GetCompleteChunkedFiles(mock.Anything, 0, 500).
+				Return([]*entity.DriveFile{{ID: 21, Size: tt.chunkedSize, IsChunk: true, SHA256: &chunkedHash}}, nil)
+			repos.driveFiles.EXPECT().GetCompleteChunkedFiles(mock.Anything, 21, 500).Return(nil, nil)
+			repos.chunks.EXPECT().GetByFileID(mock.Anything, 21).Return([]*entity.DriveFileChunk{
+				{ID: 30, DriveFileID: 21, Path: "1/part_1", Size: 3, ChunkNumber: 1},
+				{ID: 31, DriveFileID: 21, Path: "1/part_2", Size: 3, ChunkNumber: 2},
+			}, nil)
+
+			in := tt.in
+			in.DriveSavePath, in.FilesSavePath = "drive", "files"
+			report, err := ucase.NewStorageFsckUseCase(repos.repos).Check(testContext(), in)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, 5, report.Records)
+			assert.Equal(t, 7, report.Objects)
+			assert.ElementsMatch(t, tt.expectedIssues, fsckIssues(report))
+			assert.Equal(t, tt.expectedQuarantined, report.Quarantined)
+			if tt.expectedKeys != nil {
+				keys := make([]string, 0, len(objects))
+				for key := range objects {
+					keys = append(keys, key)
+				}
+				assert.ElementsMatch(t, tt.expectedKeys, keys)
+			}
+		})
+	}
+}
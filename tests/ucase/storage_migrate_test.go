@@ -0,0 +1,224 @@
+package ucase
+
+import (
+	"assistant-go/internal/layer/dto"
+	"assistant-go/internal/layer/entity"
+	"assistant-go/internal/layer/repository"
+	"assistant-go/internal/layer/ucase"
+	mocks "assistant-go/mocks/layer/repository"
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"testing"
+)
+
+// expectMigrateObjects настраивает моки на объекты objects по таблицам, прогресс переноса lastIDs
+// и сохранение прогресса после каждого объекта
+func expectMigrateObjects(repos *mockRepositories, objects map[string][]*entity.StorageObject, lastIDs map[string]int) {
+	repos.migrate.EXPECT().GetProgress(mock.Anything, "local", "s3").Return(lastIDs, nil)
+	for _, kind := range repository.StorageObjectKinds {
+		list := objects[kind]
+		repos.migrate.EXPECT().CountObjects(mock.Anything, kind, lastIDs[kind]).Return(len(list), nil)
+		lastID := lastIDs[kind]
+		if len(list) > 0 {
+			repos.migrate.EXPECT().GetObjects(mock.Anything, kind, lastID, 500).Return(list, nil)
+			lastID = list[len(list)-1].ID
+		}
+		repos.migrate.EXPECT().GetObjects(mock.Anything, kind, lastID, 500).Return(nil, nil)
+		for _, object := range list {
+			repos.migrate.EXPECT().SaveProgress(mock.Anything, "local", "s3", kind, object.ID, mock.Anything).Return(nil)
+		}
+	}
+}
+
+func TestStorageMigrate(t *testing.T) {
+	// блоб, два чанка и вложение заметки
+	objects := map[string][]*entity.StorageObject{
+		repository.StorageObjectDriveBlobs: {{ID: 1, Path: "1/blob", Size: 4}},
+		repository.StorageObjectDriveChunks: {
+			{ID: 2, Path: "1/part_1", Size: 3},
+			{ID: 3, Path: "1/part_2", Size: 3},
+		},
+		repository.StorageObjectFiles: {{ID: 4, Path: "1/note.pdf", Size: 4}},
+	}
+	sourceObjects := func() map[string][]byte {
+		return map[string][]byte{
+			"drive/1/blob":     []byte("blob"),
+			"drive/1/part_1":   []byte("abc"),
+			"drive/1/part_2":   []byte("def"),
+			"files/1/note.pdf": []byte("note"),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		in             dto.StorageMigrateIn
+		lastIDs        map[string]int
+		source         map[string][]byte
+		target         map[string][]byte
+		expected       *dto.StorageMigrateProgress
+		expectedSource map[string][]byte
+		expectedTarget map[string][]byte
+	}{
+		{
+			name:           "copies",
+			source:         sourceObjects(),
+			target:         map[string][]byte{},
+			expected:       &dto.StorageMigrateProgress{Total: 4, Checked: 4, Copied: 4, CopiedBytes: 14},
+			expectedSource: sourceObjects(),
+			expectedTarget: sourceObjects(),
+		},
+		{
+			// прогресс сохранен: перенесенные объекты не выбираются повторно
+			name: "continues",
+			lastIDs: map[string]int{
+				repository.StorageObjectDriveBlobs:  1,
+				repository.StorageObjectDriveChunks: 3,
+				repository.StorageObjectFiles:       4,
+			},
+			source:         sourceObjects(),
+			target:         map[string][]byte{},
+			expected:       &dto.StorageMigrateProgress{},
+			expectedSource: sourceObjects(),
+			expectedTarget: map[string][]byte{},
+		},
+		{
+			// при перезапуске совпадающие копии не копируются, а отсутствующие в обоих хранилищах объекты перечисляются
+			name: "restart with deleting source",
+			in:   dto.StorageMigrateIn{Restart: true, DeleteSource: true},
+			source: map[string][]byte{
+				"drive/1/blob":     []byte("blob"),
+				"drive/1/part_1":   []byte("abc"),
+				"files/1/note.pdf": []byte("note"),
+			},
+			target: map[string][]byte{
+				"drive/1/blob":   []byte("blob"),
+				"drive/1/part_1": []byte("abc"),
+			},
+			expected: &dto.StorageMigrateProgress{
+				Total:       4,
+				Checked:     4,
+				Copied:      1,
+				Skipped:     2,
+				Deleted:     3,
+				CopiedBytes: 4,
+				Missing:     []string{"drive/1/part_2"},
+			},
+			expectedSource: map[string][]byte{},
+			expectedTarget: map[string][]byte{
+				"drive/1/blob":     []byte("blob"),
+				"drive/1/part_1":   []byte("abc"),
+				"files/1/note.pdf": []byte("note"),
+			},
+		},
+		{
+			// в целевом хранилище уже лежит другая копия: она перезаписывается и проверяется заново
+			name:           "replaces stale copy",
+			source:         sourceObjects(),
+			target:         map[string][]byte{"drive/1/blob": []byte("stale")},
+			expected:       &dto.StorageMigrateProgress{Total: 4, Checked: 4, Copied: 4, CopiedBytes: 14},
+			expectedSource: sourceObjects(),
+			expectedTarget: sourceObjects(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			target := mocks.NewMockFileStorageRepository(t)
+			expectMemoryStorage(repos.storage, tt.source)
+			expectMemoryStorage(target, tt.target)
+			if tt.in.Restart {
+				repos.migrate.EXPECT().ResetProgress(mock.Anything, "local", "s3").Return(nil)
+			}
+			lastIDs := tt.lastIDs
+			if lastIDs == nil {
+				lastIDs = map[string]int{}
+			}
+			remaining := make(map[string][]*entity.StorageObject)
+			for kind, list := range objects {
+				for _, object := range list {
+					if object.ID > lastIDs[kind] {
+						remaining[kind] = append(remaining[kind], object)
+					}
+				}
+			}
+			expectMigrateObjects(repos, remaining, lastIDs)
+
+			in := tt.in
+			in.From, in.To = "local", "s3"
+			in.DriveSavePath, in.FilesSavePath = "drive", "files"
+			uc := ucase.NewStorageMigrateUseCase(repos.repos, map[string]repository.FileStorageRepository{
+				"local": repos.storage,
+				"s3":    target,
+			})
+			progress, err := uc.Migrate(testContext(), in)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, progress)
+				assert.Equal(t, tt.expectedSource, tt.source)
+				assert.Equal(t, tt.expectedTarget, tt.target)
+			}
+		})
+	}
+}
+
+func TestStorageMigrateInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          dto.StorageMigrateIn
+		expectedErr error
+	}{
+		{name: "same place", in: dto.StorageMigrateIn{From: "local", To: "local"}, expectedErr: ucase.ErrStorageMigrateSamePlace},
+		{name: "unknown place", in: dto.StorageMigrateIn{From: "local", To: "ftp"}, expectedErr: ucase.ErrStorageMigrateUnknownPlace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := newMockRepositories(t)
+			uc := ucase.NewStorageMigrateUseCase(repos.repos, map[string]repository.FileStorageRepository{
+				"local": repos.storage,
+				"s3":    mocks.NewMockFileStorageRepository(t),
+			})
+			_, err := uc.Migrate(testContext(), tt.in)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestStorageMigrateVerifyFailed(t *testing.T) {
+	repos := newMockRepositories(t)
+	target := mocks.NewMockFileStorageRepository(t)
+	expectMemoryStorage(repos.storage, map[string][]byte{"drive/1/blob": []byte("blob")})
+	repos.migrate.EXPECT().GetProgress(mock.Anything, "local", "s3").Return(map[string]int{}, nil)
+	for _, kind := range repository.StorageObjectKinds {
+		count := 0
+		if kind == repository.StorageObjectDriveBlobs {
+			count = 1
+		}
+		repos.migrate.EXPECT().CountObjects(mock.Anything, kind, 0).Return(count, nil)
+	}
+	repos.migrate.EXPECT().GetObjects(mock.Anything, repository.StorageObjectDriveBlobs, 0, 500).
+		Return([]*entity.StorageObject{{ID: 1, Path: "1/blob", Size: 4}}, nil)
+
+	// целевое хранилище сохранило не все байты: прогресс не сохраняется, перенос останавливается
+	stored := make(map[string][]byte)
+	target.EXPECT().GetFile(mock.Anything, "drive/1/blob").Return(nil, repository.ErrFileNotFoundInFilesystem).Once()
+	target.EXPECT().Save(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, in *dto.SaveFile) error {
+		buf := make([]byte, 3)
+		_, err := in.File.Read(buf)
+		stored[in.SavePath] = buf
+		return err
+	})
+	target.EXPECT().GetFile(mock.Anything, "drive/1/blob").RunAndReturn(func(_ context.Context, key string) (io.Reader, error) {
+		return bytes.NewReader(stored[key]), nil
+	})
+
+	uc := ucase.NewStorageMigrateUseCase(repos.repos, map[string]repository.FileStorageRepository{
+		"local": repos.storage,
+		"s3":    target,
+	})
+	_, err := uc.Migrate(testContext(), dto.StorageMigrateIn{From: "local", To: "s3", DriveSavePath: "drive"})
+	assert.ErrorIs(t, err, ucase.ErrStorageMigrateVerifyFailed)
+}